@@ -17,7 +17,7 @@ import (
 
 // @title Bike Microservice API
 // @version 1.1
-// @description API для управления байками
+// @description API для управления байками. Ошибки тела запроса: 400 — JSON не разбирается, 422 — JSON корректный, но значения полей недопустимы (валидация или бизнес-правила)
 
 // @host localhost:8081
 // @BasePath /
@@ -25,12 +25,19 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	// Loading environment
 	cfg, err := config.New()
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Config validation failed: %v", err)
+	}
 
 	// Create app
 	ctx := context.Background()
@@ -39,12 +46,27 @@ func main() {
 		log.Fatalf("Failed to create app: %v", err)
 	}
 
-	application.Run()
+	// SIGHUP перечитывает API-ключи без рестарта
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			application.ReloadAPIKeys()
+		}
+	}()
+
+	if err := application.Run(); err != nil {
+		log.Fatalf("Failed to start app: %v", err)
+	}
 
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
-	<-stop
+	select {
+	case <-stop:
+	case err := <-application.ServeErr():
+		log.Printf("HTTP server failed: %v", err)
+	}
 
 	// Создаём контекст с таймаутом для shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
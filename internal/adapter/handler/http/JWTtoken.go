@@ -2,6 +2,9 @@ package http
 
 import (
 	"errors"
+	"fmt"
+	"time"
+
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 
@@ -9,27 +12,86 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrTokenExpired - подпись верна, но exp уже прошёл (с учётом leeway)
+	ErrTokenExpired = errors.New("token expired")
+	// ErrInvalidToken - токен не прошёл проверку подписи или claims при отзыве
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenRevoked - токен отозван до истечения срока
+	ErrTokenRevoked = errors.New("token revoked")
+	// ErrRevocationUnavailable - кеш с отозванными токенами недоступен,
+	// а TOKEN_REVOCATION_FAIL_CLOSED требует в таком случае отказывать
+	ErrRevocationUnavailable = errors.New("token revocation check unavailable")
+)
+
+func revokedTokenCacheKey(tokenID string) string {
+	return fmt.Sprintf("revoked_token:%s", tokenID)
+}
+
 type JWTTokenService struct {
-	secretKey []byte
-	logger    ports.LoggerPort
+	// первый ключ текущий, остальные старые - живут, пока идёт ротация
+	secretKeys [][]byte
+	algorithms []string
+	// leeway - допуск на расхождение часов с сервисом, выпустившим токен
+	leeway time.Duration
+	// cache хранит отозванные токены до их exp. Должен возвращать ошибку, когда
+	// хранилище недоступно: ErrCacheMiss считается "не отозван"
+	cache ports.CachePort
+	// failClosed - при недоступном кеше отказывать, а не пропускать
+	failClosed bool
+	logger     ports.LoggerPort
 }
 
-func NewJWTTokenService(secretKey string, logger ports.LoggerPort) *JWTTokenService {
+func NewJWTTokenService(secretKeys []string, algorithms []string, leeway time.Duration, cache ports.CachePort, failClosed bool, logger ports.LoggerPort) *JWTTokenService {
+	keys := make([][]byte, len(secretKeys))
+	for i, k := range secretKeys {
+		keys[i] = []byte(k)
+	}
 	return &JWTTokenService{
-		secretKey: []byte(secretKey),
-		logger:    logger,
+		secretKeys: keys,
+		algorithms: algorithms,
+		leeway:     leeway,
+		cache:      cache,
+		failClosed: failClosed,
+		logger:     logger,
 	}
 }
 
-// проверка жвт
-func (j *JWTTokenService) VerifyToken(token string) (*domain.TokenPayload, error) {
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return j.secretKey, nil
-	})
+// parse проверяет подпись и стандартные claims. Пробуем ключи по порядку,
+// чтобы токены, подписанные предыдущим секретом, не отвалились посреди ротации
+func (j *JWTTokenService) parse(token, method string) (jwt.MapClaims, error) {
+	var (
+		parsedToken *jwt.Token
+		err         error
+	)
+	for i, key := range j.secretKeys {
+		parsedToken, err = jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+			// секрет годится только для HMAC: не даём подсунуть "none" или
+			// асимметричный алгоритм, даже если список в конфиге расширят
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
+			return key, nil
+		}, jwt.WithValidMethods(j.algorithms), jwt.WithExpirationRequired(), jwt.WithIssuedAt(), jwt.WithLeeway(j.leeway))
+		// подпись не сошлась - пробуем следующий ключ, остальные ошибки от ключа не зависят
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			continue
+		}
+		if err == nil && i > 0 {
+			j.logger.Info("Token verified with previous secret", map[string]interface{}{
+				"key_index": i,
+				"method":    method,
+			})
+		}
+		break
+	}
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrTokenExpired
+	}
 	if err != nil {
 		j.logger.Error("Failed to parse jwt", map[string]interface{}{
 			"error":  err.Error(),
-			"method": "VerifyToken",
+			"method": method,
 		})
 		return nil, err
 	}
@@ -37,10 +99,30 @@ func (j *JWTTokenService) VerifyToken(token string) (*domain.TokenPayload, error
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok {
 		j.logger.Error("Failed claims from token", map[string]interface{}{
-			"method": "VerifyToken",
+			"method": method,
 		})
 		return nil, errors.New("failed to verify")
 	}
+	return claims, nil
+}
+
+// tokenID - jti, а у токенов без него claim id
+func tokenID(claims jwt.MapClaims) (string, error) {
+	if jti, ok := claims["jti"].(string); ok && jti != "" {
+		return jti, nil
+	}
+	if id, ok := claims["id"].(string); ok && id != "" {
+		return id, nil
+	}
+	return "", errors.New("token has neither jti nor id claim")
+}
+
+// проверка жвт: подпись, claims и отзыв
+func (j *JWTTokenService) VerifyToken(token string) (*domain.TokenPayload, error) {
+	claims, err := j.parse(token, "VerifyToken")
+	if err != nil {
+		return nil, err
+	}
 
 	idStr, ok := claims["id"].(string)
 	if !ok {
@@ -74,11 +156,70 @@ func (j *JWTTokenService) VerifyToken(token string) (*domain.TokenPayload, error
 		return nil, errors.New("invalid role value")
 	}
 
+	jti, err := tokenID(claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.checkRevoked(jti); err != nil {
+		return nil, err
+	}
+
 	payload := &domain.TokenPayload{
-		ID:     id,
-		UserID: userID,
-		Role:   role,
+		ID:      id,
+		TokenID: jti,
+		UserID:  userID,
+		Role:    role,
 	}
 
 	return payload, nil
 }
+
+// checkRevoked ищет токен в списке отозванных. Недоступный кеш по умолчанию
+// пропускает токен, чтобы сбой redis не разлогинил всех
+func (j *JWTTokenService) checkRevoked(jti string) error {
+	_, err := j.cache.Get(revokedTokenCacheKey(jti))
+	if err == nil {
+		return ErrTokenRevoked
+	}
+	if errors.Is(err, ports.ErrCacheMiss) {
+		return nil
+	}
+
+	j.logger.Warn("Token revocation check failed", map[string]interface{}{
+		"error":       err.Error(),
+		"fail_closed": j.failClosed,
+		"method":      "VerifyToken",
+	})
+	if j.failClosed {
+		return ErrRevocationUnavailable
+	}
+	return nil
+}
+
+// RevokeToken отзывает токен до его exp: ключ в кеше живёт ровно столько,
+// сколько токен ещё был бы действителен. Возвращает ID токена и его exp
+func (j *JWTTokenService) RevokeToken(token string) (string, time.Time, error) {
+	claims, err := j.parse(token, "RevokeToken")
+	if errors.Is(err, ErrTokenExpired) {
+		return "", time.Time{}, err
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	jti, err := tokenID(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	// exp обязателен и уже проверен в parse
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return "", time.Time{}, fmt.Errorf("%w: token has no exp claim", ErrInvalidToken)
+	}
+
+	// с запасом на leeway: пока токен принимается, он должен числиться отозванным
+	ttl := time.Until(exp.Time) + j.leeway
+	if err := j.cache.Set(revokedTokenCacheKey(jti), []byte("1"), ttl); err != nil {
+		return "", time.Time{}, err
+	}
+	return jti, exp.Time, nil
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/redis"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// testClaims - валидные claims пользователя, exp через час
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"id":      uuid.NewString(),
+		"user_id": uuid.NewString(),
+		"role":    string(domain.AppUser),
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func newTestTokenService(secrets []string, leeway time.Duration) (*JWTTokenService, *portstest.Logger) {
+	logger := &portstest.Logger{}
+	return NewJWTTokenService(secrets, []string{"HS256"}, leeway, portstest.NewCache(), false, logger), logger
+}
+
+func TestVerifyTokenKeyRotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		signer  string
+		wantErr bool
+		// wantRotationLog - токен принят старым ключом
+		wantRotationLog bool
+	}{
+		{name: "один секрет", secrets: []string{"current"}, signer: "current"},
+		{name: "текущий ключ", secrets: []string{"current", "previous"}, signer: "current"},
+		{name: "предыдущий ключ", secrets: []string{"current", "previous"}, signer: "previous", wantRotationLog: true},
+		{name: "третий ключ", secrets: []string{"current", "previous", "oldest"}, signer: "oldest", wantRotationLog: true},
+		{name: "ключ убран из ротации", secrets: []string{"current"}, signer: "previous", wantErr: true},
+		{name: "чужой ключ", secrets: []string{"current", "previous"}, signer: "attacker", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, logger := newTestTokenService(tt.secrets, 0)
+			claims := testClaims()
+
+			payload, err := tokens.VerifyToken(signTestToken(t, tt.signer, claims))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			if err == nil && payload.UserID.String() != claims["user_id"] {
+				t.Errorf("user_id = %s, want %s", payload.UserID, claims["user_id"])
+			}
+			if _, logged := logger.Find("Token verified with previous secret"); logged != tt.wantRotationLog {
+				t.Errorf("rotation logged = %t, want %t", logged, tt.wantRotationLog)
+			}
+		})
+	}
+}
+
+// часы сервиса авторизации могут расходиться с нашими: в пределах leeway
+// токен принимается, за ним - нет
+func TestVerifyTokenLeeway(t *testing.T) {
+	tests := []struct {
+		name    string
+		leeway  time.Duration
+		claims  jwt.MapClaims
+		wantErr bool
+		// wantExpired - ошибка именно ErrTokenExpired, клиент пойдёт обновлять токен
+		wantExpired bool
+	}{
+		{name: "exp истёк в пределах допуска", leeway: 30 * time.Second, claims: jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()}},
+		{name: "exp истёк за пределами допуска", leeway: 30 * time.Second, claims: jwt.MapClaims{"exp": time.Now().Add(-40 * time.Second).Unix()}, wantErr: true, wantExpired: true},
+		{name: "exp истёк без допуска", claims: jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()}, wantErr: true, wantExpired: true},
+		{name: "iat в будущем в пределах допуска", leeway: 30 * time.Second, claims: jwt.MapClaims{"iat": time.Now().Add(10 * time.Second).Unix()}},
+		{name: "iat в будущем за пределами допуска", leeway: 30 * time.Second, claims: jwt.MapClaims{"iat": time.Now().Add(40 * time.Second).Unix()}, wantErr: true},
+		{name: "nbf в будущем в пределах допуска", leeway: 30 * time.Second, claims: jwt.MapClaims{"nbf": time.Now().Add(10 * time.Second).Unix()}},
+		{name: "nbf в будущем за пределами допуска", leeway: 30 * time.Second, claims: jwt.MapClaims{"nbf": time.Now().Add(40 * time.Second).Unix()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, _ := newTestTokenService([]string{testJWTSecret}, tt.leeway)
+			claims := testClaims()
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+
+			_, err := tokens.VerifyToken(signTestToken(t, testJWTSecret, claims))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			if expired := errors.Is(err, ErrTokenExpired); expired != tt.wantExpired {
+				t.Errorf("err = %v, want ErrTokenExpired: %t", err, tt.wantExpired)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenExpirationAndSigningMethod(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withClaims := func(set jwt.MapClaims, drop ...string) jwt.MapClaims {
+		claims := testClaims()
+		for k, v := range set {
+			claims[k] = v
+		}
+		for _, k := range drop {
+			delete(claims, k)
+		}
+		return claims
+	}
+	sign := func(method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("sign %s: %v", method.Alg(), err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name        string
+		token       func() string
+		wantErr     bool
+		wantExpired bool
+	}{
+		{name: "действующий HS256", token: func() string { return sign(jwt.SigningMethodHS256, []byte(testJWTSecret), testClaims()) }},
+		{name: "истёкший", token: func() string {
+			return sign(jwt.SigningMethodHS256, []byte(testJWTSecret), withClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))
+		}, wantErr: true, wantExpired: true},
+		{name: "без exp", token: func() string {
+			return sign(jwt.SigningMethodHS256, []byte(testJWTSecret), withClaims(nil, "exp"))
+		}, wantErr: true},
+		{name: "подписан RS256", token: func() string { return sign(jwt.SigningMethodRS256, rsaKey, testClaims()) }, wantErr: true},
+		{name: "alg none", token: func() string { return sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testClaims()) }, wantErr: true},
+		// HMAC, но не из разрешённого списка
+		{name: "подписан HS512", token: func() string { return sign(jwt.SigningMethodHS512, []byte(testJWTSecret), testClaims()) }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, _ := newTestTokenService([]string{testJWTSecret}, 0)
+			_, err := tokens.VerifyToken(tt.token())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			if expired := errors.Is(err, ErrTokenExpired); expired != tt.wantExpired {
+				t.Errorf("err = %v, want ErrTokenExpired: %t", err, tt.wantExpired)
+			}
+		})
+	}
+}
+
+// middleware отличает истёкший токен от поддельного, чтобы клиент знал, что его надо обновить
+func TestAuthMiddlewareExpiredToken(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+
+	tests := []struct {
+		name      string
+		token     string
+		wantError string
+	}{
+		{name: "истёкший", token: signTestToken(t, testJWTSecret, jwt.MapClaims{
+			"id": uuid.NewString(), "user_id": owner.String(), "role": string(domain.AppUser),
+			"iat": time.Now().Add(-2 * time.Hour).Unix(), "exp": time.Now().Add(-time.Hour).Unix(),
+		}), wantError: "token expired"},
+		{name: "чужая подпись", token: signTestToken(t, "attacker", testClaims()), wantError: "invalid or expired token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := api.do(http.MethodGet, "/bikes/my", tt.token, nil)
+			expectStatus(t, w, http.StatusUnauthorized)
+			if got := decode[map[string]string](t, w)["error"]; got != tt.wantError {
+				t.Errorf("error = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+// пока redis лежит, отзыв не проверить: fail-closed отказывает, иначе пропускает.
+// Отозвать токен тоже нельзя - успех без записи в redis был бы ложным
+func TestTokenRevocationRedisDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	mr.Close()
+
+	token := signTestToken(t, testJWTSecret, testClaims())
+	for _, failClosed := range []bool{false, true} {
+		tokens := NewJWTTokenService([]string{testJWTSecret}, []string{"HS256"}, 0, redis.NewRedisAdapter(client), failClosed, &portstest.Logger{})
+
+		_, err := tokens.VerifyToken(token)
+		if failClosed && !errors.Is(err, ErrRevocationUnavailable) {
+			t.Errorf("fail-closed: err = %v, want ErrRevocationUnavailable", err)
+		}
+		if !failClosed && err != nil {
+			t.Errorf("fail-open: err = %v, want nil", err)
+		}
+
+		if _, _, err := tokens.RevokeToken(token); err == nil {
+			t.Errorf("failClosed=%t: RevokeToken succeeded with redis down", failClosed)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListBikesResponse struct {
+	Bikes  []BikeInfo `json:"bikes"`
+	Count  int        `json:"count"`
+	Total  int        `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+}
+
+// @Summary Все байки
+// @Description Для админов и сервисных API-ключей. Байки всех пользователей, включая архивные, с фильтрами. total - сколько байков под фильтром без учёта limit и offset
+// @Tags admin
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Produce json
+// @Param type query string false "Тип байка" Enums(bmx, mtb, road)
+// @Param user_id query string false "Владелец" example:"123e4567-e89b-12d3-a456-426614174000"
+// @Param model query string false "Подстрока модели без учёта регистра" example:"trek"
+// @Param min_mileage query int false "Пробег не меньше" example:"1000"
+// @Param max_mileage query int false "Пробег не больше" example:"5000"
+// @Param created_after query string false "Добавлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-01-01"
+// @Param created_before query string false "Добавлены не позже (RFC3339 или YYYY-MM-DD, дата включает весь день)" example:"2025-12-31"
+// @Param include_deleted query bool false "Вместе с удалёнными байками" default(false)
+// @Param sort query string false "Сортировка" Enums(created_at_desc, created_at_asc, mileage_desc, mileage_asc, name_asc, name_desc, year_desc, year_asc) default(created_at_desc)
+// @Param limit query int false "Сколько байков вернуть" example:"50"
+// @Param offset query int false "Сколько байков пропустить" example:"0"
+// @Success 200 {object} ListBikesResponse "Байки"
+// @Failure 400 {object} errorResponse "Неверный фильтр или пагинация"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/bikes [get]
+func (h *BikeHandler) ListBikes(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	filter, err := parseBikeListFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Page, err = parsePage(c, h.pagination)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bikes, total, err := h.bikeService.ListBikes(c.Request.Context(), filter)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to list bikes")
+		return
+	}
+
+	bikeInfos := make([]BikeInfo, len(bikes))
+	for i, bike := range bikes {
+		bikeInfos[i] = newBikeInfo(bike)
+	}
+
+	c.JSON(http.StatusOK, ListBikesResponse{
+		Bikes:  bikeInfos,
+		Count:  len(bikeInfos),
+		Total:  total,
+		Limit:  filter.Page.Limit,
+		Offset: filter.Page.Offset,
+	})
+}
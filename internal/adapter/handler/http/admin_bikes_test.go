@@ -0,0 +1,236 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// addDatedBikes - байки разных типов, добавленные в разные дни 2025 года
+func addDatedBikes(api *testAPI) map[uuid.UUID]string {
+	names := map[uuid.UUID]string{}
+	for _, b := range []struct {
+		name      string
+		bikeType  domain.BikeType
+		createdAt time.Time
+	}{
+		{name: "mtb-jan", bikeType: domain.MTB, createdAt: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{name: "road-jan", bikeType: domain.Road, createdAt: time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)},
+		{name: "mtb-jun", bikeType: domain.MTB, createdAt: time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC)},
+		{name: "mtb-dec", bikeType: domain.MTB, createdAt: time.Date(2025, 12, 31, 8, 0, 0, 0, time.UTC)},
+	} {
+		bike := api.addBike(uuid.New(), 100)
+		bike.Type, bike.CreatedAt = b.bikeType, b.createdAt
+		api.store.AddBike(bike)
+		names[bike.BikeID] = b.name
+	}
+	return names
+}
+
+func TestListBikesCreatedRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       []string
+	}{
+		{name: "без границ", wantStatus: http.StatusOK, want: []string{"mtb-dec", "mtb-jan", "mtb-jun", "road-jan"}},
+		{name: "тип и диапазон", query: "?type=mtb&created_after=2025-01-01&created_before=2025-06-30", wantStatus: http.StatusOK, want: []string{"mtb-jan", "mtb-jun"}},
+		{name: "дата до включает весь день", query: "?type=mtb&created_before=2025-06-01", wantStatus: http.StatusOK, want: []string{"mtb-jan", "mtb-jun"}},
+		{name: "только нижняя граница", query: "?created_after=2025-06-01T00:00:00Z", wantStatus: http.StatusOK, want: []string{"mtb-dec", "mtb-jun"}},
+		{name: "другой тип в диапазоне", query: "?type=road&created_after=2025-02-01", wantStatus: http.StatusOK, want: []string{}},
+		{name: "after позже before", query: "?created_after=2025-07-01&created_before=2025-06-01", wantStatus: http.StatusBadRequest},
+		{name: "неверная дата", query: "?created_after=01.06.2025", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			names := addDatedBikes(api)
+
+			w := api.do(http.MethodGet, "/admin/bikes"+tt.query, api.token(uuid.New(), domain.Admin), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decode[ListBikesResponse](t, w)
+			got := []string{}
+			for _, b := range resp.Bikes {
+				got = append(got, names[b.BikeID])
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) || resp.Total != len(tt.want) {
+				t.Errorf("bikes = %v (total %d), want %v", got, resp.Total, tt.want)
+			}
+		})
+	}
+}
+
+// массовая правка меняет только живые байки из отбора, отвечает их числом
+// и ID, а владельцам читается уже новое значение, не кешированное старое
+func TestBulkUpdateBikes(t *testing.T) {
+	tests := []struct {
+		name string
+		// body собирается по байкам: mtb, road, ещё mtb и удалённый mtb
+		body       func(b []*domain.Bike) any
+		wantStatus int
+		// wantChanged - индексы байков, у которых поменялось поле
+		wantChanged []int
+	}{
+		{
+			name: "по ids",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{IDs: []string{b[0].BikeID.String(), b[1].BikeID.String(), b[3].BikeID.String()}, Field: "model", Value: "Epic", Confirm: true}
+			},
+			wantStatus:  http.StatusOK,
+			wantChanged: []int{0, 1},
+		},
+		{
+			name: "по текущему типу",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{CurrentType: "mtb", Field: "type", Value: "road", Confirm: true}
+			},
+			wantStatus:  http.StatusOK,
+			wantChanged: []int{0, 2},
+		},
+		{
+			name: "без подтверждения",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{CurrentType: "mtb", Field: "model", Value: "Epic"}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ids и тип сразу",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{IDs: []string{b[0].BikeID.String()}, CurrentType: "mtb", Field: "model", Value: "Epic", Confirm: true}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "без отбора",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{Field: "model", Value: "Epic", Confirm: true}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "неизвестный тип",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{CurrentType: "mtb", Field: "type", Value: "tandem", Confirm: true}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "неизвестный текущий тип",
+			body: func(b []*domain.Bike) any {
+				return BulkUpdateBikesRequest{CurrentType: "tandem", Field: "model", Value: "Epic", Confirm: true}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			var bikes []*domain.Bike
+			for _, bikeType := range []domain.BikeType{domain.MTB, domain.Road, domain.MTB, domain.MTB} {
+				bike := api.addBike(owner, 100)
+				bike.Type = bikeType
+				bikes = append(bikes, api.store.AddBike(bike))
+			}
+			bikes[3].DeletedAt = ptr(time.Now())
+			api.store.AddBike(bikes[3])
+
+			// прогреваем кеш байков владельца
+			ownerToken := api.token(owner, domain.AppUser)
+			for _, bike := range bikes[:3] {
+				expectStatus(t, api.do(http.MethodGet, "/bikes/"+bike.BikeID.String(), ownerToken, nil), http.StatusOK)
+			}
+
+			w := api.do(http.MethodPatch, "/admin/bikes", api.token(uuid.New(), domain.Admin), tt.body(bikes))
+			expectStatus(t, w, tt.wantStatus)
+
+			var wantIDs []uuid.UUID
+			for _, i := range tt.wantChanged {
+				wantIDs = append(wantIDs, bikes[i].BikeID)
+			}
+			if tt.wantStatus == http.StatusOK {
+				resp := decode[BulkUpdateBikesResponse](t, w)
+				slices.SortFunc(resp.BikeIDs, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+				slices.SortFunc(wantIDs, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+				if resp.Affected != len(wantIDs) || !slices.Equal(resp.BikeIDs, wantIDs) {
+					t.Errorf("response = %+v, want %v", resp, wantIDs)
+				}
+			}
+
+			for i, bike := range bikes {
+				stored, _ := api.store.Bike(bike.BikeID)
+				changed := stored.Type != bike.Type || stored.Model != bike.Model
+				if changed != slices.Contains(tt.wantChanged, i) {
+					t.Errorf("bike %d changed = %t, want %t", i, changed, !changed)
+				}
+				if i == 3 {
+					continue
+				}
+				got := decode[GetBikeResponse](t, api.do(http.MethodGet, "/bikes/"+bike.BikeID.String(), ownerToken, nil))
+				if got.Type != string(stored.Type) || got.Model != stored.Model {
+					t.Errorf("bike %d reads %s/%s, want %s/%s: cache is stale", i, got.Type, got.Model, stored.Type, stored.Model)
+				}
+			}
+		})
+	}
+}
+
+// массовая запись - только для админов-людей: сервисный ключ читает список,
+// но менять байки не может
+func TestBulkUpdateBikesAccess(t *testing.T) {
+	const key = "billing-key-0123456789abcdef"
+	body := BulkUpdateBikesRequest{CurrentType: "mtb", Field: "model", Value: "Epic", Confirm: true}
+
+	tests := []struct {
+		name       string
+		token      func(api *testAPI) string
+		headers    []string
+		wantStatus int
+	}{
+		{name: "админ", token: func(api *testAPI) string { return api.token(uuid.New(), domain.Admin) }, wantStatus: http.StatusOK},
+		{name: "пользователь", token: func(api *testAPI) string { return api.token(uuid.New(), domain.AppUser) }, wantStatus: http.StatusForbidden},
+		{name: "API-ключ", token: func(*testAPI) string { return "" }, headers: []string{apiKeyHeaderKey, key}, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t, withAPIKeys(config.APIKey{Service: "billing-job", Key: key}))
+			bike := api.addBike(uuid.New(), 100)
+
+			w := api.do(http.MethodPatch, "/admin/bikes", tt.token(api), body, tt.headers...)
+			expectStatus(t, w, tt.wantStatus)
+			if stored, _ := api.store.Bike(bike.BikeID); (stored.Model == "Epic") != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("model = %q after status %d", stored.Model, w.Code)
+			}
+		})
+	}
+
+	api := newTestAPI(t, withAPIKeys(config.APIKey{Service: "billing-job", Key: key}))
+	expectStatus(t, api.do(http.MethodGet, "/admin/bikes", "", nil, apiKeyHeaderKey, key), http.StatusOK)
+}
+
+// админские роуты под тем же лимитом, что и пользовательские
+func TestAdminRoutesRateLimited(t *testing.T) {
+	api := newTestAPI(t, withUserLimiter(exhausted{}))
+	token := api.token(uuid.New(), domain.Admin)
+
+	for _, path := range []string{"/admin/bikes", "/admin/stats", "/admin/components/orphaned"} {
+		w := api.do(http.MethodGet, path, token, nil)
+		expectStatus(t, w, http.StatusTooManyRequests)
+	}
+	w := api.do(http.MethodPatch, "/admin/bikes", token, BulkUpdateBikesRequest{CurrentType: "mtb", Field: "model", Value: "Epic", Confirm: true})
+	expectStatus(t, w, http.StatusTooManyRequests)
+}
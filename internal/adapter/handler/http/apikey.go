@@ -0,0 +1,78 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"sync"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type apiKeyEntry struct {
+	service string
+	hash    [sha256.Size]byte
+}
+
+// APIKeyService проверяет ключи внутренних сервисов. Храним только хеши,
+// сравниваем со всеми за постоянное время, чтобы не выдавать ключ по таймингу
+type APIKeyService struct {
+	mu     sync.RWMutex
+	keys   []apiKeyEntry
+	logger ports.LoggerPort
+}
+
+func NewAPIKeyService(cfg *config.APIKeys, logger ports.LoggerPort) *APIKeyService {
+	s := &APIKeyService{logger: logger}
+	s.Reload(cfg)
+	return s
+}
+
+// Reload подменяет набор ключей, удалённые из конфига сразу перестают работать
+func (s *APIKeyService) Reload(cfg *config.APIKeys) {
+	keys := make([]apiKeyEntry, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		keys = append(keys, apiKeyEntry{
+			service: k.Service,
+			hash:    sha256.Sum256([]byte(k.Key)),
+		})
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	s.logger.Info("API keys loaded", map[string]interface{}{
+		"count": len(keys),
+	})
+}
+
+func (s *APIKeyService) VerifyAPIKey(key string) (*domain.TokenPayload, error) {
+	hash := sha256.Sum256([]byte(key))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	service := ""
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash[:]) == 1 {
+			service = k.service
+		}
+	}
+	if service == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return &domain.TokenPayload{
+		ID:      uuid.New(),
+		UserID:  uuid.Nil,
+		Role:    domain.ServiceAccount,
+		Service: service,
+	}, nil
+}
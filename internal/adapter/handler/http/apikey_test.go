@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// staticTokens - токены по строке, без подписи
+type staticTokens map[string]*domain.TokenPayload
+
+func (t staticTokens) VerifyToken(token string) (*domain.TokenPayload, error) {
+	if payload, ok := t[token]; ok {
+		return payload, nil
+	}
+	return nil, errors.New("unknown token")
+}
+
+// apiKeyRouter повторяет разбиение роутов из NewRouter: пользовательский,
+// общий админский и только-админский
+func apiKeyRouter(keys *APIKeyService, tokens staticTokens) *gin.Engine {
+	router := gin.New()
+	auth := AuthMiddleware(tokens, keys, &portstest.Logger{})
+	ok := func(c *gin.Context) {
+		payload, _ := getAuthPayload(c, authorizationPayloadKey)
+		c.String(http.StatusOK, payload.Actor())
+	}
+
+	router.GET("/bikes/my", auth, UserOnlyMiddleware(), ok)
+	admin := router.Group("/admin", auth)
+	admin.GET("/bikes", AdminOrServiceMiddleware(), ok)
+	admin.POST("/tokens/revoke", AdminMiddleware(), ok)
+	return router
+}
+
+func TestAPIKeyVerify(t *testing.T) {
+	keys := NewAPIKeyService(&config.APIKeys{Keys: []config.APIKey{
+		{Service: "billing-job", Key: "billing-key-0123456789abcdef"},
+	}}, &portstest.Logger{})
+
+	payload, err := keys.VerifyAPIKey("billing-key-0123456789abcdef")
+	if err != nil {
+		t.Fatalf("VerifyAPIKey: %v", err)
+	}
+	if payload.Role != domain.ServiceAccount || payload.Service != "billing-job" || payload.UserID != uuid.Nil {
+		t.Errorf("payload = %+v, want service account billing-job without user", payload)
+	}
+	if got := payload.Actor(); got != "service:billing-job" {
+		t.Errorf("Actor() = %q, want service:billing-job", got)
+	}
+
+	if _, err := keys.VerifyAPIKey("wrong-key"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("wrong key: err = %v, want ErrInvalidAPIKey", err)
+	}
+
+	keys.Reload(&config.APIKeys{})
+	if _, err := keys.VerifyAPIKey("billing-key-0123456789abcdef"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("revoked key: err = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+func TestAPIKeyRouteAccess(t *testing.T) {
+	keys := NewAPIKeyService(&config.APIKeys{Keys: []config.APIKey{
+		{Service: "billing-job", Key: "billing-key-0123456789abcdef"},
+	}}, &portstest.Logger{})
+	adminID := uuid.New()
+	userID := uuid.New()
+	router := apiKeyRouter(keys, staticTokens{
+		"admin": {UserID: adminID, Role: domain.Admin},
+		"user":  {UserID: userID, Role: domain.AppUser},
+	})
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		apiKey    string
+		token     string
+		wantCode  int
+		wantActor string
+	}{
+		{"service on shared admin route", http.MethodGet, "/admin/bikes", "billing-key-0123456789abcdef", "", http.StatusOK, "service:billing-job"},
+		{"service on admin-only route", http.MethodPost, "/admin/tokens/revoke", "billing-key-0123456789abcdef", "", http.StatusForbidden, ""},
+		{"service on user route", http.MethodGet, "/bikes/my", "billing-key-0123456789abcdef", "", http.StatusForbidden, ""},
+		{"invalid key does not fall back to jwt", http.MethodGet, "/admin/bikes", "wrong-key", "admin", http.StatusUnauthorized, ""},
+		{"admin on shared route", http.MethodGet, "/admin/bikes", "", "admin", http.StatusOK, adminID.String()},
+		{"admin on admin-only route", http.MethodPost, "/admin/tokens/revoke", "", "admin", http.StatusOK, adminID.String()},
+		{"user on shared admin route", http.MethodGet, "/admin/bikes", "", "user", http.StatusForbidden, ""},
+		{"user on user route", http.MethodGet, "/bikes/my", "", "user", http.StatusOK, userID.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(apiKeyHeaderKey, tt.apiKey)
+			}
+			if tt.token != "" {
+				req.Header.Set(authorizationHeaderKey, "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantActor != "" && w.Body.String() != tt.wantActor {
+				t.Errorf("actor = %q, want %q", w.Body.String(), tt.wantActor)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Убрать байк в архив
+// @Description Байк продан или украден: пропадает из /bikes/my, списков срочности и сводки, но остаётся доступен по ID вместе с историей и виден в /bikes/my/archived. Повторный вызов ничего не меняет
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} BikeInfo "Байк в архиве"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/{id}/archive [post]
+func (h *BikeHandler) ArchiveBike(c *gin.Context) {
+	h.setBikeArchived(c, true)
+}
+
+// @Summary Вернуть байк из архива
+// @Description Возвращает архивный байк в обычные списки
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} BikeInfo "Байк снова в строю"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/{id}/unarchive [post]
+func (h *BikeHandler) UnarchiveBike(c *gin.Context) {
+	h.setBikeArchived(c, false)
+}
+
+func (h *BikeHandler) setBikeArchived(c *gin.Context, archived bool) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to archive bike", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parsedID, err := uuid.Parse(bikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
+		return
+	}
+
+	existingBike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), bikeID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != existingBike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to archive bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   existingBike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	bike, err := h.bikeService.SetBikeArchived(c.Request.Context(), parsedID, archived)
+	if err != nil {
+		if errors.Is(err, domain.ErrBikeNotFound) {
+			newErrorResponse(c, http.StatusNotFound, "Bike not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to change archive state")
+		return
+	}
+
+	c.JSON(http.StatusOK, newBikeInfo(bike))
+}
+
+// @Summary Восстановить удалённый байк
+// @Description Возвращает мягко удалённый байк вместе со всеми компонентами и историей. Отправляется событие bike.restored
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} BikeInfo "Байк восстановлен"
+// @Failure 400 {object} errorResponse "Неверный ID"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "Байк не удалён"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/{id}/restore [post]
+func (h *BikeHandler) RestoreBike(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to RestoreBike", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parsedID, err := uuid.Parse(bikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
+		return
+	}
+
+	existingBike, err := h.bikeService.GetBikeByIDWithDeleted(c.Request.Context(), bikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != existingBike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to restore bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   existingBike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	bike, err := h.bikeService.RestoreBike(c.Request.Context(), parsedID)
+	if err != nil {
+		if errors.Is(err, domain.ErrBikeNotDeleted) {
+			newErrorResponse(c, http.StatusConflict, domain.ErrBikeNotDeleted.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to restore bike")
+		return
+	}
+
+	c.JSON(http.StatusOK, newBikeInfo(bike))
+}
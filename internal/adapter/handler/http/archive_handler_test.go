@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// архивный байк пропадает из /bikes/my и сводки, но остаётся доступен по ID
+func TestArchiveBikeFlow(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	active := api.addBike(owner, 100)
+	sold := api.addBike(owner, 300)
+	token := api.token(owner, domain.AppUser)
+	path := "/bikes/" + sold.BikeID.String()
+
+	list := func(path string) []uuid.UUID {
+		t.Helper()
+		w := api.do(http.MethodGet, path, token, nil)
+		expectStatus(t, w, http.StatusOK)
+		ids := []uuid.UUID{}
+		for _, b := range decode[GetMyBikesResponse](t, w).Bikes {
+			ids = append(ids, b.BikeID)
+		}
+		return ids
+	}
+
+	w := api.do(http.MethodPost, path+"/archive", token, nil)
+	expectStatus(t, w, http.StatusOK)
+	archived := decode[BikeInfo](t, w)
+	if archived.ArchivedAt == nil {
+		t.Fatal("archived_at is not set")
+	}
+
+	if got := list("/bikes/my"); !slices.Equal(got, []uuid.UUID{active.BikeID}) {
+		t.Errorf("/bikes/my = %v, want only %s", got, active.BikeID)
+	}
+	if got := list("/bikes/my/archived"); !slices.Equal(got, []uuid.UUID{sold.BikeID}) {
+		t.Errorf("/bikes/my/archived = %v, want only %s", got, sold.BikeID)
+	}
+	if got := decode[BikeInfo](t, api.do(http.MethodGet, path, token, nil)); got.ArchivedAt == nil {
+		t.Error("GET /bikes/{id} lost archived_at")
+	}
+	if me := decode[GetMeResponse](t, api.do(http.MethodGet, "/me", token, nil)); me.Bikes.Total != 1 || me.Bikes.TotalMileage != 100 {
+		t.Errorf("/me bikes = %+v, want only the active bike", me.Bikes)
+	}
+
+	// повторная архивация не сдвигает дату
+	w = api.do(http.MethodPost, path+"/archive", token, nil)
+	expectStatus(t, w, http.StatusOK)
+	if again := decode[BikeInfo](t, w); again.ArchivedAt == nil || !again.ArchivedAt.Equal(*archived.ArchivedAt) {
+		t.Errorf("archived_at = %v after repeat, want %v", again.ArchivedAt, archived.ArchivedAt)
+	}
+
+	w = api.do(http.MethodPost, path+"/unarchive", token, nil)
+	expectStatus(t, w, http.StatusOK)
+	if got := decode[BikeInfo](t, w); got.ArchivedAt != nil {
+		t.Errorf("archived_at = %v after unarchive, want nil", got.ArchivedAt)
+	}
+	if got := list("/bikes/my"); len(got) != 2 {
+		t.Errorf("/bikes/my = %v, want both bikes", got)
+	}
+	if got := list("/bikes/my/archived"); len(got) != 0 {
+		t.Errorf("/bikes/my/archived = %v, want empty", got)
+	}
+
+	want := []domain.EventType{domain.BikeArchived, domain.BikeArchived, domain.BikeUnarchived}
+	if got := api.store.EventTypes(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestArchiveBikeAccess(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		role       domain.UserRole
+		bikeID     string
+		wantStatus int
+	}{
+		{name: "владелец", requester: owner, role: domain.AppUser, wantStatus: http.StatusOK},
+		{name: "админ", requester: uuid.New(), role: domain.Admin, wantStatus: http.StatusOK},
+		{name: "чужой байк", requester: uuid.New(), role: domain.AppUser, wantStatus: http.StatusForbidden},
+		{name: "неизвестный байк", requester: owner, role: domain.AppUser, bikeID: uuid.NewString(), wantStatus: http.StatusNotFound},
+		{name: "неверный ID", requester: owner, role: domain.AppUser, bikeID: "not-a-uuid", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+			bikeID := tt.bikeID
+			if bikeID == "" {
+				bikeID = bike.BikeID.String()
+			}
+
+			w := api.do(http.MethodPost, "/bikes/"+bikeID+"/archive", api.token(tt.requester, tt.role), nil)
+			expectStatus(t, w, tt.wantStatus)
+			stored, _ := api.store.Bike(bike.BikeID)
+			if archived := stored.ArchivedAt != nil; archived != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("stored archived = %t", archived)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
@@ -17,23 +21,176 @@ type ComponentHandler struct {
 	bikeService      *services.BikeService
 	logger           ports.LoggerPort
 	metrics          ports.MetricsPort
+	pagination       *config.Pagination
+	// maxBatchSize - общий потолок размера батча
+	maxBatchSize int
+	// warnPercent - с какого процента износа компонент помечается warning
+	warnPercent int
 }
 
 type ComponentRequest struct {
 	BikeID           string `json:"bike_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Name             string `json:"name" binding:"required" example:"handlebars"`
+	Name             string `json:"name" binding:"required,notblank" example:"handlebars"`
 	Brand            string `json:"brand,omitempty" example:"Shimano"`
 	Model            string `json:"model,omitempty" example:"Deore XT"`
 	InstalledMileage int    `json:"installed_mileage" binding:"required" example:"1000"`
-	MaxMileage       int    `json:"max_mileage" binding:"required" example:"5000"`
+	// если не передан ни один из порогов, берутся пороги по умолчанию из /admin/components/defaults
+	MaxMileage int  `json:"max_mileage,omitempty" binding:"omitempty,min=1" example:"5000"`
+	MaxAgeDays *int `json:"max_age_days,omitempty" binding:"omitempty,min=1" example:"365"`
+	// по умолчанию текущее время
+	InstalledAt *time.Time `json:"installed_at,omitempty" example:"2025-06-01T10:00:00Z"`
+	// для парных компонентов (wheels): одна позиция на байк, по умолчанию none
+	Position string `json:"position,omitempty" binding:"omitempty,oneof=front rear left right none" example:"front"`
 }
 
+// ReplaceComponent - полная замена компонента через PUT, все поля обязательны
+type ReplaceComponent struct {
+	Name             string `json:"name" binding:"required,notblank" example:"handlebars"`
+	Brand            string `json:"brand" example:"Shimano"`
+	Model            string `json:"model" example:"XT"`
+	InstalledMileage *int   `json:"installed_mileage" binding:"required" example:"1000"`
+	// нужен хотя бы один из порогов, непереданный порог снимается
+	MaxMileage int  `json:"max_mileage" binding:"omitempty,min=1" example:"5000"`
+	MaxAgeDays *int `json:"max_age_days" binding:"omitempty,min=1" example:"365"`
+	// если не передано, остаётся прежняя дата установки
+	InstalledAt *time.Time `json:"installed_at,omitempty" example:"2025-06-01T10:00:00Z"`
+	// непереданная позиция сбрасывается в none
+	Position string `json:"position" binding:"omitempty,oneof=front rear left right none" example:"front"`
+}
+
+// UpdateComponent - частичное обновление через PATCH, меняются только переданные поля
 type UpdateComponent struct {
-	Name             *string `json:"name,omitempty" example:"handlebars"`
-	Brand            *string `json:"brand,omitempty" example:"Shimano"`
-	Model            *string `json:"model,omitempty" example:"XT"`
-	InstalledMileage *int    `json:"installed_mileage,omitempty" example:"1000"`
-	MaxMileage       *int    `json:"max_mileage,omitempty" example:"5000"`
+	Name             *string    `json:"name,omitempty" binding:"omitempty,notblank" example:"handlebars"`
+	Brand            *string    `json:"brand,omitempty" example:"Shimano"`
+	Model            *string    `json:"model,omitempty" example:"XT"`
+	InstalledMileage *int       `json:"installed_mileage,omitempty" example:"1000"`
+	MaxMileage       *int       `json:"max_mileage,omitempty" example:"5000"`
+	MaxAgeDays       *int       `json:"max_age_days,omitempty" binding:"omitempty,min=1" example:"365"`
+	InstalledAt      *time.Time `json:"installed_at,omitempty" example:"2025-06-01T10:00:00Z"`
+	Position         *string    `json:"position,omitempty" binding:"omitempty,oneof=front rear left right none" example:"front"`
+}
+
+// ExtendLifeRequest - нужен ровно один из delta и max_mileage
+type ExtendLifeRequest struct {
+	Delta      int    `json:"delta,omitempty" binding:"omitempty,min=1" example:"2000"`
+	MaxMileage int    `json:"max_mileage,omitempty" binding:"omitempty,min=1" example:"7000"`
+	Reason     string `json:"reason" binding:"required,notblank,max=500" example:"Цепь растянута меньше 0.5%"`
+}
+
+// ReplaceComponentRequest - новая деталь; без порогов берутся пороги старой
+type ReplaceComponentRequest struct {
+	Brand      string `json:"brand,omitempty" binding:"max=100" example:"Shimano"`
+	Model      string `json:"model,omitempty" binding:"max=100" example:"Deore XT"`
+	MaxMileage int    `json:"max_mileage,omitempty" binding:"omitempty,min=1" example:"5000"`
+	MaxAgeDays *int   `json:"max_age_days,omitempty" binding:"omitempty,min=1" example:"365"`
+}
+
+type ComponentDefaultItem struct {
+	Name       string `json:"name" binding:"required,oneof=handlebars frame wheels" example:"wheels"`
+	MaxMileage int    `json:"max_mileage,omitempty" binding:"omitempty,min=1" example:"15000"`
+	MaxAgeDays *int   `json:"max_age_days,omitempty" binding:"omitempty,min=1" example:"730"`
+}
+
+type SetComponentDefaultsRequest struct {
+	Defaults []ComponentDefaultItem `json:"defaults" binding:"required,min=1,dive"`
+}
+
+type ComponentDefaultsResponse struct {
+	Defaults []*domain.ComponentDefault `json:"defaults"`
+}
+
+type ComponentWeightItem struct {
+	Name   string  `json:"name" binding:"required,oneof=handlebars frame wheels" example:"frame"`
+	Weight float64 `json:"weight" binding:"gt=0,lte=10" example:"1.5"`
+}
+
+type SetComponentWeightsRequest struct {
+	Weights []ComponentWeightItem `json:"weights" binding:"required,min=1,dive"`
+}
+
+type ComponentWeightsResponse struct {
+	Weights []*domain.ComponentWeight `json:"weights"`
+}
+
+type BatchUpdateComponentItem struct {
+	ID string `json:"id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UpdateComponent
+}
+
+type BatchUpdateComponentsRequest struct {
+	Items []BatchUpdateComponentItem `json:"items" binding:"required,min=1,dive"`
+	// по умолчанию true: любая ошибка отменяет весь батч
+	Atomic *bool `json:"atomic,omitempty" example:"true"`
+}
+
+type BatchItemResult struct {
+	ID        string            `json:"id"`
+	Status    string            `json:"status" enums:"updated,failed,skipped"`
+	Error     string            `json:"error,omitempty"`
+	Component *domain.Component `json:"component,omitempty"`
+}
+
+type BatchUpdateComponentsResponse struct {
+	Results []BatchItemResult `json:"results"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+}
+
+const (
+	batchStatusUpdated = "updated"
+	batchStatusCreated = "created"
+	batchStatusFailed  = "failed"
+	batchStatusSkipped = "skipped"
+)
+
+// applyTo накладывает на компонент только переданные поля
+func (u UpdateComponent) applyTo(component *domain.Component) {
+	if u.Name != nil {
+		component.Name = domain.ComponentName(*u.Name)
+	}
+	if u.Brand != nil {
+		component.Brand = *u.Brand
+	}
+	if u.Model != nil {
+		component.Model = *u.Model
+	}
+	if u.InstalledMileage != nil {
+		component.InstalledMileage = *u.InstalledMileage
+	}
+	if u.MaxMileage != nil {
+		component.MaxMileage = *u.MaxMileage
+	}
+	if u.InstalledAt != nil {
+		component.InstalledAt = *u.InstalledAt
+	}
+	if u.MaxAgeDays != nil {
+		component.MaxAgeDays = u.MaxAgeDays
+	}
+	if u.Position != nil {
+		component.Position = domain.Position(*u.Position)
+	}
+}
+
+// componentInputError превращает ошибки проверки данных компонента в понятный 4xx
+func componentInputError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrBikeNotFound):
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+	case errors.Is(err, domain.ErrDuplicatePosition):
+		newErrorResponse(c, http.StatusConflict, domain.ErrDuplicatePosition.Error())
+	case errors.Is(err, domain.ErrComponentReplaced):
+		newErrorResponse(c, http.StatusConflict, domain.ErrComponentReplaced.Error())
+	case errors.Is(err, domain.ErrNoReplacementThreshold):
+		newErrorResponse(c, http.StatusUnprocessableEntity, domain.ErrNoReplacementThreshold.Error())
+	case errors.Is(err, services.ErrInstalledInFuture):
+		newErrorResponse(c, http.StatusUnprocessableEntity, "installed_at must not be in the future")
+	case errors.Is(err, services.ErrInstalledBeforeBike), errors.Is(err, services.ErrInstalledMileageAboveBike),
+		errors.Is(err, services.ErrInstalledBeforeModelYear):
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		return false
+	}
+	return true
 }
 
 func NewComponentHandler(
@@ -41,26 +198,38 @@ func NewComponentHandler(
 	bikeService *services.BikeService,
 	logger ports.LoggerPort,
 	metrics ports.MetricsPort,
+	pagination *config.Pagination,
+	maxBatchSize int,
+	warnPercent int,
 ) *ComponentHandler {
 	return &ComponentHandler{
 		componentService: componentService,
 		bikeService:      bikeService,
 		logger:           logger,
 		metrics:          metrics,
+		pagination:       pagination,
+		maxBatchSize:     maxBatchSize,
+		warnPercent:      warnPercent,
 	}
 }
 
 // @Summary Создать компонент
-// @Description Добавление компонента к байку
+// @Description Добавление компонента к байку. С заголовком Idempotency-Key повтор того же запроса в течение суток вернёт уже созданный компонент (201, Idempotent-Replayed: true) вместо дубля. Параллельный запрос с тем же ключом дождётся первого
 // @Tags components
 // @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Ключ запроса, уникальный в пределах пользователя" example:"add-chain-91c2"
 // @Param request body ComponentRequest true "Данные компонента"
+// @Param if_bike_mileage_lte query int false "Создать, только если пробег байка не больше N, иначе 412" example:"5000"
 // @Success 201 {object} domain.Component "Компонент создан"
-// @Failure 400 {object} errorResponse "Неверный запрос"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей или ключ уже использован для другого запроса"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "Эта позиция уже занята парным компонентом"
+// @Failure 412 {object} errorResponse "Пробег байка больше if_bike_mileage_lte"
 // @Router /components [post]
 func (h *ComponentHandler) CreateComponent(c *gin.Context) {
 	start := time.Now()
@@ -70,26 +239,48 @@ func (h *ComponentHandler) CreateComponent(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to CreateComponent", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to CreateComponent", map[string]interface{}{
 			"ip": c.ClientIP(),
 		})
 		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
+	key, ok := idempotencyKey(c)
+	if !ok {
+		return
+	}
+
 	var req ComponentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed JSON parse in create component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in create component", map[string]interface{}{
 			"error": err.Error(),
 		})
-		newErrorResponse(c, http.StatusBadRequest, "Invalid JSON format")
+		bindError(c, err)
+		return
+	}
+	hash, err := requestHash(req)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to create component")
 		return
 	}
 
-	// смотрим че байк существует и принадлежит юзеру
-	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), req.BikeID)
+	// необязательное предусловие для скриптов: создаём, только если байк ещё не укатали дальше N
+	maxBikeMileage := -1
+	if value := c.Query("if_bike_mileage_lte"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			newErrorResponse(c, http.StatusBadRequest, "if_bike_mileage_lte must be a non-negative integer")
+			return
+		}
+		maxBikeMileage = n
+	}
+
+	// смотрим че байк существует и принадлежит юзеру. С primary: владелец
+	// и пробег для предусловия должны быть текущими, а не из кеша или реплики
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), req.BikeID)
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": req.BikeID,
 		})
@@ -98,7 +289,7 @@ func (h *ComponentHandler) CreateComponent(c *gin.Context) {
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied to add component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to add component", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   bike.UserID.String(),
 			"bike_id":      req.BikeID,
@@ -107,9 +298,14 @@ func (h *ComponentHandler) CreateComponent(c *gin.Context) {
 		return
 	}
 
+	if maxBikeMileage >= 0 && bike.Mileage > maxBikeMileage {
+		newErrorResponse(c, http.StatusPreconditionFailed, fmt.Sprintf("Bike mileage %d exceeds if_bike_mileage_lte=%d", bike.Mileage, maxBikeMileage))
+		return
+	}
+
 	bikeUUID, err := uuid.Parse(req.BikeID)
 	if err != nil {
-		h.logger.Error("Invalid bike ID format", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Invalid bike ID format", map[string]interface{}{
 			"bike_id": req.BikeID,
 		})
 		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
@@ -124,21 +320,41 @@ func (h *ComponentHandler) CreateComponent(c *gin.Context) {
 		InstalledAt:      time.Now(),
 		InstalledMileage: req.InstalledMileage,
 		MaxMileage:       req.MaxMileage,
+		MaxAgeDays:       req.MaxAgeDays,
+		Position:         domain.Position(req.Position),
+	}
+	if req.InstalledAt != nil {
+		component.InstalledAt = *req.InstalledAt
 	}
 
-	createdComponent, err := h.componentService.CreateComponent(c.Request.Context(), component)
+	createdComponent, replayed, err := h.componentService.CreateComponent(c.Request.Context(), component, domain.IdempotencyKey{
+		UserID:      payload.UserID,
+		Key:         key,
+		RequestHash: hash,
+	})
 	if err != nil {
-		h.logger.Error("Failed to create component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to create component", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": req.BikeID,
 		})
+		if errors.Is(err, services.ErrIdempotencyKeyReused) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if componentInputError(c, err) {
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "Failed to create component")
 		return
 	}
 
-	h.logger.Info("Component created successfully", map[string]interface{}{
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	h.logger.WithContext(c.Request.Context()).Info("Component created successfully", map[string]interface{}{
 		"component_id": createdComponent.ID,
 		"bike_id":      createdComponent.BikeID,
+		"replayed":     replayed,
 	})
 
 	newSuccessResponse(c, http.StatusCreated, "Component created successfully", createdComponent)
@@ -151,6 +367,7 @@ func (h *ComponentHandler) CreateComponent(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID компонента" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
+// @Param Accept-Language header string false "Язык display_name" example:"ru"
 // @Success 200 {object} domain.Component "Компонент найден"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Failure 403 {object} errorResponse "Доступ запрещен"
@@ -166,7 +383,7 @@ func (h *ComponentHandler) GetComponent(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to GetComponent", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetComponent", map[string]interface{}{
 			"component_id": componentID,
 			"ip":           c.ClientIP(),
 		})
@@ -176,7 +393,7 @@ func (h *ComponentHandler) GetComponent(c *gin.Context) {
 
 	component, err := h.componentService.GetComponentByID(c.Request.Context(), componentID)
 	if err != nil {
-		h.logger.Error("Failed to get component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
@@ -187,7 +404,7 @@ func (h *ComponentHandler) GetComponent(c *gin.Context) {
 	// смотрим че байк принадлежит юзеру
 	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), component.BikeID.String())
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": component.BikeID,
 		})
@@ -196,7 +413,7 @@ func (h *ComponentHandler) GetComponent(c *gin.Context) {
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied to component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to component", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   bike.UserID.String(),
 			"component_id": componentID,
@@ -205,141 +422,367 @@ func (h *ComponentHandler) GetComponent(c *gin.Context) {
 		return
 	}
 
+	localizeComponents(preferredLanguage(c), component)
 	newSuccessResponse(c, http.StatusOK, "Component found", component)
 }
 
-// @Summary Обновить компонент
-// @Description Обновление данных компонента
+type GetBikeComponentsResponse struct {
+	Components []*domain.Component `json:"components"`
+	Count      int                 `json:"count"`
+	Limit      int                 `json:"limit"`
+	Offset     int                 `json:"offset"`
+}
+
+// @Summary Компоненты байка
+// @Description Только список компонентов без данных самого байка, легче чем GET /bikes/{id}/with-components
 // @Tags components
 // @Security BearerAuth
-// @Accept json
 // @Produce json
-// @Param id path string true "ID компонента" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
-// @Param request body UpdateComponent true "Данные для обновления"
-// @Success 200 {object} domain.Component "Компонент обновлен"
-// @Failure 400 {object} errorResponse "Неверный запрос"
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param name query string false "Только компоненты с этим названием" example:"wheels"
+// @Param include_replaced query bool false "Вместе с заменёнными компонентами (история обслуживания)" default(false)
+// @Param installed_after query string false "Установлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-04-01"
+// @Param installed_before query string false "Установлены не позже (RFC3339 или YYYY-MM-DD)" example:"2025-10-01"
+// @Param limit query int false "Сколько компонентов вернуть (по умолчанию и максимум задаются в конфиге)" example:"50"
+// @Param offset query int false "Сколько компонентов пропустить" example:"0"
+// @Param sort query string false "Порядок компонентов (wear - износ по худшему из порогов)" Enums(installed_at, wear, name, max_mileage) default(installed_at)
+// @Param order query string false "Направление, по умолчанию desc для installed_at и wear, asc для name и max_mileage" Enums(asc, desc)
+// @Param Accept-Language header string false "Язык display_name компонентов" example:"ru"
+// @Success 200 {object} GetBikeComponentsResponse "Компоненты байка"
+// @Failure 400 {object} errorResponse "Неверный фильтр"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Failure 403 {object} errorResponse "Доступ запрещен"
-// @Failure 404 {object} errorResponse "Компонент не найден"
-// @Router /components/{id} [put]
-func (h *ComponentHandler) UpdateComponent(c *gin.Context) {
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/{id}/components [get]
+func (h *ComponentHandler) GetBikeComponents(c *gin.Context) {
 	start := time.Now()
 	defer func() {
 		h.metrics.RecordMetrics(c, start)
 	}()
 
-	componentID := c.Param("id")
+	bikeID := c.Param("id")
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to UpdateComponent", map[string]interface{}{
-			"component_id": componentID,
-			"ip":           c.ClientIP(),
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBikeComponents", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
 		})
 		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	// смотрим че комп. существует
-	existingComponent, err := h.componentService.GetComponentByID(c.Request.Context(), componentID)
+	filter, err := parseComponentFilter(c)
 	if err != nil {
-		h.logger.Error("Failed to get component", map[string]interface{}{
-			"error":        err.Error(),
-			"component_id": componentID,
-		})
-		newErrorResponse(c, http.StatusNotFound, "Component not found")
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Name = domain.ComponentName(c.Query("name"))
+	filter.IncludeReplaced = c.Query("include_replaced") == "true"
+	filter.Page, err = parsePage(c, h.pagination)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// смотрим че байк принадлежит юзеру
-	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), existingComponent.BikeID.String())
+	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), bikeID)
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
-			"bike_id": existingComponent.BikeID,
+			"bike_id": bikeID,
 		})
 		newErrorResponse(c, http.StatusNotFound, "Bike not found")
 		return
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied to update component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike components", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   bike.UserID.String(),
-			"component_id": componentID,
+			"bike_id":      bikeID,
 		})
 		newErrorResponse(c, http.StatusForbidden, "Access denied")
 		return
 	}
 
-	var req UpdateComponent
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed JSON parse in update component", map[string]interface{}{
-			"error": err.Error(),
-		})
-		newErrorResponse(c, http.StatusBadRequest, "Invalid JSON format")
+	components, err := h.componentService.GetComponentsByBikeID(c.Request.Context(), bikeID, filter)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get components")
 		return
 	}
+	if components == nil {
+		components = []*domain.Component{}
+	}
 
-	parsedID, err := uuid.Parse(componentID)
-	if err != nil {
-		h.logger.Error("Invalid component ID format", map[string]interface{}{
-			"component_id": componentID,
-		})
-		newErrorResponse(c, http.StatusBadRequest, "Invalid component ID")
+	localizeComponents(preferredLanguage(c), components...)
+	c.JSON(http.StatusOK, GetBikeComponentsResponse{
+		Components: components,
+		Count:      len(components),
+		Limit:      filter.Page.Limit,
+		Offset:     filter.Page.Offset,
+	})
+}
+
+type SuggestResponse struct {
+	Suggestions []string `json:"suggestions"`
+}
+
+// @Summary Подсказки брендов
+// @Description Различающиеся бренды компонентов, начинающиеся с q (без учёта регистра). Пользователь видит только свои данные, админ - все. Минимум 2 символа, не больше 20 результатов
+// @Tags components
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Префикс" example:"shi"
+// @Param limit query int false "Сколько подсказок вернуть" example:"10"
+// @Success 200 {object} SuggestResponse "Подсказки"
+// @Failure 400 {object} errorResponse "Слишком короткий запрос"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 429 {object} errorResponse "Слишком много запросов"
+// @Router /components/brands/suggest [get]
+func (h *ComponentHandler) SuggestBrands(c *gin.Context) {
+	h.suggest(c, domain.SuggestBrand)
+}
+
+// @Summary Подсказки моделей
+// @Description Различающиеся модели компонентов, начинающиеся с q (без учёта регистра). Пользователь видит только свои данные, админ - все. Минимум 2 символа, не больше 20 результатов
+// @Tags components
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Префикс" example:"deo"
+// @Param limit query int false "Сколько подсказок вернуть" example:"10"
+// @Success 200 {object} SuggestResponse "Подсказки"
+// @Failure 400 {object} errorResponse "Слишком короткий запрос"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 429 {object} errorResponse "Слишком много запросов"
+// @Router /components/models/suggest [get]
+func (h *ComponentHandler) SuggestModels(c *gin.Context) {
+	h.suggest(c, domain.SuggestModel)
+}
+
+func (h *ComponentHandler) suggest(c *gin.Context, field domain.SuggestField) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	component := &domain.Component{
-		ID:     parsedID,
-		BikeID: existingComponent.BikeID,
+	query := domain.SuggestQuery{
+		Field:  field,
+		Prefix: c.Query("q"),
+		UserID: payload.UserID,
+	}
+	if payload.Role == domain.Admin {
+		query.UserID = uuid.Nil
 	}
-	if req.Name != nil {
-		component.Name = domain.ComponentName(*req.Name)
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			newErrorResponse(c, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		query.Limit = limit
 	}
-	if req.Brand != nil {
-		component.Brand = *req.Brand
+
+	suggestions, err := h.componentService.SuggestValues(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSuggestQuery) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get suggestions")
+		return
 	}
-	if req.Model != nil {
-		component.Model = *req.Model
+
+	c.JSON(http.StatusOK, SuggestResponse{Suggestions: suggestions})
+}
+
+// @Summary Категории компонентов
+// @Description К какой категории относится каждое известное название компонента. Неизвестные названия попадают в other
+// @Tags components
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string "Название -> категория"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Router /components/categories [get]
+func (h *ComponentHandler) GetComponentCategories(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	c.JSON(http.StatusOK, domain.ComponentCategories)
+}
+
+// ComponentTypeInfo - известное название компонента с переводом для показа
+type ComponentTypeInfo struct {
+	Name        domain.ComponentName     `json:"name" example:"handlebars"`
+	DisplayName string                   `json:"display_name" example:"Руль"`
+	Category    domain.ComponentCategory `json:"category" example:"cockpit"`
+	Paired      bool                     `json:"paired"`
+}
+
+// @Summary Типы компонентов
+// @Description Известные названия компонентов с display_name на языке из Accept-Language (en, ru; по умолчанию en), категорией и признаком парного компонента. В базе и в поле name хранится само значение enum
+// @Tags components
+// @Security BearerAuth
+// @Produce json
+// @Param Accept-Language header string false "Язык display_name" example:"ru"
+// @Success 200 {array} ComponentTypeInfo "Типы компонентов"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Router /components/types [get]
+func (h *ComponentHandler) GetComponentTypes(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	lang := preferredLanguage(c)
+	types := make([]ComponentTypeInfo, 0, len(domain.ComponentNames))
+	for _, name := range domain.ComponentNames {
+		types = append(types, ComponentTypeInfo{
+			Name:        name,
+			DisplayName: name.DisplayName(lang),
+			Category:    name.Category(),
+			Paired:      domain.PairedComponents[name],
+		})
 	}
-	if req.InstalledMileage != nil {
-		component.InstalledMileage = *req.InstalledMileage
+	c.JSON(http.StatusOK, types)
+}
+
+// @Summary Продлить ресурс компонента
+// @Description Поднимает max_mileage на delta или до нового значения после осмотра. Новый порог должен быть больше прежнего и больше уже пройденного пробега. Прежний порог и причина сохраняются в истории (событие component.life_extended)
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID компонента" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param request body ExtendLifeRequest true "Прибавка или новый порог и причина"
+// @Success 200 {object} domain.ComponentWear "Компонент с пересчитанным износом"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Прибавка или порог не больше текущего"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Компонент не найден"
+// @Failure 409 {object} errorResponse "Компонент уже заменён"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /components/{id}/extend-life [post]
+func (h *ComponentHandler) ExtendComponentLife(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	componentID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to ExtendComponentLife", map[string]interface{}{
+			"component_id": componentID,
+			"ip":           c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
 	}
-	if req.MaxMileage != nil {
-		component.MaxMileage = *req.MaxMileage
+
+	parsedID, err := uuid.Parse(componentID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid component ID")
+		return
 	}
 
-	updatedComponent, err := h.componentService.UpdateComponent(c.Request.Context(), component)
+	component, err := h.componentService.GetComponentByIDForWrite(c.Request.Context(), componentID)
 	if err != nil {
-		h.logger.Error("Failed to update component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
-		newErrorResponse(c, http.StatusInternalServerError, "Update failed")
+		newErrorResponse(c, http.StatusNotFound, "Component not found")
 		return
 	}
 
-	h.logger.Info("Component updated successfully", map[string]interface{}{
-		"component_id": componentID,
+	// смотрим че байк принадлежит юзеру
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), component.BikeID.String())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": component.BikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to extend component life", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	var req ExtendLifeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in extend component life", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	updated, err := h.componentService.ExtendComponentLife(c.Request.Context(), parsedID, domain.LifeExtension{
+		Delta:      req.Delta,
+		MaxMileage: req.MaxMileage,
+		Reason:     req.Reason,
 	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidLifeExtension) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if componentInputError(c, err) {
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to extend component life")
+		return
+	}
 
-	newSuccessResponse(c, http.StatusOK, "Component updated successfully", updatedComponent)
+	localizeComponents(preferredLanguage(c), updated)
+	now := time.Now()
+	wear := updated.Wear(bike.Mileage, now)
+	c.JSON(http.StatusOK, domain.ComponentWear{
+		Component: updated,
+		Wear:      wear,
+		Status:    domain.WearStatusOf(wear, h.warnPercent),
+		Triggers:  updated.Triggers(bike.Mileage, now),
+	})
 }
 
-// @Summary Удалить компонент
-// @Description Удаление компонента
+// @Summary Заменить компонент
+// @Description Снимает компонент и ставит вместо него новый с тем же названием и позицией, installed_mileage - текущий пробег байка. Старый компонент остаётся в истории с replaced_at: GET /components/{id} или GET /bikes/{id}/components?include_replaced=true. Без порогов в запросе новый получает пороги старого. В историю пишется событие component.replaced
 // @Tags components
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param id path string true "ID компонента" example:"123e4567-e89b-12d3-a456-426614174000"
-// @Success 200 {object} successResponse "Компонент удален"
+// @Param id path string true "ID компонента" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param request body ReplaceComponentRequest true "Новая деталь"
+// @Param Accept-Language header string false "Язык display_name" example:"ru"
+// @Success 201 {object} domain.Component "Установленный компонент"
+// @Failure 400 {object} errorResponse "Некорректный JSON или ID"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Failure 403 {object} errorResponse "Доступ запрещен"
 // @Failure 404 {object} errorResponse "Компонент не найден"
-// @Router /components/{id} [delete]
-func (h *ComponentHandler) DeleteComponent(c *gin.Context) {
+// @Failure 409 {object} errorResponse "Компонент уже заменён"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /components/{id}/replace [post]
+func (h *ComponentHandler) ReplaceComponent(c *gin.Context) {
 	start := time.Now()
 	defer func() {
 		h.metrics.RecordMetrics(c, start)
@@ -349,7 +792,7 @@ func (h *ComponentHandler) DeleteComponent(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to DeleteComponent", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to ReplaceComponent", map[string]interface{}{
 			"component_id": componentID,
 			"ip":           c.ClientIP(),
 		})
@@ -357,10 +800,15 @@ func (h *ComponentHandler) DeleteComponent(c *gin.Context) {
 		return
 	}
 
-	// Смотри че компонент существует
-	existingComponent, err := h.componentService.GetComponentByID(c.Request.Context(), componentID)
+	parsedID, err := uuid.Parse(componentID)
 	if err != nil {
-		h.logger.Error("Failed to get component", map[string]interface{}{
+		newErrorResponse(c, http.StatusBadRequest, "Invalid component ID")
+		return
+	}
+
+	component, err := h.componentService.GetComponentByIDForWrite(c.Request.Context(), componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
@@ -368,19 +816,19 @@ func (h *ComponentHandler) DeleteComponent(c *gin.Context) {
 		return
 	}
 
-	// проверяем что байк принадлежит юзеру
-	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), existingComponent.BikeID.String())
+	// смотрим че байк принадлежит юзеру
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), component.BikeID.String())
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
-			"bike_id": existingComponent.BikeID,
+			"bike_id": component.BikeID,
 		})
 		newErrorResponse(c, http.StatusNotFound, "Bike not found")
 		return
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied to delete component", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to replace component", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   bike.UserID.String(),
 			"component_id": componentID,
@@ -389,19 +837,751 @@ func (h *ComponentHandler) DeleteComponent(c *gin.Context) {
 		return
 	}
 
-	err = h.componentService.DeleteComponent(c.Request.Context(), componentID)
+	var req ReplaceComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in replace component", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	installed, err := h.componentService.ReplaceComponent(c.Request.Context(), parsedID, domain.ComponentReplacement{
+		Brand:      req.Brand,
+		Model:      req.Model,
+		MaxMileage: req.MaxMileage,
+		MaxAgeDays: req.MaxAgeDays,
+	})
 	if err != nil {
-		h.logger.Error("Failed to delete component", map[string]interface{}{
+		if componentInputError(c, err) {
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Error("Failed to replace component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
-		newErrorResponse(c, http.StatusInternalServerError, "Delete failed")
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to replace component")
 		return
 	}
 
-	h.logger.Info("Component deleted successfully", map[string]interface{}{
-		"component_id": componentID,
-	})
+	localizeComponents(preferredLanguage(c), installed)
+	c.JSON(http.StatusCreated, installed)
+}
 
-	newSuccessResponse(c, http.StatusOK, "Component deleted successfully", nil)
+// @Summary Веса компонентов в срочности
+// @Description Насколько износ компонента каждого типа важен для срочности обслуживания (GET /bikes/my/urgent): urgency байка = max(износ * вес). Тип без заданного веса считается с весом 1
+// @Tags components
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} ComponentWeightsResponse "Веса по типам"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /components/weights [get]
+func (h *ComponentHandler) GetComponentWeights(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	weights, err := h.componentService.GetComponentWeights(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get component weights")
+		return
+	}
+
+	c.JSON(http.StatusOK, ComponentWeightsResponse{Weights: weights})
+}
+
+// @Summary Изменить веса компонентов в срочности
+// @Description Только для админов. Меняет веса переданных типов (больше 0, не больше 10), остальные остаются как были. Новый порядок в GET /bikes/my/urgent действует сразу
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SetComponentWeightsRequest true "Новые веса"
+// @Success 200 {object} ComponentWeightsResponse "Все веса после изменения"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/components/weights [put]
+func (h *ComponentHandler) SetComponentWeights(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SetComponentWeightsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in set component weights", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	weights := make([]*domain.ComponentWeight, len(req.Weights))
+	for i, item := range req.Weights {
+		weights[i] = &domain.ComponentWeight{
+			Name:   domain.ComponentName(item.Name),
+			Weight: item.Weight,
+		}
+	}
+
+	if _, err := h.componentService.SetComponentWeights(c.Request.Context(), weights); err != nil {
+		if errors.Is(err, services.ErrInvalidComponentWeight) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to update component weights")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Component weights changed", map[string]interface{}{
+		"audit":    "component_weights",
+		"admin_id": payload.UserID.String(),
+		"count":    len(weights),
+	})
+
+	all, err := h.componentService.GetComponentWeights(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get component weights")
+		return
+	}
+
+	c.JSON(http.StatusOK, ComponentWeightsResponse{Weights: all})
+}
+
+// @Summary Пороги замены по умолчанию
+// @Description Для админов и сервисных API-ключей. Пороги, которые подставляются при создании компонента без max_mileage и max_age_days
+// @Tags admin
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Produce json
+// @Success 200 {object} ComponentDefaultsResponse "Пороги по умолчанию"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/components/defaults [get]
+func (h *ComponentHandler) GetComponentDefaults(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	defaults, err := h.componentService.GetComponentDefaults(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get component defaults")
+		return
+	}
+	if defaults == nil {
+		defaults = []*domain.ComponentDefault{}
+	}
+
+	c.JSON(http.StatusOK, ComponentDefaultsResponse{Defaults: defaults})
+}
+
+// @Summary Изменить пороги замены по умолчанию
+// @Description Только для админов. Обновляет пороги для переданных типов, остальные остаются как были. У каждого типа нужен хотя бы один из порогов
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SetComponentDefaultsRequest true "Новые пороги"
+// @Success 200 {object} ComponentDefaultsResponse "Все пороги после изменения"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/components/defaults [put]
+func (h *ComponentHandler) SetComponentDefaults(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SetComponentDefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in set component defaults", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	defaults := make([]*domain.ComponentDefault, len(req.Defaults))
+	for i, item := range req.Defaults {
+		defaults[i] = &domain.ComponentDefault{
+			Name:       domain.ComponentName(item.Name),
+			MaxMileage: item.MaxMileage,
+			MaxAgeDays: item.MaxAgeDays,
+		}
+	}
+
+	if _, err := h.componentService.SetComponentDefaults(c.Request.Context(), defaults); err != nil {
+		if errors.Is(err, services.ErrInvalidComponentDefault) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to update component defaults")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Component defaults changed", map[string]interface{}{
+		"audit":    "component_defaults",
+		"admin_id": payload.UserID.String(),
+		"count":    len(defaults),
+	})
+
+	all, err := h.componentService.GetComponentDefaults(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get component defaults")
+		return
+	}
+
+	c.JSON(http.StatusOK, ComponentDefaultsResponse{Defaults: all})
+}
+
+// @Summary Байк компонента
+// @Description Байк, на котором стоит компонент, без отдельного запроса за bike_id
+// @Tags components
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID компонента" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} GetBikeResponse "Байк найден"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Компонент или байк не найден"
+// @Router /components/{id}/bike [get]
+func (h *ComponentHandler) GetComponentBike(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	componentID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetComponentBike", map[string]interface{}{
+			"component_id": componentID,
+			"ip":           c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	component, err := h.componentService.GetComponentByID(c.Request.Context(), componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Component not found")
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), component.BikeID.String())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": component.BikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to component", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	c.JSON(http.StatusOK, newGetBikeResponse(bike))
+}
+
+// @Summary Заменить компонент
+// @Description Полная замена данных компонента. Непереданные brand, model и пороги замены очищаются, но хотя бы один из max_mileage и max_age_days обязателен
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID компонента" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
+// @Param request body ReplaceComponent true "Новые данные компонента"
+// @Success 200 {object} domain.Component "Компонент обновлен"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Компонент не найден"
+// @Failure 409 {object} errorResponse "Позиция занята парным компонентом или компонент уже заменён"
+// @Router /components/{id} [put]
+func (h *ComponentHandler) UpdateComponent(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	componentID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to UpdateComponent", map[string]interface{}{
+			"component_id": componentID,
+			"ip":           c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// смотрим че комп. существует
+	existingComponent, err := h.componentService.GetComponentByIDForWrite(c.Request.Context(), componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Component not found")
+		return
+	}
+
+	// смотрим че байк принадлежит юзеру
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), existingComponent.BikeID.String())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": existingComponent.BikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to update component", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	var req ReplaceComponent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in update component", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	parsedID, err := uuid.Parse(componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Invalid component ID format", map[string]interface{}{
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusBadRequest, "Invalid component ID")
+		return
+	}
+
+	component := &domain.Component{
+		ID:               parsedID,
+		BikeID:           existingComponent.BikeID,
+		Name:             domain.ComponentName(req.Name),
+		Brand:            req.Brand,
+		Model:            req.Model,
+		InstalledAt:      existingComponent.InstalledAt,
+		InstalledMileage: *req.InstalledMileage,
+		MaxMileage:       req.MaxMileage,
+		MaxAgeDays:       req.MaxAgeDays,
+		Position:         domain.Position(req.Position),
+	}
+	if req.InstalledAt != nil {
+		component.InstalledAt = *req.InstalledAt
+	}
+
+	updatedComponent, err := h.componentService.UpdateComponent(c.Request.Context(), component)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to update component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		if componentInputError(c, err) {
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Update failed")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Component updated successfully", map[string]interface{}{
+		"component_id": componentID,
+	})
+
+	newSuccessResponse(c, http.StatusOK, "Component updated successfully", updatedComponent)
+}
+
+// @Summary Частично обновить компонент
+// @Description Обновление только переданных полей компонента, остальные остаются без изменений
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID компонента" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
+// @Param request body UpdateComponent true "Данные для обновления"
+// @Param Prefer header string false "return=minimal - вернуть только изменённые поля, id и updated_at" example:"return=minimal"
+// @Success 200 {object} domain.Component "Компонент обновлен"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Компонент не найден"
+// @Failure 409 {object} errorResponse "Позиция занята парным компонентом или компонент уже заменён"
+// @Router /components/{id} [patch]
+func (h *ComponentHandler) PatchComponent(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	componentID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to PatchComponent", map[string]interface{}{
+			"component_id": componentID,
+			"ip":           c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// смотрим че комп. существует
+	existingComponent, err := h.componentService.GetComponentByIDForWrite(c.Request.Context(), componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Component not found")
+		return
+	}
+
+	// смотрим че байк принадлежит юзеру
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), existingComponent.BikeID.String())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": existingComponent.BikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to update component", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	var req UpdateComponent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in update component", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	parsedID, err := uuid.Parse(componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Invalid component ID format", map[string]interface{}{
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusBadRequest, "Invalid component ID")
+		return
+	}
+
+	// берём текущее состояние и накладываем только переданные поля
+	component := *existingComponent
+	component.ID = parsedID
+	req.applyTo(&component)
+
+	updatedComponent, err := h.componentService.UpdateComponent(c.Request.Context(), &component)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to update component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		if componentInputError(c, err) {
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Update failed")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Component updated successfully", map[string]interface{}{
+		"component_id": componentID,
+	})
+
+	if preferMinimal(c) {
+		changed, err := services.ChangedFields(existingComponent, updatedComponent)
+		if err == nil {
+			c.Header("Preference-Applied", "return=minimal")
+			newSuccessResponse(c, http.StatusOK, "Component updated successfully", changed)
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Warn("Failed to diff component, returning full object", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+	}
+
+	newSuccessResponse(c, http.StatusOK, "Component updated successfully", updatedComponent)
+}
+
+// @Summary Удалить компонент
+// @Description Удаление компонента
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID компонента" example:"123e4567-e89b-12d3-a456-426614174000"
+// @Success 200 {object} successResponse "Компонент удален"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Компонент не найден"
+// @Router /components/{id} [delete]
+func (h *ComponentHandler) DeleteComponent(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	componentID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to DeleteComponent", map[string]interface{}{
+			"component_id": componentID,
+			"ip":           c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Смотри че компонент существует
+	existingComponent, err := h.componentService.GetComponentByIDForWrite(c.Request.Context(), componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Component not found")
+		return
+	}
+
+	// проверяем что байк принадлежит юзеру
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), existingComponent.BikeID.String())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": existingComponent.BikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to delete component", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	err = h.componentService.DeleteComponent(c.Request.Context(), componentID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to delete component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Delete failed")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Component deleted successfully", map[string]interface{}{
+		"component_id": componentID,
+	})
+
+	newSuccessResponse(c, http.StatusOK, "Component deleted successfully", nil)
+}
+
+// @Summary Массовое обновление компонентов
+// @Description Частичное обновление нескольких компонентов за один запрос. В режиме atomic (по умолчанию) любая ошибка — чужой компонент, невалидные данные — отменяет весь батч, иначе применяются все успешные элементы. Некорректные ID перечисляются все сразу в поле invalid. Не больше 100 элементов и не больше MAX_BATCH_SIZE
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body BatchUpdateComponentsRequest true "Список обновлений"
+// @Success 200 {object} BatchUpdateComponentsResponse "Результат по каждому компоненту"
+// @Failure 400 {object} BatchUpdateComponentsResponse "Батч отклонён"
+// @Failure 413 {object} errorResponse "Слишком большой батч"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} BatchUpdateComponentsResponse "В батче есть чужие компоненты"
+// @Router /components/batch [patch]
+func (h *ComponentHandler) BatchUpdateComponents(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to BatchUpdateComponents", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req BatchUpdateComponentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in batch update components", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if batchTooLarge(c, len(req.Items), maxComponentBatchSize, h.maxBatchSize, "items") {
+		return
+	}
+	ids := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		ids[i] = item.ID
+	}
+	if _, invalid := parseUUIDs(ids); len(invalid) > 0 {
+		newInvalidIDsResponse(c, invalid)
+		return
+	}
+	atomic := req.Atomic == nil || *req.Atomic
+
+	ctx := c.Request.Context()
+	results := make([]BatchItemResult, len(req.Items))
+	components := make([]*domain.Component, 0, len(req.Items))
+	positions := make([]int, 0, len(req.Items))
+	owners := make(map[uuid.UUID]uuid.UUID)
+	forbidden := false
+
+	// сначала проверяем права на каждый элемент, в базу пока ничего не пишем
+	for i, item := range req.Items {
+		results[i] = BatchItemResult{ID: item.ID, Status: batchStatusFailed}
+
+		existing, err := h.componentService.GetComponentByIDForWrite(ctx, item.ID)
+		if err != nil {
+			results[i].Error = "component not found"
+			continue
+		}
+
+		ownerID, ok := owners[existing.BikeID]
+		if !ok {
+			bike, err := h.bikeService.GetBikeByIDForWrite(ctx, existing.BikeID.String())
+			if err != nil {
+				results[i].Error = "bike not found"
+				continue
+			}
+			ownerID = bike.UserID
+			owners[existing.BikeID] = ownerID
+		}
+
+		if payload.Role != domain.Admin && payload.UserID != ownerID {
+			h.logger.WithContext(c.Request.Context()).Warn("Access denied to batch update component", map[string]interface{}{
+				"requester_id": payload.UserID.String(),
+				"bike_owner":   ownerID.String(),
+				"component_id": item.ID,
+			})
+			results[i].Error = "access denied"
+			forbidden = true
+			continue
+		}
+
+		component := *existing
+		item.applyTo(&component)
+		components = append(components, &component)
+		positions = append(positions, i)
+		results[i].Status = batchStatusSkipped
+	}
+
+	if atomic && len(components) != len(req.Items) {
+		status := http.StatusBadRequest
+		if forbidden {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, summarizeBatch(results))
+		return
+	}
+
+	updates, err := h.componentService.UpdateComponents(ctx, components, atomic)
+	for j, update := range updates {
+		i := positions[j]
+		switch {
+		case update.Err != nil:
+			results[i].Status = batchStatusFailed
+			results[i].Error = update.Err.Error()
+		case update.Component != nil:
+			results[i].Status = batchStatusUpdated
+			results[i].Component = update.Component
+		}
+	}
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to batch update components", map[string]interface{}{
+			"error": err.Error(),
+		})
+		c.JSON(http.StatusBadRequest, summarizeBatch(results))
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Components batch updated", map[string]interface{}{
+		"requested": len(req.Items),
+		"atomic":    atomic,
+	})
+
+	c.JSON(http.StatusOK, summarizeBatch(results))
+}
+
+func summarizeBatch(results []BatchItemResult) BatchUpdateComponentsResponse {
+	response := BatchUpdateComponentsResponse{Results: results}
+	for _, r := range results {
+		switch r.Status {
+		case batchStatusUpdated:
+			response.Updated++
+		case batchStatusFailed:
+			response.Failed++
+		}
+	}
+	return response
 }
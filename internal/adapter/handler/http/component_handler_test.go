@@ -0,0 +1,871 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateComponentPutAndPatch(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		want       domain.Component
+	}{
+		{
+			name:       "PUT заменяет компонент целиком",
+			method:     http.MethodPut,
+			body:       `{"name":"wheels","installed_mileage":200,"max_mileage":8000}`,
+			wantStatus: http.StatusOK,
+			want:       domain.Component{Name: domain.Wheels, InstalledMileage: 200, MaxMileage: 8000, Position: domain.PositionNone},
+		},
+		{
+			name:       "PUT без installed_mileage",
+			method:     http.MethodPut,
+			body:       `{"name":"wheels","max_mileage":8000}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       fullComponent(),
+		},
+		{
+			name:       "PATCH меняет только переданные поля",
+			method:     http.MethodPatch,
+			body:       `{"brand":"SRAM","max_mileage":9000}`,
+			wantStatus: http.StatusOK,
+			want: func() domain.Component {
+				c := fullComponent()
+				c.Brand, c.MaxMileage = "SRAM", 9000
+				return c
+			}(),
+		},
+		{
+			name:       "PATCH с пустым телом ничего не меняет",
+			method:     http.MethodPatch,
+			body:       `{}`,
+			wantStatus: http.StatusOK,
+			want:       fullComponent(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 1000)
+			c := fullComponent()
+			c.BikeID, c.InstalledAt = bike.BikeID, bike.CreatedAt
+			component := api.store.AddComponent(&c)
+
+			w := api.do(tt.method, "/components/"+component.ID.String(), api.token(owner, domain.AppUser), tt.body)
+			expectStatus(t, w, tt.wantStatus)
+
+			got, _ := api.store.Component(component.ID)
+			if got.Name != tt.want.Name || got.Brand != tt.want.Brand || got.Model != tt.want.Model ||
+				got.InstalledMileage != tt.want.InstalledMileage || got.MaxMileage != tt.want.MaxMileage ||
+				got.Position != tt.want.Position || !equalIntPtr(got.MaxAgeDays, tt.want.MaxAgeDays) {
+				t.Errorf("component = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// fullComponent - компонент до обновления: все необязательные поля заполнены
+func fullComponent() domain.Component {
+	days := 365
+	return domain.Component{
+		Name:             domain.Wheels,
+		Brand:            "Shimano",
+		Model:            "XT",
+		InstalledMileage: 100,
+		MaxMileage:       5000,
+		MaxAgeDays:       &days,
+		Position:         domain.PositionFront,
+	}
+}
+
+// equalIntPtr сравнивает необязательные числа по значению
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// очищенные PUT'ом поля в ответе должны пропасть, а не остаться прежними
+func TestReplaceComponentResponseClearsFields(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	bike := api.addBike(owner, 1000)
+	c := fullComponent()
+	c.BikeID, c.InstalledAt = bike.BikeID, bike.CreatedAt
+	component := api.store.AddComponent(&c)
+
+	w := api.do(http.MethodPut, "/components/"+component.ID.String(), api.token(owner, domain.AppUser),
+		`{"name":"wheels","installed_mileage":100,"max_mileage":5000}`)
+	expectStatus(t, w, http.StatusOK)
+
+	resp := decode[struct {
+		Data map[string]any `json:"data"`
+	}](t, w)
+	for _, field := range []string{"brand", "model", "max_age_days"} {
+		if v, ok := resp.Data[field]; ok {
+			t.Errorf("%s = %v, want it omitted", field, v)
+		}
+	}
+	if resp.Data["position"] != string(domain.PositionNone) {
+		t.Errorf("position = %v, want %s", resp.Data["position"], domain.PositionNone)
+	}
+}
+
+// заменённый компонент - история: ни одна правка его не переписывает, а
+// ответ на замену отдаёт его с replaced_at
+func TestReplacedComponentIsReadOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   func(old *domain.Component) string
+		body   func(old *domain.Component) string
+		status int
+	}{
+		{name: "PUT", method: http.MethodPut, status: http.StatusConflict,
+			path: func(old *domain.Component) string { return "/components/" + old.ID.String() },
+			body: func(*domain.Component) string {
+				return `{"name":"handlebars","installed_mileage":0,"max_mileage":9000}`
+			}},
+		{name: "PATCH", method: http.MethodPatch, status: http.StatusConflict,
+			path: func(old *domain.Component) string { return "/components/" + old.ID.String() },
+			body: func(*domain.Component) string { return `{"max_mileage":9000}` }},
+		{name: "продление ресурса", method: http.MethodPost, status: http.StatusConflict,
+			path: func(old *domain.Component) string { return "/components/" + old.ID.String() + "/extend-life" },
+			body: func(*domain.Component) string { return `{"max_mileage":9000,"reason":"осмотр"}` }},
+		{name: "пакетный PATCH", method: http.MethodPatch, status: http.StatusBadRequest,
+			path: func(*domain.Component) string { return "/components/batch" },
+			body: func(old *domain.Component) string {
+				return `{"items":[{"id":"` + old.ID.String() + `","max_mileage":9000}]}`
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			token := api.token(owner, domain.AppUser)
+			old := api.addComponent(api.addBike(owner, 1000), domain.Handlebars, 0)
+			expectStatus(t, api.do(http.MethodPost, "/components/"+old.ID.String()+"/replace", token, `{"brand":"Renthal"}`), http.StatusCreated)
+			replaced, _ := api.store.Component(old.ID)
+
+			expectStatus(t, api.do(tt.method, tt.path(old), token, tt.body(old)), tt.status)
+
+			got, _ := api.store.Component(old.ID)
+			if got.MaxMileage != replaced.MaxMileage || got.ReplacedAt == nil || !got.UpdatedAt.Equal(replaced.UpdatedAt) {
+				t.Errorf("replaced component = %+v, want it unchanged", got)
+			}
+		})
+	}
+}
+
+func TestBatchUpdateComponents(t *testing.T) {
+	owner, stranger, admin := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		requester uuid.UUID
+		role      domain.UserRole
+		// второй элемент батча - компонент stranger'а, если foreign
+		foreign    bool
+		atomic     string
+		secondBody string
+		wantStatus int
+		// wantBrands - бренды обоих компонентов после запроса
+		wantBrands [2]string
+		wantResult [2]string
+	}{
+		{name: "свои компоненты", requester: owner, role: domain.AppUser, wantStatus: http.StatusOK,
+			wantBrands: [2]string{"SRAM", "SRAM"}, wantResult: [2]string{batchStatusUpdated, batchStatusUpdated}},
+		{name: "чужой компонент отменяет атомарный батч", requester: owner, role: domain.AppUser, foreign: true, wantStatus: http.StatusForbidden,
+			wantBrands: [2]string{"Shimano", "Shimano"}, wantResult: [2]string{batchStatusSkipped, batchStatusFailed}},
+		{name: "чужой компонент в best-effort батче", requester: owner, role: domain.AppUser, foreign: true, atomic: `,"atomic":false`, wantStatus: http.StatusOK,
+			wantBrands: [2]string{"SRAM", "Shimano"}, wantResult: [2]string{batchStatusUpdated, batchStatusFailed}},
+		{name: "админ обновляет чужие компоненты", requester: admin, role: domain.Admin, foreign: true, wantStatus: http.StatusOK,
+			wantBrands: [2]string{"SRAM", "SRAM"}, wantResult: [2]string{batchStatusUpdated, batchStatusUpdated}},
+		{name: "невалидный элемент откатывает атомарный батч", requester: owner, role: domain.AppUser, secondBody: `"max_mileage":-5`, wantStatus: http.StatusBadRequest,
+			wantBrands: [2]string{"Shimano", "Shimano"}, wantResult: [2]string{batchStatusSkipped, batchStatusFailed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			first := api.addComponent(api.addBike(owner, 1000), domain.Handlebars, 0)
+			secondOwner := owner
+			if tt.foreign {
+				secondOwner = stranger
+			}
+			second := api.addComponent(api.addBike(secondOwner, 1000), domain.Frame, 0)
+			for _, c := range []*domain.Component{first, second} {
+				c.Brand = "Shimano"
+				api.store.AddComponent(c)
+			}
+			secondBody := `"brand":"SRAM"`
+			if tt.secondBody != "" {
+				secondBody += "," + tt.secondBody
+			}
+
+			body := `{"items":[{"id":"` + first.ID.String() + `","brand":"SRAM"},{"id":"` + second.ID.String() + `",` + secondBody + `}]` + tt.atomic + `}`
+			w := api.do(http.MethodPatch, "/components/batch", api.token(tt.requester, tt.role), body)
+			expectStatus(t, w, tt.wantStatus)
+
+			resp := decode[BatchUpdateComponentsResponse](t, w)
+			for i, c := range []*domain.Component{first, second} {
+				if got, _ := api.store.Component(c.ID); got.Brand != tt.wantBrands[i] {
+					t.Errorf("item %d brand = %q, want %q", i, got.Brand, tt.wantBrands[i])
+				}
+				if resp.Results[i].Status != tt.wantResult[i] {
+					t.Errorf("item %d status = %q (%s), want %q", i, resp.Results[i].Status, resp.Results[i].Error, tt.wantResult[i])
+				}
+			}
+		})
+	}
+}
+
+// ошибки дат установки различимы для клиента
+func TestCreateComponentInstalledAtErrors(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name        string
+		installedAt func(bike *domain.Bike) time.Time
+		wantMessage string
+	}{
+		{name: "в будущем", installedAt: func(*domain.Bike) time.Time { return time.Now().Add(48 * time.Hour) }, wantMessage: "installed_at must not be in the future"},
+		{name: "до создания байка", installedAt: func(b *domain.Bike) time.Time { return b.CreatedAt.Add(-time.Hour) }, wantMessage: services.ErrInstalledBeforeBike.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+
+			w := api.do(http.MethodPost, "/components", api.token(owner, domain.AppUser), ComponentRequest{
+				BikeID:           bike.BikeID.String(),
+				Name:             string(domain.Handlebars),
+				InstalledMileage: 1,
+				MaxMileage:       5000,
+				InstalledAt:      ptr(tt.installedAt(bike)),
+			})
+			expectStatus(t, w, http.StatusUnprocessableEntity)
+			if msg := decode[errorResponse](t, w).Message; !strings.HasPrefix(msg, tt.wantMessage) {
+				t.Errorf("message = %q, want prefix %q", msg, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestComponentDisplayNameLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		wantLang       string
+		wantHandlebars string
+	}{
+		{name: "без заголовка", wantLang: "en", wantHandlebars: "Handlebars"},
+		{name: "английский", acceptLanguage: "en-US", wantLang: "en", wantHandlebars: "Handlebars"},
+		{name: "русский с регионом", acceptLanguage: "ru-RU,en;q=0.8", wantLang: "ru", wantHandlebars: "Руль"},
+		{name: "по весу q", acceptLanguage: "en;q=0.3, ru;q=0.9", wantLang: "ru", wantHandlebars: "Руль"},
+		{name: "первый поддерживаемый", acceptLanguage: "de, ru;q=0.5", wantLang: "ru", wantHandlebars: "Руль"},
+		{name: "неподдерживаемый язык", acceptLanguage: "fr", wantLang: "en", wantHandlebars: "Handlebars"},
+		{name: "отказ от языка через q=0", acceptLanguage: "ru;q=0", wantLang: "en", wantHandlebars: "Handlebars"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			bike := api.addBike(owner, 100)
+			component := api.addComponent(bike, domain.Handlebars, 0)
+			token := api.token(owner, domain.AppUser)
+			var headers []string
+			if tt.acceptLanguage != "" {
+				headers = []string{"Accept-Language", tt.acceptLanguage}
+			}
+
+			w := api.do(http.MethodGet, "/components/types", token, nil, headers...)
+			expectStatus(t, w, http.StatusOK)
+			if got := w.Header().Get("Content-Language"); got != tt.wantLang {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLang)
+			}
+			for _, info := range decode[[]ComponentTypeInfo](t, w) {
+				if info.Name == domain.Handlebars && info.DisplayName != tt.wantHandlebars {
+					t.Errorf("types display_name = %q, want %q", info.DisplayName, tt.wantHandlebars)
+				}
+			}
+
+			// name остаётся значением enum, display_name - перевод
+			w = api.do(http.MethodGet, "/components/"+component.ID.String(), token, nil, headers...)
+			expectStatus(t, w, http.StatusOK)
+			if got := decode[struct {
+				Data domain.Component `json:"data"`
+			}](t, w).Data; got.Name != domain.Handlebars || got.DisplayName != tt.wantHandlebars {
+				t.Errorf("component name, display_name = %q, %q, want %q, %q", got.Name, got.DisplayName, domain.Handlebars, tt.wantHandlebars)
+			}
+		})
+	}
+}
+
+// в строгом режиме установка раньше модельного года отклоняется с 422
+func TestCreateComponentBeforeModelYear(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		opts       []testAPIOption
+		wantStatus int
+	}{
+		{name: "по умолчанию только предупреждение", wantStatus: http.StatusCreated},
+		{name: "строгий режим", opts: []testAPIOption{withStrictComponentYear()}, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t, tt.opts...)
+			bike := api.addBike(owner, 100)
+			bike.Year = ptr(2020)
+			bike.CreatedAt = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+			api.store.AddBike(bike)
+
+			w := api.do(http.MethodPost, "/components", api.token(owner, domain.AppUser), ComponentRequest{
+				BikeID:           bike.BikeID.String(),
+				Name:             string(domain.Handlebars),
+				InstalledMileage: 1,
+				MaxMileage:       5000,
+				InstalledAt:      ptr(time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)),
+			})
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusCreated {
+				if msg := decode[errorResponse](t, w).Message; !strings.HasPrefix(msg, services.ErrInstalledBeforeModelYear.Error()) {
+					t.Errorf("message = %q", msg)
+				}
+			}
+		})
+	}
+}
+
+func TestGetComponentTypesCategories(t *testing.T) {
+	api := newTestAPI(t)
+	w := api.do(http.MethodGet, "/components/types", api.token(uuid.New(), domain.AppUser), nil)
+	expectStatus(t, w, http.StatusOK)
+
+	types := decode[[]ComponentTypeInfo](t, w)
+	if len(types) != len(domain.ComponentNames) {
+		t.Fatalf("got %d types, want %d", len(types), len(domain.ComponentNames))
+	}
+	for _, info := range types {
+		if info.Category != info.Name.Category() {
+			t.Errorf("%s category = %q, want %q", info.Name, info.Category, info.Name.Category())
+		}
+	}
+}
+
+func TestCreateComponentIfBikeMileageLTE(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "без условия", wantStatus: http.StatusCreated},
+		{name: "пробег меньше N", query: "?if_bike_mileage_lte=1500", wantStatus: http.StatusCreated},
+		{name: "пробег равен N", query: "?if_bike_mileage_lte=1000", wantStatus: http.StatusCreated},
+		{name: "пробег больше N", query: "?if_bike_mileage_lte=999", wantStatus: http.StatusPreconditionFailed},
+		{name: "ноль", query: "?if_bike_mileage_lte=0", wantStatus: http.StatusPreconditionFailed},
+		{name: "отрицательное N", query: "?if_bike_mileage_lte=-1", wantStatus: http.StatusBadRequest},
+		{name: "N не число", query: "?if_bike_mileage_lte=lots", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 1000)
+
+			w := api.do(http.MethodPost, "/components"+tt.query, api.token(owner, domain.AppUser), ComponentRequest{
+				BikeID:           bike.BikeID.String(),
+				Name:             string(domain.Handlebars),
+				InstalledMileage: 1,
+				MaxMileage:       5000,
+			})
+			expectStatus(t, w, tt.wantStatus)
+			if created := len(api.store.Components(bike.BikeID)) == 1; created != (tt.wantStatus == http.StatusCreated) {
+				t.Errorf("component created = %t", created)
+			}
+		})
+	}
+}
+
+// владелец и пробег для записи компонента берутся с primary, а не из кеша
+// байка: здесь кеш остался от прежнего владельца и прежнего пробега
+func TestCreateComponentIgnoresCachedBike(t *testing.T) {
+	previous, owner := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name             string
+		user             uuid.UUID
+		query            string
+		installedMileage int
+		wantStatus       int
+	}{
+		{name: "прежний владелец", user: previous, installedMileage: 1, wantStatus: http.StatusForbidden},
+		{name: "предусловие по текущему пробегу", user: owner, query: "?if_bike_mileage_lte=1500", installedMileage: 1, wantStatus: http.StatusPreconditionFailed},
+		{name: "пробег установки по текущему пробегу", user: owner, installedMileage: 1500, wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(previous, 1000)
+			expectStatus(t, api.do(http.MethodGet, "/bikes/"+bike.BikeID.String(), api.token(previous, domain.AppUser), nil), http.StatusOK)
+			bike.UserID, bike.Mileage = owner, 2000
+			api.store.AddBike(bike)
+
+			w := api.do(http.MethodPost, "/components"+tt.query, api.token(tt.user, domain.AppUser), ComponentRequest{
+				BikeID:           bike.BikeID.String(),
+				Name:             string(domain.Handlebars),
+				InstalledMileage: tt.installedMileage,
+				MaxMileage:       5000,
+			})
+			expectStatus(t, w, tt.wantStatus)
+		})
+	}
+}
+
+// обновление не должно обходить проверку создания: installed_mileage <= пробег байка
+func TestUpdateComponentInstalledMileageAboveBike(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{name: "PATCH выше пробега", method: http.MethodPatch, body: `{"installed_mileage":1001}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "PUT выше пробега", method: http.MethodPut, body: `{"name":"handlebars","installed_mileage":1001,"max_mileage":5000}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "PATCH равен пробегу", method: http.MethodPatch, body: `{"installed_mileage":1000}`, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			component := api.addComponent(api.addBike(owner, 1000), domain.Handlebars, 0)
+
+			w := api.do(tt.method, "/components/"+component.ID.String(), api.token(owner, domain.AppUser), tt.body)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				if msg := decode[errorResponse](t, w).Message; !strings.HasPrefix(msg, services.ErrInstalledMileageAboveBike.Error()) {
+					t.Errorf("message = %q", msg)
+				}
+				if got, _ := api.store.Component(component.ID); got.InstalledMileage != 0 {
+					t.Errorf("installed_mileage = %d, want unchanged", got.InstalledMileage)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchComponentPreferMinimal(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name        string
+		prefer      string
+		wantMinimal bool
+	}{
+		{name: "по умолчанию полный объект"},
+		{name: "return=minimal", prefer: "return=minimal", wantMinimal: true},
+		{name: "среди других предпочтений", prefer: "respond-async, RETURN=MINIMAL", wantMinimal: true},
+		{name: "return=representation", prefer: "return=representation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			component := api.addComponent(api.addBike(owner, 1000), domain.Handlebars, 0)
+			var headers []string
+			if tt.prefer != "" {
+				headers = []string{"Prefer", tt.prefer}
+			}
+
+			w := api.do(http.MethodPatch, "/components/"+component.ID.String(), api.token(owner, domain.AppUser), `{"brand":"SRAM"}`, headers...)
+			expectStatus(t, w, http.StatusOK)
+			if applied := w.Header().Get("Preference-Applied") == "return=minimal"; applied != tt.wantMinimal {
+				t.Errorf("Preference-Applied = %q", w.Header().Get("Preference-Applied"))
+			}
+
+			data := decode[struct {
+				Data map[string]any `json:"data"`
+			}](t, w).Data
+			if data["brand"] != "SRAM" || data["id"] != component.ID.String() || data["updated_at"] == nil {
+				t.Errorf("data = %v, want brand, id and updated_at", data)
+			}
+			// неизменённые поля есть только в полном ответе
+			if _, full := data["max_mileage"]; full == tt.wantMinimal {
+				t.Errorf("data = %v, want minimal: %t", data, tt.wantMinimal)
+			}
+		})
+	}
+}
+
+func TestCreateComponentPosition(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name string
+		// existing - уже стоящий на байке компонент
+		existing     *domain.Component
+		component    string
+		position     string
+		wantStatus   int
+		wantPosition domain.Position
+	}{
+		{name: "второе переднее колесо", existing: &domain.Component{Name: domain.Wheels, Position: domain.PositionFront}, component: "wheels", position: "front", wantStatus: http.StatusConflict},
+		{name: "заднее к переднему", existing: &domain.Component{Name: domain.Wheels, Position: domain.PositionFront}, component: "wheels", position: "rear", wantStatus: http.StatusCreated, wantPosition: domain.PositionRear},
+		{name: "вместо заменённого переднего", existing: &domain.Component{Name: domain.Wheels, Position: domain.PositionFront, ReplacedAt: ptr(time.Now())}, component: "wheels", position: "front", wantStatus: http.StatusCreated, wantPosition: domain.PositionFront},
+		{name: "колесо без позиции дважды", existing: &domain.Component{Name: domain.Wheels, Position: domain.PositionNone}, component: "wheels", wantStatus: http.StatusConflict},
+		{name: "непарный компонент не ограничен", existing: &domain.Component{Name: domain.Handlebars, Position: domain.PositionFront}, component: "handlebars", position: "front", wantStatus: http.StatusCreated, wantPosition: domain.PositionFront},
+		{name: "без позиции - none", component: "wheels", wantStatus: http.StatusCreated, wantPosition: domain.PositionNone},
+		{name: "неизвестная позиция", component: "wheels", position: "middle", wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 1000)
+			if tt.existing != nil {
+				existing := api.addComponent(bike, tt.existing.Name, 0)
+				existing.Position, existing.ReplacedAt = tt.existing.Position, tt.existing.ReplacedAt
+				api.store.AddComponent(existing)
+			}
+
+			w := api.do(http.MethodPost, "/components", api.token(owner, domain.AppUser), ComponentRequest{
+				BikeID:           bike.BikeID.String(),
+				Name:             tt.component,
+				InstalledMileage: 1,
+				MaxMileage:       5000,
+				Position:         tt.position,
+			})
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			resp := decode[struct {
+				Data domain.Component `json:"data"`
+			}](t, w)
+			if resp.Data.Position != tt.wantPosition {
+				t.Errorf("position = %q, want %q", resp.Data.Position, tt.wantPosition)
+			}
+		})
+	}
+}
+
+// перенос колеса на занятую позицию - тот же конфликт, что и при создании
+func TestUpdateComponentPositionTaken(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	bike := api.addBike(owner, 1000)
+	front := api.addComponent(bike, domain.Wheels, 0)
+	front.Position = domain.PositionFront
+	api.store.AddComponent(front)
+	rear := api.addComponent(bike, domain.Wheels, 0)
+	rear.Position = domain.PositionRear
+	api.store.AddComponent(rear)
+
+	w := api.do(http.MethodPatch, "/components/"+rear.ID.String(), api.token(owner, domain.AppUser), `{"position":"front"}`)
+	expectStatus(t, w, http.StatusConflict)
+	if got, _ := api.store.Component(rear.ID); got.Position != domain.PositionRear {
+		t.Errorf("position = %q, want rear", got.Position)
+	}
+}
+
+// байк удалили между проверкой в обработчике и вставкой: репозиторий
+// отвечает ErrBikeNotFound, и это должно стать 404, а не 500
+func TestCreateComponentBikeDeletedDuringCreate(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	bike := api.addBike(owner, 1000)
+	api.store.SetError("CreateComponent", domain.ErrBikeNotFound)
+
+	w := api.do(http.MethodPost, "/components", api.token(owner, domain.AppUser), ComponentRequest{
+		BikeID:           bike.BikeID.String(),
+		Name:             string(domain.Handlebars),
+		InstalledMileage: 1,
+		MaxMileage:       5000,
+	})
+	expectStatus(t, w, http.StatusNotFound)
+	if events := api.store.Events(); len(events) != 0 {
+		t.Errorf("got %d outbox events, want none", len(events))
+	}
+}
+
+// удалённый байк для компонентов всё равно что несуществующий: на него
+// нельзя ни поставить деталь, ни заменить её, ни перенести сироту
+func TestComponentWritesOnDeletedBike(t *testing.T) {
+	component := `{"name":"frame","installed_mileage":1,"max_mileage":5000}`
+	tests := []struct {
+		name   string
+		admin  bool
+		method string
+		path   func(bike *domain.Bike, old, orphan *domain.Component) string
+		body   func(bike *domain.Bike, old, orphan *domain.Component) string
+	}{
+		{name: "создание", method: http.MethodPost,
+			path: func(*domain.Bike, *domain.Component, *domain.Component) string { return "/components" },
+			body: func(bike *domain.Bike, _, _ *domain.Component) string {
+				return `{"bike_id":"` + bike.BikeID.String() + `","name":"frame","installed_mileage":1,"max_mileage":5000}`
+			}},
+		{name: "создание пачкой", method: http.MethodPost,
+			path: func(*domain.Bike, *domain.Component, *domain.Component) string { return "/components/batch" },
+			body: func(bike *domain.Bike, _, _ *domain.Component) string {
+				return `{"bike_id":"` + bike.BikeID.String() + `","components":[` + component + `]}`
+			}},
+		{name: "импорт", method: http.MethodPost,
+			path: func(bike *domain.Bike, _, _ *domain.Component) string {
+				return "/bikes/" + bike.BikeID.String() + "/components/import"
+			},
+			body: func(*domain.Bike, *domain.Component, *domain.Component) string {
+				return `{"components":[` + component + `]}`
+			}},
+		{name: "замена", method: http.MethodPost,
+			path: func(_ *domain.Bike, old, _ *domain.Component) string {
+				return "/components/" + old.ID.String() + "/replace"
+			},
+			body: func(*domain.Bike, *domain.Component, *domain.Component) string { return `{"brand":"Renthal"}` }},
+		{name: "перенос сироты", admin: true, method: http.MethodPost,
+			path: func(*domain.Bike, *domain.Component, *domain.Component) string { return "/admin/components/orphaned" },
+			body: func(bike *domain.Bike, _, orphan *domain.Component) string {
+				return `{"action":"reassign","component_ids":["` + orphan.ID.String() + `"],"bike_id":"` + bike.BikeID.String() + `"}`
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			bike := api.addBike(owner, 1000)
+			old := api.addComponent(bike, domain.Handlebars, 0)
+			orphan := api.store.AddComponent(&domain.Component{BikeID: uuid.New(), Name: domain.Wheels, MaxMileage: 5000, InstalledAt: bike.CreatedAt})
+			token := api.token(owner, domain.AppUser)
+			expectStatus(t, api.do(http.MethodDelete, "/bikes/"+bike.BikeID.String(), token, nil), http.StatusOK)
+			if tt.admin {
+				token = api.token(uuid.New(), domain.Admin)
+			}
+
+			w := api.do(tt.method, tt.path(bike, old, orphan), token, tt.body(bike, old, orphan))
+			expectStatus(t, w, http.StatusNotFound)
+
+			stored := api.store.Components(bike.BikeID)
+			if len(stored) != 1 || stored[0].ReplacedAt != nil {
+				t.Errorf("components of the deleted bike = %+v, want only the untouched original", stored)
+			}
+		})
+	}
+}
+
+func TestExtendComponentLife(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		body       string
+		wantStatus int
+		wantMax    int
+	}{
+		{name: "прибавка", requester: owner, body: `{"delta":2000,"reason":"осмотр"}`, wantStatus: http.StatusOK, wantMax: 7000},
+		{name: "новый порог", requester: owner, body: `{"max_mileage":6000,"reason":"осмотр"}`, wantStatus: http.StatusOK, wantMax: 6000},
+		{name: "порог ниже текущего", requester: owner, body: `{"max_mileage":4500,"reason":"осмотр"}`, wantStatus: http.StatusUnprocessableEntity, wantMax: 5000},
+		{name: "порог ниже пройденного", requester: owner, body: `{"max_mileage":5500,"reason":"осмотр"}`, wantStatus: http.StatusUnprocessableEntity, wantMax: 5000},
+		{name: "без причины", requester: owner, body: `{"delta":2000}`, wantStatus: http.StatusUnprocessableEntity, wantMax: 5000},
+		{name: "чужой компонент", requester: uuid.New(), body: `{"delta":2000,"reason":"осмотр"}`, wantStatus: http.StatusForbidden, wantMax: 5000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			// компонент проехал 5800 при пороге 5000
+			component := api.addComponent(api.addBike(owner, 6000), domain.Handlebars, 200)
+
+			w := api.do(http.MethodPost, "/components/"+component.ID.String()+"/extend-life", api.token(tt.requester, domain.AppUser), tt.body)
+			expectStatus(t, w, tt.wantStatus)
+			if got, _ := api.store.Component(component.ID); got.MaxMileage != tt.wantMax {
+				t.Errorf("max_mileage = %d, want %d", got.MaxMileage, tt.wantMax)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if events := api.store.Events(); len(events) != 0 {
+					t.Errorf("got %d events, want none", len(events))
+				}
+				return
+			}
+
+			resp := decode[domain.ComponentWear](t, w)
+			if want := 5800.0 / float64(tt.wantMax); resp.MaxMileage != tt.wantMax || resp.Wear != want {
+				t.Errorf("max_mileage, wear = %d, %v, want %d, %v", resp.MaxMileage, resp.Wear, tt.wantMax, want)
+			}
+			events := api.store.Events()
+			if len(events) != 1 || events[0].Type != domain.ComponentLifeExtended {
+				t.Fatalf("events = %v, want one %s", api.store.EventTypes(), domain.ComponentLifeExtended)
+			}
+			if record, ok := events[0].Data.(domain.LifeExtensionRecord); !ok || record.PreviousMaxMileage != 5000 || record.Reason != "осмотр" {
+				t.Errorf("event data = %+v", events[0].Data)
+			}
+		})
+	}
+}
+
+// вес типа компонента меняет порядок /bikes/my/urgent, а max_wear остаётся сырым износом
+func TestComponentWeightsChangeUrgencyRanking(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []ComponentWeightItem
+		// wantFirst - какой байк первый: "frame" (рама 60%) или "handlebars" (руль 80%)
+		wantFirst   string
+		wantUrgency float64
+	}{
+		{name: "веса по умолчанию", wantFirst: "handlebars", wantUrgency: 0.8},
+		{name: "рама важнее", weights: []ComponentWeightItem{{Name: string(domain.Frame), Weight: 2}}, wantFirst: "frame", wantUrgency: 1.2},
+		{name: "руль менее важен", weights: []ComponentWeightItem{{Name: string(domain.Handlebars), Weight: 0.5}}, wantFirst: "frame", wantUrgency: 0.6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			// порог 5000 км: рама проехала 3000, руль 4000
+			frameBike := api.addBike(owner, 3000)
+			api.addComponent(frameBike, domain.Frame, 0)
+			handlebarsBike := api.addBike(owner, 4000)
+			api.addComponent(handlebarsBike, domain.Handlebars, 0)
+			names := map[uuid.UUID]string{frameBike.BikeID: "frame", handlebarsBike.BikeID: "handlebars"}
+
+			if tt.weights != nil {
+				w := api.do(http.MethodPut, "/admin/components/weights", api.token(uuid.New(), domain.Admin), SetComponentWeightsRequest{Weights: tt.weights})
+				expectStatus(t, w, http.StatusOK)
+			}
+
+			w := api.do(http.MethodGet, "/bikes/my/urgent", api.token(owner, domain.AppUser), nil)
+			expectStatus(t, w, http.StatusOK)
+			bikes := decode[GetUrgentBikesResponse](t, w).Bikes
+			if len(bikes) != 2 {
+				t.Fatalf("got %d bikes, want 2", len(bikes))
+			}
+			first := bikes[0]
+			if names[first.BikeID] != tt.wantFirst || math.Abs(first.Urgency-tt.wantUrgency) > 1e-9 {
+				t.Errorf("first = %s with urgency %v, want %s with %v", names[first.BikeID], first.Urgency, tt.wantFirst, tt.wantUrgency)
+			}
+			for _, b := range bikes {
+				wantWear := map[string]float64{"frame": 0.6, "handlebars": 0.8}[names[b.BikeID]]
+				if math.Abs(b.MaxWear-wantWear) > 1e-9 {
+					t.Errorf("%s max_wear = %v, want %v", names[b.BikeID], b.MaxWear, wantWear)
+				}
+			}
+		})
+	}
+}
+
+func TestSetComponentWeights(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.UserRole
+		body       any
+		wantStatus int
+		// wantFrame - вес рамы в GET /components/weights после запроса
+		wantFrame float64
+	}{
+		{name: "админ меняет вес", role: domain.Admin, body: SetComponentWeightsRequest{Weights: []ComponentWeightItem{{Name: string(domain.Frame), Weight: 3}}}, wantStatus: http.StatusOK, wantFrame: 3},
+		{name: "нулевой вес", role: domain.Admin, body: SetComponentWeightsRequest{Weights: []ComponentWeightItem{{Name: string(domain.Frame), Weight: 0}}}, wantStatus: http.StatusUnprocessableEntity, wantFrame: domain.DefaultComponentWeight},
+		{name: "вес больше максимума", role: domain.Admin, body: SetComponentWeightsRequest{Weights: []ComponentWeightItem{{Name: string(domain.Frame), Weight: 11}}}, wantStatus: http.StatusUnprocessableEntity, wantFrame: domain.DefaultComponentWeight},
+		{name: "неизвестный компонент", role: domain.Admin, body: `{"weights":[{"name":"saddle","weight":2}]}`, wantStatus: http.StatusUnprocessableEntity, wantFrame: domain.DefaultComponentWeight},
+		{name: "обычный пользователь", role: domain.AppUser, body: SetComponentWeightsRequest{Weights: []ComponentWeightItem{{Name: string(domain.Frame), Weight: 3}}}, wantStatus: http.StatusForbidden, wantFrame: domain.DefaultComponentWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			expectStatus(t, api.do(http.MethodPut, "/admin/components/weights", api.token(uuid.New(), tt.role), tt.body), tt.wantStatus)
+
+			w := api.do(http.MethodGet, "/components/weights", api.token(uuid.New(), domain.AppUser), nil)
+			expectStatus(t, w, http.StatusOK)
+			weights := decode[ComponentWeightsResponse](t, w).Weights
+			if len(weights) != len(domain.ComponentNames) {
+				t.Fatalf("got %d weights, want one per component type", len(weights))
+			}
+			for _, weight := range weights {
+				want := domain.DefaultComponentWeight
+				if weight.Name == domain.Frame {
+					want = tt.wantFrame
+				}
+				if weight.Weight != want {
+					t.Errorf("%s weight = %v, want %v", weight.Name, weight.Weight, want)
+				}
+			}
+		})
+	}
+}
+
+// один и тот же sort одинаково упорядочивает /components и /with-components
+func TestBikeComponentsSort(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		// want - названия компонентов в порядке ответа
+		want []domain.ComponentName
+	}{
+		{name: "по умолчанию свежие сверху", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Handlebars, domain.Wheels, domain.Frame}},
+		{name: "installed_at", query: "?sort=installed_at", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Handlebars, domain.Wheels, domain.Frame}},
+		{name: "installed_at по возрастанию", query: "?sort=installed_at&order=asc", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Frame, domain.Wheels, domain.Handlebars}},
+		{name: "wear - самые изношенные сверху", query: "?sort=wear", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Handlebars, domain.Frame, domain.Wheels}},
+		{name: "name", query: "?sort=name", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Frame, domain.Handlebars, domain.Wheels}},
+		{name: "name по убыванию", query: "?sort=name&order=desc", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Wheels, domain.Handlebars, domain.Frame}},
+		// без порога по пробегу - в конце
+		{name: "max_mileage", query: "?sort=max_mileage", wantStatus: http.StatusOK, want: []domain.ComponentName{domain.Frame, domain.Handlebars, domain.Wheels}},
+		{name: "неизвестное поле", query: "?sort=brand", wantStatus: http.StatusBadRequest},
+		{name: "неизвестное направление", query: "?sort=name&order=up", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			bike := api.addBike(owner, 5000)
+			bike.CreatedAt = time.Now().AddDate(0, 0, -10)
+			api.store.AddBike(bike)
+			days := 100
+			// износ: рама 60%, руль 62.5%, колёса 2% (только по возрасту)
+			for _, c := range []*domain.Component{
+				{Name: domain.Frame, InstalledMileage: 2000, MaxMileage: 5000, InstalledAt: time.Now().AddDate(0, 0, -3)},
+				{Name: domain.Handlebars, InstalledMileage: 0, MaxMileage: 8000, InstalledAt: time.Now().AddDate(0, 0, -1)},
+				{Name: domain.Wheels, InstalledMileage: 4000, MaxAgeDays: &days, InstalledAt: time.Now().AddDate(0, 0, -2)},
+			} {
+				c.BikeID = bike.BikeID
+				api.store.AddComponent(c)
+			}
+			token := api.token(owner, domain.AppUser)
+
+			w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/components"+tt.query, token, nil)
+			expectStatus(t, w, tt.wantStatus)
+			withComponents := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/with-components"+tt.query, token, nil)
+			expectStatus(t, withComponents, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var listed []domain.ComponentName
+			for _, c := range decode[GetBikeComponentsResponse](t, w).Components {
+				listed = append(listed, c.Name)
+			}
+			if !slices.Equal(listed, tt.want) {
+				t.Errorf("/components order = %v, want %v", listed, tt.want)
+			}
+			var nested []domain.ComponentName
+			for _, c := range decode[GetBikeWithComponentsResponse](t, withComponents).Components {
+				nested = append(nested, domain.ComponentName(c.Name))
+			}
+			if !slices.Equal(nested, tt.want) {
+				t.Errorf("/with-components order = %v, want %v", nested, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ImportComponentItem - компонент без bike_id, байк задан один на весь запрос
+type ImportComponentItem struct {
+	Name  string `json:"name" binding:"required,notblank" example:"wheels"`
+	Brand string `json:"brand,omitempty" example:"DT Swiss"`
+	Model string `json:"model,omitempty" example:"E 1900"`
+	// по умолчанию текущий пробег байка
+	InstalledMileage *int `json:"installed_mileage,omitempty" binding:"omitempty,min=0" example:"1000"`
+	// если не передан ни один из порогов, берутся пороги по умолчанию
+	MaxMileage int  `json:"max_mileage,omitempty" binding:"omitempty,min=1" example:"15000"`
+	MaxAgeDays *int `json:"max_age_days,omitempty" binding:"omitempty,min=1" example:"1825"`
+	// по умолчанию текущее время
+	InstalledAt *time.Time `json:"installed_at,omitempty" example:"2025-06-01T10:00:00Z"`
+	Position    string     `json:"position,omitempty" binding:"omitempty,oneof=front rear left right none" example:"front"`
+}
+
+type ImportComponentsRequest struct {
+	Components []ImportComponentItem `json:"components" binding:"required,min=1,dive"`
+}
+
+type CreateComponentsBatchRequest struct {
+	BikeID     string                `json:"bike_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Components []ImportComponentItem `json:"components" binding:"required,min=1,dive"`
+}
+
+type CreateComponentsBatchResponse struct {
+	Components []*domain.Component `json:"components"`
+	Count      int                 `json:"count"`
+}
+
+type ImportItemResult struct {
+	Index     int               `json:"index"`
+	Status    string            `json:"status" enums:"created,failed,skipped"`
+	Error     string            `json:"error,omitempty"`
+	Component *domain.Component `json:"component,omitempty"`
+}
+
+type ImportComponentsResponse struct {
+	Results []ImportItemResult `json:"results"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+}
+
+// @Summary Импорт компонентов одного байка
+// @Description Добавляет к байку сразу до 100 компонентов (и не больше MAX_BATCH_SIZE) одной транзакцией: либо все, либо ни одного. Владелец проверяется один раз, bike_id в элементах не нужен. Без installed_mileage берётся текущий пробег байка, без installed_at - текущее время, без порогов - пороги по умолчанию. Ошибки возвращаются по каждому элементу
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param request body ImportComponentsRequest true "Компоненты"
+// @Success 201 {object} ImportComponentsResponse "Компоненты созданы"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 413 {object} errorResponse "Слишком большой импорт"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} ImportComponentsResponse "Позиция уже занята парным компонентом, импорт откатился"
+// @Failure 422 {object} ImportComponentsResponse "Импорт отклонён, ошибки по элементам"
+// @Failure 500 {object} ImportComponentsResponse "Импорт откатился"
+// @Router /bikes/{id}/components/import [post]
+func (h *ComponentHandler) ImportComponents(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to ImportComponents", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	bikeUUID, err := uuid.Parse(bikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
+		return
+	}
+
+	var req ImportComponentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in import components", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if batchTooLarge(c, len(req.Components), maxComponentBatchSize, h.maxBatchSize, "items") {
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), bikeID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to import components", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	now := time.Now()
+	components := make([]*domain.Component, len(req.Components))
+	for i, item := range req.Components {
+		component := &domain.Component{
+			Name:             domain.ComponentName(item.Name),
+			Brand:            item.Brand,
+			Model:            item.Model,
+			InstalledMileage: bike.Mileage,
+			MaxMileage:       item.MaxMileage,
+			MaxAgeDays:       item.MaxAgeDays,
+			InstalledAt:      now,
+			Position:         domain.Position(item.Position),
+		}
+		if item.InstalledMileage != nil {
+			component.InstalledMileage = *item.InstalledMileage
+		}
+		if item.InstalledAt != nil {
+			component.InstalledAt = *item.InstalledAt
+		}
+		components[i] = component
+	}
+
+	created, err := h.componentService.ImportComponents(c.Request.Context(), bikeUUID, components)
+	if err != nil && created == nil {
+		if componentInputError(c, err) {
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Error("Failed to import components", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to import components")
+		return
+	}
+
+	response := ImportComponentsResponse{
+		Results: make([]ImportItemResult, len(created)),
+	}
+	for i, r := range created {
+		result := ImportItemResult{Index: i}
+		switch {
+		case r.Err != nil:
+			result.Status = batchStatusFailed
+			result.Error = r.Err.Error()
+			response.Failed++
+		case r.Component != nil:
+			result.Status = batchStatusCreated
+			result.Component = r.Component
+			response.Created++
+		default:
+			// откатился вместе с импортом из-за ошибки в другом элементе
+			result.Status = batchStatusSkipped
+		}
+		response.Results[i] = result
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidComponentImport):
+		c.JSON(http.StatusUnprocessableEntity, response)
+	case errors.Is(err, domain.ErrDuplicatePosition):
+		c.JSON(http.StatusConflict, response)
+	case err != nil:
+		h.logger.WithContext(c.Request.Context()).Error("Components import rolled back", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		c.JSON(http.StatusInternalServerError, response)
+	default:
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+// @Summary Создать несколько компонентов
+// @Description Создаёт до 100 компонентов (и не больше MAX_BATCH_SIZE) одного байка одной транзакцией: либо все, либо ни одного. Владелец байка проверяется один раз. Первый не прошедший проверку элемент возвращается с индексом, батч при этом не создаётся. Значения по умолчанию те же, что у импорта
+// @Tags components
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateComponentsBatchRequest true "Байк и компоненты"
+// @Success 201 {object} CreateComponentsBatchResponse "Компоненты созданы"
+// @Failure 400 {object} itemErrorResponse "Некорректный JSON, ID байка или элемент батча"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "Позиция уже занята парным компонентом"
+// @Failure 413 {object} errorResponse "Слишком большой батч"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /components/batch [post]
+func (h *ComponentHandler) CreateComponentsBatch(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to CreateComponentsBatch", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateComponentsBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in create components batch", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if batchTooLarge(c, len(req.Components), maxComponentBatchSize, h.maxBatchSize, "components") {
+		return
+	}
+
+	bikeUUID, err := uuid.Parse(req.BikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), req.BikeID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": req.BikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to add components", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      req.BikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	now := time.Now()
+	components := make([]*domain.Component, len(req.Components))
+	for i, item := range req.Components {
+		component := &domain.Component{
+			BikeID:           bikeUUID,
+			Name:             domain.ComponentName(item.Name),
+			Brand:            item.Brand,
+			Model:            item.Model,
+			InstalledMileage: bike.Mileage,
+			MaxMileage:       item.MaxMileage,
+			MaxAgeDays:       item.MaxAgeDays,
+			InstalledAt:      now,
+			Position:         domain.Position(item.Position),
+		}
+		if item.InstalledMileage != nil {
+			component.InstalledMileage = *item.InstalledMileage
+		}
+		if item.InstalledAt != nil {
+			component.InstalledAt = *item.InstalledAt
+		}
+		components[i] = component
+	}
+
+	created, err := h.componentService.CreateComponents(c.Request.Context(), components)
+	if err != nil {
+		var itemErr *services.ComponentItemError
+		if errors.As(err, &itemErr) {
+			newItemErrorResponse(c, http.StatusBadRequest, itemErr.Index, itemErr.Err.Error())
+			return
+		}
+		if componentInputError(c, err) {
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Error("Failed to create components batch", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": req.BikeID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to create components")
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateComponentsBatchResponse{
+		Components: created,
+		Count:      len(created),
+	})
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestImportComponents(t *testing.T) {
+	owner := uuid.New()
+	tooMany := make([]ImportComponentItem, maxComponentBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = ImportComponentItem{Name: string(domain.Handlebars), MaxMileage: 5000}
+	}
+
+	tests := []struct {
+		name      string
+		requester uuid.UUID
+		// bikeID - путь вместо байка владельца
+		bikeID     string
+		body       any
+		storeErr   error
+		wantStatus int
+		// wantResults - статусы элементов, wantStored - компонентов у байка в итоге
+		wantResults []string
+		wantStored  int
+	}{
+		{
+			name:      "все элементы корректны",
+			requester: owner,
+			body: ImportComponentsRequest{Components: []ImportComponentItem{
+				{Name: string(domain.Handlebars), Brand: "Renthal", Model: "Fatbar", MaxMileage: 5000},
+				{Name: string(domain.Frame), InstalledMileage: ptr(200), MaxMileage: 8000},
+			}},
+			wantStatus:  http.StatusCreated,
+			wantResults: []string{batchStatusCreated, batchStatusCreated},
+			wantStored:  2,
+		},
+		{
+			name:      "ошибка в одном элементе",
+			requester: owner,
+			body: ImportComponentsRequest{Components: []ImportComponentItem{
+				{Name: string(domain.Handlebars), MaxMileage: 5000},
+				{Name: string(domain.Frame), InstalledMileage: ptr(5000), MaxMileage: 5000},
+			}},
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantResults: []string{batchStatusSkipped, batchStatusFailed},
+		},
+		{
+			name:      "сбой базы откатывает импорт",
+			requester: owner,
+			body: ImportComponentsRequest{Components: []ImportComponentItem{
+				{Name: string(domain.Handlebars), MaxMileage: 5000},
+			}},
+			storeErr:    errors.New("connection reset"),
+			wantStatus:  http.StatusInternalServerError,
+			wantResults: []string{batchStatusFailed},
+		},
+		{name: "пустой список", requester: owner, body: `{"components":[]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "некорректный JSON", requester: owner, body: `{"components":`, wantStatus: http.StatusBadRequest},
+		{name: "слишком большой импорт", requester: owner, body: ImportComponentsRequest{Components: tooMany}, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "чужой байк", requester: uuid.New(), body: ImportComponentsRequest{Components: []ImportComponentItem{{Name: string(domain.Handlebars), MaxMileage: 5000}}}, wantStatus: http.StatusForbidden},
+		{name: "неизвестный байк", requester: owner, bikeID: uuid.NewString(), body: ImportComponentsRequest{Components: []ImportComponentItem{{Name: string(domain.Handlebars), MaxMileage: 5000}}}, wantStatus: http.StatusNotFound},
+		{name: "неверный ID байка", requester: owner, bikeID: "bike", body: ImportComponentsRequest{Components: []ImportComponentItem{{Name: string(domain.Handlebars), MaxMileage: 5000}}}, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 1200)
+			bikeID := tt.bikeID
+			if bikeID == "" {
+				bikeID = bike.BikeID.String()
+			}
+			if tt.storeErr != nil {
+				api.store.SetError("CreateComponent", tt.storeErr)
+			}
+
+			w := api.do(http.MethodPost, "/bikes/"+bikeID+"/components/import", api.token(tt.requester, domain.AppUser), tt.body)
+			expectStatus(t, w, tt.wantStatus)
+			if stored := len(api.store.Components(bike.BikeID)); stored != tt.wantStored {
+				t.Errorf("stored components = %d, want %d", stored, tt.wantStored)
+			}
+			if tt.wantResults == nil {
+				return
+			}
+
+			resp := decode[ImportComponentsResponse](t, w)
+			var statuses []string
+			for i, r := range resp.Results {
+				statuses = append(statuses, r.Status)
+				if r.Index != i || (r.Status == batchStatusFailed) != (r.Error != "") || (r.Status == batchStatusCreated) != (r.Component != nil) {
+					t.Errorf("result %d = %+v", i, r)
+				}
+			}
+			if !slices.Equal(statuses, tt.wantResults) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantResults)
+			}
+			if resp.Created != tt.wantStored {
+				t.Errorf("created = %d, want %d", resp.Created, tt.wantStored)
+			}
+		})
+	}
+}
+
+// без installed_mileage берётся пробег байка, без порогов - пороги по умолчанию,
+// bike_id - из пути
+func TestImportComponentsDefaults(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	bike := api.addBike(owner, 1200)
+	if _, err := api.store.UpsertComponentDefault(context.Background(), &domain.ComponentDefault{Name: domain.Handlebars, MaxMileage: 20000}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := api.do(http.MethodPost, "/bikes/"+bike.BikeID.String()+"/components/import", api.token(owner, domain.AppUser), ImportComponentsRequest{
+		Components: []ImportComponentItem{
+			{Name: string(domain.Handlebars)},
+			{Name: string(domain.Frame), InstalledMileage: ptr(200), MaxMileage: 5000},
+		},
+	})
+	expectStatus(t, w, http.StatusCreated)
+
+	resp := decode[ImportComponentsResponse](t, w)
+	for i, want := range []struct{ installed, maxMileage int }{{1200, 20000}, {200, 5000}} {
+		c := resp.Results[i].Component
+		if c == nil || c.BikeID != bike.BikeID || c.InstalledMileage != want.installed || c.MaxMileage != want.maxMileage {
+			t.Errorf("component %d = %+v, want bike %s, installed_mileage %d, max_mileage %d", i, c, bike.BikeID, want.installed, want.maxMileage)
+		}
+	}
+	want := []domain.EventType{domain.ComponentCreated, domain.ComponentCreated}
+	if got := api.store.EventTypes(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GetOrphanedComponentsResponse struct {
+	Components []*domain.Component `json:"components"`
+	Count      int                 `json:"count"`
+	Limit      int                 `json:"limit"`
+	Offset     int                 `json:"offset"`
+}
+
+type RepairOrphansRequest struct {
+	Action       string   `json:"action" binding:"required,oneof=reassign delete" example:"reassign"`
+	ComponentIDs []string `json:"component_ids" binding:"required,min=1" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	// только для reassign: байк, на который переносятся компоненты
+	BikeID string `json:"bike_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+type RepairOrphansResponse struct {
+	Action       string      `json:"action"`
+	Requested    int         `json:"requested"`
+	Affected     int         `json:"affected"`
+	ComponentIDs []uuid.UUID `json:"component_ids"`
+}
+
+// @Summary Компоненты без байка
+// @Description Для админов и сервисных API-ключей. Компоненты, чей bike_id не ведёт ни на один живой байк (остаются после частичных сбоев и мягкого удаления байка). Сначала самые старые
+// @Tags admin
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Produce json
+// @Param limit query int false "Сколько компонентов вернуть" example:"50"
+// @Param offset query int false "Сколько компонентов пропустить" example:"0"
+// @Success 200 {object} GetOrphanedComponentsResponse "Компоненты без байка"
+// @Failure 400 {object} errorResponse "Неверные limit или offset"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/components/orphaned [get]
+func (h *ComponentHandler) GetOrphanedComponents(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	page, err := parsePage(c, h.pagination)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	components, err := h.componentService.ListOrphanedComponents(c.Request.Context(), page)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get orphaned components")
+		return
+	}
+	if components == nil {
+		components = []*domain.Component{}
+	}
+
+	c.JSON(http.StatusOK, GetOrphanedComponentsResponse{
+		Components: components,
+		Count:      len(components),
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+	})
+}
+
+// @Summary Починить компоненты без байка
+// @Description Только для админов. action=reassign переносит компоненты на bike_id, action=delete удаляет их. Затрагиваются только компоненты, которые всё ещё без байка, остальные ID пропускаются. Не больше 1000 ids и не больше MAX_BATCH_SIZE
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RepairOrphansRequest true "Действие и компоненты"
+// @Success 200 {object} RepairOrphansResponse "Сколько компонентов затронуто"
+// @Failure 400 {object} errorResponse "Некорректный JSON или ID"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "На байке уже есть компонент в этой позиции"
+// @Failure 413 {object} errorResponse "Слишком много ids"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/components/orphaned [post]
+func (h *ComponentHandler) RepairOrphanedComponents(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req RepairOrphansRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in repair orphaned components", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if batchTooLarge(c, len(req.ComponentIDs), domain.MaxOrphanRepair, h.maxBatchSize, "ids") {
+		return
+	}
+	ids, invalid := parseUUIDs(req.ComponentIDs)
+	if len(invalid) > 0 {
+		newInvalidIDsResponse(c, invalid)
+		return
+	}
+
+	repair := domain.OrphanRepair{
+		Action:       domain.OrphanAction(req.Action),
+		ComponentIDs: ids,
+	}
+	if req.BikeID != "" {
+		bikeID, err := uuid.Parse(req.BikeID)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
+			return
+		}
+		repair.BikeID = bikeID
+	}
+
+	affected, err := h.componentService.RepairOrphanedComponents(c.Request.Context(), repair)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOrphanRepair) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if componentInputError(c, err) {
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to repair orphaned components")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Admin orphaned components repair", map[string]interface{}{
+		"audit":        "orphaned_components",
+		"requester_id": payload.UserID.String(),
+		"action":       req.Action,
+		"bike_id":      req.BikeID,
+		"affected":     len(affected),
+	})
+
+	c.JSON(http.StatusOK, RepairOrphansResponse{
+		Action:       req.Action,
+		Requested:    len(ids),
+		Affected:     len(affected),
+		ComponentIDs: affected,
+	})
+}
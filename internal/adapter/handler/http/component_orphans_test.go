@@ -0,0 +1,211 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// orphanFixture - компонент без байка, компонент живого байка и компонент
+// мягко удалённого байка: он тоже сирота, ездить ему не на чем
+type orphanFixture struct {
+	bike, deletedBike               *domain.Bike
+	orphan, attached, ofDeletedBike *domain.Component
+}
+
+func seedOrphans(api *testAPI) orphanFixture {
+	var f orphanFixture
+	f.bike = api.addBike(uuid.New(), 1000)
+	f.deletedBike = api.addBike(uuid.New(), 1000)
+	f.deletedBike.DeletedAt = ptr(time.Now())
+	api.store.AddBike(f.deletedBike)
+
+	f.orphan = api.store.AddComponent(&domain.Component{
+		BikeID:      uuid.New(),
+		Name:        domain.Frame,
+		MaxMileage:  5000,
+		InstalledAt: time.Now().Add(-time.Hour),
+	})
+	f.attached = api.addComponent(f.bike, domain.Handlebars, 0)
+	f.ofDeletedBike = api.addComponent(f.deletedBike, domain.Handlebars, 0)
+	return f
+}
+
+func TestGetOrphanedComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.UserRole
+		wantStatus int
+	}{
+		{name: "админ", role: domain.Admin, wantStatus: http.StatusOK},
+		{name: "обычный пользователь", role: domain.AppUser, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			f := seedOrphans(api)
+
+			w := api.do(http.MethodGet, "/admin/components/orphaned", api.token(uuid.New(), tt.role), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			resp := decode[GetOrphanedComponentsResponse](t, w)
+			var got []uuid.UUID
+			for _, c := range resp.Components {
+				got = append(got, c.ID)
+			}
+			want := []uuid.UUID{f.orphan.ID, f.ofDeletedBike.ID}
+			if resp.Count != len(want) || len(got) != len(want) || !slices.Contains(got, want[0]) || !slices.Contains(got, want[1]) {
+				t.Errorf("orphans = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRepairOrphanedComponents(t *testing.T) {
+	tests := []struct {
+		name string
+		role domain.UserRole
+		// request собирается из фикстуры: ID сироты и живого компонента, байк
+		request    func(f orphanFixture) any
+		wantStatus int
+		// wantOrphan - где сирота в итоге: "bike", "gone" или "orphan"
+		wantOrphan string
+	}{
+		{
+			name: "перенос на байк",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "reassign", ComponentIDs: []string{f.orphan.ID.String()}, BikeID: f.bike.BikeID.String()}
+			},
+			wantStatus: http.StatusOK,
+			wantOrphan: "bike",
+		},
+		{
+			name: "удаление",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "delete", ComponentIDs: []string{f.orphan.ID.String(), f.attached.ID.String()}}
+			},
+			wantStatus: http.StatusOK,
+			wantOrphan: "gone",
+		},
+		{
+			name: "перенос на неизвестный байк",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "reassign", ComponentIDs: []string{f.orphan.ID.String()}, BikeID: uuid.NewString()}
+			},
+			wantStatus: http.StatusNotFound,
+			wantOrphan: "orphan",
+		},
+		{
+			name: "перенос без bike_id",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "reassign", ComponentIDs: []string{f.orphan.ID.String()}}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantOrphan: "orphan",
+		},
+		{
+			name: "удаление с bike_id",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "delete", ComponentIDs: []string{f.orphan.ID.String()}, BikeID: f.bike.BikeID.String()}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantOrphan: "orphan",
+		},
+		{
+			name: "неизвестное действие",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "merge", ComponentIDs: []string{f.orphan.ID.String()}}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantOrphan: "orphan",
+		},
+		{
+			name: "некорректный ID",
+			role: domain.Admin,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "delete", ComponentIDs: []string{f.orphan.ID.String(), "x"}}
+			},
+			wantStatus: http.StatusBadRequest,
+			wantOrphan: "orphan",
+		},
+		{
+			name: "обычный пользователь",
+			role: domain.AppUser,
+			request: func(f orphanFixture) any {
+				return RepairOrphansRequest{Action: "delete", ComponentIDs: []string{f.orphan.ID.String()}}
+			},
+			wantStatus: http.StatusForbidden,
+			wantOrphan: "orphan",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			f := seedOrphans(api)
+
+			w := api.do(http.MethodPost, "/admin/components/orphaned", api.token(uuid.New(), tt.role), tt.request(f))
+			expectStatus(t, w, tt.wantStatus)
+
+			stored, exists := api.store.Component(f.orphan.ID)
+			var got string
+			switch {
+			case !exists:
+				got = "gone"
+			case stored.BikeID == f.bike.BikeID:
+				got = "bike"
+			default:
+				got = "orphan"
+			}
+			if got != tt.wantOrphan {
+				t.Errorf("orphan is %s, want %s", got, tt.wantOrphan)
+			}
+			// компоненты живого байка не трогаются никогда
+			if stored, ok := api.store.Component(f.attached.ID); !ok || stored.BikeID != f.bike.BikeID {
+				t.Errorf("component %s was changed", f.attached.ID)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decode[RepairOrphansResponse](t, w)
+			if resp.Affected != 1 || !slices.Equal(resp.ComponentIDs, []uuid.UUID{f.orphan.ID}) {
+				t.Errorf("response = %+v, want only %s affected", resp, f.orphan.ID)
+			}
+			if _, logged := api.logger.Find("Admin orphaned components repair"); !logged {
+				t.Error("repair is not audited")
+			}
+		})
+	}
+}
+
+// компонент мягко удалённого байка чинится как сирота: переносится
+// на живой байк, а байк-источник остаётся удалённым
+func TestRepairOrphanOfDeletedBike(t *testing.T) {
+	api := newTestAPI(t)
+	f := seedOrphans(api)
+
+	req := RepairOrphansRequest{Action: "reassign", ComponentIDs: []string{f.ofDeletedBike.ID.String()}, BikeID: f.bike.BikeID.String()}
+	w := api.do(http.MethodPost, "/admin/components/orphaned", api.token(uuid.New(), domain.Admin), req)
+	expectStatus(t, w, http.StatusOK)
+
+	resp := decode[RepairOrphansResponse](t, w)
+	if resp.Affected != 1 || !slices.Equal(resp.ComponentIDs, []uuid.UUID{f.ofDeletedBike.ID}) {
+		t.Errorf("response = %+v, want only %s affected", resp, f.ofDeletedBike.ID)
+	}
+	if stored, ok := api.store.Component(f.ofDeletedBike.ID); !ok || stored.BikeID != f.bike.BikeID {
+		t.Errorf("component %s is not on bike %s", f.ofDeletedBike.ID, f.bike.BikeID)
+	}
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "[REDACTED]"
+
+// заголовки, которые никогда не попадают в лог
+var sensitiveHeaders = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"x-api-key":     true,
+}
+
+// поля JSON, значения которых заменяются на redacted
+var sensitiveFields = []string{"secret", "token", "password", "api_key", "apikey", "authorization"}
+
+// DebugBodyMiddleware пишет в лог тела запроса и ответа для выбранных роутов.
+// Тело читается не больше maxBytes, остальное уходит в обработчик как есть.
+// Логируется только то, что разобралось как JSON, после вырезания секретов:
+// обрезанное или не-JSON тело не попадает в лог, чтобы не утёк токен
+func DebugBodyMiddleware(routes []string, maxBytes int, logger ports.LoggerPort) gin.HandlerFunc {
+	enabled := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		enabled[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if !enabled[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		var reqBody []byte
+		if c.Request.Body != nil {
+			// берём префикс, а тело собираем обратно без полной буферизации
+			reqBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBytes)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(reqBody), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, max: maxBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		logger.Info("HTTP body debug", map[string]interface{}{
+			"method":          c.Request.Method,
+			"path":            c.FullPath(),
+			"status":          writer.Status(),
+			"request_headers": redactHeaders(c.Request.Header),
+			"request_body":    redactBody(reqBody, maxBytes),
+			"response_body":   redactBody(writer.buf.Bytes(), maxBytes),
+		})
+	}
+}
+
+// bodyCaptureWriter копирует в буфер не больше max+1 байт ответа,
+// лишний байт нужен только чтобы понять, что ответ обрезан
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+	max int
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	if room := w.max + 1 - w.buf.Len(); room > 0 {
+		w.buf.Write(b[:min(room, len(b))])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func redactHeaders(headers map[string][]string) map[string]string {
+	out := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if sensitiveHeaders[strings.ToLower(name)] {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func redactBody(body []byte, maxBytes int) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	if len(body) > maxBytes {
+		return "[truncated, not logged]"
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "[non-JSON body, not logged]"
+	}
+	return redactValue(v)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, inner := range val {
+			if isSensitiveField(key) {
+				val[key] = redacted
+				continue
+			}
+			val[key] = redactValue(inner)
+		}
+	case []interface{}:
+		for i, inner := range val {
+			val[i] = redactValue(inner)
+		}
+	}
+	return v
+}
+
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDebugBodyMiddleware(t *testing.T) {
+	const secret = "s3cr3t-value"
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		body    string
+		// wantLogged - что должно оказаться в записи лога
+		wantLogged []string
+		wantNoLog  bool
+	}{
+		{name: "Authorization вырезается", path: "/debug", headers: map[string]string{"Authorization": "Bearer " + secret}, body: `{"model":"Trek"}`,
+			wantLogged: []string{`"Authorization":"[REDACTED]"`, `"model":"Trek"`}},
+		{name: "API-ключ вырезается", path: "/debug", headers: map[string]string{"X-API-Key": secret}, body: `{}`,
+			wantLogged: []string{`"X-Api-Key":"[REDACTED]"`}},
+		{name: "секретные поля тела", path: "/debug", body: `{"secret":"` + secret + `","nested":[{"access_token":"` + secret + `"}],"name":"hook"}`,
+			wantLogged: []string{`"secret":"[REDACTED]"`, `"access_token":"[REDACTED]"`, `"name":"hook"`}},
+		{name: "тело больше лимита не логируется", path: "/debug", body: `{"note":"` + strings.Repeat("x", 200) + `","token":"` + secret + `"}`,
+			wantLogged: []string{`"request_body":"[truncated, not logged]"`}},
+		{name: "не-JSON тело не логируется", path: "/debug", body: "token=" + secret,
+			wantLogged: []string{`"request_body":"[non-JSON body, not logged]"`}},
+		{name: "роут не выбран", path: "/quiet", headers: map[string]string{"Authorization": "Bearer " + secret}, body: `{}`, wantNoLog: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &portstest.Logger{}
+			router := gin.New()
+			router.Use(DebugBodyMiddleware([]string{"/debug"}, 128, logger))
+			var received string
+			echo := func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				received = string(body)
+				c.JSON(http.StatusOK, gin.H{"api_key": secret, "ok": true})
+			}
+			router.POST("/debug", echo)
+			router.POST("/quiet", echo)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// обработчик и клиент получают тела целиком
+			if received != tt.body {
+				t.Errorf("handler got body %q, want %q", received, tt.body)
+			}
+			if !strings.Contains(w.Body.String(), secret) {
+				t.Errorf("response body = %q, want it untouched", w.Body.String())
+			}
+
+			entry, logged := logger.Find("HTTP body debug")
+			if logged == tt.wantNoLog {
+				t.Fatalf("logged = %t, want %t", logged, !tt.wantNoLog)
+			}
+			if !logged {
+				return
+			}
+			data, err := json.Marshal(entry.Fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), secret) {
+				t.Errorf("secret leaked into log: %s", data)
+			}
+			for _, want := range append(tt.wantLogged, `"api_key":"[REDACTED]"`) {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("log %s does not contain %s", data, want)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,74 @@
 package http
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// лимиты размера батчей по эндпоинтам, поверх них действует общий MAX_BATCH_SIZE
+const (
+	maxComponentBatchSize  = 100
+	maxBikeBatchSize       = 1000
+	maxBikeCreateBatchSize = 100
+
+	maxIdempotencyKeyLength = 255
+
+	// больше за день не проехать даже на ультрамарафоне
+	maxDailyKm = 1000
+
+	// потолок limit для списка своих байков, поверх PAGINATION_MAX_LIMIT
+	maxMyBikesPageSize = 100
 )
 
+// batchTooLarge - общая проверка размера для всех батчей: лимит эндпоинта,
+// урезанный общим потолком из конфига. Проверяется до любой работы с базой,
+// чтобы огромный батч не превратился в долгую транзакцию с блокировками.
+// Отвечает 413 с действующим лимитом в сообщении
+func batchTooLarge(c *gin.Context, size, limit, hardLimit int, items string) bool {
+	limit = min(limit, hardLimit)
+	if size <= limit {
+		return false
+	}
+	newErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Batch too large: at most %d %s allowed, got %d", limit, items, size))
+	return true
+}
+
+// includeDeleted читает ?include_deleted=true: удалённые байки видят только
+// админы. Остальным отвечает 403 и возвращает ok=false
+func includeDeleted(c *gin.Context, payload *domain.TokenPayload) (include, ok bool) {
+	if c.Query("include_deleted") != "true" {
+		return false, true
+	}
+	if payload.Role != domain.Admin {
+		newErrorResponse(c, http.StatusForbidden, "include_deleted is available to admins only")
+		return false, false
+	}
+	return true, true
+}
+
+// preferMinimal - клиент попросил в ответе только изменённые поля (RFC 7240)
+func preferMinimal(c *gin.Context) bool {
+	for _, pref := range strings.Split(c.GetHeader("Prefer"), ",") {
+		if strings.EqualFold(strings.TrimSpace(pref), "return=minimal") {
+			return true
+		}
+	}
+	return false
+}
+
 func getAuthPayload(ctx *gin.Context, key string) (*domain.TokenPayload, bool) {
 	value, exists := ctx.Get(key)
 	if !exists {
@@ -17,3 +80,205 @@ func getAuthPayload(ctx *gin.Context, key string) (*domain.TokenPayload, bool) {
 	}
 	return payload, true
 }
+
+// parseUUIDs разбирает все ID батча сразу и возвращает список всех некорректных,
+// чтобы клиент мог исправить их за один заход
+func parseUUIDs(values []string) ([]uuid.UUID, []invalidEntry) {
+	ids := make([]uuid.UUID, 0, len(values))
+	var invalid []invalidEntry
+	for i, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			invalid = append(invalid, invalidEntry{Index: i, Value: value})
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, invalid
+}
+
+// parseWarnThreshold читает warn_threshold_percent, по умолчанию fallback из конфига
+func parseWarnThreshold(ctx *gin.Context, fallback int) (int, error) {
+	value := ctx.Query("warn_threshold_percent")
+	if value == "" {
+		return fallback, nil
+	}
+	percent, err := strconv.Atoi(value)
+	if err != nil || percent < 1 || percent > 100 {
+		return 0, fmt.Errorf("warn_threshold_percent must be an integer from 1 to 100")
+	}
+	return percent, nil
+}
+
+// parseDailyKm читает обязательный daily_km - предполагаемый средний пробег в день
+func parseDailyKm(ctx *gin.Context) (float64, error) {
+	value := ctx.Query("daily_km")
+	if value == "" {
+		return 0, fmt.Errorf("daily_km is required")
+	}
+	km, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(km) || km <= 0 || km > maxDailyKm {
+		return 0, fmt.Errorf("daily_km must be a number greater than 0 and at most %d", maxDailyKm)
+	}
+	return km, nil
+}
+
+// parseComponentFilter читает installed_after/installed_before и sort/order из query
+func parseComponentFilter(ctx *gin.Context) (domain.ComponentFilter, error) {
+	var filter domain.ComponentFilter
+
+	after, before, err := parseDateRange(ctx, "installed_after", "installed_before")
+	if err != nil {
+		return filter, err
+	}
+
+	filter.InstalledAfter = after
+	filter.InstalledBefore = before
+	filter.Sort = domain.ComponentSort(ctx.Query("sort"))
+	filter.Order = domain.SortOrder(ctx.Query("order"))
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseBikeFilter читает created_after/created_before из query
+func parseBikeFilter(ctx *gin.Context) (domain.BikeFilter, error) {
+	var filter domain.BikeFilter
+
+	after, before, err := parseDateRange(ctx, "created_after", "created_before")
+	if err != nil {
+		return filter, err
+	}
+
+	filter.CreatedAfter = after
+	filter.CreatedBefore = before
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseBikeListFilter читает фильтр админского списка байков из query
+func parseBikeListFilter(ctx *gin.Context) (domain.BikeListFilter, error) {
+	filter := domain.BikeListFilter{
+		Type:           domain.BikeType(ctx.Query("type")),
+		Model:          ctx.Query("model"),
+		IncludeDeleted: ctx.Query("include_deleted") == "true",
+		Sort:           domain.BikeSort(ctx.Query("sort")),
+	}
+
+	if value := ctx.Query("user_id"); value != "" {
+		userID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, fmt.Errorf("user_id must be a UUID")
+		}
+		filter.UserID = userID
+	}
+	var err error
+	if filter.MinMileage, err = parseOptionalInt(ctx, "min_mileage"); err != nil {
+		return filter, err
+	}
+	if filter.MaxMileage, err = parseOptionalInt(ctx, "max_mileage"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedAfter, filter.CreatedBefore, err = parseDateRange(ctx, "created_after", "created_before"); err != nil {
+		return filter, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseOptionalInt - целое из query, nil если параметра нет
+func parseOptionalInt(ctx *gin.Context, key string) (*int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	return &n, nil
+}
+
+// parseDateRange читает пару дат. Дата без времени в верхней границе включает весь день
+func parseDateRange(ctx *gin.Context, afterKey, beforeKey string) (*time.Time, *time.Time, error) {
+	after, err := parseDateQuery(ctx, afterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	before, err := parseDateQuery(ctx, beforeKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before != nil && len(ctx.Query(beforeKey)) == len(time.DateOnly) {
+		endOfDay := before.Add(24*time.Hour - time.Nanosecond)
+		before = &endOfDay
+	}
+	return after, before, nil
+}
+
+func parseDateQuery(ctx *gin.Context, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be a date in RFC3339 or YYYY-MM-DD format", key)
+}
+
+// parsePage читает limit/offset из query. Без limit берётся значение по умолчанию,
+// слишком большой limit урезается до максимума из конфига
+func parsePage(ctx *gin.Context, cfg *config.Pagination) (domain.Page, error) {
+	page := domain.Page{Limit: cfg.DefaultLimit}
+
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return page, fmt.Errorf("limit must be a positive integer")
+		}
+		page.Limit = min(limit, cfg.MaxLimit)
+	}
+
+	if value := ctx.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return page, fmt.Errorf("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+
+	return page, nil
+}
+
+// idempotencyKey читает заголовок Idempotency-Key, пустая строка - ключа нет.
+// При false ответ уже записан
+func idempotencyKey(c *gin.Context) (string, bool) {
+	key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(key) > maxIdempotencyKeyLength {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+		return "", false
+	}
+	return key, true
+}
+
+// requestHash - хеш разобранного тела запроса. Считается по запросу, а не по
+// доменной модели: в ней есть серверные значения вроде installed_at=now,
+// которые у повтора будут другими
+func requestHash(req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
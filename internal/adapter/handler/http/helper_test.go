@@ -0,0 +1,153 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestParseUUIDs(t *testing.T) {
+	valid := uuid.NewString()
+
+	tests := []struct {
+		name        string
+		values      []string
+		wantIDs     int
+		wantInvalid []invalidEntry
+	}{
+		{name: "все корректные", values: []string{valid, uuid.NewString()}, wantIDs: 2},
+		{name: "пустой список", values: nil},
+		{
+			name:        "все некорректные перечислены",
+			values:      []string{"x", valid, "", valid + "0"},
+			wantIDs:     1,
+			wantInvalid: []invalidEntry{{Index: 0, Value: "x"}, {Index: 2, Value: ""}, {Index: 3, Value: valid + "0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, invalid := parseUUIDs(tt.values)
+			if len(ids) != tt.wantIDs {
+				t.Errorf("ids = %v, want %d", ids, tt.wantIDs)
+			}
+			if !slices.Equal(invalid, tt.wantInvalid) {
+				t.Errorf("invalid = %v, want %v", invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestBatchEndpointsInvalidIDs(t *testing.T) {
+	valid := uuid.NewString()
+	mixed := []string{valid, "bad-1", valid, "bad-2"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+	}{
+		{name: "массовое обновление байков", method: http.MethodPatch, path: "/admin/bikes",
+			body: BulkUpdateBikesRequest{IDs: mixed, Field: "type", Value: "mtb", Confirm: true}},
+		{name: "массовое обновление компонентов", method: http.MethodPatch, path: "/components/batch",
+			body: `{"items":[{"id":"` + valid + `"},{"id":"bad-1"},{"id":"` + valid + `"},{"id":"bad-2"}]}`},
+		{name: "починка сирот", method: http.MethodPost, path: "/admin/components/orphaned",
+			body: RepairOrphansRequest{ComponentIDs: mixed, Action: "delete"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			w := api.do(tt.method, tt.path, api.token(uuid.New(), domain.Admin), tt.body)
+			expectStatus(t, w, http.StatusBadRequest)
+
+			resp := decode[invalidIDsResponse](t, w)
+			want := []invalidEntry{{Index: 1, Value: "bad-1"}, {Index: 3, Value: "bad-2"}}
+			if !slices.Equal(resp.Invalid, want) {
+				t.Errorf("invalid = %v, want %v", resp.Invalid, want)
+			}
+		})
+	}
+}
+
+// каждый батч отклоняется до записи в базу, лимит - в сообщении
+func TestBatchTooLarge(t *testing.T) {
+	repeat := func(item string, n int) string {
+		return strings.TrimSuffix(strings.Repeat(item+",", n), ",")
+	}
+	ids := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+		}
+		return ids
+	}
+	component := `{"name":"frame","max_mileage":5000}`
+
+	tests := []struct {
+		name string
+		// hardLimit - MAX_BATCH_SIZE
+		hardLimit   int
+		method      string
+		path        string
+		body        func(bikeID string) any
+		wantMessage string
+	}{
+		{name: "создание байков", hardLimit: 2, method: http.MethodPost, path: "/bikes/batch",
+			body: func(string) any {
+				return `{"bikes":[` + repeat(`{"model":"Trek","type":"mtb","mileage":1}`, 3) + `]}`
+			}, wantMessage: "at most 2 bikes allowed, got 3"},
+		{name: "импорт спецификации", hardLimit: 2, method: http.MethodPost, path: "/bikes/import",
+			body: func(string) any {
+				return `{"version":1,"type":"mtb","model":"Trek","components":[` + repeat(component, 3) + `]}`
+			}, wantMessage: "at most 2 components allowed, got 3"},
+		{name: "импорт компонентов байка", hardLimit: 2, method: http.MethodPost, path: "/bikes/{bike}/components/import",
+			body:        func(string) any { return `{"components":[` + repeat(component, 3) + `]}` },
+			wantMessage: "at most 2 items allowed, got 3"},
+		{name: "создание компонентов", hardLimit: 2, method: http.MethodPost, path: "/components/batch",
+			body: func(bikeID string) any {
+				return `{"bike_id":"` + bikeID + `","components":[` + repeat(component, 3) + `]}`
+			}, wantMessage: "at most 2 components allowed, got 3"},
+		{name: "обновление компонентов", hardLimit: 2, method: http.MethodPatch, path: "/components/batch",
+			body: func(string) any {
+				return `{"items":[` + repeat(`{"id":"`+uuid.NewString()+`"}`, 3) + `]}`
+			}, wantMessage: "at most 2 items allowed, got 3"},
+		{name: "массовое обновление байков", hardLimit: 2, method: http.MethodPatch, path: "/admin/bikes",
+			body: func(string) any {
+				return BulkUpdateBikesRequest{IDs: ids(3), Field: "type", Value: "mtb", Confirm: true}
+			}, wantMessage: "at most 2 ids allowed, got 3"},
+		{name: "починка сирот", hardLimit: 2, method: http.MethodPost, path: "/admin/components/orphaned",
+			body:        func(string) any { return RepairOrphansRequest{ComponentIDs: ids(3), Action: "delete"} },
+			wantMessage: "at most 2 ids allowed, got 3"},
+		// лимит эндпоинта ниже общего потолка
+		{name: "лимит эндпоинта", hardLimit: 500, method: http.MethodPost, path: "/components/batch",
+			body: func(bikeID string) any {
+				return `{"bike_id":"` + bikeID + `","components":[` + repeat(component, maxComponentBatchSize+1) + `]}`
+			}, wantMessage: "at most 100 components allowed, got 101"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t, withHTTPConfig(func(cfg *config.HTTP) { cfg.MaxBatchSize = tt.hardLimit }))
+			admin := uuid.New()
+			bike := api.addBike(admin, 1000)
+			path := strings.Replace(tt.path, "{bike}", bike.BikeID.String(), 1)
+
+			w := api.do(tt.method, path, api.token(admin, domain.Admin), tt.body(bike.BikeID.String()))
+			expectStatus(t, w, http.StatusRequestEntityTooLarge)
+			if msg := decode[errorResponse](t, w).Message; !strings.Contains(msg, tt.wantMessage) {
+				t.Errorf("message = %q, want %q", msg, tt.wantMessage)
+			}
+			if events := api.store.EventTypes(); len(events) != 0 {
+				t.Errorf("rejected batch wrote events %v", events)
+			}
+			if components := api.store.Components(bike.BikeID); len(components) != 0 {
+				t.Errorf("rejected batch created %d components", len(components))
+			}
+		})
+	}
+}
@@ -1,38 +1,93 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/runtime"
-	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/google/uuid"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
 	user_client "github.com/sm8ta/webike_user_microservice_nikita/pkg/client"
 	"github.com/sm8ta/webike_user_microservice_nikita/pkg/client/users"
+	"github.com/sony/gobreaker/v2"
 )
 
 type BikeHandler struct {
 	bikeService *services.BikeService
 	logger      ports.LoggerPort
 	metrics     ports.MetricsPort
-	userClient  *user_client.UserMicroservice
+	// getUser - запрос пользователя в user-service, в тестах подменяется
+	getUser func(params *users.GetUsersIDParams, authInfo runtime.ClientAuthInfoWriter) (*users.GetUsersIDOK, error)
+	// userBreaker и userTimeout ограждают запросы к user-service, см. fetchUser
+	userBreaker *gobreaker.CircuitBreaker[*users.GetUsersIDOK]
+	userTimeout time.Duration
+	// userCritical - сбой user-service делает сервис неготовым, см. UserServiceReadiness
+	userCritical bool
+	pagination   *config.Pagination
+	cache        ports.CachePort
+	publicURL    string
+	// maxBatchSize - общий потолок размера батча
+	maxBatchSize int
+	// warnPercent - порог warning по умолчанию, если в запросе его нет
+	warnPercent int
 }
 
 type BikeRequest struct {
-	Model   string `json:"model" binding:"required" example:"Mountain Bike Pro"`
-	Type    string `json:"type" binding:"required" example:"mountain"`
+	Model   string `json:"model" binding:"required,notblank" example:"Mountain Bike Pro"`
+	Type    string `json:"type" binding:"required,notblank" enums:"bmx,mtb,road" example:"mtb"`
 	Mileage int    `json:"mileage" binding:"required" example:"1500"`
+	Year    *int   `json:"year,omitempty" example:"2022"` // диапазон проверяет сервис
+}
+
+type BatchBikeItem struct {
+	BikeRequest
+	// если передан, должен совпадать с владельцем токена
+	UserID *string `json:"user_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+type CreateBikesBatchRequest struct {
+	Bikes []BatchBikeItem `json:"bikes" binding:"required,min=1,dive"`
+}
+
+type BikeBatchItemResult struct {
+	Index  int                 `json:"index"`
+	Status string              `json:"status" enums:"created,failed,skipped"`
+	Error  string              `json:"error,omitempty"`
+	Bike   *CreateBikeResponse `json:"bike,omitempty"`
+}
+
+type CreateBikesBatchResponse struct {
+	Results  []BikeBatchItemResult `json:"results"`
+	Created  int                   `json:"created"`
+	Failed   int                   `json:"failed"`
+	Replayed bool                  `json:"replayed"`
 }
 
 type UpdateBike struct {
-	Model   *string `json:"model,omitempty" example:"New Model"`
-	Type    *string `json:"type,omitempty" example:"mountain"`
+	Model   *string `json:"model,omitempty" binding:"omitempty,notblank" example:"New Model"`
+	Type    *string `json:"type,omitempty" binding:"omitempty,notblank" enums:"bmx,mtb,road" example:"mtb"`
 	Mileage *int    `json:"mileage,omitempty" example:"2000"`
+	Year    *int    `json:"year,omitempty" example:"2022"` // диапазон проверяет сервис
+}
+
+type BulkUpdateBikesRequest struct {
+	IDs         []string `json:"ids,omitempty"`
+	CurrentType string   `json:"current_type,omitempty" example:"mtb"`
+	Field       string   `json:"field" binding:"required,oneof=type model" example:"type"`
+	Value       string   `json:"value" binding:"required,notblank" example:"mtb"`
+	// без явного подтверждения ничего не меняем
+	Confirm bool `json:"confirm" example:"true"`
+}
+
+type BulkUpdateBikesResponse struct {
+	Affected int         `json:"affected"`
+	BikeIDs  []uuid.UUID `json:"bike_ids"`
 }
 
 type CreateBikeResponse struct {
@@ -41,7 +96,7 @@ type CreateBikeResponse struct {
 	BikeName  string    `json:"bike_name"`
 	Model     string    `json:"model"`
 	Type      string    `json:"type"`
-	Year      int       `json:"year"`
+	Year      *int      `json:"year"`
 	Mileage   int       `json:"mileage"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -52,15 +107,42 @@ type GetBikeResponse struct {
 	BikeName  string    `json:"bike_name"`
 	Model     string    `json:"model"`
 	Type      string    `json:"type"`
-	Year      int       `json:"year"`
+	Year      *int      `json:"year"`
 	Mileage   int       `json:"mileage"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// есть только у архивных байков
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// только у удалённых байков, видны с include_deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type GetMyBikesResponse struct {
 	Bikes []BikeInfo `json:"bikes"`
 	Count int        `json:"count"`
+	// всего байков под фильтром без учёта limit/offset
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type GetUrgentBikesResponse struct {
+	Bikes  []*domain.BikeUrgency `json:"bikes"`
+	Count  int                   `json:"count"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+type GetIncompleteBikesResponse struct {
+	Bikes  []IncompleteBikeInfo `json:"bikes"`
+	Count  int                  `json:"count"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+type IncompleteBikeInfo struct {
+	BikeInfo
+	ComponentCount int `json:"component_count"`
 }
 
 type BikeInfo struct {
@@ -69,10 +151,30 @@ type BikeInfo struct {
 	BikeName  string    `json:"bike_name"`
 	Model     string    `json:"model"`
 	Type      string    `json:"type"`
-	Year      int       `json:"year"`
+	Year      *int      `json:"year"`
 	Mileage   int       `json:"mileage"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// есть только у архивных байков
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// только у удалённых байков, видны с include_deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newBikeInfo(bike *domain.Bike) BikeInfo {
+	return BikeInfo{
+		BikeID:     bike.BikeID,
+		UserID:     bike.UserID,
+		BikeName:   bike.BikeName,
+		Model:      bike.Model,
+		Type:       string(bike.Type),
+		Year:       bike.Year,
+		Mileage:    bike.Mileage,
+		CreatedAt:  bike.CreatedAt,
+		UpdatedAt:  bike.UpdatedAt,
+		ArchivedAt: bike.ArchivedAt,
+		DeletedAt:  bike.DeletedAt,
+	}
 }
 
 type UpdateBikeResponse struct {
@@ -81,7 +183,7 @@ type UpdateBikeResponse struct {
 	BikeName  string    `json:"bike_name"`
 	Model     string    `json:"model"`
 	Type      string    `json:"type"`
-	Year      int       `json:"year"`
+	Year      *int      `json:"year"`
 	Mileage   int       `json:"mileage"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -96,24 +198,64 @@ type GetBikeWithComponentsResponse struct {
 	BikeName   string          `json:"bike_name"`
 	Model      string          `json:"model"`
 	Type       string          `json:"type"`
-	Year       int             `json:"year"`
+	Year       *int            `json:"year"`
 	Mileage    int             `json:"mileage"`
 	Components []ComponentInfo `json:"components"`
 	CreatedAt  time.Time       `json:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at"`
 }
 
+type GetComponentStatusResponse struct {
+	BikeID      uuid.UUID `json:"bike_id"`
+	Mileage     int       `json:"mileage"`
+	WarnPercent int       `json:"warn_threshold_percent"`
+	domain.ComponentStatusBuckets
+}
+
+type GetNeedsReplacementResponse struct {
+	BikeID     uuid.UUID                `json:"bike_id"`
+	Mileage    int                      `json:"mileage"`
+	Components []domain.ReplacementItem `json:"components"`
+	Count      int                      `json:"count"`
+}
+
+type GetBikeForecastResponse struct {
+	BikeID     uuid.UUID                  `json:"bike_id"`
+	Mileage    int                        `json:"mileage"`
+	DailyKm    float64                    `json:"daily_km"`
+	Components []domain.ComponentForecast `json:"components"`
+}
+
+// GetBikeWithGroupedComponentsResponse - ответ with-components при group_by=category
+type GetBikeWithGroupedComponentsResponse struct {
+	BikeID    uuid.UUID               `json:"bike_id"`
+	UserID    uuid.UUID               `json:"user_id"`
+	BikeName  string                  `json:"bike_name"`
+	Model     string                  `json:"model"`
+	Type      string                  `json:"type"`
+	Year      *int                    `json:"year"`
+	Mileage   int                     `json:"mileage"`
+	Groups    []domain.ComponentGroup `json:"groups"`
+	CreatedAt time.Time               `json:"created_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
 type ComponentInfo struct {
-	ID               uuid.UUID `json:"id"`
-	BikeID           uuid.UUID `json:"bike_id"`
-	Name             string    `json:"name"`
-	Brand            string    `json:"brand"`
-	Model            string    `json:"model"`
-	InstalledAt      time.Time `json:"installed_at"`
-	InstalledMileage int       `json:"installed_mileage"`
-	MaxMileage       int       `json:"max_mileage"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               uuid.UUID         `json:"id"`
+	BikeID           uuid.UUID         `json:"bike_id"`
+	Name             string            `json:"name"`
+	DisplayName      string            `json:"display_name"`
+	Brand            string            `json:"brand"`
+	Model            string            `json:"model"`
+	InstalledAt      time.Time         `json:"installed_at"`
+	InstalledMileage int               `json:"installed_mileage"`
+	MaxMileage       int               `json:"max_mileage"`
+	MaxAgeDays       *int              `json:"max_age_days,omitempty"`
+	Position         string            `json:"position"`
+	PercentWorn      float64           `json:"percent_worn" example:"85.5"`
+	WearStatus       domain.WearStatus `json:"wear_status" enums:"ok,warning,overdue"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 }
 
 type UserResponseInfo struct {
@@ -127,42 +269,68 @@ type UserResponseInfo struct {
 }
 
 type GetBikeWithUserResponse struct {
-	BikeID    uuid.UUID         `json:"bike_id"`
-	UserID    uuid.UUID         `json:"user_id"`
-	BikeName  string            `json:"bike_name"`
-	Model     string            `json:"model"`
-	Type      string            `json:"type"`
-	Year      int               `json:"year"`
-	Mileage   int               `json:"mileage"`
-	User      *UserResponseInfo `json:"user,omitempty"`
-	CreatedAt time.Time         `json:"created_at"`
-	UpdatedAt time.Time         `json:"updated_at"`
+	BikeID     uuid.UUID         `json:"bike_id"`
+	UserID     uuid.UUID         `json:"user_id"`
+	BikeName   string            `json:"bike_name"`
+	Model      string            `json:"model"`
+	Type       string            `json:"type"`
+	Year       *int              `json:"year"`
+	Mileage    int               `json:"mileage"`
+	User       *UserResponseInfo `json:"user"`
+	UserSource string            `json:"user_source" enums:"user_service,unavailable,invalid"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
 }
 
+// Откуда взялось поле user в GetBikeWithUserResponse
+const (
+	userSourceService     = "user_service"
+	userSourceUnavailable = "unavailable"
+	userSourceInvalid     = "invalid"
+)
+
 func NewBikeHandler(
 	bikeService *services.BikeService,
 	logger ports.LoggerPort,
 	metrics ports.MetricsPort,
 	userClient *user_client.UserMicroservice,
-
+	userService *config.UserService,
+	pagination *config.Pagination,
+	cache ports.CachePort,
+	publicURL string,
+	maxBatchSize int,
+	warnPercent int,
 ) *BikeHandler {
 	return &BikeHandler{
 		bikeService: bikeService,
 		logger:      logger,
 		metrics:     metrics,
-		userClient:  userClient,
+		getUser: func(params *users.GetUsersIDParams, authInfo runtime.ClientAuthInfoWriter) (*users.GetUsersIDOK, error) {
+			return userClient.Users.GetUsersID(params, authInfo)
+		},
+		userBreaker:  newUserServiceBreaker(userService, metrics),
+		userTimeout:  userService.Timeout,
+		userCritical: userService.ReadinessCritical,
+		pagination:   pagination,
+		cache:        cache,
+		publicURL:    publicURL,
+		maxBatchSize: maxBatchSize,
+		warnPercent:  warnPercent,
 	}
 }
 
 // @Summary Создать байк
-// @Description Создание нового байка
+// @Description Создание нового байка. С заголовком Idempotency-Key повтор того же запроса в течение суток вернёт уже созданный байк (201, Idempotent-Replayed: true) вместо дубля. Параллельный запрос с тем же ключом дождётся первого
 // @Tags bikes
 // @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Ключ запроса, уникальный в пределах пользователя" example:"create-bike-7f3a"
 // @Param request body BikeRequest true "Данные байка"
 // @Success 201 {object} CreateBikeResponse "Байк создан"
-// @Failure 400 {object} errorResponse "Неверный запрос"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 404 {object} errorResponse "Байк, созданный по этому ключу, уже удалён"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей или ключ уже использован для другого запроса"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Router /bikes [post]
 func (h *BikeHandler) CreateBike(c *gin.Context) {
@@ -173,19 +341,29 @@ func (h *BikeHandler) CreateBike(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to CreateBike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to CreateBike", map[string]interface{}{
 			"ip": c.ClientIP(),
 		})
 		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
+	key, ok := idempotencyKey(c)
+	if !ok {
+		return
+	}
+
 	var req BikeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed JSON parse in create bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in create bike", map[string]interface{}{
 			"error": err.Error(),
 		})
-		newErrorResponse(c, http.StatusBadRequest, "Invalid JSON format")
+		bindError(c, err)
+		return
+	}
+	hash, err := requestHash(req)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to create bike")
 		return
 	}
 
@@ -194,11 +372,24 @@ func (h *BikeHandler) CreateBike(c *gin.Context) {
 		Model:   req.Model,
 		Type:    domain.BikeType(req.Type),
 		Mileage: req.Mileage,
+		Year:    req.Year,
 	}
 
-	createdBike, err := h.bikeService.CreateBike(c.Request.Context(), bike)
+	createdBike, replayed, err := h.bikeService.CreateBike(c.Request.Context(), bike, domain.IdempotencyKey{
+		UserID:      payload.UserID,
+		Key:         key,
+		RequestHash: hash,
+	})
 	if err != nil {
-		h.logger.Error("Failed to create bike", map[string]interface{}{
+		if errors.Is(err, domain.ErrInvalidBikeType) || errors.Is(err, domain.ErrInvalidBikeYear) || errors.Is(err, services.ErrIdempotencyKeyReused) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrBikeNotFound) {
+			newErrorResponse(c, http.StatusNotFound, "Bike created with this Idempotency-Key was deleted")
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Error("Failed to create bike", map[string]interface{}{
 			"error":   err.Error(),
 			"user_id": payload.UserID,
 		})
@@ -206,35 +397,164 @@ func (h *BikeHandler) CreateBike(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Bike created successfully", map[string]interface{}{
-		"bike_id": createdBike.BikeID,
-		"user_id": createdBike.UserID,
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	h.logger.WithContext(c.Request.Context()).Info("Bike created successfully", map[string]interface{}{
+		"bike_id":  createdBike.BikeID,
+		"user_id":  createdBike.UserID,
+		"replayed": replayed,
 	})
 
-	response := CreateBikeResponse{
-		BikeID:    createdBike.BikeID,
-		UserID:    createdBike.UserID,
-		BikeName:  createdBike.BikeName,
-		Model:     createdBike.Model,
-		Type:      string(createdBike.Type),
-		Year:      createdBike.Year,
-		Mileage:   createdBike.Mileage,
-		CreatedAt: createdBike.CreatedAt,
+	c.JSON(http.StatusCreated, newCreateBikeResponse(createdBike))
+}
+
+func newCreateBikeResponse(bike *domain.Bike) *CreateBikeResponse {
+	return &CreateBikeResponse{
+		BikeID:    bike.BikeID,
+		UserID:    bike.UserID,
+		BikeName:  bike.BikeName,
+		Model:     bike.Model,
+		Type:      string(bike.Type),
+		Year:      bike.Year,
+		Mileage:   bike.Mileage,
+		CreatedAt: bike.CreatedAt,
 	}
+}
 
-	c.JSON(http.StatusCreated, response)
+// @Summary Создать несколько байков
+// @Description Создаёт до 100 байков (и не больше MAX_BATCH_SIZE) авторизованного пользователя: либо все, либо ни одного. С заголовком Idempotency-Key повтор того же батча вернёт уже созданные байки (200, replayed=true) вместо дублей
+// @Tags bikes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Ключ батча, уникальный в пределах пользователя" example:"sync-2025-06-01-001"
+// @Param request body CreateBikesBatchRequest true "Байки"
+// @Success 201 {object} CreateBikesBatchResponse "Байки созданы"
+// @Success 200 {object} CreateBikesBatchResponse "Повтор батча, байки уже были созданы"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 413 {object} errorResponse "Слишком большой батч"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Байки чужого пользователя или сервисный ключ"
+// @Failure 422 {object} CreateBikesBatchResponse "Батч отклонён, ошибки по элементам (или errorResponse, если ключ уже использован для другого батча)"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/batch [post]
+func (h *BikeHandler) CreateBikesBatch(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to CreateBikesBatch", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	key, ok := idempotencyKey(c)
+	if !ok {
+		return
+	}
+
+	var req CreateBikesBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in create bikes batch", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if batchTooLarge(c, len(req.Bikes), maxBikeCreateBatchSize, h.maxBatchSize, "bikes") {
+		return
+	}
+
+	bikes := make([]*domain.Bike, len(req.Bikes))
+	for i, item := range req.Bikes {
+		if item.UserID != nil && *item.UserID != payload.UserID.String() {
+			h.logger.WithContext(c.Request.Context()).Warn("Access denied to create bike for another user", map[string]interface{}{
+				"requester_id": payload.UserID.String(),
+				"user_id":      *item.UserID,
+				"index":        i,
+			})
+			newErrorResponse(c, http.StatusForbidden, fmt.Sprintf("bikes[%d] belongs to another user", i))
+			return
+		}
+		bikes[i] = &domain.Bike{
+			UserID:  payload.UserID,
+			Model:   item.Model,
+			Type:    domain.BikeType(item.Type),
+			Mileage: item.Mileage,
+			Year:    item.Year,
+		}
+	}
+
+	created, replayed, err := h.bikeService.CreateBikes(c.Request.Context(), payload.UserID, key, bikes)
+	if errors.Is(err, services.ErrIdempotencyKeyReused) {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil && created == nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to create bikes batch", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to create bikes")
+		return
+	}
+
+	response := CreateBikesBatchResponse{
+		Results:  make([]BikeBatchItemResult, len(created)),
+		Replayed: replayed,
+	}
+	for i, r := range created {
+		result := BikeBatchItemResult{Index: i}
+		switch {
+		case r.Err != nil:
+			result.Status = batchStatusFailed
+			result.Error = r.Err.Error()
+			response.Failed++
+		case r.Bike != nil:
+			result.Status = batchStatusCreated
+			result.Bike = newCreateBikeResponse(r.Bike)
+			response.Created++
+		default:
+			// откатился вместе с батчем из-за ошибки в другом элементе
+			result.Status = batchStatusSkipped
+		}
+		response.Results[i] = result
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidBikeBatch):
+		c.JSON(http.StatusUnprocessableEntity, response)
+	case err != nil:
+		h.logger.WithContext(c.Request.Context()).Error("Bikes batch rolled back", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID,
+		})
+		c.JSON(http.StatusInternalServerError, response)
+	case replayed:
+		c.Header("Idempotent-Replayed", "true")
+		c.JSON(http.StatusOK, response)
+	default:
+		c.JSON(http.StatusCreated, response)
+	}
 }
 
 // @Summary Получить байк
-// @Description Получение информации о байке по ID
+// @Description Получение информации о байке по ID. Удалённый байк отдаётся только админу с include_deleted=true
 // @Tags bikes
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "ID байка" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
+// @Param include_deleted query bool false "Искать и среди удалённых (только для админов)" default(false)
 // @Success 200 {object} GetBikeResponse "Байк найден"
 // @Failure 401 {object} errorResponse "Не авторизован"
-// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 403 {object} errorResponse "Доступ запрещен или include_deleted не от админа"
 // @Failure 404 {object} errorResponse "Байк не найден"
 // @Router /bikes/{id} [get]
 func (h *BikeHandler) GetBike(c *gin.Context) {
@@ -247,7 +567,7 @@ func (h *BikeHandler) GetBike(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to GetBike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBike", map[string]interface{}{
 			"bike_id": bikeID,
 			"ip":      c.ClientIP(),
 		})
@@ -255,9 +575,18 @@ func (h *BikeHandler) GetBike(c *gin.Context) {
 		return
 	}
 
-	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), bikeID)
+	withDeleted, ok := includeDeleted(c, payload)
+	if !ok {
+		return
+	}
+	getBike := h.bikeService.GetBikeByID
+	if withDeleted {
+		getBike = h.bikeService.GetBikeByIDWithDeleted
+	}
+
+	bike, err := getBike(c.Request.Context(), bikeID)
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -265,7 +594,7 @@ func (h *BikeHandler) GetBike(c *gin.Context) {
 		return
 	}
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied to bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   bike.UserID.String(),
 			"bike_id":      bikeID,
@@ -273,32 +602,69 @@ func (h *BikeHandler) GetBike(c *gin.Context) {
 		newErrorResponse(c, http.StatusForbidden, "Access denied")
 		return
 	}
-	response := GetBikeResponse{
-		BikeID:    bike.BikeID,
-		UserID:    bike.UserID,
-		BikeName:  bike.BikeName,
-		Model:     bike.Model,
-		Type:      string(bike.Type),
-		Year:      bike.Year,
-		Mileage:   bike.Mileage,
-		CreatedAt: bike.CreatedAt,
-		UpdatedAt: bike.UpdatedAt,
-	}
+	c.JSON(http.StatusOK, newGetBikeResponse(bike))
+}
 
-	c.JSON(http.StatusOK, response)
+func newGetBikeResponse(bike *domain.Bike) GetBikeResponse {
+	return GetBikeResponse{
+		BikeID:     bike.BikeID,
+		UserID:     bike.UserID,
+		BikeName:   bike.BikeName,
+		Model:      bike.Model,
+		Type:       string(bike.Type),
+		Year:       bike.Year,
+		Mileage:    bike.Mileage,
+		CreatedAt:  bike.CreatedAt,
+		UpdatedAt:  bike.UpdatedAt,
+		ArchivedAt: bike.ArchivedAt,
+		DeletedAt:  bike.DeletedAt,
+	}
 }
 
 // @Summary Получить байки пользователя по айди пользователя
-// @Description Получение всех байков авторизованного пользователя
+// @Description Получение всех байков авторизованного пользователя, кроме архивных (они в /bikes/my/archived)
 // @Tags bikes
 // @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param limit query int false "Сколько байков вернуть (не больше 100)" example:"20"
+// @Param offset query int false "Сколько байков пропустить" example:"0"
+// @Param sort query string false "Сортировка" Enums(created_at_desc, created_at_asc, mileage_desc, mileage_asc, name_asc, name_desc, year_desc, year_asc) default(created_at_desc)
+// @Param created_after query string false "Добавлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-01-01"
+// @Param created_before query string false "Добавлены не позже (RFC3339 или YYYY-MM-DD, дата включает весь день)" example:"2025-12-31"
+// @Param include_deleted query bool false "Вместе с удалёнными байками (только для админов)" default(false)
 // @Success 200 {object} GetMyBikesResponse "Список байков пользователя"
+// @Failure 400 {object} errorResponse "Неверные параметры пагинации, сортировка или диапазон дат"
 // @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "include_deleted не от админа"
 // @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
 // @Router /bikes/my [get]
 func (h *BikeHandler) GetMyBikes(c *gin.Context) {
+	h.listMyBikes(c, false)
+}
+
+// @Summary Архивные байки пользователя
+// @Description Байки авторизованного пользователя, убранные в архив (проданные, украденные). Они доступны по ID как обычно, но не попадают в остальные списки и сводку
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Сколько байков вернуть (не больше 100)" example:"20"
+// @Param offset query int false "Сколько байков пропустить" example:"0"
+// @Param sort query string false "Сортировка" Enums(created_at_desc, created_at_asc, mileage_desc, mileage_asc, name_asc, name_desc, year_desc, year_asc) default(created_at_desc)
+// @Param created_after query string false "Добавлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-01-01"
+// @Param created_before query string false "Добавлены не позже (RFC3339 или YYYY-MM-DD, дата включает весь день)" example:"2025-12-31"
+// @Param include_deleted query bool false "Вместе с удалёнными байками (только для админов)" default(false)
+// @Success 200 {object} GetMyBikesResponse "Архивные байки"
+// @Failure 400 {object} errorResponse "Неверные параметры пагинации, сортировка или диапазон дат"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "include_deleted не от админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/my/archived [get]
+func (h *BikeHandler) GetArchivedBikes(c *gin.Context) {
+	h.listMyBikes(c, true)
+}
+
+func (h *BikeHandler) listMyBikes(c *gin.Context, archived bool) {
 	start := time.Now()
 	defer func() {
 		h.metrics.RecordMetrics(c, start)
@@ -306,16 +672,51 @@ func (h *BikeHandler) GetMyBikes(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to GetMyBikes", map[string]interface{}{
-			"ip": c.ClientIP(),
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetMyBikes", map[string]interface{}{
+			"ip":       c.ClientIP(),
+			"archived": archived,
 		})
 		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	bikes, err := h.bikeService.GetBikesByUserID(c.Request.Context(), payload.UserID.String())
+	filter, err := parseBikeFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	withDeleted, ok := includeDeleted(c, payload)
+	if !ok {
+		return
+	}
+	filter.Archived = archived
+	filter.IncludeDeleted = withDeleted
+	filter.Sort = domain.BikeSort(c.Query("sort"))
+	if err := filter.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := parsePage(c, h.pagination)
 	if err != nil {
-		h.logger.Error("Failed to get bikes", map[string]interface{}{
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page.Limit = min(page.Limit, maxMyBikesPageSize)
+	filter.Page = page
+
+	bikes, err := h.bikeService.GetBikesByUserID(c.Request.Context(), payload.UserID.String(), filter)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bikes", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get bikes")
+		return
+	}
+	total, err := h.bikeService.CountBikesByUserID(c.Request.Context(), payload.UserID, filter)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to count bikes", map[string]interface{}{
 			"error":   err.Error(),
 			"user_id": payload.UserID,
 		})
@@ -324,29 +725,254 @@ func (h *BikeHandler) GetMyBikes(c *gin.Context) {
 	}
 	bikeInfos := make([]BikeInfo, len(bikes))
 	for i, bike := range bikes {
-		bikeInfos[i] = BikeInfo{
-			BikeID:    bike.BikeID,
-			UserID:    bike.UserID,
-			BikeName:  bike.BikeName,
-			Model:     bike.Model,
-			Type:      string(bike.Type),
-			Year:      bike.Year,
-			Mileage:   bike.Mileage,
-			CreatedAt: bike.CreatedAt,
-			UpdatedAt: bike.UpdatedAt,
+		bikeInfos[i] = newBikeInfo(bike)
+	}
+
+	response := GetMyBikesResponse{
+		Bikes:  bikeInfos,
+		Count:  len(bikeInfos),
+		Total:  total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Байки по срочности обслуживания
+// @Description Байки авторизованного пользователя, отсортированные по срочности: urgency = max(износ компонента * вес его типа), износ - по худшему из порогов (пробег с установки / max_mileage или возраст / max_age_days), веса - GET /components/weights, по умолчанию 1. max_wear, overdue и status считаются по износу без весов
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Сколько байков вернуть"
+// @Param offset query int false "Сколько байков пропустить"
+// @Param warn_threshold_percent query int false "С какого процента износа байк получает status=warning (1-100)" default(80)
+// @Param created_after query string false "Добавлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-01-01"
+// @Param created_before query string false "Добавлены не позже (RFC3339 или YYYY-MM-DD, дата включает весь день)" example:"2025-12-31"
+// @Success 200 {object} GetUrgentBikesResponse "Байки по срочности"
+// @Failure 400 {object} errorResponse "Неверные параметры пагинации или диапазон дат"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/my/urgent [get]
+func (h *BikeHandler) GetUrgentBikes(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetUrgentBikes", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parseBikeFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := parsePage(c, h.pagination)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Page = page
+
+	warnPercent, err := parseWarnThreshold(c, h.warnPercent)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bikes, err := h.bikeService.GetBikesByUrgency(c.Request.Context(), payload.UserID.String(), filter)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bikes by urgency", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get bikes")
+		return
+	}
+	if bikes == nil {
+		bikes = []*domain.BikeUrgency{}
+	}
+	for _, bike := range bikes {
+		bike.Status = domain.WearStatusOf(bike.MaxWear, warnPercent)
+	}
+
+	c.JSON(http.StatusOK, GetUrgentBikesResponse{
+		Bikes:  bikes,
+		Count:  len(bikes),
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	})
+}
+
+// @Summary Байки без компонентов
+// @Description Байки авторизованного пользователя, к которым не добавлено ни одного компонента. Админ с all=true получает такие байки всех пользователей
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Сколько байков вернуть"
+// @Param offset query int false "Сколько байков пропустить"
+// @Param all query bool false "Только для админов: по всем пользователям"
+// @Param created_after query string false "Добавлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-01-01"
+// @Param created_before query string false "Добавлены не позже (RFC3339 или YYYY-MM-DD, дата включает весь день)" example:"2025-12-31"
+// @Success 200 {object} GetIncompleteBikesResponse "Байки без компонентов"
+// @Failure 400 {object} errorResponse "Неверные параметры пагинации или диапазон дат"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "all=true доступен только админам"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/my/incomplete [get]
+func (h *BikeHandler) GetIncompleteBikes(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetIncompleteBikes", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parseBikeFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := parsePage(c, h.pagination)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Page = page
+
+	userID := payload.UserID.String()
+	if c.Query("all") == "true" {
+		if payload.Role != domain.Admin {
+			newErrorResponse(c, http.StatusForbidden, "Access denied")
+			return
+		}
+		userID = ""
+	}
+
+	bikes, err := h.bikeService.GetBikesWithoutComponents(c.Request.Context(), userID, filter)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bikes without components", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get bikes")
+		return
+	}
+
+	bikeInfos := make([]IncompleteBikeInfo, len(bikes))
+	for i, bike := range bikes {
+		bikeInfos[i] = IncompleteBikeInfo{
+			BikeInfo: newBikeInfo(bike),
+		}
+	}
+
+	c.JSON(http.StatusOK, GetIncompleteBikesResponse{
+		Bikes:  bikeInfos,
+		Count:  len(bikeInfos),
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	})
+}
+
+// @Summary Массовое изменение байков
+// @Description Только для админов, API-ключам недоступно. Меняет одно поле (type или model) у байков, отобранных по списку ids или по current_type. Требует confirm=true. Некорректные ids перечисляются все сразу в поле invalid, не больше 1000 ids и не больше MAX_BATCH_SIZE
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body BulkUpdateBikesRequest true "Фильтр и изменение"
+// @Success 200 {object} BulkUpdateBikesResponse "Сколько байков изменено"
+// @Failure 400 {object} errorResponse "Некорректный JSON или нет подтверждения"
+// @Failure 413 {object} errorResponse "Слишком много ids"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/bikes [patch]
+func (h *BikeHandler) BulkUpdateBikes(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req BulkUpdateBikesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in bulk update bikes", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if !req.Confirm {
+		newErrorResponse(c, http.StatusBadRequest, "Bulk update requires confirm=true")
+		return
+	}
+	if batchTooLarge(c, len(req.IDs), maxBikeBatchSize, h.maxBatchSize, "ids") {
+		return
+	}
+	ids, invalid := parseUUIDs(req.IDs)
+	if len(invalid) > 0 {
+		newInvalidIDsResponse(c, invalid)
+		return
+	}
+
+	update := domain.BikeBulkUpdate{
+		CurrentType: domain.BikeType(req.CurrentType),
+		Field:       domain.BikeField(req.Field),
+		Value:       req.Value,
+		IDs:         ids,
+	}
+
+	bikes, err := h.bikeService.BulkUpdateBikes(c.Request.Context(), update)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBulkUpdate) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
 		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to update bikes")
+		return
 	}
 
-	response := GetMyBikesResponse{
-		Bikes: bikeInfos,
-		Count: len(bikeInfos),
+	response := BulkUpdateBikesResponse{
+		Affected: len(bikes),
+		BikeIDs:  make([]uuid.UUID, len(bikes)),
+	}
+	for i, bike := range bikes {
+		response.BikeIDs[i] = bike.BikeID
 	}
 
+	h.logger.WithContext(c.Request.Context()).Info("Admin bulk bike update", map[string]interface{}{
+		"audit":        "bikes_bulk_update",
+		"requester_id": payload.Actor(),
+		"field":        req.Field,
+		"affected":     response.Affected,
+	})
+
 	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Обновить байк
-// @Description Обновление данных байка
+// @Description Обновление данных байка. Пробег, как и в PATCH /bikes/{id}/mileage, может только расти
 // @Tags bikes
 // @Security BearerAuth
 // @Accept json
@@ -354,9 +980,12 @@ func (h *BikeHandler) GetMyBikes(c *gin.Context) {
 // @Param id path string true "ID байка" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
 // @Param request body UpdateBike true "Данные для обновления"
 // @Success 200 {object} UpdateBikeResponse "Байк обновлен"
-// @Failure 400 {object} errorResponse "Неверный запрос"
+// @Failure 400 {object} errorResponse "Некорректный JSON или ID байка"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей или пробег меньше сохранённого"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "Пробег успели изменить параллельным запросом"
 // @Router /bikes/{id} [put]
 func (h *BikeHandler) UpdateBike(c *gin.Context) {
 	start := time.Now()
@@ -368,7 +997,7 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to UpdateBike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to UpdateBike", map[string]interface{}{
 			"bike_id": bikeID,
 			"ip":      c.ClientIP(),
 		})
@@ -376,9 +1005,9 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 		return
 	}
 
-	existingBike, err := h.bikeService.GetBikeByID(c.Request.Context(), bikeID)
+	existingBike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), bikeID)
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -387,7 +1016,7 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != existingBike.UserID {
-		h.logger.Warn("Access denied to update bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to update bike", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   existingBike.UserID.String(),
 			"bike_id":      bikeID,
@@ -398,16 +1027,16 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 
 	var req UpdateBike
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed JSON parse in update bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in update bike", map[string]interface{}{
 			"error": err.Error(),
 		})
-		newErrorResponse(c, http.StatusBadRequest, "Invalid JSON format")
+		bindError(c, err)
 		return
 	}
 
 	parsedID, err := uuid.Parse(bikeID)
 	if err != nil {
-		h.logger.Error("Invalid bike ID format", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Invalid bike ID format", map[string]interface{}{
 			"bike_id": bikeID,
 		})
 		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
@@ -428,10 +1057,23 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 	if req.Mileage != nil {
 		bike.Mileage = *req.Mileage
 	}
+	bike.Year = req.Year
 
 	updatedBike, err := h.bikeService.UpdateBike(c.Request.Context(), bike)
 	if err != nil {
-		h.logger.Error("Failed to update bike", map[string]interface{}{
+		if errors.Is(err, domain.ErrInvalidBikeType) || errors.Is(err, domain.ErrInvalidBikeYear) || errors.Is(err, domain.ErrMileageDecrease) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrMileageConflict) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrBikeNotFound) {
+			newErrorResponse(c, http.StatusNotFound, "Bike not found")
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Error("Failed to update bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -439,7 +1081,7 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Bike updated successfully", map[string]interface{}{
+	h.logger.WithContext(c.Request.Context()).Info("Bike updated successfully", map[string]interface{}{
 		"bike_id": bikeID,
 	})
 	response := UpdateBikeResponse{
@@ -457,7 +1099,7 @@ func (h *BikeHandler) UpdateBike(c *gin.Context) {
 }
 
 // @Summary Удалить байк
-// @Description Удаление байка
+// @Description Мягкое удаление: байк пропадает из всех чтений, но вместе с компонентами и их историей остаётся в базе, и его можно вернуть через POST /bikes/{id}/restore. Отправляется событие bike.deleted
 // @Tags bikes
 // @Security BearerAuth
 // @Accept json
@@ -477,7 +1119,7 @@ func (h *BikeHandler) DeleteBike(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to DeleteBike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to DeleteBike", map[string]interface{}{
 			"bike_id": bikeID,
 			"ip":      c.ClientIP(),
 		})
@@ -485,9 +1127,9 @@ func (h *BikeHandler) DeleteBike(c *gin.Context) {
 		return
 	}
 
-	existingBike, err := h.bikeService.GetBikeByID(c.Request.Context(), bikeID)
+	existingBike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), bikeID)
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -496,7 +1138,7 @@ func (h *BikeHandler) DeleteBike(c *gin.Context) {
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != existingBike.UserID {
-		h.logger.Warn("Access denied to delete bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to delete bike", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   existingBike.UserID.String(),
 			"bike_id":      bikeID,
@@ -507,7 +1149,7 @@ func (h *BikeHandler) DeleteBike(c *gin.Context) {
 
 	err = h.bikeService.DeleteBike(c.Request.Context(), bikeID)
 	if err != nil {
-		h.logger.Error("Failed to delete bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to delete bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -515,7 +1157,7 @@ func (h *BikeHandler) DeleteBike(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Bike deleted successfully", map[string]interface{}{
+	h.logger.WithContext(c.Request.Context()).Info("Bike deleted successfully", map[string]interface{}{
 		"bike_id": bikeID,
 	})
 
@@ -525,13 +1167,23 @@ func (h *BikeHandler) DeleteBike(c *gin.Context) {
 }
 
 // @Summary Получить байк с компонентами
-// @Description Получение байка со всеми компонентами
+// @Description Получение байка со всеми компонентами. С group_by=category вместо components отдаётся groups: компоненты по категориям со сводкой износа (GetBikeWithGroupedComponentsResponse)
 // @Tags bikes
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "ID байка" example:"jdk2-fsjmk-daslkdo2-321md-jsnlaljdn"
+// @Param installed_after query string false "Установлены не раньше (RFC3339 или YYYY-MM-DD)" example:"2025-04-01"
+// @Param installed_before query string false "Установлены не позже (RFC3339 или YYYY-MM-DD)" example:"2025-10-01"
+// @Param limit query int false "Сколько последних компонентов вернуть (по умолчанию и максимум задаются в конфиге)" example:"50"
+// @Param offset query int false "Сколько компонентов пропустить" example:"0"
+// @Param sort query string false "Порядок компонентов (wear - износ по худшему из порогов)" Enums(installed_at, wear, name, max_mileage) default(installed_at)
+// @Param order query string false "Направление, по умолчанию desc для installed_at и wear, asc для name и max_mileage" Enums(asc, desc)
+// @Param group_by query string false "Сгруппировать компоненты" Enums(category)
+// @Param warn_threshold_percent query int false "Порог warning в процентах износа для групп и wear_status компонентов (1-100), по умолчанию WEAR_WARN_THRESHOLD_PERCENT" default(80)
+// @Param Accept-Language header string false "Язык display_name компонентов" example:"ru"
 // @Success 200 {object} GetBikeWithComponentsResponse "Байк с компонентами"
+// @Failure 400 {object} errorResponse "Неверный фильтр"
 // @Failure 401 {object} errorResponse "Не авторизован"
 // @Failure 403 {object} errorResponse "Доступ запрещен"
 // @Failure 404 {object} errorResponse "Байк не найден"
@@ -546,7 +1198,7 @@ func (h *BikeHandler) GetBikeWithComponents(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt to GetBikeWithComponents", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBikeWithComponents", map[string]interface{}{
 			"bike_id": bikeID,
 			"ip":      c.ClientIP(),
 		})
@@ -554,9 +1206,34 @@ func (h *BikeHandler) GetBikeWithComponents(c *gin.Context) {
 		return
 	}
 
-	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID)
+	filter, err := parseComponentFilter(c)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Warn("Invalid component filter", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Page, err = parsePage(c, h.pagination)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "category" {
+		newErrorResponse(c, http.StatusBadRequest, "group_by must be category")
+		return
+	}
+	warnPercent, err := parseWarnThreshold(c, h.warnPercent)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID, filter)
 	if err != nil {
-		h.logger.Error("Failed to get bike with components", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike with components", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -565,7 +1242,7 @@ func (h *BikeHandler) GetBikeWithComponents(c *gin.Context) {
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied to bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"bike_owner":   bike.UserID.String(),
 			"bike_id":      bikeID,
@@ -573,23 +1250,51 @@ func (h *BikeHandler) GetBikeWithComponents(c *gin.Context) {
 		newErrorResponse(c, http.StatusForbidden, "Access denied")
 		return
 	}
+
+	localizeComponents(preferredLanguage(c), bike.Components...)
+	if groupBy == "category" {
+		c.JSON(http.StatusOK, GetBikeWithGroupedComponentsResponse{
+			BikeID:    bike.BikeID,
+			UserID:    bike.UserID,
+			BikeName:  bike.BikeName,
+			Model:     bike.Model,
+			Type:      string(bike.Type),
+			Year:      bike.Year,
+			Mileage:   bike.Mileage,
+			Groups:    bike.GroupByCategory(warnPercent),
+			CreatedAt: bike.CreatedAt,
+			UpdatedAt: bike.UpdatedAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newBikeWithComponentsResponse(bike, warnPercent))
+}
+
+func newBikeWithComponentsResponse(bike *domain.Bike, warnPercent int) GetBikeWithComponentsResponse {
+	now := time.Now()
 	componentInfos := make([]ComponentInfo, len(bike.Components))
 	for i, comp := range bike.Components {
 		componentInfos[i] = ComponentInfo{
 			ID:               comp.ID,
 			BikeID:           comp.BikeID,
 			Name:             string(comp.Name),
+			DisplayName:      comp.DisplayName,
 			Brand:            comp.Brand,
 			Model:            comp.Model,
 			InstalledAt:      comp.InstalledAt,
 			InstalledMileage: comp.InstalledMileage,
 			MaxMileage:       comp.MaxMileage,
+			MaxAgeDays:       comp.MaxAgeDays,
+			Position:         string(comp.Position),
+			PercentWorn:      comp.PercentWorn(bike.Mileage, now),
+			WearStatus:       comp.WearStatus(bike.Mileage, warnPercent, now),
 			CreatedAt:        comp.CreatedAt,
 			UpdatedAt:        comp.UpdatedAt,
 		}
 	}
 
-	response := GetBikeWithComponentsResponse{
+	return GetBikeWithComponentsResponse{
 		BikeID:     bike.BikeID,
 		UserID:     bike.UserID,
 		BikeName:   bike.BikeName,
@@ -601,8 +1306,6 @@ func (h *BikeHandler) GetBikeWithComponents(c *gin.Context) {
 		CreatedAt:  bike.CreatedAt,
 		UpdatedAt:  bike.UpdatedAt,
 	}
-
-	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Получить байк с пользователем
@@ -626,7 +1329,7 @@ func (h *BikeHandler) GetBikeWithUser(c *gin.Context) {
 
 	payload, exists := getAuthPayload(c, "authorization_payload")
 	if !exists {
-		h.logger.Warn("Unauthorized access attempt", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt", map[string]interface{}{
 			"bike_id": bikeID,
 			"ip":      c.ClientIP(),
 		})
@@ -636,7 +1339,7 @@ func (h *BikeHandler) GetBikeWithUser(c *gin.Context) {
 
 	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), bikeID)
 	if err != nil {
-		h.logger.Error("Failed to get bike", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
@@ -645,7 +1348,7 @@ func (h *BikeHandler) GetBikeWithUser(c *gin.Context) {
 	}
 
 	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
-		h.logger.Warn("Access denied", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied", map[string]interface{}{
 			"requester_id": payload.UserID.String(),
 			"owner_id":     bike.UserID.String(),
 			"bike_id":      bikeID,
@@ -654,51 +1357,289 @@ func (h *BikeHandler) GetBikeWithUser(c *gin.Context) {
 		return
 	}
 
-	params := users.NewGetUsersIDParams()
-	params.ID = bike.UserID.String()
-	params.Context = c.Request.Context()
+	userInfo, userSource := h.lookupUser(c, bike.UserID)
 
-	authHeader := c.GetHeader("Authorization")
-	var authInfo runtime.ClientAuthInfoWriter
-	if authHeader != "" {
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		authInfo = httptransport.BearerToken(token)
+	response := GetBikeWithUserResponse{
+		BikeID:     bike.BikeID,
+		UserID:     bike.UserID,
+		BikeName:   bike.BikeName,
+		Model:      bike.Model,
+		Type:       string(bike.Type),
+		Year:       bike.Year,
+		Mileage:    bike.Mileage,
+		User:       userInfo, // ← используем свою структуру
+		UserSource: userSource,
+		CreatedAt:  bike.CreatedAt,
+		UpdatedAt:  bike.UpdatedAt,
 	}
 
-	var userInfo *UserResponseInfo
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Заполненность байка компонентами
+// @Description Какие компоненты из стандартного набора для типа байка уже добавлены, а каких не хватает
+// @Tags bikes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} domain.BikeCompleteness "Заполненность байка"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Router /bikes/{id}/completeness [get]
+func (h *BikeHandler) GetBikeCompleteness(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBikeCompleteness", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
 
-	resp, err := h.userClient.Users.GetUsersID(params, authInfo)
+	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID, domain.ComponentFilter{})
 	if err != nil {
-		h.logger.Warn("Failed to get user from user-service", map[string]interface{}{
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike with components", map[string]interface{}{
 			"error":   err.Error(),
-			"user_id": bike.UserID.String(),
-		})
-		userInfo = nil
-	} else if resp != nil && resp.Payload != nil {
-		// Маппинг из user_models.HTTPGetUserResponse в UserResponseInfo
-		userInfo = &UserResponseInfo{
-			ID:          resp.Payload.ID,
-			Name:        resp.Payload.Name,
-			Email:       resp.Payload.Email,
-			DateOfBirth: resp.Payload.DateOfBirth,
-			Role:        resp.Payload.Role,
-			CreatedAt:   resp.Payload.CreatedAt,
-			UpdatedAt:   resp.Payload.UpdatedAt,
-		}
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
 	}
 
-	response := GetBikeWithUserResponse{
-		BikeID:    bike.BikeID,
-		UserID:    bike.UserID,
-		BikeName:  bike.BikeName,
-		Model:     bike.Model,
-		Type:      string(bike.Type),
-		Year:      bike.Year,
-		Mileage:   bike.Mileage,
-		User:      userInfo, // ← используем свою структуру
-		CreatedAt: bike.CreatedAt,
-		UpdatedAt: bike.UpdatedAt,
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, bike.Completeness())
+}
+
+// @Summary Прогноз замены компонентов
+// @Description Дата замены каждого компонента с порогом при заданном среднем пробеге в день: (max_mileage - пробег с установки) / daily_km дней от сегодня, или дата по max_age_days, если она раньше. Просроченные получают сегодняшнюю дату. Сортировка от ближайшей замены
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param daily_km query number true "Средний пробег в день, км (больше 0, не больше 1000)" example:"25"
+// @Param warn_threshold_percent query int false "Порог warning в процентах износа (1-100)" default(80)
+// @Success 200 {object} GetBikeForecastResponse "Прогноз по компонентам"
+// @Failure 400 {object} errorResponse "Неверный daily_km или порог"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Router /bikes/{id}/forecast [get]
+func (h *BikeHandler) GetBikeForecast(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBikeForecast", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	dailyKm, err := parseDailyKm(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	warnPercent, err := parseWarnThreshold(c, h.warnPercent)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID, domain.ComponentFilter{})
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike with components", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	c.JSON(http.StatusOK, GetBikeForecastResponse{
+		BikeID:     bike.BikeID,
+		Mileage:    bike.Mileage,
+		DailyKm:    dailyKm,
+		Components: domain.ForecastReplacements(bike, dailyKm, warnPercent, time.Now()),
+	})
+}
+
+// @Summary Компоненты по статусу замены
+// @Description Компоненты байка, разложенные по полосам: overdue - износ от 100%, due_soon - от warn_threshold_percent, ok - остальные. Износ - по худшему из порогов (пробег с установки / max_mileage или возраст / max_age_days), в полосе от самого изношенного. В counts - сколько компонентов в каждой полосе
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param warn_threshold_percent query int false "С какого процента износа компонент попадает в due_soon (1-100)" default(80)
+// @Param Accept-Language header string false "Язык display_name компонентов" example:"ru"
+// @Success 200 {object} GetComponentStatusResponse "Компоненты по полосам"
+// @Failure 400 {object} errorResponse "Неверный порог"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Router /bikes/{id}/component-status [get]
+func (h *BikeHandler) GetComponentStatus(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetComponentStatus", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	warnPercent, err := parseWarnThreshold(c, h.warnPercent)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID, domain.ComponentFilter{})
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike with components", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	localizeComponents(preferredLanguage(c), bike.Components...)
+	c.JSON(http.StatusOK, GetComponentStatusResponse{
+		BikeID:                 bike.BikeID,
+		Mileage:                bike.Mileage,
+		WarnPercent:            warnPercent,
+		ComponentStatusBuckets: bike.BucketByStatus(warnPercent, time.Now()),
+	})
+}
+
+// @Summary Компоненты, которые пора менять
+// @Description Только компоненты байка с превышенным порогом: пробег с установки не меньше max_mileage или возраст не меньше max_age_days. current_mileage - пробег компонента, remaining_km - остаток до max_mileage, percent_worn - износ по худшему из порогов. От самого изношенного
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param Accept-Language header string false "Язык display_name компонентов" example:"ru"
+// @Success 200 {object} GetNeedsReplacementResponse "Изношенные компоненты"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Router /bikes/{id}/components/needs-replacement [get]
+func (h *BikeHandler) GetComponentsNeedingReplacement(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetComponentsNeedingReplacement", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID, domain.ComponentFilter{})
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike with components", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	localizeComponents(preferredLanguage(c), bike.Components...)
+	items := bike.NeedingReplacement(time.Now())
+	c.JSON(http.StatusOK, GetNeedsReplacementResponse{
+		BikeID:     bike.BikeID,
+		Mileage:    bike.Mileage,
+		Components: items,
+		Count:      len(items),
+	})
+}
+
+// @Summary Стандартные наборы компонентов
+// @Description Список компонентов, которые должны быть у байка каждого типа
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string][]string "Наборы по типам байков"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Router /bikes/loadouts [get]
+func (h *BikeHandler) GetStandardLoadouts(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	c.JSON(http.StatusOK, domain.StandardLoadouts)
 }
@@ -0,0 +1,603 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// ответ PUT /bikes/{id} собирается из сохранённого байка, а не из запроса:
+// непереданные поля не должны приходить пустыми
+func TestUpdateBikeResponseReflectsStoredBike(t *testing.T) {
+	owner := uuid.New()
+	year := 2020
+
+	tests := []struct {
+		name      string
+		body      string
+		wantModel string
+		wantYear  *int
+		wantMiles int
+	}{
+		{name: "только пробег", body: `{"mileage":150}`, wantModel: "Stumpjumper", wantYear: &year, wantMiles: 150},
+		{name: "только модель", body: `{"model":"Epic"}`, wantModel: "Epic", wantYear: &year, wantMiles: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+			bike.Year = &year
+			api.store.AddBike(bike)
+
+			w := api.do(http.MethodPut, "/bikes/"+bike.BikeID.String(), api.token(owner, domain.AppUser), tt.body)
+			expectStatus(t, w, http.StatusOK)
+
+			resp := decode[UpdateBikeResponse](t, w)
+			if resp.Model != tt.wantModel || resp.Mileage != tt.wantMiles || resp.BikeName != bike.BikeName || resp.Type != string(bike.Type) {
+				t.Errorf("response = %+v, want model %q, mileage %d", resp, tt.wantModel, tt.wantMiles)
+			}
+			if !equalIntPtr(resp.Year, tt.wantYear) {
+				t.Errorf("year = %v, want %d", resp.Year, *tt.wantYear)
+			}
+		})
+	}
+}
+
+// права на запись в байк проверяются по primary, а не по кешу: в кеше
+// может лежать байк со старым владельцем
+func TestBikeWritesIgnoreCachedBike(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   func(newOwner uuid.UUID) string
+		status int
+	}{
+		{name: "правка", method: http.MethodPut, body: func(uuid.UUID) string { return `{"model":"Epic"}` }, status: http.StatusOK},
+		{name: "пробег", method: http.MethodPatch, path: "/mileage", body: func(uuid.UUID) string { return `{"mileage":2500}` }, status: http.StatusOK},
+		{name: "архивация", method: http.MethodPost, path: "/archive", body: func(uuid.UUID) string { return "" }, status: http.StatusOK},
+		{name: "передача", method: http.MethodPost, path: "/transfer", body: func(to uuid.UUID) string { return `{"new_user_id":"` + to.String() + `"}` }, status: http.StatusOK},
+		{name: "удаление", method: http.MethodDelete, body: func(uuid.UUID) string { return "" }, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous, owner, newOwner := uuid.New(), uuid.New(), uuid.New()
+			api := newTestAPI(t)
+			api.addUser(newOwner, "rider")
+			bike := api.addBike(previous, 1000)
+			path := "/bikes/" + bike.BikeID.String()
+			expectStatus(t, api.do(http.MethodGet, path, api.token(previous, domain.AppUser), nil), http.StatusOK)
+			bike.UserID, bike.Mileage = owner, 2000
+			api.store.AddBike(bike)
+
+			var body any
+			if b := tt.body(newOwner); b != "" {
+				body = b
+			}
+			expectStatus(t, api.do(tt.method, path+tt.path, api.token(previous, domain.AppUser), body), http.StatusForbidden)
+			expectStatus(t, api.do(tt.method, path+tt.path, api.token(owner, domain.AppUser), body), tt.status)
+		})
+	}
+}
+
+// байки с одинаковым created_at должны идти в одном и том же порядке,
+// иначе страницы пересекаются или теряют записи
+func TestGetMyBikesStableOrder(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for range 5 {
+		api.store.AddBike(&domain.Bike{UserID: owner, BikeName: "Same", Type: domain.MTB, Model: "X", Mileage: 1, CreatedAt: created})
+	}
+	token := api.token(owner, domain.AppUser)
+
+	list := func(query string) []uuid.UUID {
+		t.Helper()
+		w := api.do(http.MethodGet, "/bikes/my"+query, token, nil)
+		expectStatus(t, w, http.StatusOK)
+		var ids []uuid.UUID
+		for _, b := range decode[GetMyBikesResponse](t, w).Bikes {
+			ids = append(ids, b.BikeID)
+		}
+		return ids
+	}
+
+	for _, sort := range []string{"", "?sort=mileage_desc", "?sort=name_asc", "?sort=created_at_asc"} {
+		first := list(sort)
+		if len(first) != 5 {
+			t.Fatalf("%q: got %d bikes, want 5", sort, len(first))
+		}
+		for range 3 {
+			if again := list(sort); !slices.Equal(again, first) {
+				t.Fatalf("%q: order changed between calls: %v then %v", sort, first, again)
+			}
+		}
+
+		var paged []uuid.UUID
+		for offset := 0; offset < 5; offset += 2 {
+			sep := "?"
+			if sort != "" {
+				sep = "&"
+			}
+			paged = append(paged, list(sort+sep+"limit=2&offset="+strconv.Itoa(offset))...)
+		}
+		if !slices.Equal(paged, first) {
+			t.Errorf("%q: pages %v differ from full list %v", sort, paged, first)
+		}
+	}
+}
+
+func TestGetBikeWithComponentsGroupedByCategory(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	bike := api.addBike(owner, 1000)
+	api.addComponent(bike, domain.Handlebars, 0)
+	api.addComponent(bike, domain.Frame, 0)
+	wheel := api.addComponent(bike, domain.Wheels, 0)
+	wheel.Position = domain.PositionFront
+	api.store.AddComponent(wheel)
+	token := api.token(owner, domain.AppUser)
+
+	w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/with-components?group_by=category", token, nil)
+	expectStatus(t, w, http.StatusOK)
+	resp := decode[GetBikeWithGroupedComponentsResponse](t, w)
+	var categories []domain.ComponentCategory
+	for _, g := range resp.Groups {
+		categories = append(categories, g.Category)
+	}
+	if want := []domain.ComponentCategory{domain.WheelSet, domain.Cockpit, domain.FrameSet}; !slices.Equal(categories, want) {
+		t.Errorf("categories = %v, want %v", categories, want)
+	}
+
+	expectStatus(t, api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/with-components?group_by=brand", token, nil), http.StatusBadRequest)
+}
+
+func TestGetComponentStatusWarnThreshold(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       domain.ComponentStatusCounts
+	}{
+		// износ компонентов: 60%, 80%, 100%
+		{name: "порог по умолчанию", wantStatus: http.StatusOK, want: domain.ComponentStatusCounts{OK: 1, DueSoon: 1, Overdue: 1}},
+		{name: "порог 50", query: "?warn_threshold_percent=50", wantStatus: http.StatusOK, want: domain.ComponentStatusCounts{DueSoon: 2, Overdue: 1}},
+		{name: "порог 81", query: "?warn_threshold_percent=81", wantStatus: http.StatusOK, want: domain.ComponentStatusCounts{OK: 2, Overdue: 1}},
+		{name: "порог 100", query: "?warn_threshold_percent=100", wantStatus: http.StatusOK, want: domain.ComponentStatusCounts{OK: 2, Overdue: 1}},
+		{name: "порог 0", query: "?warn_threshold_percent=0", wantStatus: http.StatusBadRequest},
+		{name: "порог 101", query: "?warn_threshold_percent=101", wantStatus: http.StatusBadRequest},
+		{name: "порог не число", query: "?warn_threshold_percent=high", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 5000)
+			// порог 5000 км, установлены на 2000, 1000 и 0 км
+			api.addComponent(bike, domain.Handlebars, 2000)
+			api.addComponent(bike, domain.Frame, 1000)
+			api.addComponent(bike, domain.Wheels, 0)
+
+			w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/component-status"+tt.query, api.token(owner, domain.AppUser), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := decode[GetComponentStatusResponse](t, w).Counts; got != tt.want {
+				t.Errorf("counts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// неизвестный год везде отдаётся как null, а не 0
+func TestBikeYearNullRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want any
+	}{
+		{name: "без года", body: `{"model":"Trek","type":"mtb","mileage":10}`, want: nil},
+		{name: "с годом", body: `{"model":"Trek","type":"mtb","mileage":10,"year":2020}`, want: float64(2020)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			token := api.token(uuid.New(), domain.AppUser)
+
+			w := api.do(http.MethodPost, "/bikes", token, tt.body)
+			expectStatus(t, w, http.StatusCreated)
+			created := decode[map[string]any](t, w)
+			checkYear(t, "create", created, tt.want)
+			bikeID := created["bike_id"].(string)
+
+			for _, path := range []string{"/bikes/" + bikeID, "/bikes/" + bikeID + "/with-components"} {
+				w := api.do(http.MethodGet, path, token, nil)
+				expectStatus(t, w, http.StatusOK)
+				checkYear(t, path, decode[map[string]any](t, w), tt.want)
+			}
+
+			w = api.do(http.MethodGet, "/bikes/my", token, nil)
+			expectStatus(t, w, http.StatusOK)
+			list := decode[struct {
+				Bikes []map[string]any `json:"bikes"`
+			}](t, w)
+			if len(list.Bikes) != 1 {
+				t.Fatalf("got %d bikes, want 1", len(list.Bikes))
+			}
+			checkYear(t, "/bikes/my", list.Bikes[0], tt.want)
+		})
+	}
+}
+
+// checkYear - поле year есть в ответе и равно want (nil - null)
+func checkYear(t *testing.T, where string, bike map[string]any, want any) {
+	t.Helper()
+	year, ok := bike["year"]
+	if !ok {
+		t.Errorf("%s: year is missing", where)
+		return
+	}
+	if year != want {
+		t.Errorf("%s: year = %v, want %v", where, year, want)
+	}
+}
+
+func TestGetIncompleteBikes(t *testing.T) {
+	owner, stranger, admin := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		role       domain.UserRole
+		query      string
+		wantStatus int
+		// wantBikes - какие из байков setup попадут в ответ
+		wantBikes []string
+	}{
+		{name: "свои байки", requester: owner, role: domain.AppUser, wantStatus: http.StatusOK, wantBikes: []string{"empty", "replaced"}},
+		{name: "у пользователя нет пустых байков", requester: uuid.New(), role: domain.AppUser, wantStatus: http.StatusOK},
+		{name: "админ по всем пользователям", requester: admin, role: domain.Admin, query: "?all=true", wantStatus: http.StatusOK, wantBikes: []string{"empty", "replaced", "foreign"}},
+		{name: "пользователь не видит чужие", requester: owner, role: domain.AppUser, query: "?all=true", wantStatus: http.StatusForbidden},
+		{name: "пагинация", requester: owner, role: domain.AppUser, query: "?limit=1", wantStatus: http.StatusOK, wantBikes: []string{"replaced"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			names := map[uuid.UUID]string{}
+			addBike := func(userID uuid.UUID, name string, age time.Duration) *domain.Bike {
+				bike := api.addBike(userID, 100)
+				bike.CreatedAt = time.Now().Add(-age)
+				api.store.AddBike(bike)
+				names[bike.BikeID] = name
+				return bike
+			}
+			api.addComponent(addBike(owner, "complete", 4*time.Hour), domain.Frame, 0)
+			addBike(owner, "empty", 3*time.Hour)
+			// заменённые компоненты не считаются: байк без активных - неполный
+			replaced := api.addComponent(addBike(owner, "replaced", 2*time.Hour), domain.Frame, 0)
+			replaced.ReplacedAt = ptr(time.Now())
+			api.store.AddComponent(replaced)
+			addBike(stranger, "foreign", time.Hour)
+
+			w := api.do(http.MethodGet, "/bikes/my/incomplete"+tt.query, api.token(tt.requester, tt.role), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decode[GetIncompleteBikesResponse](t, w)
+			got := []string{}
+			for _, b := range resp.Bikes {
+				got = append(got, names[b.BikeID])
+				if b.ComponentCount != 0 {
+					t.Errorf("%s component_count = %d, want 0", names[b.BikeID], b.ComponentCount)
+				}
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.wantBikes)
+			slices.Sort(want)
+			if !slices.Equal(got, want) || resp.Count != len(want) {
+				t.Errorf("bikes = %v (count %d), want %v", got, resp.Count, want)
+			}
+		})
+	}
+}
+
+func TestCreateBikesBatchIdempotency(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	const batch = `{"bikes":[{"model":"Trek","type":"mtb","mileage":10},{"model":"Giant","type":"road","mileage":20}]}`
+
+	type call struct {
+		userID uuid.UUID
+		key    string
+		body   string
+		// wantStatus и wantSameBikes - ответ и совпадение ID с первым запросом
+		wantStatus    int
+		wantSameBikes bool
+	}
+	tests := []struct {
+		name  string
+		calls []call
+		// wantBikes - сколько байков у owner в итоге
+		wantBikes int
+	}{
+		{name: "повтор с тем же ключом", calls: []call{
+			{userID: owner, key: "sync-1", body: batch, wantStatus: http.StatusCreated},
+			{userID: owner, key: "sync-1", body: batch, wantStatus: http.StatusOK, wantSameBikes: true},
+			{userID: owner, key: "sync-1", body: batch, wantStatus: http.StatusOK, wantSameBikes: true},
+		}, wantBikes: 2},
+		{name: "тот же ключ для другого батча", calls: []call{
+			{userID: owner, key: "sync-1", body: batch, wantStatus: http.StatusCreated},
+			{userID: owner, key: "sync-1", body: `{"bikes":[{"model":"Other","type":"bmx","mileage":1}]}`, wantStatus: http.StatusUnprocessableEntity},
+		}, wantBikes: 2},
+		{name: "ключ другого пользователя не мешает", calls: []call{
+			{userID: other, key: "sync-1", body: batch, wantStatus: http.StatusCreated},
+			{userID: owner, key: "sync-1", body: batch, wantStatus: http.StatusCreated},
+		}, wantBikes: 2},
+		{name: "без ключа создаются дубли", calls: []call{
+			{userID: owner, body: batch, wantStatus: http.StatusCreated},
+			{userID: owner, body: batch, wantStatus: http.StatusCreated},
+		}, wantBikes: 4},
+		{name: "отклонённый батч не занимает ключ", calls: []call{
+			{userID: owner, key: "sync-1", body: `{"bikes":[{"model":"Trek","type":"mtb","mileage":10},{"model":"Giant","type":"road","mileage":20,"year":1700}]}`, wantStatus: http.StatusUnprocessableEntity},
+			{userID: owner, key: "sync-1", body: batch, wantStatus: http.StatusCreated},
+		}, wantBikes: 2},
+		{name: "байк для другого пользователя", calls: []call{
+			{userID: owner, key: "sync-1", body: `{"bikes":[{"model":"Trek","type":"mtb","mileage":10,"user_id":"` + other.String() + `"}]}`, wantStatus: http.StatusForbidden},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			var first []uuid.UUID
+			for i, call := range tt.calls {
+				var headers []string
+				if call.key != "" {
+					headers = []string{"Idempotency-Key", call.key}
+				}
+				w := api.do(http.MethodPost, "/bikes/batch", api.token(call.userID, domain.AppUser), call.body, headers...)
+				expectStatus(t, w, call.wantStatus)
+				if call.wantStatus != http.StatusCreated && call.wantStatus != http.StatusOK {
+					continue
+				}
+
+				resp := decode[CreateBikesBatchResponse](t, w)
+				if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != call.wantSameBikes || resp.Replayed != call.wantSameBikes {
+					t.Errorf("call %d replayed = %t (header %t), want %t", i, resp.Replayed, replayed, call.wantSameBikes)
+				}
+				var ids []uuid.UUID
+				for _, r := range resp.Results {
+					if r.Bike != nil {
+						ids = append(ids, r.Bike.BikeID)
+					}
+				}
+				if call.wantSameBikes && !slices.Equal(ids, first) {
+					t.Errorf("call %d bikes = %v, want %v", i, ids, first)
+				}
+				if first == nil && call.userID == owner {
+					first = ids
+				}
+			}
+
+			bikes, err := api.store.GetBikesByUserID(context.Background(), owner, domain.BikeFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bikes) != tt.wantBikes {
+				t.Errorf("owner has %d bikes, want %d", len(bikes), tt.wantBikes)
+			}
+		})
+	}
+}
+
+func TestGetMyBikesCreatedRange(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		// want - пробеги байков в порядке ответа
+		want []int
+	}{
+		{name: "без границ", wantStatus: http.StatusOK, want: []int{3, 2, 1}},
+		{name: "диапазон", query: "?created_after=2025-02-01&created_before=2025-12-31", wantStatus: http.StatusOK, want: []int{3, 2}},
+		{name: "диапазон с сортировкой и пагинацией", query: "?created_after=2025-02-01&sort=mileage_asc&limit=1", wantStatus: http.StatusOK, want: []int{2}},
+		{name: "пустой диапазон", query: "?created_after=2026-01-01", wantStatus: http.StatusOK, want: []int{}},
+		{name: "after позже before", query: "?created_after=2025-07-01&created_before=2025-06-01", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			for i, createdAt := range []time.Time{
+				time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 12, 31, 18, 0, 0, 0, time.UTC),
+			} {
+				bike := api.addBike(owner, i+1)
+				bike.CreatedAt = createdAt
+				api.store.AddBike(bike)
+			}
+
+			w := api.do(http.MethodGet, "/bikes/my"+tt.query, api.token(owner, domain.AppUser), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			got := []int{}
+			for _, b := range decode[GetMyBikesResponse](t, w).Bikes {
+				got = append(got, b.Mileage)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("mileages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBikeForecast(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		query      string
+		wantStatus int
+		wantDays   int
+	}{
+		{name: "целый пробег в день", requester: owner, query: "?daily_km=50", wantStatus: http.StatusOK, wantDays: 80},
+		{name: "дробный пробег в день", requester: owner, query: "?daily_km=12.5", wantStatus: http.StatusOK, wantDays: 320},
+		{name: "без daily_km", requester: owner, wantStatus: http.StatusBadRequest},
+		{name: "ноль", requester: owner, query: "?daily_km=0", wantStatus: http.StatusBadRequest},
+		{name: "отрицательный", requester: owner, query: "?daily_km=-5", wantStatus: http.StatusBadRequest},
+		{name: "не число", requester: owner, query: "?daily_km=fast", wantStatus: http.StatusBadRequest},
+		{name: "NaN", requester: owner, query: "?daily_km=NaN", wantStatus: http.StatusBadRequest},
+		{name: "больше 1000", requester: owner, query: "?daily_km=1001", wantStatus: http.StatusBadRequest},
+		{name: "чужой байк", requester: uuid.New(), query: "?daily_km=50", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			// проехал 1000 из 5000
+			bike := api.addBike(owner, 1000)
+			api.addComponent(bike, domain.Frame, 0)
+
+			w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/forecast"+tt.query, api.token(tt.requester, domain.AppUser), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			resp := decode[GetBikeForecastResponse](t, w)
+			if len(resp.Components) != 1 || resp.Components[0].DaysLeft != tt.wantDays || resp.Components[0].RemainingKm != 4000 {
+				t.Errorf("forecast = %+v, want 4000 km in %d days", resp.Components, tt.wantDays)
+			}
+		})
+	}
+}
+
+// клиент ушёл: ошибка репозитория не превращается в 404/500
+func TestCanceledRequestStatus(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	bike := api.addBike(owner, 100)
+	api.store.SetError("GetBikeByID", fmt.Errorf("%w: %w", domain.ErrRequestCanceled, context.Canceled))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/bikes/"+bike.BikeID.String(), nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+api.token(owner, domain.AppUser))
+	w := httptest.NewRecorder()
+	api.router.Engine().ServeHTTP(w, req)
+
+	expectStatus(t, w, statusClientClosedRequest)
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+}
+
+func TestGetComponentStatusBuckets(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		role       domain.UserRole
+		unknown    bool
+		wantStatus int
+	}{
+		{name: "владелец", requester: owner, role: domain.AppUser, wantStatus: http.StatusOK},
+		{name: "админ", requester: uuid.New(), role: domain.Admin, wantStatus: http.StatusOK},
+		{name: "чужой байк", requester: uuid.New(), role: domain.AppUser, wantStatus: http.StatusForbidden},
+		{name: "неизвестный байк", requester: owner, role: domain.AppUser, unknown: true, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 5000)
+			// износ 100%: остальные полосы пустые
+			overdue := api.addComponent(bike, domain.Frame, 0)
+			bikeID := bike.BikeID.String()
+			if tt.unknown {
+				bikeID = uuid.NewString()
+			}
+
+			w := api.do(http.MethodGet, "/bikes/"+bikeID+"/component-status", api.token(tt.requester, tt.role), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			raw := decode[map[string]any](t, w)
+			for _, bucket := range []string{"ok", "due_soon"} {
+				if items, ok := raw[bucket].([]any); !ok || len(items) != 0 {
+					t.Errorf("%s = %v, want []", bucket, raw[bucket])
+				}
+			}
+			resp := decode[GetComponentStatusResponse](t, w)
+			if len(resp.Overdue) != 1 || resp.Overdue[0].Component.ID != overdue.ID || resp.Overdue[0].WearPercent != 100 {
+				t.Errorf("overdue = %+v, want %s at 100%%", resp.Overdue, overdue.ID)
+			}
+			if resp.WarnPercent != 80 || resp.Mileage != 5000 {
+				t.Errorf("warn_threshold_percent, mileage = %d, %d", resp.WarnPercent, resp.Mileage)
+			}
+		})
+	}
+}
+
+func TestBikeYearBoundsStatus(t *testing.T) {
+	owner := uuid.New()
+	maxYear := domain.MaxBikeYear(time.Now())
+	wantMsg := fmt.Sprintf("year must be between %d and %d", domain.MinBikeYear, maxYear)
+
+	tests := []struct {
+		name       string
+		year       int
+		wantStatus int
+	}{
+		{name: "следующий год", year: maxYear, wantStatus: http.StatusOK},
+		{name: "будущий год", year: maxYear + 1, wantStatus: http.StatusUnprocessableEntity},
+		{name: "нулевой год", year: 0, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			token := api.token(owner, domain.AppUser)
+			year := strconv.Itoa(tt.year)
+
+			createStatus := tt.wantStatus
+			if createStatus == http.StatusOK {
+				createStatus = http.StatusCreated
+			}
+			w := api.do(http.MethodPost, "/bikes", token, `{"model":"Trek","type":"mtb","mileage":10,"year":`+year+`}`)
+			expectStatus(t, w, createStatus)
+			checkYearError(t, "create", w, tt.wantStatus, wantMsg)
+
+			bike := api.addBike(owner, 100)
+			w = api.do(http.MethodPut, "/bikes/"+bike.BikeID.String(), token, `{"year":`+year+`}`)
+			expectStatus(t, w, tt.wantStatus)
+			checkYearError(t, "update", w, tt.wantStatus, wantMsg)
+		})
+	}
+}
+
+// checkYearError - отклонённый год объясняется допустимым диапазоном
+func checkYearError(t *testing.T, where string, w *httptest.ResponseRecorder, status int, wantMsg string) {
+	t.Helper()
+	if status != http.StatusUnprocessableEntity {
+		return
+	}
+	if got := decode[errorResponse](t, w).Message; !strings.Contains(got, wantMsg) {
+		t.Errorf("%s: message = %q, want %q", where, got, wantMsg)
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// preferredLanguage выбирает язык названий по Accept-Language: берётся
+// поддерживаемый язык с наибольшим q (сравнивается базовый тег, "ru-RU" -> "ru").
+// Выбранный язык отдаётся в Content-Language
+func preferredLanguage(c *gin.Context) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !domain.IsSupportedLanguage(lang) {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+
+	lang := domain.DefaultLanguage
+	if len(candidates) > 0 {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].q > candidates[j].q
+		})
+		lang = candidates[0].lang
+	}
+	c.Header("Content-Language", lang)
+	return lang
+}
+
+// localizeComponents заполняет display_name компонентов на языке lang
+func localizeComponents(lang string, components ...*domain.Component) {
+	for _, component := range components {
+		component.DisplayName = component.Name.DisplayName(lang)
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+
+	// endpoint - POST одного ресурса: тело по варианту, ID из ответа
+	// и число созданных ресурсов пользователя
+	type endpoint struct {
+		path  string
+		body  func(variant string, bike *domain.Bike) string
+		id    func(t *testing.T, w *httptest.ResponseRecorder) uuid.UUID
+		count func(api *testAPI, userID uuid.UUID, bike *domain.Bike) int
+	}
+	endpoints := map[string]endpoint{
+		"байк": {
+			path: "/bikes",
+			body: func(variant string, _ *domain.Bike) string {
+				switch variant {
+				case "other":
+					return `{"model":"Giant","type":"road","mileage":20}`
+				case "invalid":
+					return `{"model":"Trek","type":"mtb","mileage":10,"year":1700}`
+				}
+				return `{"model":"Trek","type":"mtb","mileage":10}`
+			},
+			id: func(t *testing.T, w *httptest.ResponseRecorder) uuid.UUID {
+				return decode[CreateBikeResponse](t, w).BikeID
+			},
+			count: func(api *testAPI, userID uuid.UUID, _ *domain.Bike) int {
+				bikes, err := api.store.GetBikesByUserID(context.Background(), userID, domain.BikeFilter{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return len(bikes)
+			},
+		},
+		"компонент": {
+			path: "/components",
+			body: func(variant string, bike *domain.Bike) string {
+				switch variant {
+				case "other":
+					return `{"bike_id":"` + bike.BikeID.String() + `","name":"frame","installed_mileage":1,"max_mileage":5000}`
+				case "invalid":
+					// пробег установки больше пробега байка
+					return `{"bike_id":"` + bike.BikeID.String() + `","name":"handlebars","installed_mileage":500,"max_mileage":5000}`
+				}
+				return `{"bike_id":"` + bike.BikeID.String() + `","name":"handlebars","installed_mileage":1,"max_mileage":5000}`
+			},
+			id: func(t *testing.T, w *httptest.ResponseRecorder) uuid.UUID {
+				return decode[struct {
+					Data domain.Component `json:"data"`
+				}](t, w).Data.ID
+			},
+			count: func(api *testAPI, _ uuid.UUID, bike *domain.Bike) int {
+				return len(api.store.Components(bike.BikeID))
+			},
+		},
+	}
+
+	type call struct {
+		userID  uuid.UUID
+		key     string
+		variant string
+		// after - сдвиг часов хранилища относительно первого запроса
+		after time.Duration
+		// wantStatus и wantSame - ответ и совпадение ID с первым запросом owner
+		wantStatus int
+		wantSame   bool
+	}
+	tests := []struct {
+		name  string
+		calls []call
+		// wantCreated - сколько ресурсов owner создано в итоге
+		wantCreated int
+	}{
+		{name: "повтор с тем же ключом", calls: []call{
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated},
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated, wantSame: true},
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated, wantSame: true},
+		}, wantCreated: 1},
+		{name: "тот же ключ для другого запроса", calls: []call{
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated},
+			{userID: owner, key: "create-1", variant: "other", wantStatus: http.StatusUnprocessableEntity},
+		}, wantCreated: 1},
+		{name: "ключ другого пользователя не мешает", calls: []call{
+			{userID: other, key: "create-1", wantStatus: http.StatusCreated},
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated},
+		}, wantCreated: 1},
+		{name: "без ключа создаются дубли", calls: []call{
+			{userID: owner, wantStatus: http.StatusCreated},
+			{userID: owner, wantStatus: http.StatusCreated},
+		}, wantCreated: 2},
+		{name: "отклонённый запрос не занимает ключ", calls: []call{
+			{userID: owner, key: "create-1", variant: "invalid", wantStatus: http.StatusUnprocessableEntity},
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated},
+		}, wantCreated: 1},
+		{name: "ключ старше суток занимается заново", calls: []call{
+			{userID: owner, key: "create-1", wantStatus: http.StatusCreated},
+			{userID: owner, key: "create-1", after: 25 * time.Hour, wantStatus: http.StatusCreated},
+		}, wantCreated: 2},
+		{name: "слишком длинный ключ", calls: []call{
+			{userID: owner, key: strings.Repeat("k", maxIdempotencyKeyLength+1), wantStatus: http.StatusBadRequest},
+		}},
+	}
+	for endpointName, ep := range endpoints {
+		for _, tt := range tests {
+			t.Run(endpointName+"/"+tt.name, func(t *testing.T) {
+				api := newTestAPI(t)
+				bikes := map[uuid.UUID]*domain.Bike{owner: api.addBike(owner, 100), other: api.addBike(other, 100)}
+				before := ep.count(api, owner, bikes[owner])
+
+				var first uuid.UUID
+				for i, call := range tt.calls {
+					api.store.Now = func() time.Time { return time.Now().Add(call.after) }
+					var headers []string
+					if call.key != "" {
+						headers = []string{"Idempotency-Key", call.key}
+					}
+					w := api.do(http.MethodPost, ep.path, api.token(call.userID, domain.AppUser), ep.body(call.variant, bikes[call.userID]), headers...)
+					expectStatus(t, w, call.wantStatus)
+					if call.wantStatus != http.StatusCreated {
+						continue
+					}
+
+					if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != call.wantSame {
+						t.Errorf("call %d Idempotent-Replayed = %t, want %t", i, replayed, call.wantSame)
+					}
+					id := ep.id(t, w)
+					if call.wantSame && id != first {
+						t.Errorf("call %d id = %s, want %s", i, id, first)
+					}
+					if first == uuid.Nil && call.userID == owner {
+						first = id
+					}
+				}
+
+				if created := ep.count(api, owner, bikes[owner]) - before; created != tt.wantCreated {
+					t.Errorf("owner created %d, want %d", created, tt.wantCreated)
+				}
+			})
+		}
+	}
+}
+
+// повтор после удаления байка не создаёт его заново
+func TestCreateBikeReplayAfterDelete(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	token := api.token(owner, domain.AppUser)
+	const body = `{"model":"Trek","type":"mtb","mileage":10}`
+
+	w := api.do(http.MethodPost, "/bikes", token, body, "Idempotency-Key", "create-1")
+	expectStatus(t, w, http.StatusCreated)
+	bikeID := decode[CreateBikeResponse](t, w).BikeID
+	expectStatus(t, api.do(http.MethodDelete, "/bikes/"+bikeID.String(), token, nil), http.StatusOK)
+
+	expectStatus(t, api.do(http.MethodPost, "/bikes", token, body, "Idempotency-Key", "create-1"), http.StatusNotFound)
+	if bikes, _ := api.store.GetBikesByUserID(context.Background(), owner, domain.BikeFilter{}); len(bikes) != 0 {
+		t.Errorf("owner has %d bikes, want 0", len(bikes))
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// сколько клиенту ждать перед повтором записи
+	maintenanceRetryAfter = 2 * time.Minute
+	// флаг общий для всех реплик и лежит в redis, каждая перечитывает его не
+	// чаще этого интервала
+	maintenanceRefresh  = 5 * time.Second
+	maintenanceStoreKey = "maintenance:enabled"
+)
+
+// Maintenance - режим обслуживания: чтение работает, запись отвечает 503
+type Maintenance struct {
+	store   ports.CachePort
+	logger  ports.LoggerPort
+	metrics ports.MetricsPort
+	refresh time.Duration
+
+	mu        sync.Mutex
+	enabled   bool
+	checkedAt time.Time
+}
+
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required" example:"true"`
+}
+
+type MaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// NewMaintenance - enabled действует, пока флаг в store ни разу не переключали
+func NewMaintenance(enabled bool, store ports.CachePort, logger ports.LoggerPort, metrics ports.MetricsPort) *Maintenance {
+	return &Maintenance{
+		store:   store,
+		logger:  logger,
+		metrics: metrics,
+		refresh: maintenanceRefresh,
+		enabled: enabled,
+	}
+}
+
+// isEnabled отдаёт флаг из store с локальным кешем на refresh. Если store
+// недоступен, остаётся последнее известное значение
+func (m *Maintenance) isEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Since(m.checkedAt) < m.refresh {
+		return m.enabled
+	}
+	m.checkedAt = time.Now()
+
+	value, err := m.store.Get(maintenanceStoreKey)
+	switch {
+	case err == nil:
+		m.enabled = string(value) == "1"
+	case !errors.Is(err, ports.ErrCacheMiss):
+		m.logger.Warn("Failed to read maintenance flag", map[string]interface{}{
+			"error":   err.Error(),
+			"enabled": m.enabled,
+		})
+	}
+	return m.enabled
+}
+
+// Middleware пропускает GET/HEAD/OPTIONS, а остальные методы блокирует, пока режим включён.
+// Переключатель самого режима не блокируется, иначе его не выключить
+func (m *Maintenance) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "/admin/maintenance" {
+			c.Next()
+			return
+		}
+
+		// чтение не ходит за флагом вовсе
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if !m.isEnabled() {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(maintenanceRetryAfter.Seconds())))
+		newErrorResponse(c, http.StatusServiceUnavailable, "Service is in maintenance mode, writes are temporarily disabled")
+		c.Abort()
+	}
+}
+
+// @Summary Статус режима обслуживания
+// @Description Включён ли режим, в котором запись отвечает 503. Флаг общий для всех реплик сервиса
+// @Tags admin
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Produce json
+// @Success 200 {object} MaintenanceResponse "Текущий статус"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Router /admin/maintenance [get]
+func (m *Maintenance) GetMaintenance(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		m.metrics.RecordMetrics(c, start)
+	}()
+
+	c.JSON(http.StatusOK, MaintenanceResponse{Enabled: m.isEnabled()})
+}
+
+// @Summary Переключить режим обслуживания
+// @Description Для админов и сервисных API-ключей. Включённый режим отвечает 503 на POST/PUT/PATCH/DELETE, чтение продолжает работать. Флаг хранится в redis и действует на все реплики, остальные подхватывают его в течение 5 секунд
+// @Tags admin
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body MaintenanceRequest true "Новый статус"
+// @Success 200 {object} MaintenanceResponse "Статус изменён"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Не удалось сохранить флаг"
+// @Router /admin/maintenance [put]
+func (m *Maintenance) SetMaintenance(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		m.metrics.RecordMetrics(c, start)
+	}()
+
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	value := []byte("0")
+	if *req.Enabled {
+		value = []byte("1")
+	}
+	if err := m.store.Set(maintenanceStoreKey, value, 0); err != nil {
+		m.logger.Error("Failed to store maintenance flag", map[string]interface{}{
+			"error": err.Error(),
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to change maintenance mode")
+		return
+	}
+	m.mu.Lock()
+	m.enabled = *req.Enabled
+	m.checkedAt = time.Now()
+	m.mu.Unlock()
+
+	fields := map[string]interface{}{
+		"enabled": *req.Enabled,
+	}
+	if payload, ok := getAuthPayload(c, authorizationPayloadKey); ok {
+		fields["requester_id"] = payload.Actor()
+	}
+	m.logger.Warn("Maintenance mode changed", fields)
+
+	c.JSON(http.StatusOK, MaintenanceResponse{Enabled: *req.Enabled})
+}
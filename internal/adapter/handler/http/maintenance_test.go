@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	owner, admin := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		method     string
+		path       string
+		body       string
+		admin      bool
+		wantStatus int
+	}{
+		{name: "чтение в режиме обслуживания", enabled: true, method: http.MethodGet, path: "/bikes/my", wantStatus: http.StatusOK},
+		{name: "создание в режиме обслуживания", enabled: true, method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":"mtb","mileage":1}`, wantStatus: http.StatusServiceUnavailable},
+		{name: "удаление в режиме обслуживания", enabled: true, method: http.MethodDelete, path: "/bikes/{bike}", wantStatus: http.StatusServiceUnavailable},
+		{name: "создание без режима", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":"mtb","mileage":1}`, wantStatus: http.StatusCreated},
+		{name: "выключение режима не блокируется", enabled: true, method: http.MethodPut, path: "/admin/maintenance", body: `{"enabled":false}`, admin: true, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			api.maintenance.enabled = tt.enabled
+			bike := api.addBike(owner, 100)
+			token := api.token(owner, domain.AppUser)
+			if tt.admin {
+				token = api.token(admin, domain.Admin)
+			}
+			path := tt.path
+			if path == "/bikes/{bike}" {
+				path = "/bikes/" + bike.BikeID.String()
+			}
+			var body any
+			if tt.body != "" {
+				body = tt.body
+			}
+
+			w := api.do(tt.method, path, token, body)
+			expectStatus(t, w, tt.wantStatus)
+			if retry := w.Header().Get("Retry-After"); (tt.wantStatus == http.StatusServiceUnavailable) != (retry != "") {
+				t.Errorf("Retry-After = %q", retry)
+			}
+			if stored, _ := api.store.Bike(bike.BikeID); tt.enabled && tt.method == http.MethodDelete && stored.DeletedAt != nil {
+				t.Error("bike was deleted in maintenance mode")
+			}
+		})
+	}
+}
+
+func TestMaintenanceToggle(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.token(uuid.New(), domain.Admin)
+
+	w := api.do(http.MethodPut, "/admin/maintenance", token, MaintenanceRequest{Enabled: ptr(true)})
+	expectStatus(t, w, http.StatusOK)
+	if got := decode[MaintenanceResponse](t, api.do(http.MethodGet, "/admin/maintenance", token, nil)); !got.Enabled {
+		t.Fatal("maintenance is not enabled after PUT")
+	}
+	expectStatus(t, api.do(http.MethodPost, "/bikes", api.token(uuid.New(), domain.AppUser), `{"model":"Trek","type":"mtb","mileage":1}`), http.StatusServiceUnavailable)
+
+	// обычному пользователю переключатель недоступен
+	expectStatus(t, api.do(http.MethodPut, "/admin/maintenance", api.token(uuid.New(), domain.AppUser), `{"enabled":false}`), http.StatusForbidden)
+}
+
+// флаг общий для реплик: другая реплика видит переключение после refresh,
+// а при сбое redis держит последнее известное значение
+func TestMaintenanceSharedAcrossInstances(t *testing.T) {
+	api := newTestAPI(t)
+	replica := NewMaintenance(false, api.cache, api.logger, api.metrics)
+	token := api.token(uuid.New(), domain.Admin)
+
+	expectStatus(t, api.do(http.MethodPut, "/admin/maintenance", token, MaintenanceRequest{Enabled: ptr(true)}), http.StatusOK)
+	if !replica.isEnabled() {
+		t.Fatal("replica does not see maintenance enabled on another instance")
+	}
+
+	expectStatus(t, api.do(http.MethodPut, "/admin/maintenance", token, MaintenanceRequest{Enabled: ptr(false)}), http.StatusOK)
+	if !replica.isEnabled() {
+		t.Error("replica re-read the flag before refresh")
+	}
+
+	replica.refresh = 0
+	api.cache.Err = errors.New("redis down")
+	if !replica.isEnabled() {
+		t.Error("replica dropped the last known flag on store error")
+	}
+	api.cache.Err = nil
+	if replica.isEnabled() {
+		t.Error("replica does not see maintenance disabled after refresh")
+	}
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+)
+
+// профиль меняется редко, а /me дергают на каждом открытии приложения
+const userProfileCacheTTL = time.Minute
+
+// причины, по которым вместо профиля отдаётся user: null (метка reason
+// в user_enrichment_failures_total)
+const (
+	enrichmentTimeout  = "timeout"
+	enrichmentServer   = "5xx"
+	enrichmentNotFound = "not_found"
+	enrichmentInvalid  = "invalid"
+	enrichmentOther    = "other"
+	// запрос не отправлялся: цепь разомкнута после серии сбоев
+	enrichmentCircuitOpen = "circuit_open"
+)
+
+// enrichmentFailureReason раскладывает ошибку клиента user-service по причинам.
+// И сгенерированные ответы, и runtime.APIError умеют IsCode/IsServerError
+func enrichmentFailureReason(err error) string {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return enrichmentTimeout
+	}
+
+	var statusErr interface {
+		IsCode(code int) bool
+		IsServerError() bool
+	}
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.IsCode(http.StatusNotFound):
+			return enrichmentNotFound
+		case statusErr.IsServerError():
+			return enrichmentServer
+		}
+	}
+	return enrichmentOther
+}
+
+type GetMeResponse struct {
+	UserID     uuid.UUID          `json:"user_id"`
+	Role       domain.UserRole    `json:"role"`
+	User       *UserResponseInfo  `json:"user"`
+	UserSource string             `json:"user_source" enums:"user_service,unavailable,invalid"`
+	Bikes      domain.BikeSummary `json:"bikes"`
+}
+
+// @Summary Мой профиль
+// @Description Данные из токена, профиль из user-service (null, если сервис недоступен) и сводка по байкам
+// @Tags me
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} GetMeResponse "Профиль"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступно только пользователям"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /me [get]
+func (h *BikeHandler) GetMe(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetMe", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	summary, err := h.bikeService.GetUserBikeSummary(c.Request.Context(), payload.UserID.String())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike summary", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID.String(),
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get bikes")
+		return
+	}
+
+	userInfo, userSource := h.lookupUser(c, payload.UserID)
+
+	c.JSON(http.StatusOK, GetMeResponse{
+		UserID:     payload.UserID,
+		Role:       payload.Role,
+		User:       userInfo,
+		UserSource: userSource,
+		Bikes:      summary,
+	})
+}
+
+// lookupUser достает пользователя из user-service. Успешный ответ кешируется
+// ненадолго, ошибки нет - при сбое вернется nil и причина в userSource
+func (h *BikeHandler) lookupUser(c *gin.Context, userID uuid.UUID) (*UserResponseInfo, string) {
+	cacheKey := services.UserProfileCacheKey(userID)
+	if cached, err := h.cache.Get(cacheKey); err == nil {
+		var userInfo UserResponseInfo
+		if err := json.Unmarshal(cached, &userInfo); err == nil {
+			h.metrics.IncUserEnrichmentSuccess("cache")
+			return &userInfo, userSourceService
+		}
+	}
+
+	resp, err := h.fetchUser(c, userID)
+	if err != nil {
+		reason := enrichmentFailureReason(err)
+		if isBreakerOpen(err) {
+			// сервис и так лежит, лог на каждый запрос ничего не добавит
+			h.metrics.IncUserEnrichmentFailure(enrichmentCircuitOpen)
+			return nil, userSourceUnavailable
+		}
+		h.metrics.IncUserEnrichmentFailure(reason)
+		h.logger.WithContext(c.Request.Context()).Warn("Failed to get user from user-service", map[string]interface{}{
+			"error":   err.Error(),
+			"reason":  reason,
+			"user_id": userID.String(),
+		})
+		return nil, userSourceUnavailable
+	}
+	if resp == nil || resp.Payload == nil {
+		h.metrics.IncUserEnrichmentFailure(enrichmentInvalid)
+		return nil, userSourceUnavailable
+	}
+
+	// пустой или чужой пользователь хуже, чем его отсутствие
+	if resp.Payload.ID != userID.String() || (resp.Payload.Name == "" && resp.Payload.Email == "") {
+		h.logger.WithContext(c.Request.Context()).Warn("User-service returned incomplete user", map[string]interface{}{
+			"user_id":     userID.String(),
+			"returned_id": resp.Payload.ID,
+		})
+		h.metrics.IncUserEnrichmentFailure(enrichmentInvalid)
+		return nil, userSourceInvalid
+	}
+
+	// Маппинг из user_models.HTTPGetUserResponse в UserResponseInfo
+	userInfo := &UserResponseInfo{
+		ID:          resp.Payload.ID,
+		Name:        resp.Payload.Name,
+		Email:       resp.Payload.Email,
+		DateOfBirth: resp.Payload.DateOfBirth,
+		Role:        resp.Payload.Role,
+		CreatedAt:   resp.Payload.CreatedAt,
+		UpdatedAt:   resp.Payload.UpdatedAt,
+	}
+
+	if data, err := json.Marshal(userInfo); err == nil {
+		if err := h.cache.Set(cacheKey, data, userProfileCacheTTL); err != nil {
+			h.logger.WithContext(c.Request.Context()).Warn("Failed to cache user profile", map[string]interface{}{
+				"error":   err.Error(),
+				"user_id": userID.String(),
+			})
+		}
+	}
+
+	h.metrics.IncUserEnrichmentSuccess("service")
+	return userInfo, userSourceService
+}
+
+// userServiceAuth пробрасывает в user-service токен вызывающего и
+// тот же X-Request-ID, чтобы запрос прослеживался через оба сервиса
+func userServiceAuth(c *gin.Context) runtime.ClientAuthInfoWriter {
+	writers := []runtime.ClientAuthInfoWriter{requestIDWriter(c.Request.Context())}
+	authHeader := c.GetHeader("Authorization")
+	if authHeader != "" {
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		writers = append(writers, httptransport.BearerToken(token))
+	}
+	return httptransport.Compose(writers...)
+}
+
+// requestIDWriter добавляет в исходящий запрос X-Request-ID текущего запроса
+func requestIDWriter(ctx context.Context) runtime.ClientAuthInfoWriter {
+	return runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		if id := domain.RequestIDFromContext(ctx); id != "" {
+			return r.SetHeaderParam(domain.RequestIDHeader, id)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/google/uuid"
+)
+
+func TestGetBikeWithUserPartialPayload(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name string
+		// user - ответ user-service, nil - 404
+		user        *UserResponseInfo
+		wantSource  string
+		wantUser    bool
+		wantFailure string
+	}{
+		{name: "полный профиль", user: &UserResponseInfo{ID: owner.String(), Name: "Rider", Email: "rider@example.com"}, wantSource: userSourceService, wantUser: true},
+		{name: "только email", user: &UserResponseInfo{ID: owner.String(), Email: "rider@example.com"}, wantSource: userSourceService, wantUser: true},
+		{name: "пустой профиль", user: &UserResponseInfo{ID: owner.String()}, wantSource: userSourceInvalid, wantFailure: enrichmentInvalid},
+		{name: "чужой профиль", user: &UserResponseInfo{ID: uuid.NewString(), Name: "Other", Email: "other@example.com"}, wantSource: userSourceInvalid, wantFailure: enrichmentInvalid},
+		{name: "пользователь не найден", wantSource: userSourceUnavailable, wantFailure: enrichmentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+			if tt.user != nil {
+				api.users[owner] = *tt.user
+			}
+
+			w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/with-user", api.token(owner, domain.AppUser), nil)
+			expectStatus(t, w, http.StatusOK)
+
+			resp := decode[GetBikeWithUserResponse](t, w)
+			if resp.UserSource != tt.wantSource {
+				t.Errorf("user_source = %q, want %q", resp.UserSource, tt.wantSource)
+			}
+			if (resp.User != nil) != tt.wantUser {
+				t.Errorf("user = %+v, want present: %t", resp.User, tt.wantUser)
+			}
+			if tt.wantFailure != "" && api.metrics.EnrichmentFailures(tt.wantFailure) != 1 {
+				t.Errorf("enrichment failures %q = %d, want 1", tt.wantFailure, api.metrics.EnrichmentFailures(tt.wantFailure))
+			}
+			// неполный профиль не должен закрепиться в кеше
+			if cached := api.cache.Has(services.UserProfileCacheKey(owner)); cached != tt.wantUser {
+				t.Errorf("profile cached = %t, want %t", cached, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestGetMe(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		knownUser  bool
+		bikes      int
+		wantSource string
+	}{
+		{name: "профиль и байки", knownUser: true, bikes: 2, wantSource: userSourceService},
+		{name: "без байков", knownUser: true, wantSource: userSourceService},
+		{name: "user-service не знает пользователя", bikes: 1, wantSource: userSourceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			if tt.knownUser {
+				api.addUser(owner, "rider")
+			}
+			for range tt.bikes {
+				api.addBike(owner, 100)
+			}
+			api.addBike(uuid.New(), 500)
+
+			w := api.do(http.MethodGet, "/me", api.token(owner, domain.AppUser), nil)
+			expectStatus(t, w, http.StatusOK)
+
+			resp := decode[GetMeResponse](t, w)
+			if resp.UserID != owner || resp.Role != domain.AppUser {
+				t.Errorf("user_id, role = %s, %s, want %s, %s", resp.UserID, resp.Role, owner, domain.AppUser)
+			}
+			if resp.UserSource != tt.wantSource || (resp.User != nil) != tt.knownUser {
+				t.Errorf("user = %+v (%s), want present: %t (%s)", resp.User, resp.UserSource, tt.knownUser, tt.wantSource)
+			}
+			if resp.Bikes.Total != tt.bikes || resp.Bikes.TotalMileage != 100*tt.bikes || resp.Bikes.ByType[domain.MTB] != tt.bikes {
+				t.Errorf("bikes = %+v, want %d bikes", resp.Bikes, tt.bikes)
+			}
+		})
+	}
+}
+
+func TestGetMeCachesUser(t *testing.T) {
+	api := newTestAPI(t)
+	owner := uuid.New()
+	api.addUser(owner, "rider")
+	token := api.token(owner, domain.AppUser)
+
+	expectStatus(t, api.do(http.MethodGet, "/me", token, nil), http.StatusOK)
+	if ttl := api.cache.TTL(services.UserProfileCacheKey(owner)); ttl != userProfileCacheTTL {
+		t.Errorf("profile cache TTL = %s, want %s", ttl, userProfileCacheTTL)
+	}
+
+	// пока профиль в кеше, user-service не нужен
+	delete(api.users, owner)
+	if resp := decode[GetMeResponse](t, api.do(http.MethodGet, "/me", token, nil)); resp.User == nil || resp.User.Name != "rider" {
+		t.Errorf("user = %+v, want cached profile", resp.User)
+	}
+}
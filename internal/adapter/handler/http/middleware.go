@@ -1,22 +1,54 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationType       = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	apiKeyHeaderKey         = "X-API-Key"
 )
 
-func AuthMiddleware(token ports.TokenService) gin.HandlerFunc {
+// AuthMiddleware пускает по X-API-Key для внутренних сервисов, иначе по JWT
+func AuthMiddleware(token ports.TokenService, apiKeys ports.APIKeyVerifier, logger ports.LoggerPort) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(apiKeyHeaderKey); apiKey != "" {
+			payload, err := apiKeys.VerifyAPIKey(apiKey)
+			if err != nil {
+				logger.Warn("Rejected API key", map[string]interface{}{
+					"ip":     c.ClientIP(),
+					"method": c.Request.Method,
+					"path":   c.FullPath(),
+				})
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "invalid api key",
+				})
+				c.Abort()
+				return
+			}
+
+			logger.Info("API key request", map[string]interface{}{
+				"audit":   "api_key",
+				"service": payload.Service,
+				"ip":      c.ClientIP(),
+				"method":  c.Request.Method,
+				"path":    c.FullPath(),
+			})
+			c.Set(authorizationPayloadKey, payload)
+			c.Next()
+			return
+		}
+
 		authorizationHeader := c.GetHeader(authorizationHeaderKey)
 		if authorizationHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -46,6 +78,27 @@ func AuthMiddleware(token ports.TokenService) gin.HandlerFunc {
 
 		accessToken := fields[1]
 		payload, err := token.VerifyToken(accessToken)
+		if errors.Is(err, ErrTokenExpired) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "token expired",
+			})
+			c.Abort()
+			return
+		}
+		if errors.Is(err, ErrTokenRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "token revoked",
+			})
+			c.Abort()
+			return
+		}
+		if errors.Is(err, ErrRevocationUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "token revocation check unavailable",
+			})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "invalid or expired token",
@@ -60,6 +113,16 @@ func AuthMiddleware(token ports.TokenService) gin.HandlerFunc {
 }
 
 func AdminMiddleware() gin.HandlerFunc {
+	return requireRole(domain.Admin)
+}
+
+// AdminOrServiceMiddleware - админские роуты, которые разрешены и
+// сервисным API-ключам (batch-джобам)
+func AdminOrServiceMiddleware() gin.HandlerFunc {
+	return requireRole(domain.Admin, domain.ServiceAccount)
+}
+
+func requireRole(roles ...domain.UserRole) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		payload, ok := getAuthPayload(ctx, authorizationPayloadKey)
 		if !ok {
@@ -70,7 +133,7 @@ func AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if payload.Role != domain.Admin {
+		if !slices.Contains(roles, payload.Role) {
 			ctx.JSON(http.StatusForbidden, gin.H{
 				"error": "admin access required",
 			})
@@ -81,3 +144,68 @@ func AdminMiddleware() gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// UserOnlyMiddleware закрывает пользовательские роуты от API-ключей:
+// у ключа нет пользователя, от имени которого читать или менять байки
+func UserOnlyMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, ok := getAuthPayload(ctx, authorizationPayloadKey)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"error": "authorization required",
+			})
+			ctx.Abort()
+			return
+		}
+
+		if payload.Role == domain.ServiceAccount {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": "API keys are limited to admin routes",
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func InFlightMiddleware(metrics ports.MetricsPort, group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics.IncInFlight(group)
+		defer metrics.DecInFlight(group)
+
+		c.Next()
+	}
+}
+
+// длиннее пришедший X-Request-ID не принимаем и генерируем свой
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware берёт X-Request-ID клиента или генерирует UUID, кладёт его
+// в контекст запроса для логов и исходящих вызовов и отдаёт в ответе
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(domain.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Request = c.Request.WithContext(domain.WithRequestID(c.Request.Context(), id))
+		c.Header(domain.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID - непустой, не длиннее лимита, только видимые ASCII-символы,
+// чтобы чужой ID не сломал заголовки и строки логов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInFlightMiddleware(t *testing.T) {
+	const requests = 5
+	metrics := &portstest.Metrics{}
+	release := make(chan struct{})
+	started := make(chan struct{}, requests)
+
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard), InFlightMiddleware(metrics, "bikes"))
+	router.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+	}
+	for range requests {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("requests did not start")
+		}
+	}
+	if got := metrics.InFlight("bikes"); got != requests {
+		t.Errorf("in flight while handlers block = %d, want %d", got, requests)
+	}
+
+	close(release)
+	wg.Wait()
+	if got := metrics.InFlight("bikes"); got != 0 {
+		t.Errorf("in flight after responses = %d, want 0", got)
+	}
+
+	// паника в хендлере не должна оставлять запрос "висеть" в gauge
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	expectStatus(t, w, http.StatusInternalServerError)
+	if got := metrics.InFlight("bikes"); got != 0 {
+		t.Errorf("in flight after panic = %d, want 0", got)
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateMileageRequest struct {
+	Mileage *int `json:"mileage" binding:"required,min=0" example:"1650"`
+}
+
+// @Summary Обновить пробег байка
+// @Description Записывает показание одометра. Пробег может только расти: значение меньше сохранённого отклоняется, иначе износ компонентов считается неверно. Равное значение допускается
+// @Tags bikes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param request body UpdateMileageRequest true "Новый пробег"
+// @Success 200 {object} BikeInfo "Пробег обновлён"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "Пробег изменили параллельным запросом"
+// @Failure 422 {object} errorResponse "Пробег меньше сохранённого или отрицательный"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/{id}/mileage [patch]
+func (h *BikeHandler) UpdateMileage(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to UpdateMileage", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateMileageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in update mileage", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		bindError(c, err)
+		return
+	}
+
+	existingBike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), bikeID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != existingBike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to update bike mileage", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   existingBike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	bike, err := h.bikeService.UpdateMileage(c.Request.Context(), bikeID, *req.Mileage)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMileageDecrease):
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, domain.ErrMileageConflict):
+			newErrorResponse(c, http.StatusConflict, err.Error())
+		case errors.Is(err, domain.ErrBikeNotFound):
+			newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "Failed to update mileage")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newBikeInfo(bike))
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Лимит подсказок брендов и моделей: они дёргаются на каждое нажатие клавиши
+const (
+	SuggestRateLimitPerSecond = 5
+	SuggestRateLimitBurst     = 20
+)
+
+// RateLimitMiddleware ограничивает запросы по пользователю из токена, без токена - по IP.
+// Корзины живут в общем хранилище, так что лимит один на все реплики.
+// На каждый ответ ставит X-RateLimit-*: Limit - размер корзины,
+// Remaining - сколько запросов осталось, Reset - через сколько секунд корзина снова полная.
+// Если лимитов несколько, заголовки ставит последний, то есть самый узкий.
+// Без хранилища запросы пропускаются без заголовков: лимит - защита, а не причина отказа
+func RateLimitMiddleware(limiter ports.RateLimiter, logger ports.LoggerPort) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		if payload, ok := getAuthPayload(c, authorizationPayloadKey); ok {
+			key = payload.UserID.String() + payload.Service
+		}
+
+		q, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			logger.WithContext(c.Request.Context()).Warn("Rate limiter unavailable, request allowed", map[string]interface{}{
+				"error": err.Error(),
+			})
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(q.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(q.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(q.Reset)))
+
+		if !q.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(1, ceilSeconds(q.Retry))))
+			newErrorResponse(c, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
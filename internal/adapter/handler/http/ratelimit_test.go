@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/redis"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// rateLimitedRouter - один роут за RateLimitMiddleware, пользователь берётся из X-Test-User
+func rateLimitedRouter(limiter ports.RateLimiter) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			c.Set(authorizationPayloadKey, &domain.TokenPayload{UserID: uuid.MustParse(id), Role: domain.AppUser})
+		}
+	}, RateLimitMiddleware(limiter, &portstest.Logger{}))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func redisBucket(t *testing.T, perSecond float64, burst int) ports.RateLimiter {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC))
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return redis.NewTokenBucket(client, "user", perSecond, burst)
+}
+
+func ping(router http.Handler, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitHeadersDecrement(t *testing.T) {
+	router := rateLimitedRouter(redisBucket(t, 1, 3))
+	user := uuid.NewString()
+
+	tests := []struct {
+		status    int
+		remaining string
+		reset     string
+	}{
+		{http.StatusNoContent, "2", "1"},
+		{http.StatusNoContent, "1", "2"},
+		{http.StatusNoContent, "0", "3"},
+		{http.StatusTooManyRequests, "0", "3"},
+	}
+	for i, tt := range tests {
+		w := ping(router, user)
+		if w.Code != tt.status {
+			t.Fatalf("request %d: status %d, want %d", i+1, w.Code, tt.status)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("request %d: X-RateLimit-Limit = %q, want 3", i+1, got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.remaining {
+			t.Errorf("request %d: X-RateLimit-Remaining = %q, want %s", i+1, got, tt.remaining)
+		}
+		if got := w.Header().Get("X-RateLimit-Reset"); got != tt.reset {
+			t.Errorf("request %d: X-RateLimit-Reset = %q, want %s", i+1, got, tt.reset)
+		}
+	}
+
+	w := ping(router, user)
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	router := rateLimitedRouter(redisBucket(t, 1, 1))
+
+	first, second := uuid.NewString(), uuid.NewString()
+	if w := ping(router, first); w.Code != http.StatusNoContent {
+		t.Fatalf("first user: status %d", w.Code)
+	}
+	if w := ping(router, first); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("first user over limit: status %d, want 429", w.Code)
+	}
+	if w := ping(router, second); w.Code != http.StatusNoContent {
+		t.Fatalf("second user shares the first user's bucket: status %d", w.Code)
+	}
+}
+
+// под параллельной нагрузкой каждый ответ видит свой остаток, ни один не повторяется
+func TestRateLimitHeadersUnderConcurrency(t *testing.T) {
+	const burst, requests = 10, 30
+	router := rateLimitedRouter(redisBucket(t, 1, burst))
+	user := uuid.NewString()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = make(map[string]int)
+		over int
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := ping(router, user)
+			mu.Lock()
+			defer mu.Unlock()
+			if w.Code == http.StatusTooManyRequests {
+				over++
+				return
+			}
+			seen[w.Header().Get("X-RateLimit-Remaining")]++
+		}()
+	}
+	wg.Wait()
+
+	if over != requests-burst {
+		t.Errorf("%d requests rejected, want %d", over, requests-burst)
+	}
+	for i := 0; i < burst; i++ {
+		if n := seen[strconv.Itoa(i)]; n != 1 {
+			t.Errorf("remaining %d seen %d times, want once", i, n)
+		}
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string) (ports.RateLimitQuota, error) {
+	return ports.RateLimitQuota{}, errors.New("redis is down")
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	w := ping(rateLimitedRouter(failingLimiter{}), uuid.NewString())
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status %d, want request to pass when the limiter is unavailable", w.Code)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "" {
+		t.Errorf("X-RateLimit-Remaining = %q without limiter state, want no header", got)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessCheck - зависимость, которую проверяет /ready. Сбой Optional
+// зависимости отмечается в ответе, но не делает сервис неготовым
+type ReadinessCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+}
+
+const (
+	readinessOK       = "ok"
+	readinessDown     = "down"
+	readinessDegraded = "degraded"
+)
+
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// ReadinessHandler проверяет все зависимости параллельно, каждую не дольше
+// timeout. Обязательная зависимость недоступна - 503
+func ReadinessHandler(checks []ReadinessCheck, timeout time.Duration, logger ports.LoggerPort) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errs := make([]error, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+				defer cancel()
+				errs[i] = check.Check(ctx)
+			}()
+		}
+		wg.Wait()
+
+		response := ReadinessResponse{
+			Status: readinessOK,
+			Checks: make(map[string]string, len(checks)),
+		}
+		ready := true
+		for i, check := range checks {
+			if errs[i] == nil {
+				response.Checks[check.Name] = readinessOK
+				continue
+			}
+			response.Checks[check.Name] = readinessDown
+			logger.WithContext(c.Request.Context()).Warn("Readiness check failed", map[string]interface{}{
+				"dependency": check.Name,
+				"optional":   check.Optional,
+				"error":      errs[i].Error(),
+			})
+			if check.Optional {
+				response.Status = readinessDegraded
+			} else {
+				ready = false
+			}
+		}
+
+		if !ready {
+			response.Status = readinessDown
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
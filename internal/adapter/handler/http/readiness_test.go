@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/runtime"
+	"github.com/sm8ta/webike_user_microservice_nikita/pkg/client/users"
+	"github.com/sony/gobreaker/v2"
+)
+
+var errDown = errors.New("connection refused")
+
+func readiness(t *testing.T, checks []ReadinessCheck) (int, ReadinessResponse) {
+	t.Helper()
+	router := gin.New()
+	router.GET("/ready", ReadinessHandler(checks, time.Second, &portstest.Logger{}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	var resp ReadinessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return w.Code, resp
+}
+
+func TestReadinessHandler(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errDown }
+
+	tests := []struct {
+		name       string
+		checks     []ReadinessCheck
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "all up",
+			checks:     []ReadinessCheck{{Name: "postgres", Check: up}, {Name: "redis", Check: up}},
+			wantCode:   http.StatusOK,
+			wantStatus: readinessOK,
+			wantChecks: map[string]string{"postgres": readinessOK, "redis": readinessOK},
+		},
+		{
+			name:       "optional down",
+			checks:     []ReadinessCheck{{Name: "postgres", Check: up}, {Name: "user_service", Check: down, Optional: true}},
+			wantCode:   http.StatusOK,
+			wantStatus: readinessDegraded,
+			wantChecks: map[string]string{"postgres": readinessOK, "user_service": readinessDown},
+		},
+		{
+			name:       "required down",
+			checks:     []ReadinessCheck{{Name: "postgres", Check: down}, {Name: "user_service", Check: down, Optional: true}},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: readinessDown,
+			wantChecks: map[string]string{"postgres": readinessDown, "user_service": readinessDown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := readiness(t, tt.checks)
+			if code != tt.wantCode || resp.Status != tt.wantStatus {
+				t.Errorf("got %d %q, want %d %q", code, resp.Status, tt.wantCode, tt.wantStatus)
+			}
+			for name, want := range tt.wantChecks {
+				if resp.Checks[name] != want {
+					t.Errorf("checks[%s] = %q, want %q", name, resp.Checks[name], want)
+				}
+			}
+		})
+	}
+}
+
+// проверка готовности не влияет на breaker запросов за пользователем и только
+// читает его состояние
+func TestUserServiceReadiness(t *testing.T) {
+	metrics := &portstest.Metrics{}
+	h := &BikeHandler{
+		userBreaker: newUserServiceBreaker(&config.UserService{
+			Timeout:            50 * time.Millisecond,
+			BreakerFailures:    2,
+			BreakerOpenTimeout: time.Minute,
+		}, metrics),
+		userTimeout: 50 * time.Millisecond,
+	}
+	failRequest := func() {
+		h.userBreaker.Execute(func() (*users.GetUsersIDOK, error) {
+			return nil, runtime.NewAPIError("getUsersIdInternalServerError", nil, http.StatusInternalServerError)
+		})
+	}
+
+	calls := 0
+	var probeErr error
+	check := h.UserServiceReadiness(func(ctx context.Context) error {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("probe runs without a deadline")
+		}
+		return probeErr
+	})
+	if !check.Optional {
+		t.Fatal("user-service check must be optional")
+	}
+
+	// сбои пробника цепь не размыкают
+	probeErr = errDown
+	for range 3 {
+		if err := check.Check(context.Background()); !errors.Is(err, errDown) {
+			t.Fatalf("failing probe: err = %v, want %v", err, errDown)
+		}
+	}
+	if state := h.userBreaker.State(); state != gobreaker.StateClosed {
+		t.Fatalf("breaker state after failing probes = %s, want closed", state)
+	}
+
+	// сервис принимает TCP, но отвечает 5xx: удачный пробник между запросами
+	// не сбрасывает счётчик сбоев
+	probeErr = nil
+	failRequest()
+	if err := check.Check(context.Background()); err != nil {
+		t.Fatalf("healthy probe: %v", err)
+	}
+	failRequest()
+	if state := metrics.BreakerState(userServiceBreaker); state != int(gobreaker.StateOpen) {
+		t.Fatalf("breaker state = %d, want open", state)
+	}
+
+	// цепь разомкнута - проверка отвечает сразу, в сеть не ходит
+	if err := check.Check(context.Background()); !isBreakerOpen(err) {
+		t.Errorf("open breaker: err = %v, want ErrOpenState", err)
+	}
+	if calls != 4 {
+		t.Errorf("probe calls = %d, want 4", calls)
+	}
+}
+
+// по умолчанию недоступный user-service - degraded, с ReadinessCritical - 503
+func TestUserServiceReadinessCriticality(t *testing.T) {
+	down := func(context.Context) error { return errDown }
+	for _, critical := range []bool{false, true} {
+		h := NewBikeHandler(nil, &portstest.Logger{}, &portstest.Metrics{}, nil, &config.UserService{
+			Timeout:            50 * time.Millisecond,
+			BreakerFailures:    5,
+			BreakerOpenTimeout: time.Minute,
+			ReadinessCritical:  critical,
+		}, &config.Pagination{}, portstest.NewCache(), "", 100, 80)
+
+		code, resp := readiness(t, []ReadinessCheck{h.UserServiceReadiness(down)})
+		wantCode, wantStatus := http.StatusOK, readinessDegraded
+		if critical {
+			wantCode, wantStatus = http.StatusServiceUnavailable, readinessDown
+		}
+		if code != wantCode || resp.Status != wantStatus {
+			t.Errorf("critical=%t: got %d %q, want %d %q", critical, code, resp.Status, wantCode, wantStatus)
+		}
+	}
+}
@@ -1,9 +1,17 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
+// statusClientClosedRequest - нестандартный код nginx: клиент закрыл
+// соединение, не дождавшись ответа
+const statusClientClosedRequest = 499
+
 type errorResponse struct {
 	Success bool   `json:"success" example:"false"`
 	Message string `json:"message" example:"Error"`
@@ -15,7 +23,49 @@ type successResponse struct {
 	Data    interface{} `json:"data,omitempty" swaggertype:"object"`
 }
 
+// invalidEntry - элемент батча с некорректным ID
+type invalidEntry struct {
+	Index int    `json:"index" example:"2"`
+	Value string `json:"value" example:"not-a-uuid"`
+}
+
+type invalidIDsResponse struct {
+	Success bool           `json:"success" example:"false"`
+	Message string         `json:"message" example:"Invalid IDs in batch"`
+	Invalid []invalidEntry `json:"invalid"`
+}
+
+func newInvalidIDsResponse(c *gin.Context, invalid []invalidEntry) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, invalidIDsResponse{
+		Success: false,
+		Message: "Invalid IDs in batch",
+		Invalid: invalid,
+	})
+}
+
+// itemErrorResponse - ошибка конкретного элемента батча
+type itemErrorResponse struct {
+	Success bool   `json:"success" example:"false"`
+	Message string `json:"message" example:"validation error"`
+	Index   int    `json:"index" example:"2"`
+}
+
+func newItemErrorResponse(c *gin.Context, statusCode int, index int, message string) {
+	c.AbortWithStatusJSON(statusCode, itemErrorResponse{
+		Success: false,
+		Message: message,
+		Index:   index,
+	})
+}
+
+// newErrorResponse отвечает ошибкой. Если клиент уже ушёл, ошибка почти наверняка
+// следствие отмены (в том числе domain.ErrRequestCanceled из репозиториев):
+// тело писать некому, в метрики попадает 499 вместо ложных 404/500
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
+	if errors.Is(c.Request.Context().Err(), context.Canceled) {
+		c.AbortWithStatus(statusClientClosedRequest)
+		return
+	}
 	c.AbortWithStatusJSON(statusCode, errorResponse{
 		Success: false,
 		Message: message,
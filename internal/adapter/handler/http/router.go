@@ -1,9 +1,13 @@
 package http
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 
 	"github.com/gin-contrib/cors"
@@ -11,70 +15,220 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
 )
 
 type Router struct {
 	router *gin.Engine
+	server *http.Server
+	served atomic.Uint64
 }
 
 func NewRouter(
 	cfg *config.HTTP,
 	tokenService ports.TokenService,
+	apiKeys ports.APIKeyVerifier,
+	logger ports.LoggerPort,
+	metrics ports.MetricsPort,
 	bikeHandler *BikeHandler,
 	componentHandler *ComponentHandler,
+	webhookHandler *WebhookHandler,
+	statsHandler *StatsHandler,
+	maintenance *Maintenance,
+	tokenHandler *TokenHandler,
+	readiness []ReadinessCheck,
+	userLimiter ports.RateLimiter,
+	suggestLimiter ports.RateLimiter,
 ) (*Router, error) {
 	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	if err := registerValidators(); err != nil {
+		return nil, err
+	}
+
+	r := &Router{}
 	router := gin.Default()
 
+	// счётчик обслуженных запросов для итогов при остановке
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		r.served.Add(1)
+	})
+	router.Use(RequestIDMiddleware())
+
 	// CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{cfg.AllowedOrigins},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Prefer", "Idempotency-Key", apiKeyHeaderKey, domain.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After", "Preference-Applied", "Idempotent-Replayed", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", domain.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
+	router.Use(maintenance.Middleware())
+
+	// только для отладки, включается явным списком роутов
+	if len(cfg.DebugBodyRoutes) > 0 {
+		logger.Warn("Request/response body logging enabled", map[string]interface{}{
+			"routes": cfg.DebugBodyRoutes,
+		})
+		router.Use(DebugBodyMiddleware(cfg.DebugBodyRoutes, cfg.DebugBodyMaxBytes, logger))
+	}
+
 	// Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Стабильный путь спецификации для генерации клиентов
+	router.GET("/openapi.json", func(c *gin.Context) {
+		doc, err := swag.ReadDoc()
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, "OpenAPI spec is not available")
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+	})
 
 	// Metrics
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// Health check
+	// Health check - только liveness, зависимости не трогает
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	// Readiness - готов ли сервис принимать трафик
+	router.GET("/ready", ReadinessHandler(readiness, cfg.ReadinessTimeout, logger))
+
+	// общий лимит пользователя, ставится после auth, чтобы считать по токену
+	rateLimit := RateLimitMiddleware(userLimiter, logger)
+	// API-ключам доступны только явно перечисленные админские роуты ниже
+	userOnly := UserOnlyMiddleware()
 
 	// Bikes routes
 	bikes := router.Group("/bikes")
-	bikes.Use(AuthMiddleware(tokenService))
+	bikes.Use(InFlightMiddleware(metrics, "bikes"), AuthMiddleware(tokenService, apiKeys, logger), userOnly, rateLimit)
 	{
 		bikes.POST("", bikeHandler.CreateBike)
+		bikes.POST("/batch", bikeHandler.CreateBikesBatch)
+		bikes.POST("/import", bikeHandler.ImportBikeSpec)
 		bikes.GET("/my", bikeHandler.GetMyBikes)
+		bikes.GET("/my/urgent", bikeHandler.GetUrgentBikes)
+		bikes.GET("/my/incomplete", bikeHandler.GetIncompleteBikes)
+		bikes.GET("/my/archived", bikeHandler.GetArchivedBikes)
+		bikes.GET("/loadouts", bikeHandler.GetStandardLoadouts)
 		bikes.GET("/:id", bikeHandler.GetBike)
 		bikes.PUT("/:id", bikeHandler.UpdateBike)
+		bikes.PATCH("/:id/mileage", bikeHandler.UpdateMileage)
 		bikes.DELETE("/:id", bikeHandler.DeleteBike)
 		bikes.GET("/:id/with-components", bikeHandler.GetBikeWithComponents)
+		bikes.GET("/:id/components", componentHandler.GetBikeComponents)
 		bikes.GET("/:id/with-user", bikeHandler.GetBikeWithUser)
+		bikes.GET("/:id/completeness", bikeHandler.GetBikeCompleteness)
+		bikes.GET("/:id/spec", bikeHandler.GetBikeSpec)
+		bikes.GET("/:id/qr", bikeHandler.GetBikeQR)
+		bikes.GET("/:id/forecast", bikeHandler.GetBikeForecast)
+		bikes.GET("/:id/component-status", bikeHandler.GetComponentStatus)
+		bikes.GET("/:id/components/needs-replacement", bikeHandler.GetComponentsNeedingReplacement)
+		bikes.POST("/:id/archive", bikeHandler.ArchiveBike)
+		bikes.POST("/:id/unarchive", bikeHandler.UnarchiveBike)
+		bikes.POST("/:id/restore", bikeHandler.RestoreBike)
+		bikes.POST("/:id/transfer", bikeHandler.TransferBike)
+		bikes.POST("/:id/components/import", componentHandler.ImportComponents)
+	}
+	// Me routes
+	me := router.Group("/me")
+	me.Use(InFlightMiddleware(metrics, "me"), AuthMiddleware(tokenService, apiKeys, logger), userOnly, rateLimit)
+	{
+		me.GET("", bikeHandler.GetMe)
 	}
+	// Подсказки дёргаются на каждое нажатие клавиши, у них свой, более узкий лимит
+	suggestLimit := RateLimitMiddleware(suggestLimiter, logger)
+
 	// Components routes
 	components := router.Group("/components")
-	components.Use(AuthMiddleware(tokenService))
+	components.Use(InFlightMiddleware(metrics, "components"), AuthMiddleware(tokenService, apiKeys, logger), userOnly, rateLimit)
 	{
 		components.POST("", componentHandler.CreateComponent)
+		components.POST("/batch", componentHandler.CreateComponentsBatch)
+		components.PATCH("/batch", componentHandler.BatchUpdateComponents)
+		components.GET("/categories", componentHandler.GetComponentCategories)
+		components.GET("/types", componentHandler.GetComponentTypes)
+		components.GET("/weights", componentHandler.GetComponentWeights)
+		components.GET("/brands/suggest", suggestLimit, componentHandler.SuggestBrands)
+		components.GET("/models/suggest", suggestLimit, componentHandler.SuggestModels)
 		components.GET("/:id", componentHandler.GetComponent)
+		components.GET("/:id/bike", componentHandler.GetComponentBike)
+		components.POST("/:id/extend-life", componentHandler.ExtendComponentLife)
+		components.POST("/:id/replace", componentHandler.ReplaceComponent)
 		components.PUT("/:id", componentHandler.UpdateComponent)
+		components.PATCH("/:id", componentHandler.PatchComponent)
 		components.DELETE("/:id", componentHandler.DeleteComponent)
 	}
-	return &Router{router: router}, nil
+	// Admin routes
+	admin := router.Group("/admin")
+	admin.Use(InFlightMiddleware(metrics, "admin"), AuthMiddleware(tokenService, apiKeys, logger), rateLimit)
+	// batch-джобы по API-ключу: чтение и режим обслуживания на время выкладки
+	shared := admin.Group("", AdminOrServiceMiddleware())
+	{
+		shared.GET("/bikes", bikeHandler.ListBikes)
+		shared.GET("/stats", statsHandler.GetFleetStats)
+		shared.GET("/components/defaults", componentHandler.GetComponentDefaults)
+		shared.GET("/components/orphaned", componentHandler.GetOrphanedComponents)
+		shared.GET("/maintenance", maintenance.GetMaintenance)
+		shared.PUT("/maintenance", maintenance.SetMaintenance)
+	}
+	// массовые записи, справочники и отзыв токенов - только админы-люди
+	adminOnly := admin.Group("", AdminMiddleware())
+	{
+		adminOnly.PATCH("/bikes", bikeHandler.BulkUpdateBikes)
+		adminOnly.PUT("/components/defaults", componentHandler.SetComponentDefaults)
+		adminOnly.PUT("/components/weights", componentHandler.SetComponentWeights)
+		adminOnly.POST("/components/orphaned", componentHandler.RepairOrphanedComponents)
+		adminOnly.POST("/tokens/revoke", tokenHandler.RevokeToken)
+	}
+	// Webhooks routes
+	webhooks := router.Group("/webhooks")
+	webhooks.Use(InFlightMiddleware(metrics, "webhooks"), AuthMiddleware(tokenService, apiKeys, logger), userOnly, rateLimit)
+	{
+		webhooks.POST("", webhookHandler.CreateWebhook)
+		webhooks.GET("", webhookHandler.GetMyWebhooks)
+		webhooks.GET("/:id", webhookHandler.GetWebhook)
+		webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+	}
+	server := &http.Server{
+		Handler:           router,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	r.router = router
+	r.server = server
+	return r, nil
+}
+
+// RequestsServed - сколько запросов обработано с момента старта
+func (r *Router) RequestsServed() uint64 {
+	return r.served.Load()
+}
+
+// Listen занимает адрес заранее, чтобы ошибка bind вернулась вызывающему,
+// а не потерялась в горутине Serve
+func (r *Router) Listen(addr string) (net.Listener, error) {
+	r.server.Addr = addr
+	return net.Listen("tcp", addr)
+}
+
+// Serve блокируется до Shutdown, после него возвращает http.ErrServerClosed
+func (r *Router) Serve(ln net.Listener) error {
+	return r.server.Serve(ln)
 }
 
-func (r *Router) Serve(addr string) error {
-	return r.router.Run(addr)
+// Shutdown перестаёт принимать соединения и ждёт завершения начатых
+// запросов, пока не истечёт ctx
+func (r *Router) Shutdown(ctx context.Context) error {
+	return r.server.Shutdown(ctx)
 }
 
 func (r *Router) Engine() *gin.Engine {
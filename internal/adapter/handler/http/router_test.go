@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestsServed(t *testing.T) {
+	api := newTestAPI(t)
+	if got := api.router.RequestsServed(); got != 0 {
+		t.Fatalf("served before requests = %d, want 0", got)
+	}
+
+	// считаются все ответы, в том числе ошибки и неизвестные пути
+	expectStatus(t, api.do(http.MethodGet, "/health", "", nil), http.StatusOK)
+	expectStatus(t, api.do(http.MethodGet, "/bikes/my", "", nil), http.StatusUnauthorized)
+	expectStatus(t, api.do(http.MethodGet, "/nope", "", nil), http.StatusNotFound)
+
+	token := api.token(uuid.New(), domain.AppUser)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			api.do(http.MethodGet, "/bikes/my", token, nil)
+		}()
+	}
+	wg.Wait()
+
+	if got := api.router.RequestsServed(); got != 13 {
+		t.Errorf("served = %d, want 13", got)
+	}
+}
@@ -0,0 +1,249 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+// @Summary Экспорт сборки байка
+// @Description Отдаёт сборку байка (модель, тип, компоненты с брендами, моделями и порогами) без ID, владельца и пробега. Результат можно передать в POST /bikes/import на другом аккаунте
+// @Tags bikes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} domain.BikeSpec "Спецификация сборки"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Router /bikes/{id}/spec [get]
+func (h *BikeHandler) GetBikeSpec(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBikeSpec", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeWithComponents(c.Request.Context(), bikeID, domain.ComponentFilter{})
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike with components", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike spec", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.NewBikeSpec(bike))
+}
+
+// @Summary Импорт сборки байка
+// @Description Создаёт байк авторизованного пользователя по спецификации из GET /bikes/{id}/spec. Байк и компоненты создаются одной транзакцией, компоненты считаются установленными сейчас на нулевом пробеге
+// @Tags bikes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body domain.BikeSpec true "Спецификация сборки"
+// @Success 201 {object} GetBikeWithComponentsResponse "Байк создан"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Сервисный ключ"
+// @Failure 413 {object} errorResponse "Слишком много компонентов"
+// @Failure 422 {object} errorResponse "Неподдерживаемая версия или недопустимые значения"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/import [post]
+func (h *BikeHandler) ImportBikeSpec(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to ImportBikeSpec", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var spec domain.BikeSpec
+	if err := c.ShouldBindJSON(&spec); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in import bike spec", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	if batchTooLarge(c, len(spec.Components), domain.MaxSpecComponents, h.maxBatchSize, "components") {
+		return
+	}
+
+	bike, err := h.bikeService.ImportBikeSpec(c.Request.Context(), payload.UserID, &spec)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidBikeSpec) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to import bike spec")
+		return
+	}
+
+	localizeComponents(preferredLanguage(c), bike.Components...)
+	c.JSON(http.StatusCreated, newBikeWithComponentsResponse(bike, h.warnPercent))
+}
+
+// границы и значение по умолчанию для стороны QR-кода в пикселях
+const (
+	defaultQRSize = 256
+	minQRSize     = 64
+	maxQRSize     = 1024
+)
+
+// @Summary QR-код байка
+// @Description PNG или SVG с QR-кодом ссылки на спецификацию байка (GET /bikes/{id}/spec). Если внешний адрес API не настроен, в коде только ID байка
+// @Tags bikes
+// @Security BearerAuth
+// @Produce png
+// @Produce image/svg+xml
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param size query int false "Сторона картинки в пикселях (64-1024)" default(256)
+// @Param format query string false "Формат" Enums(png, svg) default(png)
+// @Success 200 {file} binary "QR-код"
+// @Failure 400 {object} errorResponse "Неверный размер или формат"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /bikes/{id}/qr [get]
+func (h *BikeHandler) GetBikeQR(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetBikeQR", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	size := defaultQRSize
+	if value := c.Query("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < minQRSize || parsed > maxQRSize {
+			newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("size must be an integer from %d to %d", minQRSize, maxQRSize))
+			return
+		}
+		size = parsed
+	}
+	format := c.DefaultQuery("format", "png")
+	if format != "png" && format != "svg" {
+		newErrorResponse(c, http.StatusBadRequest, "format must be png or svg")
+		return
+	}
+
+	bike, err := h.bikeService.GetBikeByID(c.Request.Context(), bikeID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != bike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to bike QR", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   bike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	content := bike.BikeID.String()
+	if h.publicURL != "" {
+		content = fmt.Sprintf("%s/bikes/%s/spec", h.publicURL, bike.BikeID)
+	}
+
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to encode QR code", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", qrSVG(code.Bitmap(), size))
+		return
+	}
+
+	png, err := code.PNG(size)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to render QR code", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// qrSVG рисует матрицу QR-кода (с белой рамкой) квадратами в viewBox,
+// size задаёт итоговый размер картинки
+func qrSVG(bitmap [][]bool, size int) []byte {
+	n := len(bitmap)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.Bytes()
+}
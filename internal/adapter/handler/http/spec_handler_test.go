@@ -0,0 +1,197 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+func TestBikeSpecRoundTrip(t *testing.T) {
+	api := newTestAPI(t)
+	owner, friend := uuid.New(), uuid.New()
+	bike := api.addBike(owner, 3000)
+	bike.Year = ptr(2022)
+	api.store.AddBike(bike)
+	for _, c := range []domain.Component{
+		{Name: domain.Wheels, Brand: "DT Swiss", Model: "G 1800", Position: domain.PositionFront, MaxMileage: 15000},
+		{Name: domain.Wheels, Brand: "DT Swiss", Model: "G 1800", Position: domain.PositionRear, MaxMileage: 12000},
+		{Name: domain.Frame, Brand: "Canyon", MaxAgeDays: ptr(3650), Position: domain.PositionNone},
+	} {
+		component := api.addComponent(bike, c.Name, 1000)
+		component.Brand, component.Model, component.Position, component.MaxMileage, component.MaxAgeDays = c.Brand, c.Model, c.Position, c.MaxMileage, c.MaxAgeDays
+		api.store.AddComponent(component)
+	}
+
+	w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/spec", api.token(owner, domain.AppUser), nil)
+	expectStatus(t, w, http.StatusOK)
+	exported := w.Body.String()
+	// в спецификации нет ничего, что привязывает её к аккаунту
+	for _, leak := range []string{bike.BikeID.String(), owner.String(), `"mileage"`, `"installed_mileage"`, `"id"`, `"user_id"`} {
+		if strings.Contains(exported, leak) {
+			t.Errorf("spec contains %s: %s", leak, exported)
+		}
+	}
+
+	w = api.do(http.MethodPost, "/bikes/import", api.token(friend, domain.AppUser), exported)
+	expectStatus(t, w, http.StatusCreated)
+	imported := decode[GetBikeWithComponentsResponse](t, w)
+	if imported.UserID != friend || imported.BikeID == bike.BikeID || imported.Mileage != 0 {
+		t.Errorf("imported bike = %s of %s with mileage %d, want a new bike of %s", imported.BikeID, imported.UserID, imported.Mileage, friend)
+	}
+	if got := api.store.Components(imported.BikeID); len(got) != 3 {
+		t.Fatalf("imported bike has %d components, want 3", len(got))
+	}
+
+	// спецификация импортированного байка совпадает с исходной
+	w = api.do(http.MethodGet, "/bikes/"+imported.BikeID.String()+"/spec", api.token(friend, domain.AppUser), nil)
+	expectStatus(t, w, http.StatusOK)
+	var original, copied domain.BikeSpec
+	if err := json.Unmarshal([]byte(exported), &original); err != nil {
+		t.Fatal(err)
+	}
+	copied = decode[domain.BikeSpec](t, w)
+	if !reflect.DeepEqual(sortedSpec(original), sortedSpec(copied)) {
+		t.Errorf("round trip spec = %+v, want %+v", copied, original)
+	}
+}
+
+// sortedSpec упорядочивает компоненты: порядок вывода зависит от времени установки
+func sortedSpec(spec domain.BikeSpec) domain.BikeSpec {
+	components := append([]domain.ComponentSpec(nil), spec.Components...)
+	slices.SortFunc(components, func(a, b domain.ComponentSpec) int {
+		return strings.Compare(string(a.Name)+string(a.Position), string(b.Name)+string(b.Position))
+	})
+	spec.Components = components
+	return spec
+}
+
+func TestGetBikeSpecAccess(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		role       domain.UserRole
+		unknown    bool
+		wantStatus int
+	}{
+		{name: "владелец", requester: owner, role: domain.AppUser, wantStatus: http.StatusOK},
+		{name: "админ", requester: uuid.New(), role: domain.Admin, wantStatus: http.StatusOK},
+		{name: "чужой байк", requester: uuid.New(), role: domain.AppUser, wantStatus: http.StatusForbidden},
+		{name: "байка нет", requester: owner, role: domain.AppUser, unknown: true, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bikeID := api.addBike(owner, 100).BikeID
+			if tt.unknown {
+				bikeID = uuid.New()
+			}
+			expectStatus(t, api.do(http.MethodGet, "/bikes/"+bikeID.String()+"/spec", api.token(tt.requester, tt.role), nil), tt.wantStatus)
+		})
+	}
+}
+
+func TestImportBikeSpecInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "обрезанный JSON", body: `{"version":1,`, wantStatus: http.StatusBadRequest},
+		{name: "другая версия", body: `{"version":2,"type":"road","model":"Grail","components":[]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "неизвестный тип", body: `{"version":1,"type":"unicycle","model":"Grail","components":[]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "компонент без порогов", body: `{"version":1,"type":"road","model":"Grail","components":[{"name":"frame"}]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "два передних колеса", body: `{"version":1,"type":"road","model":"Grail","components":[{"name":"wheels","position":"front","max_mileage":1},{"name":"wheels","position":"front","max_mileage":1}]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "без компонентов", body: `{"version":1,"type":"road","model":"Grail","components":[]}`, wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			owner := uuid.New()
+			expectStatus(t, api.do(http.MethodPost, "/bikes/import", api.token(owner, domain.AppUser), tt.body), tt.wantStatus)
+			bikes, err := api.bikeService.GetBikesByUserID(t.Context(), owner.String(), domain.BikeFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created := len(bikes) == 1; created != (tt.wantStatus == http.StatusCreated) {
+				t.Errorf("bike created = %t", created)
+			}
+		})
+	}
+}
+
+func TestGetBikeQR(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name       string
+		requester  uuid.UUID
+		query      string
+		wantStatus int
+		wantType   string
+		wantSize   int
+	}{
+		{name: "PNG по умолчанию", requester: owner, wantStatus: http.StatusOK, wantType: "image/png", wantSize: defaultQRSize},
+		{name: "PNG заданного размера", requester: owner, query: "?size=512", wantStatus: http.StatusOK, wantType: "image/png", wantSize: 512},
+		{name: "минимальный размер", requester: owner, query: "?size=64", wantStatus: http.StatusOK, wantType: "image/png", wantSize: 64},
+		{name: "SVG", requester: owner, query: "?format=svg&size=300", wantStatus: http.StatusOK, wantType: "image/svg+xml", wantSize: 300},
+		{name: "размер меньше минимума", requester: owner, query: "?size=63", wantStatus: http.StatusBadRequest},
+		{name: "размер больше максимума", requester: owner, query: "?size=1025", wantStatus: http.StatusBadRequest},
+		{name: "размер не число", requester: owner, query: "?size=big", wantStatus: http.StatusBadRequest},
+		{name: "неизвестный формат", requester: owner, query: "?format=gif", wantStatus: http.StatusBadRequest},
+		{name: "чужой байк", requester: uuid.New(), wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+
+			w := api.do(http.MethodGet, "/bikes/"+bike.BikeID.String()+"/qr"+tt.query, api.token(tt.requester, domain.AppUser), nil)
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+
+			// в коде ссылка на спецификацию байка
+			code, err := qrcode.New("https://bikes.example.com/bikes/"+bike.BikeID.String()+"/spec", qrcode.Medium)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantType == "image/svg+xml" {
+				if !bytes.Equal(w.Body.Bytes(), qrSVG(code.Bitmap(), tt.wantSize)) {
+					t.Error("SVG does not encode the spec URL")
+				}
+				return
+			}
+
+			img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+			if err != nil {
+				t.Fatalf("invalid PNG: %v", err)
+			}
+			if bounds := img.Bounds(); bounds.Dx() != tt.wantSize || bounds.Dy() != tt.wantSize {
+				t.Errorf("PNG is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantSize, tt.wantSize)
+			}
+			want, err := code.PNG(tt.wantSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w.Body.Bytes(), want) {
+				t.Error("PNG does not encode the spec URL")
+			}
+		})
+	}
+}
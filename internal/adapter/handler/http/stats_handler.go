@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	statsService *services.StatsService
+	logger       ports.LoggerPort
+	metrics      ports.MetricsPort
+	// warnPercent - порог warning по умолчанию, если в запросе его нет
+	warnPercent int
+}
+
+func NewStatsHandler(
+	statsService *services.StatsService,
+	logger ports.LoggerPort,
+	metrics ports.MetricsPort,
+	warnPercent int,
+) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+		logger:       logger,
+		metrics:      metrics,
+		warnPercent:  warnPercent,
+	}
+}
+
+// @Summary Сводка по парку
+// @Description Для админов и сервисных API-ключей. Пользователи с байками, байки по типам, компоненты по названиям, просроченные компоненты. Кешируется на минуту
+// @Tags admin
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Produce json
+// @Param warn_threshold_percent query int false "С какого процента износа компонент считается warning (1-100)" default(80)
+// @Success 200 {object} domain.FleetStats "Сводка"
+// @Failure 400 {object} errorResponse "Неверный порог"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /admin/stats [get]
+func (h *StatsHandler) GetFleetStats(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	warnPercent, err := parseWarnThreshold(c, h.warnPercent)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := h.statsService.GetFleetStats(c.Request.Context(), warnPercent)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestGetFleetStatsWarnThreshold(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{query: "", wantStatus: http.StatusOK},
+		{query: "?warn_threshold_percent=1", wantStatus: http.StatusOK},
+		{query: "?warn_threshold_percent=100", wantStatus: http.StatusOK},
+		{query: "?warn_threshold_percent=0", wantStatus: http.StatusBadRequest},
+		{query: "?warn_threshold_percent=101", wantStatus: http.StatusBadRequest},
+		{query: "?warn_threshold_percent=-5", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			api := newTestAPI(t)
+			w := api.do(http.MethodGet, "/admin/stats"+tt.query, api.token(uuid.New(), domain.Admin), nil)
+			expectStatus(t, w, tt.wantStatus)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sm8ta/webike_user_microservice_nikita/pkg/client/users"
+)
+
+const testJWTSecret = "test-secret-0123456789abcdef"
+
+// testAPI - роутер из NewRouter поверх сервисов с репозиториями в памяти
+type testAPI struct {
+	t       *testing.T
+	store   *portstest.Store
+	cache   *portstest.Cache
+	logger  *portstest.Logger
+	metrics *portstest.Metrics
+	router  *Router
+
+	bikeService      *services.BikeService
+	componentService *services.ComponentService
+	bikeHandler      *BikeHandler
+	tokens           *JWTTokenService
+	maintenance      *Maintenance
+	// users - пользователи, которых "знает" user-service
+	users map[uuid.UUID]UserResponseInfo
+}
+
+type testAPIOption func(*testAPIConfig)
+
+type testAPIConfig struct {
+	http        config.HTTP
+	pagination  config.Pagination
+	userService config.UserService
+	strictYear  bool
+	warnPercent int
+	apiKeys     config.APIKeys
+	userLimiter ports.RateLimiter
+}
+
+func withHTTPConfig(fn func(*config.HTTP)) testAPIOption {
+	return func(c *testAPIConfig) { fn(&c.http) }
+}
+
+func withStrictComponentYear() testAPIOption {
+	return func(c *testAPIConfig) { c.strictYear = true }
+}
+
+func withAPIKeys(keys ...config.APIKey) testAPIOption {
+	return func(c *testAPIConfig) { c.apiKeys.Keys = keys }
+}
+
+func withUserLimiter(limiter ports.RateLimiter) testAPIOption {
+	return func(c *testAPIConfig) { c.userLimiter = limiter }
+}
+
+func newTestAPI(t *testing.T, opts ...testAPIOption) *testAPI {
+	t.Helper()
+	cfg := testAPIConfig{
+		http: config.HTTP{
+			Env:               "test",
+			AllowedOrigins:    "*",
+			MaxBatchSize:      100,
+			ReadinessTimeout:  time.Second,
+			DebugBodyMaxBytes: 1024,
+		},
+		pagination:  config.Pagination{DefaultLimit: 20, MaxLimit: 100},
+		userService: config.UserService{Timeout: time.Second, BreakerFailures: 5, BreakerOpenTimeout: time.Minute},
+		warnPercent: 80,
+		userLimiter: unlimited{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	api := &testAPI{
+		t:       t,
+		store:   portstest.NewStore(),
+		cache:   portstest.NewCache(),
+		logger:  &portstest.Logger{},
+		metrics: &portstest.Metrics{},
+		users:   make(map[uuid.UUID]UserResponseInfo),
+	}
+
+	validate := validator.New()
+	if err := services.RegisterValidators(validate); err != nil {
+		t.Fatalf("RegisterValidators: %v", err)
+	}
+	api.bikeService = services.NewBikeService(api.store, api.store, api.logger, validate, api.cache, api.store, api.store, api.store, api.metrics)
+	api.componentService = services.NewComponentService(api.store, api.store, api.logger, validate, api.cache, api.store, api.store, api.store, cfg.strictYear)
+	webhookService := services.NewWebhookService(api.store, api.logger, validate, false)
+	statsService := services.NewStatsService(fleetStats{}, api.logger, api.cache)
+
+	api.tokens = NewJWTTokenService([]string{testJWTSecret}, []string{"HS256"}, 0, api.cache, false, api.logger)
+	api.bikeHandler = NewBikeHandler(api.bikeService, api.logger, api.metrics, nil, &cfg.userService, &cfg.pagination, api.cache, "https://bikes.example.com", cfg.http.MaxBatchSize, cfg.warnPercent)
+	api.bikeHandler.getUser = api.getUser
+	api.maintenance = NewMaintenance(false, api.cache, api.logger, api.metrics)
+
+	router, err := NewRouter(
+		&cfg.http,
+		api.tokens,
+		NewAPIKeyService(&cfg.apiKeys, api.logger),
+		api.logger,
+		api.metrics,
+		api.bikeHandler,
+		NewComponentHandler(api.componentService, api.bikeService, api.logger, api.metrics, &cfg.pagination, cfg.http.MaxBatchSize, cfg.warnPercent),
+		NewWebhookHandler(webhookService, api.logger, api.metrics),
+		NewStatsHandler(statsService, api.logger, api.metrics, cfg.warnPercent),
+		api.maintenance,
+		NewTokenHandler(api.tokens, api.logger, api.metrics),
+		nil,
+		cfg.userLimiter,
+		unlimited{},
+	)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	api.router = router
+	return api
+}
+
+// getUser отвечает как user-service: известный пользователь или 404.
+// Payload собирается из JSON, как его разбирает сгенерированный клиент
+func (api *testAPI) getUser(params *users.GetUsersIDParams, _ runtime.ClientAuthInfoWriter) (*users.GetUsersIDOK, error) {
+	id, err := uuid.Parse(params.ID)
+	if err != nil {
+		return nil, runtime.NewAPIError("getUsersIdNotFound", nil, http.StatusNotFound)
+	}
+	user, ok := api.users[id]
+	if !ok {
+		return nil, runtime.NewAPIError("getUsersIdNotFound", nil, http.StatusNotFound)
+	}
+	data, err := json.Marshal(map[string]any{"Payload": map[string]string{
+		"id": user.ID, "name": user.Name, "email": user.Email, "role": user.Role,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	var resp users.GetUsersIDOK
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// addUser регистрирует пользователя в поддельном user-service
+func (api *testAPI) addUser(id uuid.UUID, name string) {
+	api.users[id] = UserResponseInfo{ID: id.String(), Name: name, Email: name + "@example.com", Role: string(domain.AppUser)}
+}
+
+// token - подписанный access-токен на час
+func (api *testAPI) token(userID uuid.UUID, role domain.UserRole) string {
+	return signTestToken(api.t, testJWTSecret, jwt.MapClaims{
+		"id":      uuid.NewString(),
+		"user_id": userID.String(),
+		"role":    string(role),
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+func signTestToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// do отправляет запрос с токеном (пустой - без авторизации). body - строка
+// с JSON как есть или значение для json.Marshal
+func (api *testAPI) do(method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	api.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			api.t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	api.router.Engine().ServeHTTP(w, req)
+	return w
+}
+
+// addBike - байк пользователя с пробегом mileage, созданный сутки назад
+func (api *testAPI) addBike(userID uuid.UUID, mileage int) *domain.Bike {
+	return api.store.AddBike(&domain.Bike{
+		UserID:    userID,
+		BikeName:  "Trail",
+		Type:      domain.MTB,
+		Model:     "Stumpjumper",
+		Mileage:   mileage,
+		CreatedAt: time.Now().Add(-24 * time.Hour),
+	})
+}
+
+// addComponent - компонент байка с порогом 5000 км, установленный на пробеге installed
+func (api *testAPI) addComponent(bike *domain.Bike, name domain.ComponentName, installed int) *domain.Component {
+	return api.store.AddComponent(&domain.Component{
+		BikeID:           bike.BikeID,
+		Name:             name,
+		InstalledMileage: installed,
+		MaxMileage:       5000,
+		InstalledAt:      bike.CreatedAt,
+	})
+}
+
+// decode разбирает JSON ответа, падая на ошибке
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return v
+}
+
+// expectStatus сверяет код ответа и показывает тело при расхождении
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, want, w.Body.String())
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// unlimited - лимитер без ограничений
+type unlimited struct{}
+
+func (unlimited) Allow(context.Context, string) (ports.RateLimitQuota, error) {
+	return ports.RateLimitQuota{Allowed: true, Limit: 1000, Remaining: 1000}, nil
+}
+
+// fleetStats - пустая сводка вместо StatsRepository
+type fleetStats struct{}
+
+func (fleetStats) GetFleetStats(context.Context, int) (*domain.FleetStats, error) {
+	return &domain.FleetStats{}, nil
+}
+
+// exhausted - лимитер с пустой корзиной: отказывает всем
+type exhausted struct{}
+
+func (exhausted) Allow(context.Context, string) (ports.RateLimitQuota, error) {
+	return ports.RateLimitQuota{Limit: 1, Retry: time.Second, Reset: time.Second}, nil
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler - отзыв JWT до истечения срока (выход, компрометация)
+type TokenHandler struct {
+	tokenService *JWTTokenService
+	logger       ports.LoggerPort
+	metrics      ports.MetricsPort
+}
+
+type RevokeTokenRequest struct {
+	Token string `json:"token" binding:"required,notblank" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+type RevokeTokenResponse struct {
+	TokenID   string    `json:"token_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewTokenHandler(tokenService *JWTTokenService, logger ports.LoggerPort, metrics ports.MetricsPort) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+		logger:       logger,
+		metrics:      metrics,
+	}
+}
+
+// @Summary Отозвать токен
+// @Description Только для админов. Токен перестаёт приниматься сразу, а не по exp. Отзыв хранится в redis до exp токена. Повторный отзыв ничего не меняет
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RevokeTokenRequest true "Токен, который нужно отозвать"
+// @Success 200 {object} RevokeTokenResponse "Токен отозван"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Нужны права админа"
+// @Failure 422 {object} errorResponse "Токен не наш, битый или уже истёк"
+// @Failure 503 {object} errorResponse "Кеш недоступен, отзыв не сохранён"
+// @Router /admin/tokens/revoke [post]
+func (h *TokenHandler) RevokeToken(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	tokenID, expiresAt, err := h.tokenService.RevokeToken(req.Token)
+	if err != nil {
+		if errors.Is(err, ErrTokenExpired) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, "Token already expired, nothing to revoke")
+			return
+		}
+		if errors.Is(err, ErrInvalidToken) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).Error("Failed to store revoked token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		newErrorResponse(c, http.StatusServiceUnavailable, "Failed to revoke token")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Token revoked", map[string]interface{}{
+		"audit":        "token_revoke",
+		"requester_id": payload.UserID.String(),
+		"token_id":     tokenID,
+		"expires_at":   expiresAt,
+	})
+
+	c.JSON(http.StatusOK, RevokeTokenResponse{
+		TokenID:   tokenID,
+		ExpiresAt: expiresAt,
+	})
+}
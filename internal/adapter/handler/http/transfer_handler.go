@@ -0,0 +1,154 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TransferBikeRequest struct {
+	NewUserID string `json:"new_user_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+// @Summary Передать байк другому пользователю
+// @Description Меняет владельца байка одной транзакцией, компоненты и их история переезжают вместе с байком. Новый владелец проверяется в user-service. Отправляется событие bike.transferred
+// @Tags bikes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID байка" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Param request body TransferBikeRequest true "Новый владелец"
+// @Success 200 {object} BikeInfo "Байк передан"
+// @Failure 400 {object} errorResponse "Неверный ID или байк уже принадлежит этому пользователю"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Байк не найден"
+// @Failure 409 {object} errorResponse "Владельца байка успели сменить"
+// @Failure 422 {object} errorResponse "Новый владелец не найден"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} errorResponse "User-service недоступен"
+// @Router /bikes/{id}/transfer [post]
+func (h *BikeHandler) TransferBike(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	bikeID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to TransferBike", map[string]interface{}{
+			"bike_id": bikeID,
+			"ip":      c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parsedID, err := uuid.Parse(bikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid bike ID")
+		return
+	}
+
+	var req TransferBikeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in transfer bike", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+	newUserID, err := uuid.Parse(req.NewUserID)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid new user ID")
+		return
+	}
+
+	existingBike, err := h.bikeService.GetBikeByIDForWrite(c.Request.Context(), bikeID)
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != existingBike.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to transfer bike", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"bike_owner":   existingBike.UserID.String(),
+			"bike_id":      bikeID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	// не ходим в user-service, если передавать некому
+	if existingBike.UserID == newUserID {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrSameOwner.Error())
+		return
+	}
+	if !h.checkUserExists(c, newUserID) {
+		return
+	}
+
+	// пока шёл запрос в user-service, владельца могли сменить: сервис
+	// передаст байк, только если владелец всё ещё тот же
+	bike, err := h.bikeService.TransferOwnership(c.Request.Context(), parsedID, existingBike.UserID, newUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSameOwner):
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrOwnerChanged):
+			newErrorResponse(c, http.StatusConflict, "Bike owner has changed, retry the request")
+		case errors.Is(err, domain.ErrBikeNotFound):
+			newErrorResponse(c, http.StatusNotFound, "Bike not found")
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "Failed to transfer bike")
+		}
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Bike ownership transferred", map[string]interface{}{
+		"audit":            "bike_transfer",
+		"requester_id":     payload.UserID.String(),
+		"bike_id":          bikeID,
+		"previous_user_id": existingBike.UserID.String(),
+		"new_user_id":      newUserID.String(),
+	})
+
+	c.JSON(http.StatusOK, newBikeInfo(bike))
+}
+
+// checkUserExists спрашивает user-service, есть ли пользователь. В отличие от
+// lookupUser сбой здесь не прощается: передать байк непроверенному владельцу нельзя.
+// При false ответ уже записан
+func (h *BikeHandler) checkUserExists(c *gin.Context, userID uuid.UUID) bool {
+	resp, err := h.fetchUser(c, userID)
+	if err != nil {
+		reason := enrichmentFailureReason(err)
+		if isBreakerOpen(err) {
+			reason = enrichmentCircuitOpen
+		}
+		if reason == enrichmentNotFound {
+			newErrorResponse(c, http.StatusUnprocessableEntity, "New owner not found")
+			return false
+		}
+		h.logger.WithContext(c.Request.Context()).Warn("Failed to check user in user-service", map[string]interface{}{
+			"error":   err.Error(),
+			"reason":  reason,
+			"user_id": userID.String(),
+		})
+		newErrorResponse(c, http.StatusServiceUnavailable, "Failed to verify new owner")
+		return false
+	}
+	if resp == nil || resp.Payload == nil || resp.Payload.ID != userID.String() {
+		newErrorResponse(c, http.StatusServiceUnavailable, "Failed to verify new owner")
+		return false
+	}
+	return true
+}
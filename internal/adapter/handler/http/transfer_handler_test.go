@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/uuid"
+	"github.com/sm8ta/webike_user_microservice_nikita/pkg/client/users"
+)
+
+func TestTransferBike(t *testing.T) {
+	owner, stranger, admin, newOwner := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		requester uuid.UUID
+		role      domain.UserRole
+		to        uuid.UUID
+		// known - новый владелец известен user-service
+		known      bool
+		wantStatus int
+		wantOwner  uuid.UUID
+	}{
+		{name: "владелец передаёт байк", requester: owner, role: domain.AppUser, to: newOwner, known: true, wantStatus: http.StatusOK, wantOwner: newOwner},
+		{name: "передача самому себе", requester: owner, role: domain.AppUser, to: owner, known: true, wantStatus: http.StatusBadRequest, wantOwner: owner},
+		{name: "чужой байк", requester: stranger, role: domain.AppUser, to: stranger, known: true, wantStatus: http.StatusForbidden, wantOwner: owner},
+		{name: "админ передаёт чужой байк", requester: admin, role: domain.Admin, to: newOwner, known: true, wantStatus: http.StatusOK, wantOwner: newOwner},
+		{name: "админ передаёт текущему владельцу", requester: admin, role: domain.Admin, to: owner, known: true, wantStatus: http.StatusBadRequest, wantOwner: owner},
+		{name: "новый владелец неизвестен", requester: owner, role: domain.AppUser, to: newOwner, wantStatus: http.StatusUnprocessableEntity, wantOwner: owner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+			if tt.known {
+				api.addUser(tt.to, "rider")
+			}
+
+			w := api.do(http.MethodPost, "/bikes/"+bike.BikeID.String()+"/transfer", api.token(tt.requester, tt.role),
+				TransferBikeRequest{NewUserID: tt.to.String()})
+			expectStatus(t, w, tt.wantStatus)
+
+			stored, _ := api.store.Bike(bike.BikeID)
+			if stored.UserID != tt.wantOwner {
+				t.Errorf("owner = %s, want %s", stored.UserID, tt.wantOwner)
+			}
+			events := api.store.EventTypes()
+			if tt.wantStatus == http.StatusOK && (len(events) != 1 || events[0] != domain.BikeTransferred) {
+				t.Errorf("events = %v, want [%s]", events, domain.BikeTransferred)
+			}
+			if tt.wantStatus != http.StatusOK && len(events) != 0 {
+				t.Errorf("events = %v, want none", events)
+			}
+		})
+	}
+}
+
+// владелец сменился между проверкой доступа и UPDATE, пока шёл запрос в
+// user-service: передача не должна пройти по уже прочитанному владельцу
+func TestTransferBikeOwnerChanged(t *testing.T) {
+	api := newTestAPI(t)
+	owner, other, newOwner := uuid.New(), uuid.New(), uuid.New()
+	bike := api.addBike(owner, 100)
+	api.addUser(newOwner, "rider")
+	token := api.token(owner, domain.AppUser)
+
+	api.bikeHandler.getUser = func(params *users.GetUsersIDParams, authInfo runtime.ClientAuthInfoWriter) (*users.GetUsersIDOK, error) {
+		changed, _ := api.store.Bike(bike.BikeID)
+		changed.UserID = other
+		api.store.AddBike(changed)
+		return api.getUser(params, authInfo)
+	}
+
+	w := api.do(http.MethodPost, "/bikes/"+bike.BikeID.String()+"/transfer", token, TransferBikeRequest{NewUserID: newOwner.String()})
+	expectStatus(t, w, http.StatusConflict)
+
+	if stored, _ := api.store.Bike(bike.BikeID); stored.UserID != other {
+		t.Errorf("owner = %s, want %s", stored.UserID, other)
+	}
+	if events := api.store.EventTypes(); len(events) != 0 {
+		t.Errorf("events = %v, want none", events)
+	}
+}
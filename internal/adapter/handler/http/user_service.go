@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sm8ta/webike_user_microservice_nikita/pkg/client/users"
+	"github.com/sony/gobreaker/v2"
+)
+
+// имя breaker'а - метка name в circuit_breaker_state
+const userServiceBreaker = "user_service"
+
+// newUserServiceBreaker размыкает цепь после cfg.BreakerFailures сбоев подряд:
+// пока она открыта, запросы в user-service не уходят вовсе. 404 - нормальный
+// ответ, а отмена запроса клиентом ничего не говорит о здоровье сервиса
+func newUserServiceBreaker(cfg *config.UserService, metrics ports.MetricsPort) *gobreaker.CircuitBreaker[*users.GetUsersIDOK] {
+	metrics.SetCircuitBreakerState(userServiceBreaker, int(gobreaker.StateClosed))
+
+	return gobreaker.NewCircuitBreaker[*users.GetUsersIDOK](gobreaker.Settings{
+		Name:    userServiceBreaker,
+		Timeout: cfg.BreakerOpenTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= uint32(cfg.BreakerFailures)
+		},
+		IsSuccessful: func(err error) bool {
+			return err == nil || enrichmentFailureReason(err) == enrichmentNotFound
+		},
+		IsExcluded: func(err error) bool {
+			return errors.Is(err, context.Canceled)
+		},
+		OnStateChange: func(name string, _, to gobreaker.State) {
+			metrics.SetCircuitBreakerState(name, int(to))
+		},
+	})
+}
+
+// isBreakerOpen - запрос не отправлялся, потому что цепь разомкнута
+func isBreakerOpen(err error) bool {
+	return errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests)
+}
+
+// fetchUser запрашивает пользователя у user-service через breaker, не дольше
+// userTimeout и не дольше, чем живёт сам запрос
+func (h *BikeHandler) fetchUser(c *gin.Context, userID uuid.UUID) (*users.GetUsersIDOK, error) {
+	return h.userBreaker.Execute(func() (*users.GetUsersIDOK, error) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), h.userTimeout)
+		defer cancel()
+
+		params := users.NewGetUsersIDParams()
+		params.ID = userID.String()
+		params.Context = ctx
+
+		return h.getUser(params, userServiceAuth(c))
+	})
+}
+
+// UserServiceReadiness - проверка user-service для /ready. Пока breaker запросов
+// за пользователем разомкнут, сеть не трогаем. Сама проверка в breaker не
+// идёт: удачный TCP-пробник сбрасывал бы счётчик сбоев сервиса, который
+// принимает соединения, но отвечает 5xx, а в полуоткрытом состоянии занимал бы
+// единственный пробный запрос. Без user-service байки работают, только /me и
+// with-user отдают user: null, поэтому по умолчанию сбой делает сервис
+// degraded, а не 503. USER_SERVICE_READINESS_CRITICAL=true делает его обязательным
+func (h *BikeHandler) UserServiceReadiness(check func(ctx context.Context) error) ReadinessCheck {
+	return ReadinessCheck{
+		Name:     userServiceBreaker,
+		Optional: !h.userCritical,
+		Check: func(ctx context.Context) error {
+			if h.userBreaker.State() == gobreaker.StateOpen {
+				return gobreaker.ErrOpenState
+			}
+			ctx, cancel := context.WithTimeout(ctx, h.userTimeout)
+			defer cancel()
+			return check(ctx)
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// notBlank отклоняет строки, состоящие только из пробелов
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// jsonFieldName - в ошибках валидации поля называем так же, как в JSON
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" || name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func registerValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v.RegisterValidation("notblank", notBlank)
+}
+
+// bindError отвечает на ошибку ShouldBindJSON: битый JSON - 400,
+// корректный JSON с недопустимыми значениями или типами полей - 422
+func bindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, fmt.Sprintf("%s failed on '%s'", fe.Field(), fe.Tag()))
+		}
+		newErrorResponse(c, http.StatusUnprocessableEntity, "Validation failed: "+strings.Join(fields, "; "))
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		newErrorResponse(c, http.StatusUnprocessableEntity, fmt.Sprintf("Validation failed: %s must be %s", typeErr.Field, typeErr.Type))
+		return
+	}
+
+	newErrorResponse(c, http.StatusBadRequest, "Invalid JSON format")
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestBlankRequiredStrings(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name   string
+		method string
+		// {bike} и {component} в path и body заменяются на ID
+		path       string
+		body       string
+		wantStatus int
+		wantField  string
+	}{
+		{name: "модель байка из пробелов", method: http.MethodPost, path: "/bikes", body: `{"model":"   ","type":"mtb","mileage":10}`, wantStatus: http.StatusUnprocessableEntity, wantField: "model"},
+		{name: "тип байка из пробелов", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":" \t ","mileage":10}`, wantStatus: http.StatusUnprocessableEntity, wantField: "type"},
+		{name: "байк с нормальными полями", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":"mtb","mileage":10}`, wantStatus: http.StatusCreated},
+		{name: "обновление модели пробелами", method: http.MethodPut, path: "/bikes/{bike}", body: `{"model":"  "}`, wantStatus: http.StatusUnprocessableEntity, wantField: "model"},
+		{name: "имя компонента из пробелов", method: http.MethodPost, path: "/components", body: `{"bike_id":"{bike}","name":"   ","installed_mileage":1}`, wantStatus: http.StatusUnprocessableEntity, wantField: "name"},
+		{name: "PATCH имени компонента пробелами", method: http.MethodPatch, path: "/components/{component}", body: `{"name":" "}`, wantStatus: http.StatusUnprocessableEntity, wantField: "name"},
+		{name: "PUT имени компонента пробелами", method: http.MethodPut, path: "/components/{component}", body: `{"name":" ","installed_mileage":1,"max_mileage":100}`, wantStatus: http.StatusUnprocessableEntity, wantField: "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+			component := api.addComponent(bike, domain.Handlebars, 0)
+			replacer := strings.NewReplacer("{bike}", bike.BikeID.String(), "{component}", component.ID.String())
+
+			w := api.do(tt.method, replacer.Replace(tt.path), api.token(owner, domain.AppUser), replacer.Replace(tt.body))
+			expectStatus(t, w, tt.wantStatus)
+			if tt.wantField == "" {
+				return
+			}
+			resp := decode[errorResponse](t, w)
+			if want := tt.wantField + " failed on 'notblank'"; !strings.Contains(resp.Message, want) {
+				t.Errorf("message = %q, want it to contain %q", resp.Message, want)
+			}
+		})
+	}
+}
+
+// 400 - тело не разбирается как JSON, 422 - JSON корректный, но значения нет
+func TestMalformedVersusInvalidBody(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name   string
+		method string
+		// {bike} и {component} в path и body заменяются на ID
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "создание байка: обрезанный JSON", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek",`, wantStatus: http.StatusBadRequest},
+		{name: "создание байка: не JSON", method: http.MethodPost, path: "/bikes", body: `model=Trek`, wantStatus: http.StatusBadRequest},
+		{name: "создание байка: пустое тело", method: http.MethodPost, path: "/bikes", body: ``, wantStatus: http.StatusBadRequest},
+		{name: "создание байка: строка вместо числа", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":"mtb","mileage":"ten"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "создание байка: нет обязательного поля", method: http.MethodPost, path: "/bikes", body: `{"type":"mtb","mileage":10}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "создание байка: неизвестный тип", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":"unicycle","mileage":10}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "создание байка: год вне диапазона", method: http.MethodPost, path: "/bikes", body: `{"model":"Trek","type":"mtb","mileage":10,"year":1700}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "обновление байка: обрезанный JSON", method: http.MethodPut, path: "/bikes/{bike}", body: `{"model":`, wantStatus: http.StatusBadRequest},
+		{name: "обновление байка: строка вместо числа", method: http.MethodPut, path: "/bikes/{bike}", body: `{"mileage":"ten"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "обновление байка: неизвестный тип", method: http.MethodPut, path: "/bikes/{bike}", body: `{"type":"unicycle"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "создание компонента: обрезанный JSON", method: http.MethodPost, path: "/components", body: `{"bike_id":"{bike}"`, wantStatus: http.StatusBadRequest},
+		{name: "создание компонента: строка вместо числа", method: http.MethodPost, path: "/components", body: `{"bike_id":"{bike}","name":"frame","installed_mileage":"one"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "создание компонента: отрицательный порог", method: http.MethodPost, path: "/components", body: `{"bike_id":"{bike}","name":"frame","installed_mileage":1,"max_mileage":-5}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "создание компонента: неизвестная позиция", method: http.MethodPost, path: "/components", body: `{"bike_id":"{bike}","name":"wheels","installed_mileage":1,"position":"middle"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "PATCH компонента: обрезанный JSON", method: http.MethodPatch, path: "/components/{component}", body: `{"brand":`, wantStatus: http.StatusBadRequest},
+		{name: "PATCH компонента: строка вместо числа", method: http.MethodPatch, path: "/components/{component}", body: `{"max_mileage":"many"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "PUT компонента: обрезанный JSON", method: http.MethodPut, path: "/components/{component}", body: `[`, wantStatus: http.StatusBadRequest},
+		{name: "PUT компонента: нет обязательного поля", method: http.MethodPut, path: "/components/{component}", body: `{"name":"frame","max_mileage":100}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			bike := api.addBike(owner, 100)
+			component := api.addComponent(bike, domain.Handlebars, 0)
+			replacer := strings.NewReplacer("{bike}", bike.BikeID.String(), "{component}", component.ID.String())
+
+			w := api.do(tt.method, replacer.Replace(tt.path), api.token(owner, domain.AppUser), replacer.Replace(tt.body))
+			expectStatus(t, w, tt.wantStatus)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	logger         ports.LoggerPort
+	metrics        ports.MetricsPort
+}
+
+type WebhookRequest struct {
+	URL        string   `json:"url" binding:"required,notblank" example:"https://example.com/hooks/bikes"`
+	EventTypes []string `json:"event_types" binding:"required,min=1" example:"bike.created,component.updated"`
+	Secret     string   `json:"secret" binding:"required,min=16" example:"a-long-shared-secret"`
+}
+
+type GetWebhooksResponse struct {
+	Webhooks []*domain.Webhook `json:"webhooks"`
+	Count    int               `json:"count"`
+}
+
+func NewWebhookHandler(
+	webhookService *services.WebhookService,
+	logger ports.LoggerPort,
+	metrics ports.MetricsPort,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         logger,
+		metrics:        metrics,
+	}
+}
+
+// @Summary Зарегистрировать вебхук
+// @Description Подписка на события байков и компонентов пользователя. Каждый запрос подписывается HMAC-SHA256 в заголовке X-Webhook-Signature. Хост URL - доменное имя: IP-адреса и localhost отклоняются, доставка на внутренние адреса и по редиректам не выполняется
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequest true "Данные вебхука"
+// @Success 201 {object} domain.Webhook "Вебхук создан"
+// @Failure 400 {object} errorResponse "Некорректный JSON"
+// @Failure 422 {object} errorResponse "Недопустимые значения полей"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to CreateWebhook", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed JSON parse in create webhook", map[string]interface{}{
+			"error": err.Error(),
+		})
+		bindError(c, err)
+		return
+	}
+
+	eventTypes := make([]domain.EventType, len(req.EventTypes))
+	for i, t := range req.EventTypes {
+		eventTypes[i] = domain.EventType(t)
+	}
+
+	webhook := &domain.Webhook{
+		UserID:     payload.UserID,
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     req.Secret,
+	}
+
+	createdWebhook, err := h.webhookService.CreateWebhook(c.Request.Context(), webhook)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to create webhook", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": payload.UserID,
+		})
+		if errors.Is(err, services.ErrInvalidWebhook) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	newSuccessResponse(c, http.StatusCreated, "Webhook created successfully", createdWebhook)
+}
+
+// @Summary Получить мои вебхуки
+// @Description Список вебхуков авторизованного пользователя
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} GetWebhooksResponse "Список вебхуков"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 500 {object} errorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetMyWebhooks(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetMyWebhooks", map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooksByUserID(c.Request.Context(), payload.UserID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Failed to get webhooks")
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*domain.Webhook{}
+	}
+
+	c.JSON(http.StatusOK, GetWebhooksResponse{
+		Webhooks: webhooks,
+		Count:    len(webhooks),
+	})
+}
+
+// @Summary Получить вебхук
+// @Description Получение вебхука по ID
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID вебхука" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} domain.Webhook "Вебхук найден"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Вебхук не найден"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	webhookID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to GetWebhook", map[string]interface{}{
+			"webhook_id": webhookID,
+			"ip":         c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhookByID(c.Request.Context(), webhookID)
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != webhook.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to webhook", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"owner_id":     webhook.UserID.String(),
+			"webhook_id":   webhookID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, "Webhook found", webhook)
+}
+
+// @Summary Удалить вебхук
+// @Description Отписка от событий
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID вебхука" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"
+// @Success 200 {object} successResponse "Вебхук удален"
+// @Failure 401 {object} errorResponse "Не авторизован"
+// @Failure 403 {object} errorResponse "Доступ запрещен"
+// @Failure 404 {object} errorResponse "Вебхук не найден"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		h.metrics.RecordMetrics(c, start)
+	}()
+
+	webhookID := c.Param("id")
+
+	payload, exists := getAuthPayload(c, "authorization_payload")
+	if !exists {
+		h.logger.WithContext(c.Request.Context()).Warn("Unauthorized access attempt to DeleteWebhook", map[string]interface{}{
+			"webhook_id": webhookID,
+			"ip":         c.ClientIP(),
+		})
+		newErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhookByID(c.Request.Context(), webhookID)
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	if payload.Role != domain.Admin && payload.UserID != webhook.UserID {
+		h.logger.WithContext(c.Request.Context()).Warn("Access denied to delete webhook", map[string]interface{}{
+			"requester_id": payload.UserID.String(),
+			"owner_id":     webhook.UserID.String(),
+			"webhook_id":   webhookID,
+		})
+		newErrorResponse(c, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), webhookID); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "Delete failed")
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, "Webhook deleted successfully", nil)
+}
@@ -0,0 +1,84 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// EventTypeHeader и UserIDHeader дублируют поля события, чтобы
+	// потребители могли фильтровать сообщения без разбора JSON
+	EventTypeHeader = "event-type"
+	UserIDHeader    = "user-id"
+
+	writeTimeout = 10 * time.Second
+
+	metricsSink = "kafka"
+)
+
+// Publisher пишет доменные события в топик Kafka. Ключ сообщения - ID байка,
+// так что события одного байка попадают в одну партицию и читаются по порядку
+type Publisher struct {
+	writer  *kafka.Writer
+	metrics ports.MetricsPort
+}
+
+func NewPublisher(brokers []string, topic string, metrics ports.MetricsPort) *Publisher {
+	return &Publisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			WriteTimeout: writeTimeout,
+		},
+		metrics: metrics,
+	}
+}
+
+// Publish пишет синхронно: ошибка возвращается релею outbox, и событие
+// уйдёт повторно на следующем проходе. Запрос, породивший событие, от этого не падает
+func (p *Publisher) Publish(ctx context.Context, event *domain.Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+
+	headers := []kafka.Header{
+		{Key: EventTypeHeader, Value: []byte(event.Type)},
+	}
+	// у событий компонентов удалённого байка владельца нет, нулевой UUID
+	// в заголовке выглядел бы как настоящий пользователь
+	if event.UserID != uuid.Nil {
+		headers = append(headers, kafka.Header{Key: UserIDHeader, Value: []byte(event.UserID.String())})
+	}
+
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.BikeID.String()),
+		Value:   value,
+		Headers: headers,
+		Time:    event.OccurredAt,
+	})
+	p.metrics.RecordEventPublish(metricsSink, err == nil)
+	if err != nil {
+		// в лог ошибку пишет релей вместе с outbox_id и номером попытки
+		return fmt.Errorf("failed to publish event to kafka: %w", err)
+	}
+	return nil
+}
+
+// Close дописывает буфер и закрывает соединения с брокерами
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 )
 
@@ -72,7 +73,23 @@ func (l *LoggerAdapter) Warn(msg string, fields map[string]interface{}) {
 	l.logger.Warn(msg, slog.Any("fields", fields))
 }
 
+func (l *LoggerAdapter) WithContext(ctx context.Context) ports.LoggerPort {
+	return l.withRequestID(ctx)
+}
+
+// withRequestID - копия логгера с request_id из ctx, вне запроса - тот же логгер
+func (l *LoggerAdapter) withRequestID(ctx context.Context) *LoggerAdapter {
+	id := domain.RequestIDFromContext(ctx)
+	if id == "" {
+		return l
+	}
+	return &LoggerAdapter{
+		logger: l.logger.With(slog.String("request_id", id)),
+	}
+}
+
 func (l *LoggerAdapter) InfoGRPC(ctx context.Context, msg string, fields any) {
+	l = l.withRequestID(ctx)
 	if fields == nil {
 		l.logger.InfoContext(ctx, msg)
 		return
@@ -81,6 +98,7 @@ func (l *LoggerAdapter) InfoGRPC(ctx context.Context, msg string, fields any) {
 }
 
 func (l *LoggerAdapter) ErrorGRPC(ctx context.Context, msg string, fields any) {
+	l = l.withRequestID(ctx)
 	if fields == nil {
 		l.logger.ErrorContext(ctx, msg)
 		return
@@ -89,6 +107,7 @@ func (l *LoggerAdapter) ErrorGRPC(ctx context.Context, msg string, fields any) {
 }
 
 func (l *LoggerAdapter) DebugGRPC(ctx context.Context, msg string, fields any) {
+	l = l.withRequestID(ctx)
 	if fields == nil {
 		l.logger.DebugContext(ctx, msg)
 		return
@@ -97,6 +116,7 @@ func (l *LoggerAdapter) DebugGRPC(ctx context.Context, msg string, fields any) {
 }
 
 func (l *LoggerAdapter) WarnGRPC(ctx context.Context, msg string, fields any) {
+	l = l.withRequestID(ctx)
 	if fields == nil {
 		l.logger.WarnContext(ctx, msg)
 		return
@@ -0,0 +1,122 @@
+package memcache
+
+import (
+	"container/list"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+var ErrCacheMiss = ports.ErrCacheMiss
+
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache - LRU-кеш в памяти процесса с TTL и лимитом записей.
+// Нужен как запасной вариант, когда redis недоступен
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	e := el.Value.(*entry)
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		m.remove(el)
+		return nil, ErrCacheMiss
+	}
+
+	m.order.MoveToFront(el)
+	return e.value, nil
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := m.items[key]; ok {
+		e := el.Value.(*entry)
+		e.value = value
+		e.expiresAt = expiresAt
+		m.order.MoveToFront(el)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	// выкидываем самые давно использованные записи
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+func (m *MemoryCache) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+	return nil
+}
+
+// DeletePattern понимает тот же glob, что и redis SCAN MATCH для наших ключей (*, ?, [..])
+func (m *MemoryCache) DeletePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, el := range m.items {
+		if ok, _ := path.Match(pattern, key); ok {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+// Purge очищает кеш целиком
+func (m *MemoryCache) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]*list.Element)
+	m.order.Init()
+}
+
+func (m *MemoryCache) remove(el *list.Element) {
+	m.order.Remove(el)
+	delete(m.items, el.Value.(*entry).key)
+}
+
+var _ ports.CachePort = (*MemoryCache)(nil)
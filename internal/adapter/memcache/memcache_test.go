@@ -0,0 +1,115 @@
+package memcache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		// touch - ключ, прочитанный перед вставкой лишнего
+		touch    string
+		wantGone string
+	}{
+		{name: "вытесняется самый старый", wantGone: "a"},
+		{name: "чтение продлевает жизнь записи", touch: "a", wantGone: "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewMemoryCache(3)
+			for _, key := range []string{"a", "b", "c"} {
+				if err := cache.Set(key, []byte(key), 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.touch != "" {
+				if _, err := cache.Get(tt.touch); err != nil {
+					t.Fatalf("Get(%s): %v", tt.touch, err)
+				}
+			}
+			if err := cache.Set("d", []byte("d"), 0); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, key := range []string{"a", "b", "c", "d"} {
+				_, err := cache.Get(key)
+				if gone := errors.Is(err, ErrCacheMiss); gone != (key == tt.wantGone) {
+					t.Errorf("Get(%s) err = %v, want evicted: %t", key, err, key == tt.wantGone)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	cache := NewMemoryCache(10)
+	if err := cache.Set("short", []byte("v"), 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Set("forever", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Get("short"); err != nil {
+		t.Fatalf("Get before expiry: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := cache.Get("short"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get after expiry err = %v, want ErrCacheMiss", err)
+	}
+	if _, err := cache.Get("forever"); err != nil {
+		t.Errorf("Get without TTL: %v", err)
+	}
+	// истёкшая запись не должна занимать место в LRU
+	if cache.order.Len() != 1 {
+		t.Errorf("entries = %d, want 1", cache.order.Len())
+	}
+}
+
+func TestMemoryCacheDeletePattern(t *testing.T) {
+	cache := NewMemoryCache(10)
+	for _, key := range []string{"u:a:profile", "u:a:bikes:list:1", "u:b:profile", "bike:1"} {
+		if err := cache.Set(key, []byte("v"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.DeletePattern("u:a:*"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"u:a:profile": false, "u:a:bikes:list:1": false, "u:b:profile": true, "bike:1": true} {
+		if _, err := cache.Get(key); (err == nil) != want {
+			t.Errorf("Get(%s) err = %v, want present: %t", key, err, want)
+		}
+	}
+	if err := cache.DeletePattern("u:[a"); err == nil {
+		t.Error("malformed pattern accepted")
+	}
+}
+
+func TestMemoryCacheConcurrent(t *testing.T) {
+	cache := NewMemoryCache(50)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				key := fmt.Sprintf("k:%d:%d", g, i%20)
+				_ = cache.Set(key, []byte("v"), time.Minute)
+				_, _ = cache.Get(key)
+				if i%50 == 0 {
+					_ = cache.DeletePattern(fmt.Sprintf("k:%d:*", g))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := cache.order.Len(); n > 50 || n != len(cache.items) {
+		t.Errorf("entries = %d (index %d), want at most 50 and consistent", n, len(cache.items))
+	}
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (r *BikeRepository) GetBikesByIDs(ctx context.Context, bike_ids []uuid.UUID) ([]*domain.Bike, error) {
+	query := `SELECT user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at
+		FROM bikes WHERE bike_id = ANY($1) AND deleted_at IS NULL`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(bike_ids))
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var bikes []*domain.Bike
+	for rows.Next() {
+		bike := &domain.Bike{}
+		err := rows.Scan(
+			&bike.UserID,
+			&bike.BikeID,
+			&bike.BikeName,
+			&bike.Type,
+			&bike.Model,
+			&bike.Year,
+			&bike.Mileage,
+			&bike.CreatedAt,
+			&bike.UpdatedAt,
+			&bike.ArchivedAt,
+		)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		bikes = append(bikes, bike)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bikes, nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// ListBikes - все байки под админским фильтром. Значения фильтра идут только
+// параметрами, в текст запроса попадают лишь условия и колонки сортировки отсюда
+func (r *BikeRepository) ListBikes(ctx context.Context, filter domain.BikeListFilter) ([]*domain.Bike, error) {
+	query := `SELECT user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at, deleted_at
+		FROM bikes WHERE true`
+	query, args := appendBikeListFilter(query, nil, filter)
+	query += " ORDER BY " + bikeSortColumns[filter.Sort] + ", bike_id"
+	query, args = appendPage(query, args, filter.Page)
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	bikes := []*domain.Bike{}
+	for rows.Next() {
+		bike := &domain.Bike{}
+		err := rows.Scan(
+			&bike.UserID,
+			&bike.BikeID,
+			&bike.BikeName,
+			&bike.Type,
+			&bike.Model,
+			&bike.Year,
+			&bike.Mileage,
+			&bike.CreatedAt,
+			&bike.UpdatedAt,
+			&bike.ArchivedAt,
+			&bike.DeletedAt,
+		)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		bikes = append(bikes, bike)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bikes, nil
+}
+
+// CountBikes считает байки под админским фильтром, Page и Sort не учитываются
+func (r *BikeRepository) CountBikes(ctx context.Context, filter domain.BikeListFilter) (int, error) {
+	query, args := appendBikeListFilter(`SELECT COUNT(*) FROM bikes WHERE true`, nil, filter)
+
+	var total int
+	if err := conn(ctx, r.readDB).QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, dbError(ctx, err)
+	}
+	return total, nil
+}
+
+func appendBikeListFilter(query string, args []interface{}, filter domain.BikeListFilter) (string, []interface{}) {
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		query += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if filter.UserID != uuid.Nil {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.Model != "" {
+		// % и _ во вводе ищем буквально
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.Model) + "%"
+		args = append(args, pattern)
+		query += fmt.Sprintf(" AND model ILIKE $%d", len(args))
+	}
+	if filter.MinMileage != nil {
+		args = append(args, *filter.MinMileage)
+		query += fmt.Sprintf(" AND mileage >= $%d", len(args))
+	}
+	if filter.MaxMileage != nil {
+		args = append(args, *filter.MaxMileage)
+		query += fmt.Sprintf(" AND mileage <= $%d", len(args))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		query += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+	return query, args
+}
@@ -5,25 +5,36 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
 type ComponentRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	readDB *sql.DB
 }
 
-func NewComponentRepository(db *sql.DB) *ComponentRepository {
-	return &ComponentRepository{db: db}
+// readDB - пул реплики для чтения, nil означает читать с primary
+func NewComponentRepository(db *sql.DB, readDB *sql.DB) *ComponentRepository {
+	if readDB == nil {
+		readDB = db
+	}
+	return &ComponentRepository{db: db, readDB: readDB}
 }
 
+// CreateComponent вставляет компонент, только пока байк не удалён. FK при
+// мягком удалении не срабатывает, поэтому байк проверяется в том же запросе,
+// а FOR SHARE не даёт удалить его до конца транзакции. Удалённый или
+// несуществующий байк - domain.ErrBikeNotFound
 func (r *ComponentRepository) CreateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error) {
-	query := `INSERT INTO components (id, bike_id, name, brand, model, installed_at, installed_mileage, max_mileage)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	query := `INSERT INTO components (id, bike_id, name, brand, model, installed_at, installed_mileage, max_mileage, max_age_days, position)
+		SELECT $1::uuid, $2::uuid, $3::varchar, $4::varchar, $5::varchar, $6::timestamp, $7::int, NULLIF($8::int, 0), $9::int, $10::varchar
+		WHERE EXISTS (SELECT 1 FROM bikes WHERE bike_id = $2 AND deleted_at IS NULL FOR SHARE)
 		RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		component.ID,
 		component.BikeID,
 		component.Name,
@@ -32,6 +43,8 @@ func (r *ComponentRepository) CreateComponent(ctx context.Context, component *do
 		component.InstalledAt,
 		component.InstalledMileage,
 		component.MaxMileage,
+		component.MaxAgeDays,
+		component.Position,
 	).Scan(
 		&component.ID,
 		&component.CreatedAt,
@@ -39,31 +52,49 @@ func (r *ComponentRepository) CreateComponent(ctx context.Context, component *do
 	)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBikeNotFound
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code {
 			case "23502":
 				return nil, fmt.Errorf("required field is missing")
-			case "23503":
-				return nil, fmt.Errorf("bike does not exist")
+			case "23514":
+				return nil, domain.ErrNoReplacementThreshold
+			case "23505":
+				if pqErr.Constraint == "uq_components_paired_position" {
+					return nil, domain.ErrDuplicatePosition
+				}
+				return nil, dbError(ctx, err)
 			default:
-				return nil, err
+				return nil, dbError(ctx, err)
 			}
 		}
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 
 	return component, nil
 }
 
 func (r *ComponentRepository) GetComponentByID(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	return r.getComponent(ctx, r.readDB, componentID)
+}
+
+// GetComponentByIDForWrite - компонент с primary: его правят и пишут целиком
+// обратно, и с отставшей реплики правка молча откатила бы предыдущую
+func (r *ComponentRepository) GetComponentByIDForWrite(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	return r.getComponent(ctx, r.db, componentID)
+}
+
+func (r *ComponentRepository) getComponent(ctx context.Context, db *sql.DB, componentID uuid.UUID) (*domain.Component, error) {
 	query := `
-		SELECT id, bike_id, name, brand, model, installed_at, installed_mileage, max_mileage, created_at, updated_at
+		SELECT id, bike_id, name, COALESCE(brand, ''), COALESCE(model, ''), installed_at, installed_mileage, COALESCE(max_mileage, 0), max_age_days, position, replaced_at, created_at, updated_at
 		FROM components
 		WHERE id = $1
 	`
 
 	var component domain.Component
-	err := r.db.QueryRowContext(ctx, query, componentID).Scan(
+	err := conn(ctx, db).QueryRowContext(ctx, query, componentID).Scan(
 		&component.ID,
 		&component.BikeID,
 		&component.Name,
@@ -72,6 +103,9 @@ func (r *ComponentRepository) GetComponentByID(ctx context.Context, componentID
 		&component.InstalledAt,
 		&component.InstalledMileage,
 		&component.MaxMileage,
+		&component.MaxAgeDays,
+		&component.Position,
+		&component.ReplacedAt,
 		&component.CreatedAt,
 		&component.UpdatedAt,
 	)
@@ -80,20 +114,65 @@ func (r *ComponentRepository) GetComponentByID(ctx context.Context, componentID
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("component not found")
 		}
-		return nil, fmt.Errorf("failed to get component: %w", err)
+		return nil, fmt.Errorf("failed to get component: %w", dbError(ctx, err))
 	}
 
 	return &component, nil
 }
 
-func (r *ComponentRepository) GetComponentsByBikeID(ctx context.Context, bike_id uuid.UUID) ([]*domain.Component, error) {
-	query := `SELECT id, bike_id, name, brand, model, installed_at, installed_mileage, max_mileage, created_at, updated_at
-		FROM components WHERE bike_id = $1
-		ORDER BY installed_at DESC`
+// componentSortColumns - выражения для ORDER BY, сортировка никогда не берётся из запроса напрямую.
+// Для wear нужен пробег байка, поэтому в запросе всегда есть b
+var componentSortColumns = map[domain.ComponentSort]string{
+	domain.ComponentSortInstalledAt: "c.installed_at",
+	domain.ComponentSortWear:        componentWearSQL,
+	domain.ComponentSortName:        "c.name",
+	domain.ComponentSortMaxMileage:  "c.max_mileage",
+}
+
+func (r *ComponentRepository) GetComponentsByBikeID(ctx context.Context, bike_id uuid.UUID, filter domain.ComponentFilter) ([]*domain.Component, error) {
+	query := `SELECT c.id, c.bike_id, c.name, COALESCE(c.brand, ''), COALESCE(c.model, ''), c.installed_at, c.installed_mileage, COALESCE(c.max_mileage, 0), c.max_age_days, c.position, c.replaced_at, c.created_at, c.updated_at
+		FROM components c
+		JOIN bikes b ON b.bike_id = c.bike_id
+		WHERE c.bike_id = $1`
+	args := []interface{}{bike_id}
+
+	if !filter.IncludeReplaced {
+		query += " AND c.replaced_at IS NULL"
+	}
+
+	if filter.Name != "" {
+		args = append(args, filter.Name)
+		query += fmt.Sprintf(" AND c.name = $%d", len(args))
+	}
+	if filter.InstalledAfter != nil {
+		args = append(args, *filter.InstalledAfter)
+		query += fmt.Sprintf(" AND c.installed_at >= $%d", len(args))
+	}
+	if filter.InstalledBefore != nil {
+		args = append(args, *filter.InstalledBefore)
+		query += fmt.Sprintf(" AND c.installed_at <= $%d", len(args))
+	}
+
+	sort, order := filter.Ordering()
+	column, ok := componentSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported component sort %q", sort)
+	}
+	direction := "ASC"
+	if order == domain.SortDesc {
+		direction = "DESC"
+	}
+	// без порога по пробегу max_mileage NULL - такие всегда в конце.
+	// id как тай-брейкер, иначе порядок при равных значениях плавает между страницами
+	query += fmt.Sprintf(" ORDER BY %s %s NULLS LAST, c.id", column, direction)
+	if filter.Page.Limit > 0 {
+		args = append(args, filter.Page.Limit, filter.Page.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, bike_id)
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -110,42 +189,51 @@ func (r *ComponentRepository) GetComponentsByBikeID(ctx context.Context, bike_id
 			&component.InstalledAt,
 			&component.InstalledMileage,
 			&component.MaxMileage,
+			&component.MaxAgeDays,
+			&component.Position,
+			&component.ReplacedAt,
 			&component.CreatedAt,
 			&component.UpdatedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, dbError(ctx, err)
 		}
 		components = append(components, component)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 
 	return components, nil
 }
 
+// UpdateComponent переписывает данные компонента. Заменённый компонент -
+// уже история и не меняется: для него domain.ErrComponentReplaced
 func (r *ComponentRepository) UpdateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error) {
 	query := `UPDATE components
-		SET 
-			name = COALESCE(NULLIF($1, ''), name),
-			brand = COALESCE(NULLIF($2, ''), brand),
-			model = COALESCE(NULLIF($3, ''), model),
-			installed_at = COALESCE(NULLIF($4, '0001-01-01 00:00:00+00'::timestamp), installed_at),
-			installed_mileage = COALESCE(NULLIF($5, 0), installed_mileage),
-			max_mileage = COALESCE(NULLIF($6, 0), max_mileage),
+		SET
+			name = $1,
+			brand = $2,
+			model = $3,
+			installed_at = $4,
+			installed_mileage = $5,
+			max_mileage = NULLIF($6, 0),
+			max_age_days = $7,
+			position = $8,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $7
-		RETURNING id, bike_id, name, brand, model, installed_at, installed_mileage, max_mileage, created_at, updated_at`
+		WHERE id = $9 AND replaced_at IS NULL
+		RETURNING id, bike_id, name, COALESCE(brand, ''), COALESCE(model, ''), installed_at, installed_mileage, COALESCE(max_mileage, 0), max_age_days, position, replaced_at, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		component.Name,
 		component.Brand,
 		component.Model,
 		component.InstalledAt,
 		component.InstalledMileage,
 		component.MaxMileage,
+		component.MaxAgeDays,
+		component.Position,
 		component.ID,
 	).Scan(
 		&component.ID,
@@ -156,34 +244,88 @@ func (r *ComponentRepository) UpdateComponent(ctx context.Context, component *do
 		&component.InstalledAt,
 		&component.InstalledMileage,
 		&component.MaxMileage,
+		&component.MaxAgeDays,
+		&component.Position,
+		&component.ReplacedAt,
 		&component.CreatedAt,
 		&component.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
+			var exists bool
+			if err := conn(ctx, r.db).QueryRowContext(ctx,
+				`SELECT EXISTS (SELECT 1 FROM components WHERE id = $1)`, component.ID).Scan(&exists); err != nil {
+				return nil, dbError(ctx, err)
+			}
+			if exists {
+				return nil, domain.ErrComponentReplaced
+			}
 			return nil, fmt.Errorf("component not found")
 		}
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23502" {
-			return nil, fmt.Errorf("required field is missing")
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23502":
+				return nil, fmt.Errorf("required field is missing")
+			case "23514":
+				return nil, domain.ErrNoReplacementThreshold
+			case "23505":
+				if pqErr.Constraint == "uq_components_paired_position" {
+					return nil, domain.ErrDuplicatePosition
+				}
+			}
 		}
-		return nil, fmt.Errorf("error updating component: %w", err)
+		return nil, fmt.Errorf("error updating component: %w", dbError(ctx, err))
 	}
 
 	return component, nil
 }
 
+// MarkComponentReplaced помечает компонент заменённым. Уже заменённый
+// (например, параллельным запросом) даёт domain.ErrComponentReplaced
+func (r *ComponentRepository) MarkComponentReplaced(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	query := `UPDATE components
+		SET replaced_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND replaced_at IS NULL
+		RETURNING id, bike_id, name, COALESCE(brand, ''), COALESCE(model, ''), installed_at, installed_mileage, COALESCE(max_mileage, 0), max_age_days, position, replaced_at, created_at, updated_at`
+
+	var component domain.Component
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, componentID).Scan(
+		&component.ID,
+		&component.BikeID,
+		&component.Name,
+		&component.Brand,
+		&component.Model,
+		&component.InstalledAt,
+		&component.InstalledMileage,
+		&component.MaxMileage,
+		&component.MaxAgeDays,
+		&component.Position,
+		&component.ReplacedAt,
+		&component.CreatedAt,
+		&component.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrComponentReplaced
+		}
+		return nil, dbError(ctx, err)
+	}
+
+	return &component, nil
+}
+
 func (r *ComponentRepository) DeleteComponent(ctx context.Context, component_id uuid.UUID) error {
 	query := `DELETE FROM components WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, component_id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, component_id)
 	if err != nil {
-		return err
+		return dbError(ctx, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return dbError(ctx, err)
 	}
 
 	if rowsAffected == 0 {
@@ -192,3 +334,57 @@ func (r *ComponentRepository) DeleteComponent(ctx context.Context, component_id
 
 	return nil
 }
+
+// колонки для подсказок, имя колонки никогда не берётся из запроса
+// componentWearSQL - износ компонента c при пробеге байка b по худшему из порогов,
+// тот же расчёт, что domain.Component.Wear
+const componentWearSQL = `COALESCE(GREATEST(
+	(b.mileage - c.installed_mileage)::float8 / NULLIF(c.max_mileage, 0),
+	EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - c.installed_at)) / 86400 / c.max_age_days
+), 0)`
+
+var suggestColumns = map[domain.SuggestField]string{
+	domain.SuggestBrand: "brand",
+	domain.SuggestModel: "model",
+}
+
+// SuggestValues ищет различающиеся без учёта регистра значения по префиксу
+func (r *ComponentRepository) SuggestValues(ctx context.Context, q domain.SuggestQuery) ([]string, error) {
+	column, ok := suggestColumns[q.Field]
+	if !ok {
+		return nil, fmt.Errorf("field %q has no suggestions", q.Field)
+	}
+
+	// % и _ во вводе пользователя ищем буквально
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q.Prefix) + "%"
+
+	query := fmt.Sprintf(`SELECT MIN(c.%[1]s) FROM components c`, column)
+	args := []interface{}{pattern, q.Limit}
+	if q.UserID != uuid.Nil {
+		args = append(args, q.UserID)
+		query += ` JOIN bikes b ON b.bike_id = c.bike_id AND b.user_id = $3`
+	}
+	query += fmt.Sprintf(` WHERE c.%[1]s ILIKE $1
+		GROUP BY lower(c.%[1]s)
+		ORDER BY lower(c.%[1]s)
+		LIMIT $2`, column)
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		values = append(values, value)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// вставка компонента сама проверяет, что байк жив, и держит его строку до
+// конца транзакции: удалённый байк не найдётся, и строк не будет
+func TestCreateComponentRequiresLiveBike(t *testing.T) {
+	guard := regexp.QuoteMeta(`WHERE EXISTS (SELECT 1 FROM bikes WHERE bike_id = $2 AND deleted_at IS NULL FOR SHARE)`)
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		wantErr error
+	}{
+		{name: "живой байк", rows: sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(uuid.New(), time.Now(), time.Now())},
+		{name: "удалённый байк", rows: sqlmock.NewRows([]string{"id", "created_at", "updated_at"}), wantErr: domain.ErrBikeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Close() })
+			mock.ExpectQuery(`INSERT INTO components .* SELECT .* ` + guard).WillReturnRows(tt.rows)
+
+			component := &domain.Component{ID: uuid.New(), BikeID: uuid.New(), Name: domain.Frame, InstalledAt: time.Now(), MaxMileage: 5000}
+			_, err = NewComponentRepository(db, nil).CreateComponent(context.Background(), component)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// заменённый компонент UPDATE не трогает: существующий id без строки -
+// это ErrComponentReplaced, а не "не найден"; replaced_at приходит в ответе
+func TestUpdateComponentSkipsReplaced(t *testing.T) {
+	update := `UPDATE components SET .* WHERE id = \$9 AND replaced_at IS NULL RETURNING .* position, replaced_at, created_at, updated_at`
+	exists := regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM components WHERE id = $1)`)
+	tests := []struct {
+		name    string
+		expect  func(mock sqlmock.Sqlmock, id uuid.UUID)
+		wantErr string
+	}{
+		{name: "действующий", expect: func(mock sqlmock.Sqlmock, id uuid.UUID) {
+			mock.ExpectQuery(update).WillReturnRows(sqlmock.NewRows([]string{"id", "bike_id", "name", "brand", "model", "installed_at", "installed_mileage", "max_mileage", "max_age_days", "position", "replaced_at", "created_at", "updated_at"}).
+				AddRow(id, uuid.New(), domain.Frame, "", "", time.Now(), 0, 9000, nil, "", nil, time.Now(), time.Now()))
+		}},
+		{name: "заменённый", wantErr: domain.ErrComponentReplaced.Error(), expect: func(mock sqlmock.Sqlmock, id uuid.UUID) {
+			mock.ExpectQuery(update).WillReturnRows(sqlmock.NewRows(nil))
+			mock.ExpectQuery(exists).WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		}},
+		{name: "несуществующий", wantErr: "component not found", expect: func(mock sqlmock.Sqlmock, id uuid.UUID) {
+			mock.ExpectQuery(update).WillReturnRows(sqlmock.NewRows(nil))
+			mock.ExpectQuery(exists).WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Close() })
+			id := uuid.New()
+			tt.expect(mock, id)
+
+			component := &domain.Component{ID: id, Name: domain.Frame, InstalledAt: time.Now(), MaxMileage: 9000}
+			updated, err := NewComponentRepository(db, nil).UpdateComponent(context.Background(), component)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || updated.ReplacedAt != nil {
+				t.Fatalf("UpdateComponent = %+v, %v", updated, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// правки читают компонент с primary, обычное чтение - с реплики
+func TestGetComponentByIDForWriteReadsPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { primary.Close() })
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { replica.Close() })
+
+	componentID := uuid.New()
+	componentRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "bike_id", "name", "brand", "model", "installed_at", "installed_mileage", "max_mileage", "max_age_days", "position", "replaced_at", "created_at", "updated_at"}).
+			AddRow(componentID, uuid.New(), domain.Frame, "", "", time.Now(), 0, 5000, nil, "", nil, time.Now(), time.Now())
+	}
+	query := regexp.QuoteMeta(`FROM components`)
+	primaryMock.ExpectQuery(query).WithArgs(componentID).WillReturnRows(componentRows())
+	replicaMock.ExpectQuery(query).WithArgs(componentID).WillReturnRows(componentRows())
+
+	repo := NewComponentRepository(primary, replica)
+	if _, err := repo.GetComponentByIDForWrite(context.Background(), componentID); err != nil {
+		t.Fatalf("GetComponentByIDForWrite: %v", err)
+	}
+	if _, err := repo.GetComponentByID(context.Background(), componentID); err != nil {
+		t.Fatalf("GetComponentByID: %v", err)
+	}
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("primary: %v", err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("replica: %v", err)
+	}
+}
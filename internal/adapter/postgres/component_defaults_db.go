@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+func (r *ComponentRepository) ListComponentDefaults(ctx context.Context) ([]*domain.ComponentDefault, error) {
+	query := `SELECT name, COALESCE(max_mileage, 0), max_age_days, updated_at
+		FROM component_defaults
+		ORDER BY name`
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var defaults []*domain.ComponentDefault
+	for rows.Next() {
+		d := &domain.ComponentDefault{}
+		if err := rows.Scan(&d.Name, &d.MaxMileage, &d.MaxAgeDays, &d.UpdatedAt); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		defaults = append(defaults, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return defaults, nil
+}
+
+func (r *ComponentRepository) UpsertComponentDefault(ctx context.Context, d *domain.ComponentDefault) (*domain.ComponentDefault, error) {
+	query := `INSERT INTO component_defaults (name, max_mileage, max_age_days)
+		VALUES ($1, NULLIF($2, 0), $3)
+		ON CONFLICT (name) DO UPDATE SET
+			max_mileage = EXCLUDED.max_mileage,
+			max_age_days = EXCLUDED.max_age_days,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, d.Name, d.MaxMileage, d.MaxAgeDays).Scan(&d.UpdatedAt); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return d, nil
+}
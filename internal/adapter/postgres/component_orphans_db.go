@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// orphanedSQL - у компонента нет живого байка: байка нет совсем или он мягко
+// удалён. Повторяется в UPDATE и DELETE, чтобы починка не задела компонент,
+// который уже перенесли или чей байк восстановили
+const orphanedSQL = `NOT EXISTS (SELECT 1 FROM bikes b WHERE b.bike_id = components.bike_id AND b.deleted_at IS NULL)`
+
+// ListOrphanedComponents - компоненты, чей bike_id не ведёт ни на один живой
+// байк. Сначала самые старые. Читаем с primary: отставшая реплика покажет
+// сиротами компоненты только что созданных байков
+func (r *ComponentRepository) ListOrphanedComponents(ctx context.Context, page domain.Page) ([]*domain.Component, error) {
+	query := `SELECT c.id, c.bike_id, c.name, COALESCE(c.brand, ''), COALESCE(c.model, ''), c.installed_at, c.installed_mileage, COALESCE(c.max_mileage, 0), c.max_age_days, c.position, c.created_at, c.updated_at
+		FROM components c
+		LEFT JOIN bikes b ON b.bike_id = c.bike_id
+		WHERE b.bike_id IS NULL OR b.deleted_at IS NOT NULL
+		ORDER BY c.created_at, c.id`
+	args := []interface{}{}
+	if page.Limit > 0 {
+		args = append(args, page.Limit, page.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var components []*domain.Component
+	for rows.Next() {
+		component := &domain.Component{}
+		err := rows.Scan(
+			&component.ID,
+			&component.BikeID,
+			&component.Name,
+			&component.Brand,
+			&component.Model,
+			&component.InstalledAt,
+			&component.InstalledMileage,
+			&component.MaxMileage,
+			&component.MaxAgeDays,
+			&component.Position,
+			&component.CreatedAt,
+			&component.UpdatedAt,
+		)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		components = append(components, component)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return components, nil
+}
+
+// ReassignOrphanedComponents переносит на bikeID те из ids, что до сих пор
+// без байка, и возвращает их ID
+func (r *ComponentRepository) ReassignOrphanedComponents(ctx context.Context, ids []uuid.UUID, bikeID uuid.UUID) ([]uuid.UUID, error) {
+	query := `UPDATE components SET bike_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($2) AND ` + orphanedSQL + `
+		RETURNING id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, bikeID, pq.Array(ids))
+	if err != nil {
+		return nil, reassignError(ctx, err)
+	}
+	// нарушение ограничения pq может вернуть и при чтении строк
+	moved, err := scanIDs(ctx, rows)
+	if err != nil {
+		return nil, reassignError(ctx, err)
+	}
+	return moved, nil
+}
+
+func reassignError(ctx context.Context, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23503":
+			return domain.ErrBikeNotFound
+		case "23505":
+			if pqErr.Constraint == "uq_components_paired_position" {
+				return domain.ErrDuplicatePosition
+			}
+		}
+	}
+	return dbError(ctx, err)
+}
+
+// DeleteOrphanedComponents удаляет те из ids, что до сих пор без байка, и возвращает их ID
+func (r *ComponentRepository) DeleteOrphanedComponents(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	query := `DELETE FROM components WHERE id = ANY($1) AND ` + orphanedSQL + ` RETURNING id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return scanIDs(ctx, rows)
+}
+
+func scanIDs(ctx context.Context, rows *sql.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return ids, nil
+}
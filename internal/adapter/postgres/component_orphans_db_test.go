@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// сирота - компонент без живого байка: и список, и починка считают сиротами
+// компоненты мягко удалённых байков, иначе их нельзя ни найти, ни починить
+func TestOrphanedComponentsIncludeDeletedBikes(t *testing.T) {
+	ids := []uuid.UUID{uuid.New()}
+	bikeID := uuid.New()
+	orphaned := regexp.QuoteMeta(`NOT EXISTS (SELECT 1 FROM bikes b WHERE b.bike_id = components.bike_id AND b.deleted_at IS NULL)`)
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		call   func(r *ComponentRepository) ([]uuid.UUID, error)
+	}{
+		{
+			name: "список",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM components c\s+LEFT JOIN bikes b ON b.bike_id = c.bike_id\s+` + regexp.QuoteMeta(`WHERE b.bike_id IS NULL OR b.deleted_at IS NOT NULL`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "bike_id", "name", "brand", "model", "installed_at", "installed_mileage", "max_mileage", "max_age_days", "position", "created_at", "updated_at"}).
+						AddRow(ids[0], uuid.New(), domain.Frame, "", "", time.Now(), 0, 5000, 0, "", time.Now(), time.Now()))
+			},
+			call: func(r *ComponentRepository) ([]uuid.UUID, error) {
+				components, err := r.ListOrphanedComponents(context.Background(), domain.Page{})
+				var got []uuid.UUID
+				for _, c := range components {
+					got = append(got, c.ID)
+				}
+				return got, err
+			},
+		},
+		{
+			name: "перенос",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE components SET bike_id = \$1.* WHERE id = ANY\(\$2\) AND ` + orphaned).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]))
+			},
+			call: func(r *ComponentRepository) ([]uuid.UUID, error) {
+				return r.ReassignOrphanedComponents(context.Background(), ids, bikeID)
+			},
+		},
+		{
+			name: "удаление",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`DELETE FROM components WHERE id = ANY\(\$1\) AND ` + orphaned).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]))
+			},
+			call: func(r *ComponentRepository) ([]uuid.UUID, error) {
+				return r.DeleteOrphanedComponents(context.Background(), ids)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Close() })
+			tt.expect(mock)
+
+			got, err := tt.call(NewComponentRepository(db, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, ids) {
+				t.Errorf("ids = %v, want %v", got, ids)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+func (r *ComponentRepository) ListComponentWeights(ctx context.Context) ([]*domain.ComponentWeight, error) {
+	query := `SELECT name, weight, updated_at
+		FROM component_weights
+		ORDER BY name`
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var weights []*domain.ComponentWeight
+	for rows.Next() {
+		w := &domain.ComponentWeight{}
+		if err := rows.Scan(&w.Name, &w.Weight, &w.UpdatedAt); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		weights = append(weights, w)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return weights, nil
+}
+
+func (r *ComponentRepository) UpsertComponentWeight(ctx context.Context, w *domain.ComponentWeight) (*domain.ComponentWeight, error) {
+	query := `INSERT INTO component_weights (name, weight)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET
+			weight = EXCLUDED.weight,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, w.Name, w.Weight).Scan(&w.UpdatedAt); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return w, nil
+}
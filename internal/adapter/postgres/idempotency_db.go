@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// ClaimKey занимает ключ или перезаписывает протухший. Конкурентный запрос
+// с тем же ключом ждёт на первичном ключе, пока первый не завершится,
+// и после его коммита получает false
+func (r *IdempotencyRepository) ClaimKey(ctx context.Context, scope domain.IdempotencyScope, key domain.IdempotencyKey, resourceIDs []uuid.UUID, ttl time.Duration) (bool, error) {
+	query := `INSERT INTO idempotency_keys (user_id, scope, key, request_hash, resource_ids)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, scope, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, resource_ids = EXCLUDED.resource_ids, created_at = CURRENT_TIMESTAMP
+		WHERE idempotency_keys.created_at < CURRENT_TIMESTAMP - make_interval(secs => $6)`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, key.UserID, scope, key.Key, key.RequestHash, pq.Array(resourceIDs), ttl.Seconds())
+	if err != nil {
+		return false, dbError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError(ctx, err)
+	}
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) GetKey(ctx context.Context, scope domain.IdempotencyScope, userID uuid.UUID, key string) (string, []uuid.UUID, error) {
+	query := `SELECT request_hash, resource_ids FROM idempotency_keys WHERE user_id = $1 AND scope = $2 AND key = $3`
+
+	var (
+		requestHash string
+		ids         []string
+	)
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, scope, key).Scan(&requestHash, pq.Array(&ids)); err != nil {
+		return "", nil, dbError(ctx, err)
+	}
+
+	resourceIDs := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return "", nil, dbError(ctx, err)
+		}
+		resourceIDs[i] = parsed
+	}
+	return requestHash, resourceIDs, nil
+}
+
+// PurgeExpiredKeys удаляет ключи старше ttl: ClaimKey их всё равно
+// перезапишет, а без очистки таблица растёт с каждым созданием
+func (r *IdempotencyRepository) PurgeExpiredKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE created_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, ttl.Seconds())
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	return purged, nil
+}
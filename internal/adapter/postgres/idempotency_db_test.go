@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ключ батча хранит все ID батча и живёт столько же, сколько одиночные ключи
+func TestIdempotencyKeyBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	key := domain.IdempotencyKey{UserID: uuid.New(), Key: "sync-1", RequestHash: "hash"}
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	ttl := 24 * time.Hour
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO idempotency_keys (user_id, scope, key, request_hash, resource_ids)`)).
+		WithArgs(key.UserID, domain.IdempotencyScopeBikeBatch, key.Key, key.RequestHash, pq.Array(ids), ttl.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT request_hash, resource_ids FROM idempotency_keys`)).
+		WithArgs(key.UserID, domain.IdempotencyScopeBikeBatch, key.Key).
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "resource_ids"}).
+			AddRow(key.RequestHash, "{"+ids[0].String()+","+ids[1].String()+"}"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM idempotency_keys WHERE created_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`)).
+		WithArgs(ttl.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	repo := NewIdempotencyRepository(db)
+	ctx := context.Background()
+	claimed, err := repo.ClaimKey(ctx, domain.IdempotencyScopeBikeBatch, key, ids, ttl)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimKey = %t, %v", claimed, err)
+	}
+	hash, got, err := repo.GetKey(ctx, domain.IdempotencyScopeBikeBatch, key.UserID, key.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != key.RequestHash || !slices.Equal(got, ids) {
+		t.Errorf("GetKey = %q, %v, want %q, %v", hash, got, key.RequestHash, ids)
+	}
+	purged, err := repo.PurgeExpiredKeys(ctx, ttl)
+	if err != nil || purged != 3 {
+		t.Errorf("PurgeExpiredKeys = %d, %v, want 3", purged, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bikes(user_id) уже покрыт idx_bikes_user_id, а components(bike_id) -
+-- ведущей колонкой idx_components_bike_id_installed_at
+CREATE INDEX IF NOT EXISTS idx_components_installed_at ON components(installed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_components_installed_at;
+-- +goose StatementEnd
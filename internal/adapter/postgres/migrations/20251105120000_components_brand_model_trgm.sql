@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- триграммы ускоряют ILIKE 'prefix%' для подсказок брендов и моделей
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_components_brand_trgm ON components USING gin (brand gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_components_model_trgm ON components USING gin (model gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_components_model_trgm;
+DROP INDEX IF EXISTS idx_components_brand_trgm;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- год неизвестен - NULL, а не 0
+ALTER TABLE bikes ALTER COLUMN year DROP NOT NULL;
+UPDATE bikes SET year = NULL WHERE year = 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE bikes SET year = 0 WHERE year IS NULL;
+ALTER TABLE bikes ALTER COLUMN year SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- компонент может стареть по времени: нужен хотя бы один из порогов
+ALTER TABLE components ADD COLUMN IF NOT EXISTS max_age_days INT CHECK (max_age_days > 0);
+ALTER TABLE components ALTER COLUMN max_mileage DROP NOT NULL;
+ALTER TABLE components ADD CONSTRAINT chk_components_threshold
+    CHECK (max_mileage IS NOT NULL OR max_age_days IS NOT NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE components DROP CONSTRAINT IF EXISTS chk_components_threshold;
+DELETE FROM components WHERE max_mileage IS NULL;
+ALTER TABLE components ALTER COLUMN max_mileage SET NOT NULL;
+ALTER TABLE components DROP COLUMN IF EXISTS max_age_days;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- парные компоненты (передний/задний) различаются позицией
+ALTER TABLE components ADD COLUMN IF NOT EXISTS position VARCHAR(10) NOT NULL DEFAULT 'none'
+    CHECK (position IN ('front', 'rear', 'left', 'right', 'none'));
+-- у парных типов одна позиция на байк, 'none' не ограничен
+CREATE UNIQUE INDEX IF NOT EXISTS uq_components_paired_position
+    ON components (bike_id, name, position)
+    WHERE name IN ('wheels') AND position <> 'none';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_components_paired_position;
+ALTER TABLE components DROP COLUMN IF EXISTS position;
+-- +goose StatementEnd
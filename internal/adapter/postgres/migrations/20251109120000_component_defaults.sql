@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- пороги замены по умолчанию, правятся админом без деплоя
+CREATE TABLE IF NOT EXISTS component_defaults (
+    name VARCHAR(50) PRIMARY KEY CHECK (name IN ('handlebars', 'frame', 'wheels')),
+    max_mileage INT CHECK (max_mileage > 0),
+    max_age_days INT CHECK (max_age_days > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_component_defaults_threshold
+        CHECK (max_mileage IS NOT NULL OR max_age_days IS NOT NULL)
+);
+
+INSERT INTO component_defaults (name, max_mileage, max_age_days) VALUES
+    ('handlebars', 30000, 1825),
+    ('frame', 100000, 3650),
+    ('wheels', 15000, NULL)
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS component_defaults;
+-- +goose StatementEnd
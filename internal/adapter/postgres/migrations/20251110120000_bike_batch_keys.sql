@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ключ идемпотентности батча байков -> какие байки он создал
+CREATE TABLE IF NOT EXISTS bike_batch_keys (
+    user_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    bike_ids UUID[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bike_batch_keys;
+-- +goose StatementEnd
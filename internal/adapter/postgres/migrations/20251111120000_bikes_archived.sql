@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- архив: байк продан или украден, история остаётся, но в списках по умолчанию его нет
+ALTER TABLE bikes ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_bikes_user_archived ON bikes (user_id) WHERE archived_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_bikes_user_archived;
+ALTER TABLE bikes DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- вес износа компонента в срочности обслуживания байка, правится админом без деплоя.
+-- Типа без строки здесь вес 1
+CREATE TABLE IF NOT EXISTS component_weights (
+    name VARCHAR(50) PRIMARY KEY CHECK (name IN ('handlebars', 'frame', 'wheels')),
+    weight DOUBLE PRECISION NOT NULL CHECK (weight > 0 AND weight <= 10),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO component_weights (name, weight) VALUES
+    ('handlebars', 1.0),
+    ('frame', 1.5),
+    ('wheels', 1.25)
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS component_weights;
+-- +goose StatementEnd
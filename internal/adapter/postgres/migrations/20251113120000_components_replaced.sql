@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- заменённый компонент остаётся в базе для истории обслуживания, но на байке его уже нет
+ALTER TABLE components ADD COLUMN IF NOT EXISTS replaced_at TIMESTAMP NULL;
+-- старое и новое колесо на одной позиции живут рядом, ограничение только для установленных
+DROP INDEX IF EXISTS uq_components_paired_position;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_components_paired_position
+    ON components (bike_id, name, position)
+    WHERE name IN ('wheels') AND position <> 'none' AND replaced_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM components WHERE replaced_at IS NOT NULL;
+DROP INDEX IF EXISTS uq_components_paired_position;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_components_paired_position
+    ON components (bike_id, name, position)
+    WHERE name IN ('wheels') AND position <> 'none';
+ALTER TABLE components DROP COLUMN IF EXISTS replaced_at;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- мягкое удаление: байк и история компонентов остаются, пока байк можно восстановить
+ALTER TABLE bikes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_bikes_user_deleted ON bikes (user_id) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM bikes WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_bikes_user_deleted;
+ALTER TABLE bikes DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- релей забирает строки коротким claim на время lease и публикует уже
+-- после commit. seq задаёт порядок внутри одной транзакции, где created_at
+-- одинаковый, bike_id - чтобы не обгонять события одного байка
+ALTER TABLE outbox ADD COLUMN seq BIGSERIAL;
+ALTER TABLE outbox ADD COLUMN bike_id UUID;
+ALTER TABLE outbox ADD COLUMN claimed_until TIMESTAMP;
+
+UPDATE outbox SET bike_id = (payload->>'bike_id')::uuid WHERE sent_at IS NULL;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox(seq) WHERE sent_at IS NULL;
+CREATE INDEX idx_outbox_pending_bike ON outbox(bike_id, seq) WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_pending_bike;
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox(created_at) WHERE sent_at IS NULL;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS claimed_until;
+ALTER TABLE outbox DROP COLUMN IF EXISTS bike_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS seq;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- один механизм идемпотентности для всех созданий: ключ может вести на
+-- несколько ресурсов, батчи байков переезжают из bike_batch_keys со своим
+-- scope и дальше протухают по тому же TTL, что и одиночные ключи
+ALTER TABLE idempotency_keys ALTER COLUMN resource_id TYPE UUID[] USING ARRAY[resource_id];
+ALTER TABLE idempotency_keys RENAME COLUMN resource_id TO resource_ids;
+
+INSERT INTO idempotency_keys (user_id, scope, key, request_hash, resource_ids, created_at)
+SELECT user_id, 'bike_batch', key, request_hash, bike_ids, created_at FROM bike_batch_keys
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS bike_batch_keys;
+
+-- для периодической очистки протухших ключей
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_keys_created_at;
+
+CREATE TABLE IF NOT EXISTS bike_batch_keys (
+    user_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    bike_ids UUID[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, key)
+);
+
+INSERT INTO bike_batch_keys (user_id, key, request_hash, bike_ids, created_at)
+SELECT user_id, key, request_hash, resource_ids, created_at FROM idempotency_keys WHERE scope = 'bike_batch';
+DELETE FROM idempotency_keys WHERE scope = 'bike_batch';
+
+ALTER TABLE idempotency_keys RENAME COLUMN resource_ids TO resource_id;
+ALTER TABLE idempotency_keys ALTER COLUMN resource_id TYPE UUID USING resource_id[1];
+-- +goose StatementEnd
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) Enqueue(ctx context.Context, event *domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	query := `INSERT INTO outbox (id, event_type, payload, bike_id) VALUES ($1, $2, $3, $4)`
+
+	// событие без байка пишется с NULL bike_id: такие события не упорядочены
+	// между собой и в ClaimPending друг друга не блокируют
+	bikeID := uuid.NullUUID{UUID: event.BikeID, Valid: event.BikeID != uuid.Nil}
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, event.ID, event.Type, payload, bikeID)
+	return dbError(ctx, err)
+}
+
+// outboxClaimLock - ключ advisory-лока, под которым реплики по очереди
+// забирают сообщения. Без него две реплики могли бы одновременно взять
+// разные события одного байка: каждая не видит незакоммиченный claim другой
+const outboxClaimLock = 7340021
+
+// ClaimPending помечает до limit сообщений как взятые на lease и возвращает их
+// в порядке записи. Сообщения байка, у которого уже есть взятое кем-то
+// неотправленное событие, пропускаются целиком, так что события одного байка
+// публикует только один релей и по порядку. Отправка идёт после commit,
+// строки на это время не блокируются
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, outboxClaimLock); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	query := `WITH claimable AS (
+			SELECT o.id FROM outbox o
+			WHERE o.sent_at IS NULL AND o.attempts < $2
+				AND (o.claimed_until IS NULL OR o.claimed_until < CURRENT_TIMESTAMP)
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox e
+					WHERE e.bike_id = o.bike_id AND e.sent_at IS NULL AND e.attempts < $2
+						AND e.claimed_until >= CURRENT_TIMESTAMP
+				)
+			ORDER BY o.seq
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox SET claimed_until = CURRENT_TIMESTAMP + make_interval(secs => $3)
+		FROM claimable
+		WHERE outbox.id = claimable.id
+		RETURNING outbox.id, outbox.event_type, outbox.payload, COALESCE(outbox.bike_id, '00000000-0000-0000-0000-000000000000'),
+			outbox.attempts, COALESCE(outbox.last_error, ''), outbox.created_at, outbox.seq`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, maxAttempts, lease.Seconds())
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		msg := &domain.OutboxMessage{}
+		if err := rows.Scan(
+			&msg.ID,
+			&msg.EventType,
+			&msg.Payload,
+			&msg.BikeID,
+			&msg.Attempts,
+			&msg.LastError,
+			&msg.CreatedAt,
+			&msg.Seq,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		messages = append(messages, msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	// RETURNING порядок не гарантирует
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Seq < messages[j].Seq
+	})
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE outbox SET sent_at = CURRENT_TIMESTAMP, attempts = attempts + 1, claimed_until = NULL WHERE id = $1`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	return dbError(ctx, err)
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, claimed_until = NULL WHERE id = $1`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, reason)
+	return dbError(ctx, err)
+}
+
+// Release возвращает взятые, но не отправленные сообщения в очередь,
+// не считая это попыткой
+func (r *OutboxRepository) Release(ctx context.Context, ids []uuid.UUID) error {
+	query := `UPDATE outbox SET claimed_until = NULL WHERE id = ANY($1) AND sent_at IS NULL`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, pq.Array(ids))
+	return dbError(ctx, err)
+}
@@ -11,24 +11,31 @@ import (
 )
 
 type BikeRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	readDB *sql.DB
 }
 
-func NewBikeRepository(db *sql.DB) *BikeRepository {
+// readDB - пул реплики для чтения, nil означает читать с primary
+func NewBikeRepository(db *sql.DB, readDB *sql.DB) *BikeRepository {
+	if readDB == nil {
+		readDB = db
+	}
 	return &BikeRepository{
-		db,
+		db:     db,
+		readDB: readDB,
 	}
 }
 
 func (r *BikeRepository) CreateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error) {
 	query := `INSERT INTO bikes (user_id, bike_id, bike_name, type, model, year, mileage)
 	VALUES ($1, $2, $3, $4, $5, $6, $7)
-    RETURNING bike_id, created_at, updated_at`
+    RETURNING bike_id, created_at, updated_at, archived_at`
 
-	err := r.db.QueryRowContext(ctx, query, bike.UserID, bike.BikeID, bike.BikeName, bike.Type, bike.Model, bike.Year, bike.Mileage).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bike.UserID, bike.BikeID, bike.BikeName, bike.Type, bike.Model, bike.Year, bike.Mileage).Scan(
 		&bike.BikeID,
 		&bike.CreatedAt,
 		&bike.UpdatedAt,
+		&bike.ArchivedAt,
 	)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
@@ -38,20 +45,39 @@ func (r *BikeRepository) CreateBike(ctx context.Context, bike *domain.Bike) (*do
 			case "23503":
 				return nil, fmt.Errorf("user does not exist")
 			default:
-				return nil, err
+				return nil, dbError(ctx, err)
 			}
 		}
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 	return bike, nil
 }
 
+// GetBikeByID - байк, если он не удалён
 func (r *BikeRepository) GetBikeByID(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
-	query := `SELECT user_id, bike_id, bike_name, type, model, year, mileage, created_at, updated_at
+	return r.getBike(ctx, r.readDB, bike_id, false)
+}
+
+// GetBikeByIDForWrite - байк, если он не удалён, но с primary: по нему решают,
+// можно ли писать (владелец, пробег), и отставшая реплика тут недопустима
+func (r *BikeRepository) GetBikeByIDForWrite(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	return r.getBike(ctx, r.db, bike_id, false)
+}
+
+// GetBikeByIDWithDeleted - байк по ID, в том числе удалённый
+func (r *BikeRepository) GetBikeByIDWithDeleted(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	return r.getBike(ctx, r.readDB, bike_id, true)
+}
+
+func (r *BikeRepository) getBike(ctx context.Context, db *sql.DB, bike_id uuid.UUID, withDeleted bool) (*domain.Bike, error) {
+	query := `SELECT user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at, deleted_at
               FROM bikes WHERE bike_id = $1`
+	if !withDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
 	bike := &domain.Bike{}
-	err := r.db.QueryRowContext(ctx, query, bike_id).Scan(
+	err := conn(ctx, db).QueryRowContext(ctx, query, bike_id).Scan(
 		&bike.UserID,
 		&bike.BikeID,
 		&bike.BikeName,
@@ -61,25 +87,32 @@ func (r *BikeRepository) GetBikeByID(ctx context.Context, bike_id uuid.UUID) (*d
 		&bike.Mileage,
 		&bike.CreatedAt,
 		&bike.UpdatedAt,
+		&bike.ArchivedAt,
+		&bike.DeletedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("bike not found")
+		return nil, domain.ErrBikeNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 
 	return bike, nil
 }
 
-func (r *BikeRepository) GetBikesByUserID(ctx context.Context, user_id uuid.UUID) ([]*domain.Bike, error) {
-	query := `SELECT user_id, bike_id, bike_name, type, model, year, mileage, created_at, updated_at
+func (r *BikeRepository) GetBikesByUserID(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.Bike, error) {
+	query := `SELECT user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at, deleted_at
               FROM bikes WHERE user_id = $1`
+	args := []interface{}{user_id}
+
+	query, args = appendBikeFilter(query, args, "", filter)
+	query += " ORDER BY " + bikeSortColumns[filter.Sort] + ", bike_id"
+	query, args = appendPage(query, args, filter.Page)
 
-	rows, err := r.db.QueryContext(ctx, query, user_id)
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -97,37 +130,148 @@ func (r *BikeRepository) GetBikesByUserID(ctx context.Context, user_id uuid.UUID
 			&bike.Mileage,
 			&bike.CreatedAt,
 			&bike.UpdatedAt,
+			&bike.ArchivedAt,
+			&bike.DeletedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, dbError(ctx, err)
 		}
 		bikes = append(bikes, bike)
 	}
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, dbError(ctx, err)
 	}
 	return bikes, nil
 }
+
+// bikeSortColumns - ORDER BY для каждого допустимого sort, пустой - по умолчанию.
+// В запрос попадают только значения отсюда, не строка из query
+var bikeSortColumns = map[domain.BikeSort]string{
+	"":                           "created_at DESC",
+	domain.BikeSortCreatedAtDesc: "created_at DESC",
+	domain.BikeSortCreatedAtAsc:  "created_at ASC",
+	domain.BikeSortMileageDesc:   "mileage DESC",
+	domain.BikeSortMileageAsc:    "mileage ASC",
+	domain.BikeSortNameAsc:       "bike_name ASC",
+	domain.BikeSortNameDesc:      "bike_name DESC",
+	domain.BikeSortYearDesc:      "year DESC NULLS LAST",
+	domain.BikeSortYearAsc:       "year ASC NULLS LAST",
+}
+
+// CountBikesByUserID считает байки под фильтром, Page и Sort не учитываются
+func (r *BikeRepository) CountBikesByUserID(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) (int, error) {
+	query := `SELECT COUNT(*) FROM bikes WHERE user_id = $1`
+	args := []interface{}{user_id}
+	query, args = appendBikeFilter(query, args, "", filter)
+
+	var total int
+	if err := conn(ctx, r.readDB).QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, dbError(ctx, err)
+	}
+	return total, nil
+}
+
+// DeleteBike удаляет байк мягко: ставит deleted_at, компоненты остаются
 func (r *BikeRepository) DeleteBike(ctx context.Context, bike_id uuid.UUID) error {
-	query := `DELETE FROM bikes WHERE bike_id = $1`
+	query := `UPDATE bikes
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE bike_id = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, bike_id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, bike_id)
 	if err != nil {
-		return err
+		return dbError(ctx, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return dbError(ctx, err)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("Bike not found")
+		return domain.ErrBikeNotFound
 	}
 
 	return nil
 }
 
+// RestoreBike снимает deleted_at. Байк не удалён (или его нет) - domain.ErrBikeNotDeleted
+func (r *BikeRepository) RestoreBike(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	query := `UPDATE bikes
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE bike_id = $1 AND deleted_at IS NOT NULL
+		RETURNING user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at`
+
+	bike := &domain.Bike{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bike_id).Scan(
+		&bike.UserID,
+		&bike.BikeID,
+		&bike.BikeName,
+		&bike.Type,
+		&bike.Model,
+		&bike.Year,
+		&bike.Mileage,
+		&bike.CreatedAt,
+		&bike.UpdatedAt,
+		&bike.ArchivedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrBikeNotDeleted
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bike, nil
+}
+
+// TransferBike меняет владельца байка с from_user_id на to_user_id. Компоненты
+// привязаны к bike_id и переезжают вместе с ним. Если владелец уже не
+// from_user_id - ErrOwnerChanged
+func (r *BikeRepository) TransferBike(ctx context.Context, bike_id uuid.UUID, from_user_id uuid.UUID, to_user_id uuid.UUID) (*domain.Bike, error) {
+	query := `UPDATE bikes
+		SET user_id = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE bike_id = $1 AND user_id = $2 AND deleted_at IS NULL
+		RETURNING user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at`
+
+	bike := &domain.Bike{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bike_id, from_user_id, to_user_id).Scan(
+		&bike.UserID,
+		&bike.BikeID,
+		&bike.BikeName,
+		&bike.Type,
+		&bike.Model,
+		&bike.Year,
+		&bike.Mileage,
+		&bike.CreatedAt,
+		&bike.UpdatedAt,
+		&bike.ArchivedAt,
+	)
+	if err == sql.ErrNoRows {
+		// не обновилось: либо байка уже нет, либо у него другой владелец
+		return nil, r.missedUpdate(ctx, bike_id, domain.ErrOwnerChanged)
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bike, nil
+}
+
+// missedUpdate объясняет UPDATE с условием, не задевший ни одной строки:
+// байка нет - ErrBikeNotFound, иначе не выполнилось условие - conditionErr
+func (r *BikeRepository) missedUpdate(ctx context.Context, bike_id uuid.UUID, conditionErr error) error {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM bikes WHERE bike_id = $1 AND deleted_at IS NULL)`, bike_id).Scan(&exists)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	if !exists {
+		return domain.ErrBikeNotFound
+	}
+	return conditionErr
+}
+
+// UpdateBike меняет непустые поля. Пробег, как и в UpdateMileage, только растёт:
+// если его успели увеличить выше нового значения - ErrMileageConflict
 func (r *BikeRepository) UpdateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error) {
 	query := `UPDATE bikes
 		SET 
@@ -137,10 +281,10 @@ func (r *BikeRepository) UpdateBike(ctx context.Context, bike *domain.Bike) (*do
 			year = COALESCE(NULLIF($4, 0), year),
 			mileage = COALESCE(NULLIF($5, 0), mileage),
 			updated_at = CURRENT_TIMESTAMP
-		WHERE bike_id = $6
-		RETURNING user_id, bike_id, bike_name, type, model, year, mileage, created_at, updated_at`
+		WHERE bike_id = $6 AND deleted_at IS NULL AND ($5 = 0 OR mileage <= $5)
+		RETURNING user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		bike.BikeName,
 		bike.Type,
 		bike.Model,
@@ -157,17 +301,294 @@ func (r *BikeRepository) UpdateBike(ctx context.Context, bike *domain.Bike) (*do
 		&bike.Mileage,
 		&bike.CreatedAt,
 		&bike.UpdatedAt,
+		&bike.ArchivedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("bike not found")
+			return nil, r.missedUpdate(ctx, bike.BikeID, domain.ErrMileageConflict)
 		}
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23502" {
 			return nil, fmt.Errorf("required field is missing")
 		}
-		return nil, fmt.Errorf("error updating bike: %w", err)
+		return nil, fmt.Errorf("error updating bike: %w", dbError(ctx, err))
 	}
 
 	return bike, nil
 }
+
+// SetBikeArchived убирает байк в архив или возвращает из него. Повторная
+// архивация не сдвигает archived_at
+func (r *BikeRepository) SetBikeArchived(ctx context.Context, bike_id uuid.UUID, archived bool) (*domain.Bike, error) {
+	query := `UPDATE bikes
+		SET
+			archived_at = CASE WHEN $2 THEN COALESCE(archived_at, CURRENT_TIMESTAMP) ELSE NULL END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE bike_id = $1 AND deleted_at IS NULL
+		RETURNING user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at`
+
+	bike := &domain.Bike{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bike_id, archived).Scan(
+		&bike.UserID,
+		&bike.BikeID,
+		&bike.BikeName,
+		&bike.Type,
+		&bike.Model,
+		&bike.Year,
+		&bike.Mileage,
+		&bike.CreatedAt,
+		&bike.UpdatedAt,
+		&bike.ArchivedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrBikeNotFound
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bike, nil
+}
+
+// UpdateMileage записывает пробег, только если он не меньше текущего.
+// Ни одной строки - байка нет или пробег уже больше, различает вызывающий
+func (r *BikeRepository) UpdateMileage(ctx context.Context, bike_id uuid.UUID, mileage int) (*domain.Bike, error) {
+	query := `UPDATE bikes
+		SET mileage = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE bike_id = $1 AND deleted_at IS NULL AND mileage <= $2
+		RETURNING user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at`
+
+	bike := &domain.Bike{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bike_id, mileage).Scan(
+		&bike.UserID,
+		&bike.BikeID,
+		&bike.BikeName,
+		&bike.Type,
+		&bike.Model,
+		&bike.Year,
+		&bike.Mileage,
+		&bike.CreatedAt,
+		&bike.UpdatedAt,
+		&bike.ArchivedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrMileageConflict
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bike, nil
+}
+
+// GetBikesByUrgency отдаёт байки пользователя, отсортированные по взвешенному
+// износу самого срочного компонента (см. domain.BikeUrgency). Всё считается одним запросом
+func (r *BikeRepository) GetBikesByUrgency(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.BikeUrgency, error) {
+	query := `SELECT b.user_id, b.bike_id, b.bike_name, b.type, COALESCE(b.model, ''), b.year, b.mileage, b.created_at, b.updated_at, b.archived_at,
+			COALESCE(w.score, 0), COALESCE(w.max_wear, 0), w.id, w.name, COALESCE(w.overdue, 0)
+		FROM bikes b
+		LEFT JOIN LATERAL (
+			SELECT x.id, x.name,
+				x.wear * x.weight AS score,
+				MAX(x.wear) OVER () AS max_wear,
+				COUNT(*) FILTER (WHERE x.wear >= 1) OVER () AS overdue
+			FROM (
+				SELECT c.id, c.name, ` + componentWearSQL + ` AS wear, COALESCE(cw.weight, 1) AS weight
+				FROM components c
+				LEFT JOIN component_weights cw ON cw.name = c.name
+				WHERE c.bike_id = b.bike_id AND c.replaced_at IS NULL
+			) x
+			ORDER BY score DESC, x.wear DESC
+			LIMIT 1
+		) w ON true
+		WHERE b.user_id = $1`
+	args := []interface{}{user_id}
+
+	query, args = appendBikeFilter(query, args, "b.", filter)
+	query += " ORDER BY COALESCE(w.score, 0) DESC, b.bike_id"
+	query, args = appendPage(query, args, filter.Page)
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var bikes []*domain.BikeUrgency
+
+	for rows.Next() {
+		bike := &domain.BikeUrgency{}
+		var worstID uuid.NullUUID
+		var worstName sql.NullString
+		err := rows.Scan(
+			&bike.UserID,
+			&bike.BikeID,
+			&bike.BikeName,
+			&bike.Type,
+			&bike.Model,
+			&bike.Year,
+			&bike.Mileage,
+			&bike.CreatedAt,
+			&bike.UpdatedAt,
+			&bike.ArchivedAt,
+			&bike.Urgency,
+			&bike.MaxWear,
+			&worstID,
+			&worstName,
+			&bike.ComponentsOverdue,
+		)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		if worstID.Valid {
+			bike.WorstComponentID = &worstID.UUID
+		}
+		if worstName.Valid {
+			name := domain.ComponentName(worstName.String)
+			bike.WorstComponent = &name
+		}
+		bike.Overdue = bike.MaxWear >= 1
+		bikes = append(bikes, bike)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bikes, nil
+}
+
+// GetBikesWithoutComponents - байки, к которым ещё не добавили ни одного компонента.
+// uuid.Nil вместо user_id - по всем пользователям
+func (r *BikeRepository) GetBikesWithoutComponents(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.Bike, error) {
+	query := `SELECT b.user_id, b.bike_id, b.bike_name, b.type, COALESCE(b.model, ''), b.year, b.mileage, b.created_at, b.updated_at, b.archived_at
+		FROM bikes b
+		LEFT JOIN components c ON c.bike_id = b.bike_id AND c.replaced_at IS NULL
+		WHERE c.id IS NULL`
+	var args []interface{}
+	if user_id != uuid.Nil {
+		args = append(args, user_id)
+		query += fmt.Sprintf(" AND b.user_id = $%d", len(args))
+	}
+	query, args = appendBikeFilter(query, args, "b.", filter)
+	query += " ORDER BY b.created_at DESC, b.bike_id"
+	query, args = appendPage(query, args, filter.Page)
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var bikes []*domain.Bike
+
+	for rows.Next() {
+		bike := &domain.Bike{}
+		err := rows.Scan(
+			&bike.UserID,
+			&bike.BikeID,
+			&bike.BikeName,
+			&bike.Type,
+			&bike.Model,
+			&bike.Year,
+			&bike.Mileage,
+			&bike.CreatedAt,
+			&bike.UpdatedAt,
+			&bike.ArchivedAt,
+		)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		bikes = append(bikes, bike)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bikes, nil
+}
+
+// колонки для массового обновления, имя колонки никогда не берётся из запроса
+var bulkUpdateColumns = map[domain.BikeField]string{
+	domain.BikeFieldType:  "type",
+	domain.BikeFieldModel: "model",
+}
+
+// BulkUpdateBikes меняет одно поле у всех подходящих байков одним UPDATE
+// и возвращает изменённые байки
+func (r *BikeRepository) BulkUpdateBikes(ctx context.Context, update domain.BikeBulkUpdate) ([]*domain.Bike, error) {
+	column, ok := bulkUpdateColumns[update.Field]
+	if !ok {
+		return nil, fmt.Errorf("field %q cannot be bulk updated", update.Field)
+	}
+
+	query := fmt.Sprintf(`UPDATE bikes SET %s = $1, updated_at = CURRENT_TIMESTAMP`, column)
+	args := []interface{}{update.Value}
+	if len(update.IDs) > 0 {
+		args = append(args, pq.Array(update.IDs))
+		query += " WHERE bike_id = ANY($2)"
+	} else {
+		args = append(args, update.CurrentType)
+		query += " WHERE type = $2"
+	}
+	query += " AND deleted_at IS NULL"
+	query += " RETURNING user_id, bike_id, bike_name, type, COALESCE(model, ''), year, mileage, created_at, updated_at, archived_at"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var bikes []*domain.Bike
+
+	for rows.Next() {
+		bike := &domain.Bike{}
+		err := rows.Scan(
+			&bike.UserID,
+			&bike.BikeID,
+			&bike.BikeName,
+			&bike.Type,
+			&bike.Model,
+			&bike.Year,
+			&bike.Mileage,
+			&bike.CreatedAt,
+			&bike.UpdatedAt,
+			&bike.ArchivedAt,
+		)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		bikes = append(bikes, bike)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return bikes, nil
+}
+
+// appendBikeFilter дописывает условия фильтра к запросу, где уже есть WHERE.
+// prefix - алиас таблицы bikes с точкой или пустая строка
+func appendBikeFilter(query string, args []interface{}, prefix string, filter domain.BikeFilter) (string, []interface{}) {
+	if !filter.IncludeDeleted {
+		query += fmt.Sprintf(" AND %sdeleted_at IS NULL", prefix)
+	}
+	if filter.Archived {
+		query += fmt.Sprintf(" AND %sarchived_at IS NOT NULL", prefix)
+	} else {
+		query += fmt.Sprintf(" AND %sarchived_at IS NULL", prefix)
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		query += fmt.Sprintf(" AND %screated_at >= $%d", prefix, len(args))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		query += fmt.Sprintf(" AND %screated_at <= $%d", prefix, len(args))
+	}
+	return query, args
+}
+
+// appendPage дописывает LIMIT/OFFSET, нулевой Limit - без ограничения
+func appendPage(query string, args []interface{}, page domain.Page) (string, []interface{}) {
+	if page.Limit > 0 {
+		args = append(args, page.Limit, page.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+	return query, args
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// массовая правка: колонка берётся из белого списка, отбор - по ids или по
+// типу, удалённые байки не трогаются, изменённые возвращаются
+func TestBulkUpdateBikes(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	tests := []struct {
+		name   string
+		update domain.BikeBulkUpdate
+		query  string
+		args   []driver.Value
+	}{
+		{
+			name:   "по ids",
+			update: domain.BikeBulkUpdate{IDs: ids, Field: domain.BikeFieldModel, Value: "Epic"},
+			query:  `UPDATE bikes SET model = $1, updated_at = CURRENT_TIMESTAMP WHERE bike_id = ANY($2) AND deleted_at IS NULL RETURNING`,
+			args:   []driver.Value{"Epic", pq.Array(ids)},
+		},
+		{
+			name:   "по типу",
+			update: domain.BikeBulkUpdate{CurrentType: domain.MTB, Field: domain.BikeFieldType, Value: "road"},
+			query:  `UPDATE bikes SET type = $1, updated_at = CURRENT_TIMESTAMP WHERE type = $2 AND deleted_at IS NULL RETURNING`,
+			args:   []driver.Value{"road", domain.MTB},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Close() })
+
+			rows := sqlmock.NewRows([]string{"user_id", "bike_id", "bike_name", "type", "model", "year", "mileage", "created_at", "updated_at", "archived_at"})
+			for _, id := range ids {
+				rows.AddRow(uuid.New(), id, "Trail", "road", "Epic", nil, 100, time.Now(), time.Now(), nil)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(tt.args...).WillReturnRows(rows)
+
+			bikes, err := NewBikeRepository(db, nil).BulkUpdateBikes(context.Background(), tt.update)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bikes) != len(ids) || bikes[0].BikeID != ids[0] || bikes[1].BikeID != ids[1] {
+				t.Errorf("bikes = %+v, want %v", bikes, ids)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// поле не из белого списка не доходит до базы
+func TestBulkUpdateBikesUnknownField(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	update := domain.BikeBulkUpdate{CurrentType: domain.MTB, Field: "user_id", Value: uuid.NewString()}
+	if _, err := NewBikeRepository(db, nil).BulkUpdateBikes(context.Background(), update); err == nil {
+		t.Fatal("unknown field was accepted")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// проверки перед записью читают байк с primary, обычное чтение - с реплики
+func TestGetBikeByIDForWriteReadsPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { primary.Close() })
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { replica.Close() })
+
+	bikeID := uuid.New()
+	bikeRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"user_id", "bike_id", "bike_name", "type", "model", "year", "mileage", "created_at", "updated_at", "archived_at", "deleted_at"}).
+			AddRow(uuid.New(), bikeID, "Trail", "mtb", "", nil, 100, time.Now(), time.Now(), nil, nil)
+	}
+	query := regexp.QuoteMeta(`FROM bikes WHERE bike_id = $1 AND deleted_at IS NULL`)
+	primaryMock.ExpectQuery(query).WithArgs(bikeID).WillReturnRows(bikeRows())
+	replicaMock.ExpectQuery(query).WithArgs(bikeID).WillReturnRows(bikeRows())
+
+	repo := NewBikeRepository(primary, replica)
+	if _, err := repo.GetBikeByIDForWrite(context.Background(), bikeID); err != nil {
+		t.Fatalf("GetBikeByIDForWrite: %v", err)
+	}
+	if _, err := repo.GetBikeByID(context.Background(), bikeID); err != nil {
+		t.Fatalf("GetBikeByID: %v", err)
+	}
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("primary: %v", err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("replica: %v", err)
+	}
+}
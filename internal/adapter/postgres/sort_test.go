@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+// любой допустимый sort должен давать ORDER BY, а без sort - новые сверху
+func TestBikeSortColumns(t *testing.T) {
+	if got := bikeSortColumns[""]; got != "created_at DESC" {
+		t.Errorf("default order = %q, want created_at DESC", got)
+	}
+	for _, sort := range domain.BikeSorts {
+		column, ok := bikeSortColumns[sort]
+		if !ok {
+			t.Errorf("sort %q has no ORDER BY column", sort)
+			continue
+		}
+		field, direction, _ := strings.Cut(column, " ")
+		if !strings.HasPrefix(string(sort), strings.TrimPrefix(field, "bike_")) {
+			t.Errorf("sort %q orders by %q", sort, column)
+		}
+		if wantDesc := strings.HasSuffix(string(sort), "_desc"); wantDesc != strings.HasPrefix(direction, "DESC") {
+			t.Errorf("sort %q has direction %q", sort, direction)
+		}
+	}
+}
+
+func TestComponentSortColumns(t *testing.T) {
+	for _, sort := range []domain.ComponentSort{
+		domain.ComponentSortInstalledAt,
+		domain.ComponentSortWear,
+		domain.ComponentSortName,
+		domain.ComponentSortMaxMileage,
+	} {
+		if _, ok := componentSortColumns[sort]; !ok {
+			t.Errorf("sort %q has no ORDER BY column", sort)
+		}
+	}
+}
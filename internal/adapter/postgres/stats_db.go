@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+// StatsRepository только читает, поэтому ходит в реплику, если она есть
+type StatsRepository struct {
+	db *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB, readDB *sql.DB) *StatsRepository {
+	if readDB != nil {
+		db = readDB
+	}
+	return &StatsRepository{db: db}
+}
+
+func (r *StatsRepository) GetFleetStats(ctx context.Context, warnPercent int) (*domain.FleetStats, error) {
+	stats := &domain.FleetStats{
+		BikesByType:      map[domain.BikeType]int{},
+		ComponentsByName: map[domain.ComponentName]int{},
+		WarnThreshold:    warnPercent,
+		GeneratedAt:      time.Now().UTC(),
+	}
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT user_id), COUNT(*) FROM bikes WHERE deleted_at IS NULL`,
+	).Scan(&stats.UsersWithBikes, &stats.TotalBikes)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	err = r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE wear >= 1),
+			COUNT(*) FILTER (WHERE wear < 1 AND wear * 100 >= $1)
+		FROM (
+			SELECT `+componentWearSQL+` AS wear
+			FROM components c JOIN bikes b ON b.bike_id = c.bike_id
+			WHERE c.replaced_at IS NULL AND b.deleted_at IS NULL
+		) w`,
+		warnPercent,
+	).Scan(&stats.TotalComponents, &stats.OverdueComponents, &stats.WarningComponents)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT type, COUNT(*) FROM bikes WHERE deleted_at IS NULL GROUP BY type`)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bikeType domain.BikeType
+		var count int
+		if err := rows.Scan(&bikeType, &count); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		stats.BikesByType[bikeType] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	nameRows, err := r.db.QueryContext(ctx, `SELECT c.name, COUNT(*) FROM components c JOIN bikes b ON b.bike_id = c.bike_id
+		WHERE c.replaced_at IS NULL AND b.deleted_at IS NULL GROUP BY c.name`)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer nameRows.Close()
+	for nameRows.Next() {
+		var name domain.ComponentName
+		var count int
+		if err := nameRows.Scan(&name, &count); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		stats.ComponentsByName[name] = count
+	}
+	if err := nameRows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	if stats.UsersWithBikes > 0 {
+		stats.AvgBikesPerUser = float64(stats.TotalBikes) / float64(stats.UsersWithBikes)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+type txKey struct{}
+
+// executor - общее подмножество *sql.DB и *sql.Tx
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn возвращает транзакцию из контекста, если она открыта, иначе сам пул
+func conn(ctx context.Context, db *sql.DB) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// dbError помечает ошибку запроса как domain.ErrRequestCanceled, если клиент
+// ушёл и контекст отменён, чтобы выше её не путали с отказом БД.
+// Истёкший дедлайн остаётся обычной ошибкой
+func dbError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, domain.ErrRequestCanceled) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("%w: %w", domain.ErrRequestCanceled, err)
+	}
+	return err
+}
+
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction выполняет fn в одной транзакции. Все репозитории,
+// вызванные с переданным в fn контекстом, пишут в эту же транзакцию.
+// Вложенный вызов переиспользует уже открытую транзакцию
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", dbError(ctx, err))
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", dbError(ctx, err))
+	}
+	return nil
+}
+
+// WithoutTransaction прячет транзакцию из ctx, остальные значения остаются
+func (t *Transactor) WithoutTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txKey{}, nil)
+}
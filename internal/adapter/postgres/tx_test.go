@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+var errConnRefused = errors.New("connection refused")
+
+// failingConnector - пул без базы: любое подключение падает с errConnRefused
+type failingConnector struct{}
+
+func (failingConnector) Connect(context.Context) (driver.Conn, error) { return nil, errConnRefused }
+func (failingConnector) Driver() driver.Driver                        { return failingDriver{} }
+
+type failingDriver struct{}
+
+func (failingDriver) Open(string) (driver.Conn, error) { return nil, errConnRefused }
+
+func TestDBError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		err          error
+		wantCanceled bool
+	}{
+		{name: "обычная ошибка", ctx: context.Background(), err: errConnRefused},
+		{name: "ошибка драйвера после отмены", ctx: canceled, err: errConnRefused, wantCanceled: true},
+		{name: "context.Canceled от драйвера", ctx: context.Background(), err: fmt.Errorf("query: %w", context.Canceled), wantCanceled: true},
+		{name: "истёкший дедлайн", ctx: context.Background(), err: context.DeadlineExceeded},
+		{name: "уже помеченная", ctx: canceled, err: fmt.Errorf("%w: %w", domain.ErrRequestCanceled, context.Canceled), wantCanceled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dbError(tt.ctx, tt.err)
+			if got := errors.Is(err, domain.ErrRequestCanceled); got != tt.wantCanceled {
+				t.Errorf("dbError(%v) = %v, canceled %t, want %t", tt.err, err, got, tt.wantCanceled)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("dbError(%v) = %v, original error lost", tt.err, err)
+			}
+		})
+	}
+
+	if err := dbError(canceled, nil); err != nil {
+		t.Errorf("dbError(nil) = %v, want nil", err)
+	}
+}
+
+// отменённый контекст доходит до репозиториев и возвращается как ErrRequestCanceled,
+// а отказ базы при живом контексте - нет
+func TestRepositoriesCanceledContext(t *testing.T) {
+	db := sql.OpenDB(failingConnector{})
+	t.Cleanup(func() { db.Close() })
+	bikes := NewBikeRepository(db, db)
+	transactor := NewTransactor(db)
+
+	calls := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{name: "GetBikeByID", call: func(ctx context.Context) error {
+			_, err := bikes.GetBikeByID(ctx, uuid.New())
+			return err
+		}},
+		{name: "GetBikesByUserID", call: func(ctx context.Context) error {
+			_, err := bikes.GetBikesByUserID(ctx, uuid.New(), domain.BikeFilter{})
+			return err
+		}},
+		{name: "DeleteBike", call: func(ctx context.Context) error {
+			return bikes.DeleteBike(ctx, uuid.New())
+		}},
+		{name: "WithinTransaction", call: func(ctx context.Context) error {
+			return transactor.WithinTransaction(ctx, func(context.Context) error { return nil })
+		}},
+	}
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if err := tt.call(ctx); !errors.Is(err, domain.ErrRequestCanceled) {
+				t.Errorf("canceled context: err = %v, want ErrRequestCanceled", err)
+			}
+
+			err := tt.call(context.Background())
+			if err == nil || errors.Is(err, domain.ErrRequestCanceled) {
+				t.Errorf("live context: err = %v, want plain database error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error) {
+	query := `INSERT INTO webhooks (id, user_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		webhook.ID,
+		webhook.UserID,
+		webhook.URL,
+		pq.Array(eventTypesToStrings(webhook.EventTypes)),
+		webhook.Secret,
+	).Scan(
+		&webhook.ID,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23502" {
+			return nil, fmt.Errorf("required field is missing")
+		}
+		return nil, dbError(ctx, err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepository) GetWebhookByID(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error) {
+	query := `SELECT id, user_id, url, event_types, secret, created_at
+		FROM webhooks WHERE id = $1`
+
+	webhook, err := scanWebhook(conn(ctx, r.db).QueryRowContext(ctx, query, webhookID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", dbError(ctx, err))
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Webhook, error) {
+	query := `SELECT id, user_id, url, event_types, secret, created_at
+		FROM webhooks WHERE user_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var webhooks []*domain.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+	query := `DELETE FROM webhooks WHERE id = $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) CreateDeadLetter(ctx context.Context, deadLetter *domain.WebhookDeadLetter) error {
+	query := `INSERT INTO webhook_dead_letters (id, webhook_id, event_id, event_type, payload, error, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		deadLetter.ID,
+		deadLetter.WebhookID,
+		deadLetter.EventID,
+		deadLetter.EventType,
+		deadLetter.Payload,
+		deadLetter.Error,
+		deadLetter.Attempts,
+	)
+	return dbError(ctx, err)
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	for _, d := range deliveries {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, d.ID, d.WebhookID, d.EventID, d.EventType, d.Payload); err != nil {
+			return dbError(ctx, err)
+		}
+	}
+	return nil
+}
+
+// webhookClaimLock - ключ advisory-лока, под которым реплики по очереди
+// забирают доставки, как outboxClaimLock для outbox
+const webhookClaimLock = 7340022
+
+// ClaimDueDeliveries берёт на lease доставки, время которых пришло, - от
+// каждого вебхука не больше одной и только если другая его доставка сейчас
+// не отправляется. Так недоступный подписчик занимает одно место в проходе,
+// а не весь проход
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, webhookClaimLock); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	query := `WITH claimable AS (
+			SELECT due.id FROM (
+				SELECT DISTINCT ON (d.webhook_id) d.id, d.next_attempt_at
+				FROM webhook_deliveries d
+				WHERE d.completed_at IS NULL AND d.next_attempt_at <= CURRENT_TIMESTAMP
+					AND (d.claimed_until IS NULL OR d.claimed_until < CURRENT_TIMESTAMP)
+					AND NOT EXISTS (
+						SELECT 1 FROM webhook_deliveries e
+						WHERE e.webhook_id = d.webhook_id AND e.completed_at IS NULL
+							AND e.claimed_until >= CURRENT_TIMESTAMP
+					)
+				ORDER BY d.webhook_id, d.next_attempt_at, d.created_at
+			) due
+			ORDER BY due.next_attempt_at
+			LIMIT $1
+		)
+		UPDATE webhook_deliveries SET claimed_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM claimable
+		WHERE webhook_deliveries.id = claimable.id
+		RETURNING webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.event_id,
+			webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.attempts,
+			COALESCE(webhook_deliveries.last_error, ''), webhook_deliveries.next_attempt_at, webhook_deliveries.created_at`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d := &domain.WebhookDelivery{}
+		if err := rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.EventID,
+			&d.EventType,
+			&d.Payload,
+			&d.Attempts,
+			&d.LastError,
+			&d.NextAttemptAt,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) CompleteDelivery(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE webhook_deliveries SET completed_at = CURRENT_TIMESTAMP, claimed_until = NULL WHERE id = $1`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	return dbError(ctx, err)
+}
+
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, id uuid.UUID, delay time.Duration, reason string) error {
+	query := `UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_error = $3,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), claimed_until = NULL
+		WHERE id = $1`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, delay.Seconds(), reason)
+	return dbError(ctx, err)
+}
+
+func (r *WebhookRepository) PurgeCompletedDeliveries(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := `DELETE FROM webhook_deliveries
+		WHERE completed_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	return purged, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{}
+	var eventTypes []string
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.UserID,
+		&webhook.URL,
+		pq.Array(&eventTypes),
+		&webhook.Secret,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.EventTypes = make([]domain.EventType, len(eventTypes))
+	for i, t := range eventTypes {
+		webhook.EventTypes[i] = domain.EventType(t)
+	}
+
+	return webhook, nil
+}
+
+func eventTypesToStrings(types []domain.EventType) []string {
+	result := make([]string, len(types))
+	for i, t := range types {
+		result[i] = string(t)
+	}
+	return result
+}
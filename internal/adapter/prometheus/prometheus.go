@@ -10,8 +10,16 @@ import (
 )
 
 type PrometheusAdapter struct {
-	httpRequestsTotal   *prometheus.CounterVec
-	httpRequestDuration *prometheus.HistogramVec
+	httpRequestsTotal    *prometheus.CounterVec
+	httpRequestDuration  *prometheus.HistogramVec
+	httpRequestsInFlight *prometheus.GaugeVec
+	userEnrichmentOK     *prometheus.CounterVec
+	userEnrichmentFailed *prometheus.CounterVec
+	bikeCacheHits        *prometheus.CounterVec
+	bikeCacheMisses      *prometheus.CounterVec
+	eventsPublished      *prometheus.CounterVec
+	eventPublishFailed   *prometheus.CounterVec
+	circuitBreakerState  *prometheus.GaugeVec
 }
 
 func NewPrometheusAdapter() ports.MetricsPort {
@@ -31,10 +39,74 @@ func NewPrometheusAdapter() ports.MetricsPort {
 			},
 			[]string{"path", "method", "status", "app_name"},
 		),
+		httpRequestsInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "http_requests_in_flight",
+				Help: "Number of HTTP requests currently being served",
+			},
+			[]string{"group", "app_name"},
+		),
+		userEnrichmentOK: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "user_enrichment_success_total",
+				Help: "Number of successful user-service enrichments",
+			},
+			[]string{"source", "app_name"},
+		),
+		userEnrichmentFailed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "user_enrichment_failures_total",
+				Help: "Number of responses degraded to user: null",
+			},
+			[]string{"reason", "app_name"},
+		),
+		bikeCacheHits: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "bike_cache_hits_total",
+				Help: "Number of bike lookups served from cache",
+			},
+			[]string{"operation", "app_name"},
+		),
+		bikeCacheMisses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "bike_cache_misses_total",
+				Help: "Number of bike lookups that went to the database",
+			},
+			[]string{"operation", "app_name"},
+		),
+		eventsPublished: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "events_published_total",
+				Help: "Number of domain events delivered to an external sink",
+			},
+			[]string{"sink", "app_name"},
+		),
+		eventPublishFailed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "event_publish_failures_total",
+				Help: "Number of failed domain event deliveries, retried by the outbox relay",
+			},
+			[]string{"sink", "app_name"},
+		),
+		circuitBreakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "circuit_breaker_state",
+				Help: "Circuit breaker state: 0 closed, 1 half-open, 2 open",
+			},
+			[]string{"name", "app_name"},
+		),
 	}
 
 	prometheus.MustRegister(adapter.httpRequestsTotal)
 	prometheus.MustRegister(adapter.httpRequestDuration)
+	prometheus.MustRegister(adapter.httpRequestsInFlight)
+	prometheus.MustRegister(adapter.userEnrichmentOK)
+	prometheus.MustRegister(adapter.userEnrichmentFailed)
+	prometheus.MustRegister(adapter.bikeCacheHits)
+	prometheus.MustRegister(adapter.bikeCacheMisses)
+	prometheus.MustRegister(adapter.eventsPublished)
+	prometheus.MustRegister(adapter.eventPublishFailed)
+	prometheus.MustRegister(adapter.circuitBreakerState)
 
 	// ебаная строчка
 	adapter.httpRequestsTotal.WithLabelValues("/health", "GET", "200", "bike_microservice").Add(0)
@@ -70,3 +142,39 @@ func (p *PrometheusAdapter) RecordMetrics(c *gin.Context, start time.Time) {
 	p.IncrementCounter("http_requests_total", labels)
 	p.RecordDuration("api_request_duration_seconds", time.Since(start), labels)
 }
+
+func (p *PrometheusAdapter) IncInFlight(group string) {
+	p.httpRequestsInFlight.WithLabelValues(group, "bike_microservice").Inc()
+}
+
+func (p *PrometheusAdapter) DecInFlight(group string) {
+	p.httpRequestsInFlight.WithLabelValues(group, "bike_microservice").Dec()
+}
+
+func (p *PrometheusAdapter) IncUserEnrichmentSuccess(source string) {
+	p.userEnrichmentOK.WithLabelValues(source, "bike_microservice").Inc()
+}
+
+func (p *PrometheusAdapter) IncUserEnrichmentFailure(reason string) {
+	p.userEnrichmentFailed.WithLabelValues(reason, "bike_microservice").Inc()
+}
+
+func (p *PrometheusAdapter) SetCircuitBreakerState(name string, state int) {
+	p.circuitBreakerState.WithLabelValues(name, "bike_microservice").Set(float64(state))
+}
+
+func (p *PrometheusAdapter) RecordCacheResult(operation string, hit bool) {
+	if hit {
+		p.bikeCacheHits.WithLabelValues(operation, "bike_microservice").Inc()
+		return
+	}
+	p.bikeCacheMisses.WithLabelValues(operation, "bike_microservice").Inc()
+}
+
+func (p *PrometheusAdapter) RecordEventPublish(sink string, ok bool) {
+	if ok {
+		p.eventsPublished.WithLabelValues(sink, "bike_microservice").Inc()
+		return
+	}
+	p.eventPublishFailed.WithLabelValues(sink, "bike_microservice").Inc()
+}
@@ -0,0 +1,161 @@
+package redis
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/memcache"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// как часто пробуем вернуться на redis после сбоя
+const fallbackRetryInterval = 5 * time.Second
+
+type invalidation struct {
+	key     string
+	pattern bool
+}
+
+// FallbackAdapter ходит в redis, а пока он недоступен - в локальный LRU.
+// Инвалидации, сделанные во время сбоя, проигрываются в redis после восстановления,
+// иначе там остались бы устаревшие записи
+type FallbackAdapter struct {
+	primary  ports.CachePort
+	fallback *memcache.MemoryCache
+	logger   ports.LoggerPort
+
+	mu         sync.Mutex
+	degraded   bool
+	retryAfter time.Time
+	pending    []invalidation
+	maxPending int
+	lostWrites bool
+}
+
+func NewFallbackAdapter(client *redis.Client, fallbackSize int, degraded bool, logger ports.LoggerPort) *FallbackAdapter {
+	a := &FallbackAdapter{
+		primary:    NewRedisAdapter(client),
+		fallback:   memcache.NewMemoryCache(fallbackSize),
+		logger:     logger,
+		maxPending: fallbackSize,
+	}
+	if degraded {
+		a.markDegraded(errors.New("redis unavailable at startup"))
+	}
+	return a
+}
+
+func (a *FallbackAdapter) Get(key string) ([]byte, error) {
+	if a.usePrimary() {
+		value, err := a.primary.Get(key)
+		if err == nil || errors.Is(err, ports.ErrCacheMiss) {
+			return value, err
+		}
+		a.markDegraded(err)
+	}
+	return a.fallback.Get(key)
+}
+
+func (a *FallbackAdapter) Set(key string, value []byte, ttl time.Duration) error {
+	if a.usePrimary() {
+		err := a.primary.Set(key, value, ttl)
+		if err == nil {
+			return nil
+		}
+		a.markDegraded(err)
+	}
+	return a.fallback.Set(key, value, ttl)
+}
+
+func (a *FallbackAdapter) Delete(key string) error {
+	if a.usePrimary() {
+		err := a.primary.Delete(key)
+		if err == nil {
+			return nil
+		}
+		a.markDegraded(err)
+	}
+	a.remember(invalidation{key: key})
+	return a.fallback.Delete(key)
+}
+
+func (a *FallbackAdapter) DeletePattern(pattern string) error {
+	if a.usePrimary() {
+		err := a.primary.DeletePattern(pattern)
+		if err == nil {
+			return nil
+		}
+		a.markDegraded(err)
+	}
+	a.remember(invalidation{key: pattern, pattern: true})
+	return a.fallback.DeletePattern(pattern)
+}
+
+// usePrimary решает, идти ли в redis. В деградации раз в fallbackRetryInterval
+// пробуем восстановиться: проигрываем накопленные инвалидации и чистим локальный кеш
+func (a *FallbackAdapter) usePrimary() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.degraded {
+		return true
+	}
+	if time.Now().Before(a.retryAfter) {
+		return false
+	}
+
+	for len(a.pending) > 0 {
+		inv := a.pending[0]
+		var err error
+		if inv.pattern {
+			err = a.primary.DeletePattern(inv.key)
+		} else {
+			err = a.primary.Delete(inv.key)
+		}
+		if err != nil {
+			a.retryAfter = time.Now().Add(fallbackRetryInterval)
+			return false
+		}
+		a.pending = a.pending[1:]
+	}
+
+	if a.lostWrites {
+		a.logger.Warn("Some cache invalidations were dropped while redis was down, entries may be stale until TTL", nil)
+	}
+
+	a.degraded = false
+	a.lostWrites = false
+	a.pending = nil
+	a.fallback.Purge()
+	a.logger.Info("Redis is back, leaving in-memory cache fallback", nil)
+	return true
+}
+
+func (a *FallbackAdapter) markDegraded(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.degraded {
+		a.logger.Warn("Redis unavailable, using in-memory cache fallback", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	a.degraded = true
+	a.retryAfter = time.Now().Add(fallbackRetryInterval)
+}
+
+func (a *FallbackAdapter) remember(inv invalidation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.pending) >= a.maxPending {
+		a.lostWrites = true
+		return
+	}
+	a.pending = append(a.pending, inv)
+}
+
+var _ ports.CachePort = (*FallbackAdapter)(nil)
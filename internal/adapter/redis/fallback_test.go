@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestFallback(t *testing.T, degraded bool) (*FallbackAdapter, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return NewFallbackAdapter(client, 10, degraded, &portstest.Logger{}), mr
+}
+
+func TestFallbackAdapterUsesRedisWhenHealthy(t *testing.T) {
+	cache, mr := newTestFallback(t, false)
+	if err := cache.Set("k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := mr.Get("k"); err != nil || got != "v" {
+		t.Errorf("redis k = %q, %v, want v", got, err)
+	}
+	if _, err := cache.fallback.Get("k"); !errors.Is(err, ports.ErrCacheMiss) {
+		t.Errorf("local cache used while redis is healthy")
+	}
+}
+
+func TestFallbackAdapterDegradesAndRecovers(t *testing.T) {
+	cache, mr := newTestFallback(t, false)
+	if err := cache.Set("stale", []byte("old"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// redis отвечает ошибкой: запись и чтение идут в локальный кеш
+	mr.SetError("LOADING redis is loading the dataset in memory")
+	if err := cache.Set("local", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("Set while degraded: %v", err)
+	}
+	if got, err := cache.Get("local"); err != nil || string(got) != "v" {
+		t.Fatalf("Get while degraded = %q, %v, want v", got, err)
+	}
+	// инвалидация во время сбоя должна дойти до redis после восстановления
+	if err := cache.Delete("stale"); err != nil {
+		t.Fatalf("Delete while degraded: %v", err)
+	}
+
+	mr.SetError("")
+	cache.mu.Lock()
+	cache.retryAfter = time.Time{}
+	cache.mu.Unlock()
+
+	if _, err := cache.Get("stale"); !errors.Is(err, ports.ErrCacheMiss) {
+		t.Errorf("Get(stale) after recovery err = %v, want ErrCacheMiss", err)
+	}
+	if mr.Exists("stale") {
+		t.Error("invalidation made while degraded was not replayed")
+	}
+	// локальный кеш сброшен, чтобы не отдавать его после возврата на redis
+	if _, err := cache.Get("local"); !errors.Is(err, ports.ErrCacheMiss) {
+		t.Errorf("Get(local) after recovery err = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestFallbackAdapterDegradedAtStartup(t *testing.T) {
+	cache, mr := newTestFallback(t, true)
+	if err := cache.Set("k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("k") {
+		t.Error("write went to redis before the retry interval")
+	}
+	if got, err := cache.Get("k"); err != nil || string(got) != "v" {
+		t.Errorf("Get = %q, %v, want v", got, err)
+	}
+}
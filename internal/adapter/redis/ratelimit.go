@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript пополняет и списывает корзину одним шагом. Время берётся
+// у redis, а не у реплик: их часы могут расходиться. Корзина - hash с числом
+// токенов и временем последнего пополнения в миллисекундах, живёт ровно
+// столько, сколько ей нужно, чтобы снова стать полной
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// TokenBucket - token bucket в redis, общий для всех реплик. Скрипт
+// выполняется атомарно, поэтому параллельные запросы одного пользователя
+// не видят одно и то же число токенов
+type TokenBucket struct {
+	client *redis.Client
+	prefix string
+	rate   float64
+	burst  int
+}
+
+// NewTokenBucket пропускает в среднем perSecond запросов в секунду и до burst
+// подряд. name разделяет корзины разных лимитов одного пользователя
+func NewTokenBucket(client *redis.Client, name string, perSecond float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		client: client,
+		prefix: "ratelimit:" + name + ":",
+		rate:   perSecond,
+		burst:  burst,
+	}
+}
+
+func (b *TokenBucket) Allow(ctx context.Context, key string) (ports.RateLimitQuota, error) {
+	result, err := tokenBucketScript.Run(ctx, b.client, []string{b.prefix + key}, b.rate, b.burst).Slice()
+	if err != nil {
+		return ports.RateLimitQuota{}, err
+	}
+	if len(result) != 2 {
+		return ports.RateLimitQuota{}, fmt.Errorf("unexpected rate limit script result: %v", result)
+	}
+	allowed, _ := result[0].(int64)
+	raw, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return ports.RateLimitQuota{}, fmt.Errorf("invalid token count %q: %w", raw, err)
+	}
+
+	return ports.RateLimitQuota{
+		Allowed:   allowed == 1,
+		Limit:     b.burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     b.secondsFor(float64(b.burst) - tokens),
+		Retry:     b.secondsFor(1 - tokens),
+	}, nil
+}
+
+func (b *TokenBucket) secondsFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+var _ ports.RateLimiter = (*TokenBucket)(nil)
@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestBucket(t *testing.T, perSecond float64, burst int) (*TokenBucket, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	// время redis стоит на месте, пока тест его не сдвинет
+	mr.SetTime(time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC))
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewTokenBucket(client, "test", perSecond, burst), mr
+}
+
+func TestTokenBucketRemainingDecrements(t *testing.T) {
+	bucket, _ := newTestBucket(t, 1, 3)
+	ctx := context.Background()
+
+	tests := []struct {
+		allowed   bool
+		remaining int
+		reset     time.Duration
+	}{
+		{allowed: true, remaining: 2, reset: time.Second},
+		{allowed: true, remaining: 1, reset: 2 * time.Second},
+		{allowed: true, remaining: 0, reset: 3 * time.Second},
+		{allowed: false, remaining: 0, reset: 3 * time.Second},
+	}
+	for i, tt := range tests {
+		q, err := bucket.Allow(ctx, "user")
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+		if q.Allowed != tt.allowed || q.Remaining != tt.remaining || q.Reset != tt.reset || q.Limit != 3 {
+			t.Errorf("request %d: got %+v, want allowed=%v remaining=%d reset=%s limit=3", i+1, q, tt.allowed, tt.remaining, tt.reset)
+		}
+	}
+}
+
+func TestTokenBucketRefills(t *testing.T) {
+	bucket, mr := newTestBucket(t, 2, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := bucket.Allow(ctx, "user"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q, err := bucket.Allow(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Allowed {
+		t.Fatalf("empty bucket allowed a request: %+v", q)
+	}
+	if q.Retry != 500*time.Millisecond {
+		t.Errorf("retry = %s, want 500ms", q.Retry)
+	}
+
+	mr.SetTime(time.Date(2025, 11, 1, 12, 0, 1, 0, time.UTC))
+	q, err = bucket.Allow(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Allowed || q.Remaining != 1 {
+		t.Errorf("after 1s at 2/s got %+v, want allowed with 1 remaining", q)
+	}
+}
+
+func TestTokenBucketKeysAreIndependent(t *testing.T) {
+	bucket, _ := newTestBucket(t, 1, 1)
+	ctx := context.Background()
+
+	for _, key := range []string{"alice", "bob"} {
+		q, err := bucket.Allow(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !q.Allowed {
+			t.Errorf("%s: first request rejected", key)
+		}
+	}
+}
+
+// параллельные запросы не должны получить один и тот же остаток:
+// каждый токен выдаётся ровно один раз
+func TestTokenBucketConcurrentRequests(t *testing.T) {
+	const burst, requests = 20, 50
+	bucket, _ := newTestBucket(t, 1, burst)
+	ctx := context.Background()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		remaining []int
+		rejected  int
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q, err := bucket.Allow(ctx, "user")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if q.Allowed {
+				remaining = append(remaining, q.Remaining)
+			} else {
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(remaining) != burst || rejected != requests-burst {
+		t.Fatalf("allowed %d, rejected %d; want %d and %d", len(remaining), rejected, burst, requests-burst)
+	}
+	sort.Ints(remaining)
+	for i, r := range remaining {
+		if r != i {
+			t.Fatalf("remaining values %v, want each of 0..%d exactly once", remaining, burst-1)
+		}
+	}
+}
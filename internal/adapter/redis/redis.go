@@ -23,7 +23,7 @@ func NewRedisAdapter(client *redis.Client) ports.CachePort {
 func (r *RedisAdapter) Get(key string) ([]byte, error) {
 	result, err := r.client.Get(r.ctx, key).Result()
 	if err == redis.Nil {
-		return nil, err
+		return nil, ports.ErrCacheMiss
 	}
 	if err != nil {
 		return nil, err
@@ -40,4 +40,29 @@ func (r *RedisAdapter) Delete(key string) error {
 	return r.client.Del(r.ctx, key).Err()
 }
 
+// DeletePattern удаляет все ключи по glob-шаблону, обходя keyspace через SCAN,
+// чтобы не блокировать redis как KEYS
+func (r *RedisAdapter) DeletePattern(pattern string) error {
+	iter := r.client.Scan(r.ctx, 0, pattern, 100).Iterator()
+
+	batch := make([]string, 0, 100)
+	for iter.Next(r.ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := r.client.Del(r.ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return r.client.Del(r.ctx, batch...).Err()
+	}
+	return nil
+}
+
 var _ ports.CachePort = (*RedisAdapter)(nil)
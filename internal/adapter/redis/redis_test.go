@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestAdapter(t *testing.T) (*RedisAdapter, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisAdapter(client).(*RedisAdapter), mr
+}
+
+func TestRedisAdapterDeletePattern(t *testing.T) {
+	const userA, userB = "0b6a3c5e-0000-0000-0000-00000000000a", "0b6a3c5e-0000-0000-0000-00000000000b"
+
+	tests := []struct {
+		name     string
+		keys     []string
+		pattern  string
+		wantKept []string
+	}{
+		{
+			name: "пространство пользователя",
+			keys: []string{
+				"u:" + userA + ":profile",
+				"u:" + userA + ":bikes:list:x",
+				"u:" + userA + ":suggest:brand:sh",
+				"u:" + userB + ":profile",
+				"bike:1",
+				"stats:fleet:80",
+			},
+			pattern:  "u:" + userA + ":*",
+			wantKept: []string{"u:" + userB + ":profile", "bike:1", "stats:fleet:80"},
+		},
+		{
+			name:     "только списки байков",
+			keys:     []string{"u:" + userA + ":bikes:list:x", "u:" + userA + ":bikes:count:x", "u:" + userA + ":profile"},
+			pattern:  "u:" + userA + ":bikes:*",
+			wantKept: []string{"u:" + userA + ":profile"},
+		},
+		{
+			name:     "нет подходящих ключей",
+			keys:     []string{"bike:1"},
+			pattern:  "u:" + userA + ":*",
+			wantKept: []string{"bike:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, mr := newTestAdapter(t)
+			for _, key := range tt.keys {
+				if err := cache.Set(key, []byte("v"), 0); err != nil {
+					t.Fatalf("Set(%s): %v", key, err)
+				}
+			}
+
+			if err := cache.DeletePattern(tt.pattern); err != nil {
+				t.Fatalf("DeletePattern: %v", err)
+			}
+			if got := mr.Keys(); len(got) != len(tt.wantKept) {
+				t.Fatalf("keys = %v, want %v", got, tt.wantKept)
+			}
+			for _, key := range tt.wantKept {
+				if !mr.Exists(key) {
+					t.Errorf("key %s was deleted", key)
+				}
+			}
+		})
+	}
+}
+
+// ключей больше, чем помещается в один батч DEL
+func TestRedisAdapterDeletePatternManyKeys(t *testing.T) {
+	cache, mr := newTestAdapter(t)
+	for i := range 250 {
+		if err := cache.Set(fmt.Sprintf("u:a:bikes:list:%d", i), []byte("v"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.Set("u:b:profile", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.DeletePattern("u:a:*"); err != nil {
+		t.Fatalf("DeletePattern: %v", err)
+	}
+	if got := mr.Keys(); len(got) != 1 || got[0] != "u:b:profile" {
+		t.Errorf("keys = %v, want [u:b:profile]", got)
+	}
+}
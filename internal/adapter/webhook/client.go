@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// errForbiddenAddress - вебхук ведёт на loopback, частную сеть или другой
+// адрес, куда сервис не должен ходить по запросу пользователя
+var errForbiddenAddress = errors.New("webhook address is not public")
+
+// newClient - HTTP-клиент доставки. control проверяет каждый адрес прямо
+// перед соединением, уже после DNS, поэтому перепривязка имени на внутренний
+// адрес после регистрации вебхука проверку не обходит. Редиректы не
+// выполняются: ответ 3xx считается неудачной попыткой
+func newClient(control func(network, address string, conn syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: deliveryTimeout, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// через прокси проверялся бы адрес прокси, а не подписчика
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicOnly - Control для net.Dialer, пропускает только публичные адреса
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublic(ip) {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, host)
+	}
+	return nil
+}
+
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsUnspecified()
+}
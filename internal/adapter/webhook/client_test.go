@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+func TestPublicOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		allowed bool
+	}{
+		{name: "публичный IPv4", address: "93.184.216.34:443", allowed: true},
+		{name: "публичный IPv6", address: "[2606:2800:220:1::1]:443", allowed: true},
+		{name: "loopback", address: "127.0.0.1:443"},
+		{name: "loopback не из 127.0.0.1", address: "127.1.2.3:8080"},
+		{name: "loopback IPv6", address: "[::1]:443"},
+		{name: "loopback через IPv4-mapped", address: "[::ffff:127.0.0.1]:443"},
+		{name: "частная 10/8", address: "10.0.0.5:443"},
+		{name: "частная 172.16/12", address: "172.16.0.1:443"},
+		{name: "частная 192.168/16", address: "192.168.1.1:443"},
+		{name: "частная IPv6 fc00::/7", address: "[fd00::1]:443"},
+		{name: "link-local, метаданные облака", address: "169.254.169.254:80"},
+		{name: "link-local IPv6", address: "[fe80::1]:443"},
+		{name: "unspecified", address: "0.0.0.0:443"},
+		{name: "unspecified IPv6", address: "[::]:443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := publicOnly("tcp", tt.address, nil)
+			if tt.allowed && err != nil {
+				t.Errorf("publicOnly(%s) = %v, want allowed", tt.address, err)
+			}
+			if !tt.allowed && !errors.Is(err, errForbiddenAddress) {
+				t.Errorf("publicOnly(%s) = %v, want errForbiddenAddress", tt.address, err)
+			}
+		})
+	}
+}
+
+// проверка стоит в транспорте, а не только при регистрации: адрес, в
+// который разрешилось имя, проверяется при каждой доставке
+func TestSendRejectsInternalAddress(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	d := &Dispatcher{client: newClient(publicOnly)}
+	err := d.send(context.Background(), &domain.Webhook{URL: server.URL, Secret: "secret"}, domain.BikeCreated, []byte(`{}`))
+	if !errors.Is(err, errForbiddenAddress) {
+		t.Errorf("send = %v, want errForbiddenAddress", err)
+	}
+	if calls.Load() != 0 {
+		t.Error("request reached a loopback subscriber")
+	}
+}
+
+// редирект не выполняется и считается неудачной доставкой
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	var calls atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	t.Cleanup(target.Close)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+
+	d := &Dispatcher{client: newClient(nil)}
+	if err := d.send(context.Background(), &domain.Webhook{URL: redirect.URL, Secret: "secret"}, domain.BikeCreated, []byte(`{}`)); err == nil {
+		t.Error("redirect response was treated as delivered")
+	}
+	if calls.Load() != 0 {
+		t.Error("redirect was followed")
+	}
+}
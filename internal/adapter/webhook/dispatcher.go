@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+
+	maxAttempts     = 5
+	initialBackoff  = 10 * time.Second
+	deliveryTimeout = 10 * time.Second
+
+	pollInterval = time.Second
+	// claimBatchSize - сколько вебхуков обслуживается за проход, параллельно
+	claimBatchSize = 20
+	// claimLease - сколько взятая доставка принадлежит одному воркеру.
+	// Больше deliveryTimeout, чтобы попытка успела закончиться до конца lease
+	claimLease = time.Minute
+	// completedRetention - сколько хранить завершённые доставки: пока строка
+	// есть, повтор события из outbox не доставит его ещё раз
+	completedRetention = 24 * time.Hour
+	purgeInterval      = time.Hour
+)
+
+// Dispatcher ставит доменные события в очереди вебхуков подписчиков (Publish,
+// его зовёт релей outbox) и отдельно от релея доставляет их (Run)
+type Dispatcher struct {
+	tx          ports.Transactor
+	webhookRepo ports.WebhookRepository
+	bikeRepo    ports.BikeRepository
+	logger      ports.LoggerPort
+	client      *http.Client
+}
+
+func NewDispatcher(
+	tx ports.Transactor,
+	webhookRepo ports.WebhookRepository,
+	bikeRepo ports.BikeRepository,
+	logger ports.LoggerPort,
+) *Dispatcher {
+	return &Dispatcher{
+		tx:          tx,
+		webhookRepo: webhookRepo,
+		bikeRepo:    bikeRepo,
+		logger:      logger,
+		client:      newClient(publicOnly),
+	}
+}
+
+// Publish ставит событие в очередь каждого подписанного вебхука владельца и
+// в сеть не ходит, поэтому недоступный подписчик релей не задерживает.
+// Ошибка - только если не удалось загрузить вебхуки или записать очередь:
+// тогда событие повторит релей, а уже поставленные доставки не задвоятся
+func (d *Dispatcher) Publish(ctx context.Context, event *domain.Event) error {
+	userID := event.UserID
+	if userID == uuid.Nil {
+		bike, err := d.bikeRepo.GetBikeByID(ctx, event.BikeID)
+		if errors.Is(err, domain.ErrBikeNotFound) {
+			// байк удалён, доставлять некому
+			d.logger.Warn("Event bike not found, webhooks skipped", map[string]interface{}{
+				"event_type": event.Type,
+				"bike_id":    event.BikeID,
+			})
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve bike owner: %w", err)
+		}
+		userID = bike.UserID
+	}
+
+	webhooks, err := d.webhookRepo.GetWebhooksByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, wh := range webhooks {
+		if !wh.Subscribed(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			ID:        uuid.New(),
+			WebhookID: wh.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   body,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := d.webhookRepo.EnqueueDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// Run доставляет события из очередей вебхуков до отмены ctx и раз в
+// purgeInterval чистит завершённые доставки
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	purgeAt := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.deliverBatch(ctx); err != nil && ctx.Err() == nil {
+				d.logger.Error("Webhook delivery failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			if time.Now().After(purgeAt) {
+				purgeAt = time.Now().Add(purgeInterval)
+				d.purge(ctx)
+			}
+		}
+	}
+}
+
+// deliverBatch берёт по одной доставке с вебхука и делает по одной попытке
+// параллельно. Каждая попытка не дольше deliveryTimeout, так что проход
+// укладывается в lease, сколько бы подписчиков ни лежало
+func (d *Dispatcher) deliverBatch(ctx context.Context) error {
+	var deliveries []*domain.WebhookDelivery
+	err := d.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		deliveries, err = d.webhookRepo.ClaimDueDeliveries(ctx, claimBatchSize, claimLease)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver делает одну попытку. Неудача откладывает следующую с backoff, а
+// после maxAttempts доставка уходит в dead letter. Прерванная остановкой
+// попытка не считается: доставку заберёт следующий проход после lease
+func (d *Dispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	// итог попытки записываем и при остановке воркера
+	store := context.WithoutCancel(ctx)
+	attempt := delivery.Attempts + 1
+
+	wh, err := d.webhookRepo.GetWebhookByID(store, delivery.WebhookID)
+	if err == nil {
+		err = d.send(ctx, wh, delivery.EventType, delivery.Payload)
+	}
+	if err == nil {
+		d.logger.Debug("Webhook delivered", map[string]interface{}{
+			"webhook_id": delivery.WebhookID,
+			"event_type": delivery.EventType,
+			"attempt":    attempt,
+		})
+		d.record(delivery, d.webhookRepo.CompleteDelivery(store, delivery.ID))
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	d.logger.Warn("Webhook delivery failed", map[string]interface{}{
+		"error":      err.Error(),
+		"webhook_id": delivery.WebhookID,
+		"event_type": delivery.EventType,
+		"attempt":    attempt,
+	})
+
+	if attempt < maxAttempts {
+		d.record(delivery, d.webhookRepo.RetryDelivery(store, delivery.ID, initialBackoff<<(attempt-1), err.Error()))
+		return
+	}
+
+	deadLetter := &domain.WebhookDeadLetter{
+		ID:        uuid.New(),
+		WebhookID: delivery.WebhookID,
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		Payload:   delivery.Payload,
+		Error:     err.Error(),
+		Attempts:  attempt,
+	}
+	d.record(delivery, d.tx.WithinTransaction(store, func(ctx context.Context) error {
+		if err := d.webhookRepo.CreateDeadLetter(ctx, deadLetter); err != nil {
+			return err
+		}
+		return d.webhookRepo.CompleteDelivery(ctx, delivery.ID)
+	}))
+}
+
+// record логирует сбой записи итога. Доставка тогда остаётся взятой и
+// повторится после lease
+func (d *Dispatcher) record(delivery *domain.WebhookDelivery, err error) {
+	if err == nil {
+		return
+	}
+	d.logger.Error("Failed to record webhook delivery result", map[string]interface{}{
+		"error":       err.Error(),
+		"delivery_id": delivery.ID,
+		"webhook_id":  delivery.WebhookID,
+		"event_id":    delivery.EventID,
+	})
+}
+
+func (d *Dispatcher) purge(ctx context.Context) {
+	purged, err := d.webhookRepo.PurgeCompletedDeliveries(ctx, completedRetention)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error("Failed to purge webhook deliveries", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	if purged > 0 {
+		d.logger.Info("Purged completed webhook deliveries", map[string]interface{}{
+			"count": purged,
+		})
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, wh *domain.Webhook, eventType domain.EventType, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(SignatureHeader, Sign(wh.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign считает HMAC-SHA256 тела запроса, получатель проверяет его своим секретом
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var _ ports.EventPublisher = (*Dispatcher)(nil)
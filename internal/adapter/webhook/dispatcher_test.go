@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/google/uuid"
+)
+
+// newTestDispatcher - диспетчер, которому можно на loopback тестовых серверов
+func newTestDispatcher(store *portstest.Store) *Dispatcher {
+	d := NewDispatcher(store, store, store, &portstest.Logger{})
+	d.client = newClient(nil)
+	return d
+}
+
+// subscriber - вебхук с тестовым сервером, отвечающим status
+type subscriber struct {
+	webhook *domain.Webhook
+	calls   atomic.Int32
+}
+
+func addSubscriber(t *testing.T, store *portstest.Store, userID uuid.UUID, status int) *subscriber {
+	t.Helper()
+	sub := &subscriber{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	webhook, err := store.CreateWebhook(context.Background(), &domain.Webhook{
+		UserID:     userID,
+		URL:        server.URL,
+		EventTypes: []domain.EventType{domain.BikeCreated},
+		Secret:     "webhook-secret-0123456789",
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	sub.webhook = webhook
+	return sub
+}
+
+// Publish только ставит события в очередь: сеть не трогает, повтор не задваивает
+func TestPublishEnqueuesDeliveries(t *testing.T) {
+	store := portstest.NewStore()
+	d := newTestDispatcher(store)
+	owner := uuid.New()
+	sub := addSubscriber(t, store, owner, http.StatusOK)
+	addSubscriber(t, store, uuid.New(), http.StatusOK)
+
+	event := domain.NewEvent(domain.BikeCreated, owner, uuid.New(), nil)
+	for range 2 {
+		if err := d.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	pending := store.PendingDeliveries()
+	if len(pending) != 1 || pending[0].WebhookID != sub.webhook.ID || pending[0].EventID != event.ID {
+		t.Fatalf("pending deliveries = %+v, want one for the owner's webhook", pending)
+	}
+	if calls := sub.calls.Load(); calls != 0 {
+		t.Errorf("Publish made %d HTTP calls, want none", calls)
+	}
+}
+
+// лежащий подписчик не задерживает остальных: его доставка откладывается
+// с backoff, а после maxAttempts уходит в dead letter
+func TestDeliverBatchRetriesPerWebhook(t *testing.T) {
+	store := portstest.NewStore()
+	now := time.Now()
+	store.Now = func() time.Time { return now }
+	d := newTestDispatcher(store)
+	ctx := context.Background()
+	owner := uuid.New()
+	healthy := addSubscriber(t, store, owner, http.StatusOK)
+	dead := addSubscriber(t, store, owner, http.StatusInternalServerError)
+
+	for range 2 {
+		if err := d.Publish(ctx, domain.NewEvent(domain.BikeCreated, owner, uuid.New(), nil)); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	// за проход - одна доставка на вебхук
+	if err := d.deliverBatch(ctx); err != nil {
+		t.Fatalf("deliverBatch: %v", err)
+	}
+	if healthy.calls.Load() != 1 || dead.calls.Load() != 1 {
+		t.Fatalf("calls: healthy %d, dead %d, want 1 and 1", healthy.calls.Load(), dead.calls.Load())
+	}
+
+	// вторые события уходят обоим сразу, а повтор первого у лежащего ждёт backoff
+	if err := d.deliverBatch(ctx); err != nil {
+		t.Fatalf("deliverBatch: %v", err)
+	}
+	if healthy.calls.Load() != 2 || dead.calls.Load() != 2 {
+		t.Fatalf("calls: healthy %d, dead %d, want 2 and 2", healthy.calls.Load(), dead.calls.Load())
+	}
+	if err := d.deliverBatch(ctx); err != nil {
+		t.Fatalf("deliverBatch: %v", err)
+	}
+	if calls := dead.calls.Load(); calls != 2 {
+		t.Fatalf("dead webhook retried before backoff: %d calls", calls)
+	}
+
+	for range 2 * maxAttempts {
+		now = now.Add(time.Hour)
+		if err := d.deliverBatch(ctx); err != nil {
+			t.Fatalf("deliverBatch: %v", err)
+		}
+	}
+	if pending := store.PendingDeliveries(); len(pending) != 0 {
+		t.Errorf("pending deliveries = %d, want none", len(pending))
+	}
+	deadLetters := store.DeadLetters()
+	if len(deadLetters) != 2 {
+		t.Fatalf("dead letters = %d, want 2", len(deadLetters))
+	}
+	for _, dl := range deadLetters {
+		if dl.WebhookID != dead.webhook.ID || dl.Attempts != maxAttempts {
+			t.Errorf("dead letter = %+v, want webhook %s after %d attempts", dl, dead.webhook.ID, maxAttempts)
+		}
+	}
+	if calls := dead.calls.Load(); calls != 2*maxAttempts {
+		t.Errorf("dead webhook calls = %d, want %d", calls, 2*maxAttempts)
+	}
+}
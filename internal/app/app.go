@@ -3,15 +3,21 @@ package app
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	nethttp "net/http"
+	"sync"
+	"time"
 
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/handler/http"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/kafka"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/logger"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/postgres"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/prometheus"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/redis"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/adapter/webhook"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/config"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/services"
@@ -26,9 +32,20 @@ type App struct {
 	Config       *config.Container
 	Logger       ports.LoggerPort
 	DB           *sql.DB
+	ReplicaDB    *sql.DB
 	RedisClient  *redisClient.Client
 	RedisAdapter ports.CachePort
 	HTTPRouter   *http.Router
+	OutboxRelay  *services.OutboxRelay
+	Webhooks     *webhook.Dispatcher
+	Idempotency  *services.IdempotencyPurger
+	Kafka        *kafka.Publisher // nil, если KAFKA_BROKERS не задан
+	APIKeys      *http.APIKeyService
+
+	startedAt time.Time
+	stopRelay context.CancelFunc
+	relayDone chan struct{}
+	serveErr  chan error
 }
 
 func New(ctx context.Context, cfg *config.Container) (*App, error) {
@@ -39,99 +56,289 @@ func New(ctx context.Context, cfg *config.Container) (*App, error) {
 		"env": cfg.App.Env,
 	})
 
+	// Зависимости ждём по очереди в пределах общего бюджета STARTUP_TIMEOUT.
+	// При ошибке закрываем всё, что успели открыть
+	waiter := newStartupWaiter(cfg.App.StartupTimeout, cfg.App.StartupRetryInterval, loggerAdapter)
+	var closers []func() error
+	fail := func(err error) (*App, error) {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+		return nil, err
+	}
+
 	// Set redis
 	redisConn := redisClient.NewClient(&redisClient.Options{
 		Addr:     cfg.Redis.Address,
 		Password: cfg.Redis.Password,
 		DB:       0,
 	})
-	if _, err := redisConn.Ping(ctx).Result(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	closers = append(closers, redisConn.Close)
+	redisDep := dependency{name: "redis", check: func(ctx context.Context) error {
+		return redisConn.Ping(ctx).Err()
+	}}
+	var cacheAdapter ports.CachePort
+	if cfg.Redis.FallbackEnabled {
+		// без redis стартуем сразу на локальном кеше, адаптер сам вернётся на redis
+		err := redisDep.check(ctx)
+		if err != nil {
+			loggerAdapter.Warn("Redis is not reachable, starting with in-memory cache", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		cacheAdapter = redis.NewFallbackAdapter(redisConn, cfg.Redis.FallbackSize, err != nil, loggerAdapter)
+	} else {
+		if err := waiter.wait(ctx, redisDep); err != nil {
+			return fail(fmt.Errorf("failed to connect to Redis: %w", err))
+		}
+		cacheAdapter = redis.NewRedisAdapter(redisConn)
 	}
-	cacheAdapter := redis.NewRedisAdapter(redisConn)
 
 	// Connect DB
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.Password, cfg.DB.Name)
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", postgresDSN(cfg.DB))
 	if err != nil {
-		return nil, fmt.Errorf("Failed to connect to database:%w", err)
+		return fail(fmt.Errorf("Failed to connect to database:%w", err))
 	}
-
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("Failed to ping database:%w", err)
+	closers = append(closers, db.Close)
+	if err := waiter.wait(ctx, dependency{name: "postgres", check: db.PingContext}); err != nil {
+		return fail(fmt.Errorf("Failed to ping database:%w", err))
 	}
 
-	// Migrate DB
+	// Migrate DB. Не повторяем: база уже отвечает, и ошибка миграции сама не пройдёт
 	if err := goose.Up(db, "./internal/adapter/postgres/migrations"); err != nil {
-		return nil, fmt.Errorf("Failed to run migrations:%w", err)
+		return fail(fmt.Errorf("Failed to run migrations:%w", err))
+	}
+
+	// Connect read replica
+	var replicaDB *sql.DB
+	if cfg.DBReplica != nil {
+		replicaDB, err = sql.Open("postgres", postgresDSN(cfg.DBReplica))
+		if err != nil {
+			return fail(fmt.Errorf("Failed to connect to replica database:%w", err))
+		}
+		closers = append(closers, replicaDB.Close)
+		if err := waiter.wait(ctx, dependency{name: "postgres replica", check: replicaDB.PingContext}); err != nil {
+			return fail(fmt.Errorf("Failed to ping replica database:%w", err))
+		}
+		loggerAdapter.Info("Read replica enabled", map[string]interface{}{
+			"host": cfg.DBReplica.Host,
+		})
 	}
 
 	// Validate
 	validate := validator.New()
+	if err := services.RegisterValidators(validate); err != nil {
+		return fail(fmt.Errorf("failed to register validators: %w", err))
+	}
 
 	// Observability
 	metrics := prometheus.NewPrometheusAdapter()
 
 	// Repositories
-	bikeRepo := postgres.NewBikeRepository(db)
-	componentRepo := postgres.NewComponentRepository(db)
+	bikeRepo := postgres.NewBikeRepository(db, replicaDB)
+	componentRepo := postgres.NewComponentRepository(db, replicaDB)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+	transactor := postgres.NewTransactor(db)
+	statsRepo := postgres.NewStatsRepository(db, replicaDB)
+
+	// Events
+	webhookDispatcher := webhook.NewDispatcher(transactor, webhookRepo, bikeRepo, loggerAdapter)
+	var kafkaPublisher *kafka.Publisher
+	var brokerPublisher ports.EventPublisher = services.NopPublisher{}
+	if len(cfg.Kafka.Brokers) > 0 {
+		kafkaPublisher = kafka.NewPublisher(cfg.Kafka.Brokers, cfg.Kafka.Topic, metrics)
+		closers = append(closers, kafkaPublisher.Close)
+		brokerPublisher = kafkaPublisher
+		loggerAdapter.Info("Kafka event publishing enabled", map[string]interface{}{
+			"brokers": cfg.Kafka.Brokers,
+			"topic":   cfg.Kafka.Topic,
+		})
+	}
+	outboxRelay := services.NewOutboxRelay(transactor, outboxRepo, services.NewFanoutPublisher(brokerPublisher, webhookDispatcher), loggerAdapter)
 
 	// Services
-	bikeService := services.NewBikeService(bikeRepo, componentRepo, loggerAdapter, validate, cacheAdapter)
-	componentService := services.NewComponentService(componentRepo, loggerAdapter, validate, cacheAdapter)
+	bikeService := services.NewBikeService(bikeRepo, componentRepo, loggerAdapter, validate, cacheAdapter, transactor, outboxRepo, idempotencyRepo, metrics)
+	componentService := services.NewComponentService(componentRepo, bikeRepo, loggerAdapter, validate, cacheAdapter, transactor, outboxRepo, idempotencyRepo, cfg.App.StrictComponentYear)
+	statsService := services.NewStatsService(statsRepo, loggerAdapter, cacheAdapter)
+	webhookService := services.NewWebhookService(webhookRepo, loggerAdapter, validate, cfg.App.Env == "production")
 
-	// User service client init
-	transport := httptransport.New(cfg.UserService.URL, "", []string{"http"})
+	// User service client init. Без user-service не работает только обогащение
+	// ответов данными пользователя, поэтому его недоступность не мешает старту
+	// адрес уже проверен в cfg.Validate
+	userScheme, userHost, userBasePath, _ := cfg.UserService.Endpoint()
+	loggerAdapter.Info("User service endpoint", map[string]interface{}{
+		"scheme":    userScheme,
+		"host":      userHost,
+		"base_path": userBasePath,
+	})
+	if err := waiter.wait(ctx, dependency{name: "user service", check: tcpReachable(cfg.UserService.DialAddress())}); err != nil {
+		loggerAdapter.Warn("User service is not reachable, starting without it", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	transport := httptransport.New(userHost, userBasePath, []string{userScheme})
 	userClient := user_client.New(transport, strfmt.Default)
 
 	// HTTP Handlers
-	tokenService := http.NewJWTTokenService(cfg.Token.Secret, loggerAdapter)
-	bikeHandler := http.NewBikeHandler(bikeService, loggerAdapter, metrics, userClient)
-	componentHandler := http.NewComponentHandler(componentService, bikeService, loggerAdapter, metrics)
+	// отозванные токены и флаг обслуживания - только в redis: локальный
+	// fallback-кеш ответил бы промахом вместо ошибки, и fail-closed не сработал
+	// бы, а флаг разошёлся бы между репликами
+	sharedStore := redis.NewRedisAdapter(redisConn)
+	tokenService := http.NewJWTTokenService(cfg.Token.Secrets, cfg.Token.Algorithms, cfg.Token.Leeway, sharedStore, cfg.Token.RevocationFailClosed, loggerAdapter)
+	apiKeyService := http.NewAPIKeyService(cfg.APIKeys, loggerAdapter)
+	bikeHandler := http.NewBikeHandler(bikeService, loggerAdapter, metrics, userClient, cfg.UserService, cfg.Pagination, cacheAdapter, cfg.HTTP.PublicURL, cfg.HTTP.MaxBatchSize, cfg.App.WarnThresholdPercent)
+	componentHandler := http.NewComponentHandler(componentService, bikeService, loggerAdapter, metrics, cfg.Pagination, cfg.HTTP.MaxBatchSize, cfg.App.WarnThresholdPercent)
+	webhookHandler := http.NewWebhookHandler(webhookService, loggerAdapter, metrics)
+	statsHandler := http.NewStatsHandler(statsService, loggerAdapter, metrics, cfg.App.WarnThresholdPercent)
+	maintenance := http.NewMaintenance(cfg.App.Maintenance, sharedStore, loggerAdapter, metrics)
+	tokenHandler := http.NewTokenHandler(tokenService, loggerAdapter, metrics)
 
 	// Init HTTP router
 	router, err := http.NewRouter(
 		cfg.HTTP,
 		tokenService,
+		apiKeyService,
+		loggerAdapter,
+		metrics,
 		bikeHandler,
 		componentHandler,
+		webhookHandler,
+		statsHandler,
+		maintenance,
+		tokenHandler,
+		append(readinessChecks(db, replicaDB, redisConn, cfg.Redis.FallbackEnabled),
+			bikeHandler.UserServiceReadiness(tcpReachable(cfg.UserService.DialAddress()))),
+		redis.NewTokenBucket(redisConn, "user", float64(cfg.HTTP.RateLimitPerSecond), cfg.HTTP.RateLimitBurst),
+		redis.NewTokenBucket(redisConn, "suggest", http.SuggestRateLimitPerSecond, http.SuggestRateLimitBurst),
 	)
 	if err != nil {
-		db.Close()
-		redisConn.Close()
-		return nil, fmt.Errorf("failed to initialize router: %w", err)
+		return fail(fmt.Errorf("failed to initialize router: %w", err))
 	}
 
 	return &App{
 		Config:       cfg,
 		Logger:       loggerAdapter,
 		DB:           db,
+		ReplicaDB:    replicaDB,
 		RedisClient:  redisConn,
 		RedisAdapter: cacheAdapter,
 		HTTPRouter:   router,
+		OutboxRelay:  outboxRelay,
+		Webhooks:     webhookDispatcher,
+		Idempotency:  services.NewIdempotencyPurger(idempotencyRepo, loggerAdapter),
+		Kafka:        kafkaPublisher,
+		APIKeys:      apiKeyService,
+		startedAt:    time.Now(),
 	}, nil
 }
 
+// ReloadAPIKeys перечитывает ключи сервисов. При ошибке остаётся старый набор
+func (a *App) ReloadAPIKeys() error {
+	apiKeys, err := config.LoadAPIKeys()
+	if err == nil {
+		err = apiKeys.Validate()
+	}
+	if err != nil {
+		a.Logger.Error("Failed to reload API keys", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	a.APIKeys.Reload(apiKeys)
+	return nil
+}
+
 // Runs all services
 func (a *App) Run() error {
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	a.stopRelay = stopRelay
+	a.relayDone = make(chan struct{})
+	go func() {
+		defer close(a.relayDone)
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			a.OutboxRelay.Run(relayCtx)
+		}()
+		// вебхуки доставляются отдельно: релей только ставит их в очередь
+		go func() {
+			defer wg.Done()
+			a.Webhooks.Run(relayCtx)
+		}()
+		go func() {
+			defer wg.Done()
+			a.Idempotency.Run(relayCtx)
+		}()
+		wg.Wait()
+	}()
+
 	listenAddr := fmt.Sprintf("%s:%s", a.Config.HTTP.URL, a.Config.HTTP.Port)
 	a.Logger.Info("Starting HTTP server", map[string]interface{}{
 		"addr": listenAddr,
 	})
 
-	if err := a.HTTPRouter.Serve(listenAddr); err != nil {
+	ln, err := a.HTTPRouter.Listen(listenAddr)
+	if err != nil {
 		a.Logger.Error("HTTP server error", map[string]interface{}{
 			"error": err.Error(),
 		})
 		return err
 	}
+
+	// Сервер работает в фоне, остановка - через Stop
+	a.serveErr = make(chan error, 1)
+	go func() {
+		if err := a.HTTPRouter.Serve(ln); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			a.Logger.Error("HTTP server error", map[string]interface{}{
+				"error": err.Error(),
+			})
+			a.serveErr <- err
+		}
+	}()
 	return nil
 }
 
+// ServeErr - ошибка, с которой HTTP-сервер упал сам, без вызова Stop
+func (a *App) ServeErr() <-chan error {
+	return a.serveErr
+}
+
 // Stops all services
 func (a *App) Stop(ctx context.Context) error {
 	a.Logger.Info("Shutting down gracefully...", nil)
+	clean := true
+
+	// Дожидаемся начатых запросов, пока БД и Redis ещё открыты
+	if err := a.HTTPRouter.Shutdown(ctx); err != nil {
+		clean = false
+		a.Logger.Warn("HTTP server did not drain in time", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	// Stop outbox relay, webhook delivery and key purge before closing the database
+	if a.stopRelay != nil {
+		a.stopRelay()
+		select {
+		case <-a.relayDone:
+		case <-ctx.Done():
+			clean = false
+			a.Logger.Warn("Background workers did not stop in time", nil)
+		}
+	}
+
+	// Relay остановлен, новых сообщений в Kafka не будет
+	if a.Kafka != nil {
+		if err := a.Kafka.Close(); err != nil {
+			a.Logger.Error("Kafka publisher close error", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
 
 	// Close database
 	if err := a.DB.Close(); err != nil {
@@ -140,6 +347,15 @@ func (a *App) Stop(ctx context.Context) error {
 		})
 	}
 
+	// Close read replica
+	if a.ReplicaDB != nil {
+		if err := a.ReplicaDB.Close(); err != nil {
+			a.Logger.Error("Replica database close error", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	// Close Redis
 	if err := a.RedisClient.Close(); err != nil {
 		a.Logger.Error("Redis close error", map[string]interface{}{
@@ -147,6 +363,30 @@ func (a *App) Stop(ctx context.Context) error {
 		})
 	}
 
-	a.Logger.Info("Application stopped successfully", nil)
+	a.Logger.Info("Application stopped", map[string]interface{}{
+		"uptime":          time.Since(a.startedAt).Round(time.Second).String(),
+		"requests_served": a.HTTPRouter.RequestsServed(),
+		"clean":           clean,
+	})
 	return nil
 }
+
+func postgresDSN(cfg *config.DB) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
+}
+
+// readinessChecks - зависимости для /ready. Redis с локальным кешем не
+// обязателен: без него сервис работает, хоть и деградированно
+func readinessChecks(db, replicaDB *sql.DB, redisConn *redisClient.Client, cacheFallback bool) []http.ReadinessCheck {
+	checks := []http.ReadinessCheck{
+		{Name: "postgres", Check: db.PingContext},
+		{Name: "redis", Check: func(ctx context.Context) error {
+			return redisConn.Ping(ctx).Err()
+		}, Optional: cacheFallback},
+	}
+	if replicaDB != nil {
+		checks = append(checks, http.ReadinessCheck{Name: "postgres_replica", Check: replicaDB.PingContext})
+	}
+	return checks
+}
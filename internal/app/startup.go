@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+// startupCheckTimeout - сколько ждать ответа на одну проверку, чтобы
+// зависший connect не съел весь бюджет старта
+const startupCheckTimeout = 5 * time.Second
+
+// dependency - внешняя зависимость, готовность которой проверяется при старте
+type dependency struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// startupWaiter ждёт зависимости в пределах общего на весь старт бюджета.
+// В деплое база и Redis часто поднимаются на несколько секунд позже сервиса,
+// и падать на первой же ошибке значит уходить в crash loop
+type startupWaiter struct {
+	deadline time.Time
+	interval time.Duration
+	logger   ports.LoggerPort
+}
+
+func newStartupWaiter(budget, interval time.Duration, logger ports.LoggerPort) *startupWaiter {
+	return &startupWaiter{
+		deadline: time.Now().Add(budget),
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// wait проверяет зависимость каждые interval, пока проверка не пройдёт
+// или не кончится бюджет. Ошибка называет зависимость и последнюю причину
+func (w *startupWaiter) wait(ctx context.Context, dep dependency) error {
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		checkCtx, cancel := context.WithTimeout(ctx, startupCheckTimeout)
+		err := dep.check(checkCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				w.logger.Info("Dependency is ready", map[string]interface{}{
+					"dependency": dep.name,
+					"attempts":   attempt,
+					"waited":     time.Since(started).Round(time.Millisecond).String(),
+				})
+			}
+			return nil
+		}
+
+		remaining := time.Until(w.deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%s is not ready after %d attempts in %s: %w",
+				dep.name, attempt, time.Since(started).Round(time.Second), err)
+		}
+		w.logger.Warn("Waiting for dependency", map[string]interface{}{
+			"dependency": dep.name,
+			"attempt":    attempt,
+			"error":      err.Error(),
+			"remaining":  remaining.Round(time.Second).String(),
+		})
+
+		timer := time.NewTimer(min(w.interval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: startup canceled: %w", dep.name, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// tcpReachable - проверка, что по адресу принимают соединения. Для user-service
+// этого достаточно: health-эндпоинта у его клиента нет. Адрес без порта - это порт 80
+func tcpReachable(address string) func(ctx context.Context) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "80")
+	}
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+)
+
+var errNotReady = errors.New("connection refused")
+
+// flakyCheck - проверка, которая падает failures раз, а потом проходит
+func flakyCheck(failures int) (check func(context.Context) error, calls *int) {
+	calls = new(int)
+	return func(context.Context) error {
+		*calls++
+		if *calls <= failures {
+			return errNotReady
+		}
+		return nil
+	}, calls
+}
+
+func TestStartupWaiterWait(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		budget    time.Duration
+		wantErr   bool
+		wantCalls int
+		// wantWaitLog - ожидание попало в лог
+		wantWaitLog bool
+	}{
+		{name: "готова сразу", budget: time.Second, wantCalls: 1},
+		{name: "поднялась через несколько попыток", failures: 3, budget: time.Second, wantCalls: 4, wantWaitLog: true},
+		{name: "не поднялась за бюджет", failures: 1000, budget: 20 * time.Millisecond, wantErr: true, wantWaitLog: true},
+		{name: "бюджет исчерпан до первой проверки", failures: 1000, budget: 0, wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &portstest.Logger{}
+			waiter := newStartupWaiter(tt.budget, time.Millisecond, logger)
+			check, calls := flakyCheck(tt.failures)
+
+			err := waiter.wait(context.Background(), dependency{name: "postgres", check: check})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, errNotReady) || !strings.HasPrefix(err.Error(), "postgres is not ready")) {
+				t.Errorf("err = %v, want it to name the dependency and wrap the last error", err)
+			}
+			if tt.wantCalls != 0 && *calls != tt.wantCalls {
+				t.Errorf("checks = %d, want %d", *calls, tt.wantCalls)
+			}
+			if _, logged := logger.Find("Waiting for dependency"); logged != tt.wantWaitLog {
+				t.Errorf("wait logged = %t, want %t", logged, tt.wantWaitLog)
+			}
+			if _, logged := logger.Find("Dependency is ready"); logged != (tt.wantWaitLog && !tt.wantErr) {
+				t.Errorf("ready logged = %t", logged)
+			}
+		})
+	}
+}
+
+// бюджет общий на весь старт: медленная первая зависимость съедает время следующих
+func TestStartupWaiterSharedBudget(t *testing.T) {
+	waiter := newStartupWaiter(30*time.Millisecond, time.Millisecond, &portstest.Logger{})
+
+	slow, _ := flakyCheck(1000)
+	if err := waiter.wait(context.Background(), dependency{name: "redis", check: slow}); err == nil {
+		t.Fatal("redis: want timeout")
+	}
+	next, calls := flakyCheck(1)
+	err := waiter.wait(context.Background(), dependency{name: "postgres", check: next})
+	if err == nil || !strings.HasPrefix(err.Error(), "postgres") || *calls != 1 {
+		t.Errorf("postgres: err = %v after %d checks, want immediate timeout", err, *calls)
+	}
+}
+
+func TestStartupWaiterCanceled(t *testing.T) {
+	waiter := newStartupWaiter(time.Minute, time.Hour, &portstest.Logger{})
+	ctx, cancel := context.WithCancel(context.Background())
+	check, _ := flakyCheck(1000)
+
+	done := make(chan error, 1)
+	go func() { done <- waiter.wait(ctx, dependency{name: "redis", check: check}) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || !strings.HasPrefix(err.Error(), "redis") {
+			t.Errorf("err = %v, want canceled redis wait", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not stop on cancel")
+	}
+}
+
+func TestTCPReachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	if err := tcpReachable(address)(context.Background()); err != nil {
+		t.Errorf("open port: err = %v", err)
+	}
+
+	listener.Close()
+	if err := tcpReachable(address)(context.Background()); err == nil {
+		t.Error("closed port: want error")
+	}
+}
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,19 +18,44 @@ type (
 		App         *App
 		Token       *Token
 		DB          *DB
+		DBReplica   *DB
 		HTTP        *HTTP
 		Redis       *Redis
 		UserService *UserService
+		Kafka       *Kafka
+		Pagination  *Pagination
+		APIKeys     *APIKeys
 	}
 
 	App struct {
 		Name string
 		Env  string
+		// Режим обслуживания, пока общий флаг в redis ни разу не переключали
+		// через /admin/maintenance
+		Maintenance bool
+		// Отклонять компоненты, установленные раньше модельного года байка,
+		// по умолчанию только предупреждение в логе
+		StrictComponentYear bool
+		// Сколько ждать Redis, Postgres и user-service при старте и как часто
+		// их проверять. 0 - одна попытка без ожидания
+		StartupTimeout       time.Duration
+		StartupRetryInterval time.Duration
+		// WarnThresholdPercent - с какого процента износа компонент в ответах
+		// помечается warning, если в запросе не передан warn_threshold_percent
+		WarnThresholdPercent int
 	}
 
 	Token struct {
-		Secret   string
-		Duration string
+		// Secrets - текущий секрет первым, дальше предыдущие на время ротации
+		Secrets []string
+		// Algorithms - какие алгоритмы подписи принимаем, по умолчанию только HS256
+		Algorithms []string
+		Duration   string
+		// Leeway - допуск на расхождение часов с сервисом авторизации при проверке exp, nbf и iat
+		Leeway time.Duration
+		// RevocationFailClosed - отказывать во входе, если кеш отозванных токенов недоступен.
+		// По умолчанию такие токены пропускаются
+		RevocationFailClosed bool
 	}
 
 	DB struct {
@@ -39,18 +71,122 @@ type (
 		Port           string
 		AllowedOrigins string
 		URL            string
+		// Внешний адрес API для ссылок в QR-кодах, без него в QR кладётся ID байка
+		PublicURL string
+		// Таймауты сервера, защищают от медленных клиентов (slowloris)
+		ReadHeaderTimeout time.Duration
+		ReadTimeout       time.Duration
+		WriteTimeout      time.Duration
+		IdleTimeout       time.Duration
+		// Отладочный лог тел запросов и ответов, по умолчанию выключен.
+		// DebugBodyRoutes - шаблоны роутов gin, например "/bikes/:id"
+		DebugBodyRoutes   []string
+		DebugBodyMaxBytes int
+		// В production включается только вместе с DEBUG_BODY_LOG_FORCE=true
+		DebugBodyForce bool
+		// MaxBatchSize - общий потолок элементов в любом батче, поверх лимитов эндпоинтов
+		MaxBatchSize int
+		// ReadinessTimeout - сколько /ready ждёт каждую зависимость
+		ReadinessTimeout time.Duration
+		// лимит запросов одного пользователя на все реплики: в среднем
+		// RateLimitPerSecond в секунду и до RateLimitBurst подряд
+		RateLimitPerSecond int
+		RateLimitBurst     int
 	}
 
 	Redis struct {
 		Address  string
 		Password string
+		// Локальный LRU на случай недоступности redis, включается явно
+		FallbackEnabled bool
+		FallbackSize    int
 	}
 
 	UserService struct {
+		// полный адрес, например https://users.internal:8443/api.
+		// Без схемы ("users:8080") считается http
 		URL string
+		// Timeout - потолок одного запроса к user-service
+		Timeout time.Duration
+		// после BreakerFailures сбоев подряд запросы не отправляются
+		// BreakerOpenTimeout, затем пропускается пробный
+		BreakerFailures    int
+		BreakerOpenTimeout time.Duration
+		// ReadinessCritical - недоступный user-service делает /ready 503,
+		// а не degraded. По умолчанию байки работают и без него
+		ReadinessCritical bool
+	}
+
+	Kafka struct {
+		// без брокеров события уходят только в вебхуки
+		Brokers []string
+		Topic   string
+	}
+
+	Pagination struct {
+		DefaultLimit int
+		MaxLimit     int
+	}
+
+	// APIKeys - ключи внутренних сервисов. Берутся из API_KEYS_FILE
+	// (по строке "service:key"), иначе из API_KEYS ("service:key,service2:key2")
+	APIKeys struct {
+		File string
+		Keys []APIKey
+	}
+
+	APIKey struct {
+		Service string
+		Key     string
 	}
 )
 
+const (
+	defaultPageLimit = 50
+	defaultMaxLimit  = 200
+
+	minAPIKeyLength = 32
+
+	defaultCacheFallbackSize = 10000
+
+	// user-service рядом при локальном запуске
+	defaultUserServiceURL = "http://localhost:8080"
+
+	// профиль - необязательное обогащение, ждать его дольше пары секунд незачем
+	defaultUserServiceTimeout            = 2 * time.Second
+	defaultUserServiceBreakerFailures    = 5
+	defaultUserServiceBreakerOpenTimeout = 30 * time.Second
+
+	// Дефолты таймаутов HTTP-сервера:
+	// заголовки должны прийти за 5s, весь запрос за 15s,
+	// ответ уйти за 30s, keep-alive соединение живёт без запросов 2m
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 15 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+
+	defaultDebugBodyMaxBytes = 4096
+
+	defaultMaxBatchSize = 500
+
+	defaultWarnThresholdPercent = 80
+
+	defaultKafkaTopic = "bike-events"
+
+	defaultReadinessTimeout = 2 * time.Second
+
+	defaultRateLimitPerSecond = 10
+	defaultRateLimitBurst     = 50
+
+	// зависимости в деплое поднимаются за секунды, минуты хватает с запасом
+	defaultStartupTimeout       = time.Minute
+	defaultStartupRetryInterval = 2 * time.Second
+
+	// больше пары минут - это уже не расхождение часов, а продление жизни токена
+	defaultTokenLeeway = 30 * time.Second
+	maxTokenLeeway     = 5 * time.Minute
+)
+
 func New() (*Container, error) {
 	if os.Getenv("APP_ENV") != "production" {
 		err := godotenv.Load()
@@ -62,11 +198,31 @@ func New() (*Container, error) {
 	app := &App{
 		Name: os.Getenv("APP_NAME"),
 		Env:  os.Getenv("APP_ENV"),
+
+		Maintenance:         os.Getenv("MAINTENANCE_MODE") == "true",
+		StrictComponentYear: os.Getenv("COMPONENT_YEAR_CHECK_STRICT") == "true",
+
+		StartupTimeout:       durationEnv("STARTUP_TIMEOUT", defaultStartupTimeout),
+		StartupRetryInterval: durationEnv("STARTUP_RETRY_INTERVAL", defaultStartupRetryInterval),
+
+		WarnThresholdPercent: intEnv("WEAR_WARN_THRESHOLD_PERCENT", defaultWarnThresholdPercent),
 	}
 
+	// TOKEN_SECRETS главнее, TOKEN_SECRET оставлен для старых окружений
+	secrets := listEnv("TOKEN_SECRETS")
+	if len(secrets) == 0 && os.Getenv("TOKEN_SECRET") != "" {
+		secrets = []string{os.Getenv("TOKEN_SECRET")}
+	}
 	token := &Token{
-		Secret:   os.Getenv("TOKEN_SECRET"),
-		Duration: os.Getenv("TOKEN_DURATION"),
+		Secrets:    secrets,
+		Algorithms: listEnv("TOKEN_ALGORITHMS"),
+		Duration:   os.Getenv("TOKEN_DURATION"),
+		Leeway:     durationEnv("TOKEN_LEEWAY", defaultTokenLeeway),
+
+		RevocationFailClosed: os.Getenv("TOKEN_REVOCATION_FAIL_CLOSED") == "true",
+	}
+	if len(token.Algorithms) == 0 {
+		token.Algorithms = []string{"HS256"}
 	}
 
 	db := &DB{
@@ -77,28 +233,329 @@ func New() (*Container, error) {
 		Name:     os.Getenv("DB_NAME"),
 	}
 
+	// Реплика необязательна: без DB_REPLICA_HOST всё читается с primary.
+	// Непереданные учётные данные берутся от primary
+	var dbReplica *DB
+	if host := os.Getenv("DB_REPLICA_HOST"); host != "" {
+		dbReplica = &DB{
+			Host:     host,
+			Port:     envOr("DB_REPLICA_PORT", db.Port),
+			User:     envOr("DB_REPLICA_USER", db.User),
+			Password: envOr("DB_REPLICA_PASSWORD", db.Password),
+			Name:     envOr("DB_REPLICA_NAME", db.Name),
+		}
+	}
+
 	http := &HTTP{
 		Port:           os.Getenv("HTTP_PORT"),
 		AllowedOrigins: os.Getenv("ALLOWED_ORIGINS"),
 		URL:            os.Getenv("HTTP_URL"),
+		PublicURL:      strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"),
 		Env:            os.Getenv("APP_ENV"),
+
+		ReadHeaderTimeout: durationEnv("HTTP_READ_HEADER_TIMEOUT", defaultReadHeaderTimeout),
+		ReadTimeout:       durationEnv("HTTP_READ_TIMEOUT", defaultReadTimeout),
+		WriteTimeout:      durationEnv("HTTP_WRITE_TIMEOUT", defaultWriteTimeout),
+		IdleTimeout:       durationEnv("HTTP_IDLE_TIMEOUT", defaultIdleTimeout),
+
+		DebugBodyRoutes:   listEnv("DEBUG_BODY_LOG_ROUTES"),
+		DebugBodyMaxBytes: intEnv("DEBUG_BODY_LOG_MAX_BYTES", defaultDebugBodyMaxBytes),
+		DebugBodyForce:    os.Getenv("DEBUG_BODY_LOG_FORCE") == "true",
+
+		MaxBatchSize:     intEnv("MAX_BATCH_SIZE", defaultMaxBatchSize),
+		ReadinessTimeout: durationEnv("READINESS_TIMEOUT", defaultReadinessTimeout),
+
+		RateLimitPerSecond: intEnv("RATE_LIMIT_PER_SECOND", defaultRateLimitPerSecond),
+		RateLimitBurst:     intEnv("RATE_LIMIT_BURST", defaultRateLimitBurst),
 	}
 
 	redis := &Redis{
 		Address:  os.Getenv("REDIS_ADDRESS"),
 		Password: os.Getenv("REDIS_PASSWORD"),
+
+		FallbackEnabled: os.Getenv("CACHE_FALLBACK_ENABLED") == "true",
+		FallbackSize:    intEnv("CACHE_FALLBACK_SIZE", defaultCacheFallbackSize),
 	}
 
 	userService := &UserService{
-		URL: os.Getenv("USER_SERVICE_URL"),
+		URL:                envOr("USER_SERVICE_URL", defaultUserServiceURL),
+		Timeout:            durationEnv("USER_SERVICE_TIMEOUT", defaultUserServiceTimeout),
+		BreakerFailures:    intEnv("USER_SERVICE_BREAKER_FAILURES", defaultUserServiceBreakerFailures),
+		BreakerOpenTimeout: durationEnv("USER_SERVICE_BREAKER_OPEN_TIMEOUT", defaultUserServiceBreakerOpenTimeout),
+		ReadinessCritical:  os.Getenv("USER_SERVICE_READINESS_CRITICAL") == "true",
+	}
+
+	kafka := &Kafka{
+		Brokers: listEnv("KAFKA_BROKERS"),
+		Topic:   envOr("KAFKA_TOPIC", defaultKafkaTopic),
+	}
+
+	pagination := &Pagination{
+		DefaultLimit: intEnv("PAGINATION_DEFAULT_LIMIT", defaultPageLimit),
+		MaxLimit:     intEnv("PAGINATION_MAX_LIMIT", defaultMaxLimit),
+	}
+
+	apiKeys, err := LoadAPIKeys()
+	if err != nil {
+		return nil, err
 	}
 
 	return &Container{
 		App:         app,
 		Token:       token,
 		DB:          db,
+		DBReplica:   dbReplica,
 		HTTP:        http,
 		Redis:       redis,
 		UserService: userService,
+		Kafka:       kafka,
+		Pagination:  pagination,
+		APIKeys:     apiKeys,
 	}, nil
 }
+
+// LoadAPIKeys читает ключи заново, вызывается и при старте, и при перезагрузке
+func LoadAPIKeys() (*APIKeys, error) {
+	apiKeys := &APIKeys{File: os.Getenv("API_KEYS_FILE")}
+
+	var entries []string
+	if apiKeys.File != "" {
+		data, err := os.ReadFile(apiKeys.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API_KEYS_FILE: %w", err)
+		}
+		entries = strings.Split(string(data), "\n")
+	} else {
+		entries = strings.Split(os.Getenv("API_KEYS"), ",")
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		service, key, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("API key entry must look like service:key")
+		}
+		apiKeys.Keys = append(apiKeys.Keys, APIKey{
+			Service: strings.TrimSpace(service),
+			Key:     strings.TrimSpace(key),
+		})
+	}
+
+	return apiKeys, nil
+}
+
+// Validate проверяет, что у каждого ключа есть сервис и ключ достаточной длины
+func (a *APIKeys) Validate() error {
+	var errs []error
+	for i, k := range a.Keys {
+		if k.Service == "" {
+			errs = append(errs, fmt.Errorf("API key #%d has no service name", i+1))
+		}
+		if len(k.Key) < minAPIKeyLength {
+			errs = append(errs, fmt.Errorf("API key for %q must be at least %d characters", k.Service, minAPIKeyLength))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет все обязательные настройки и возвращает
+// одну ошибку со списком всех отсутствующих или некорректных
+func (c *Container) Validate() error {
+	var errs []error
+
+	required := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+	port := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+			return
+		}
+		if p, err := strconv.Atoi(value); err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be a valid port, got %q", name, value))
+		}
+	}
+
+	if c.App.StartupTimeout < 0 {
+		errs = append(errs, fmt.Errorf("STARTUP_TIMEOUT must be a non-negative duration like 60s"))
+	}
+	if c.App.StartupRetryInterval <= 0 {
+		errs = append(errs, fmt.Errorf("STARTUP_RETRY_INTERVAL must be a positive duration like 2s"))
+	}
+	if c.App.WarnThresholdPercent < 1 || c.App.WarnThresholdPercent > 100 {
+		errs = append(errs, fmt.Errorf("WEAR_WARN_THRESHOLD_PERCENT must be an integer from 1 to 100"))
+	}
+
+	if len(c.Token.Secrets) == 0 {
+		errs = append(errs, fmt.Errorf("TOKEN_SECRETS or TOKEN_SECRET is required"))
+	}
+	for _, alg := range c.Token.Algorithms {
+		// секреты общие, так что годятся только HMAC
+		if alg != "HS256" && alg != "HS384" && alg != "HS512" {
+			errs = append(errs, fmt.Errorf("TOKEN_ALGORITHMS supports only HS256, HS384, HS512, got %q", alg))
+		}
+	}
+	if c.Token.Duration != "" {
+		if _, err := time.ParseDuration(c.Token.Duration); err != nil {
+			errs = append(errs, fmt.Errorf("TOKEN_DURATION must be a duration like 15m, got %q", c.Token.Duration))
+		}
+	}
+	if c.Token.Leeway < 0 || c.Token.Leeway > maxTokenLeeway {
+		errs = append(errs, fmt.Errorf("TOKEN_LEEWAY must be a duration from 0s to %s", maxTokenLeeway))
+	}
+
+	required("DB_HOST", c.DB.Host)
+	port("DB_PORT", c.DB.Port)
+	required("DB_USER", c.DB.User)
+	required("DB_NAME", c.DB.Name)
+
+	if c.DBReplica != nil {
+		port("DB_REPLICA_PORT", c.DBReplica.Port)
+	}
+
+	port("HTTP_PORT", c.HTTP.Port)
+	positive := func(name string, value time.Duration) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration like 10s", name))
+		}
+	}
+	positive("HTTP_READ_HEADER_TIMEOUT", c.HTTP.ReadHeaderTimeout)
+	positive("HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout)
+	positive("HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout)
+	positive("HTTP_IDLE_TIMEOUT", c.HTTP.IdleTimeout)
+	positive("READINESS_TIMEOUT", c.HTTP.ReadinessTimeout)
+	if c.HTTP.MaxBatchSize < 1 {
+		errs = append(errs, fmt.Errorf("MAX_BATCH_SIZE must be a positive integer"))
+	}
+	if c.HTTP.RateLimitPerSecond < 1 {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_PER_SECOND must be a positive integer"))
+	}
+	if c.HTTP.RateLimitBurst < 1 {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_BURST must be a positive integer"))
+	}
+
+	if len(c.HTTP.DebugBodyRoutes) > 0 {
+		if c.HTTP.Env == "production" && !c.HTTP.DebugBodyForce {
+			errs = append(errs, fmt.Errorf("DEBUG_BODY_LOG_ROUTES is not allowed in production without DEBUG_BODY_LOG_FORCE=true"))
+		}
+		if c.HTTP.DebugBodyMaxBytes < 1 {
+			errs = append(errs, fmt.Errorf("DEBUG_BODY_LOG_MAX_BYTES must be a positive integer"))
+		}
+	}
+
+	required("REDIS_ADDRESS", c.Redis.Address)
+	if c.Redis.FallbackEnabled && c.Redis.FallbackSize < 1 {
+		errs = append(errs, fmt.Errorf("CACHE_FALLBACK_SIZE must be a positive integer"))
+	}
+
+	if _, _, _, err := c.UserService.Endpoint(); err != nil {
+		errs = append(errs, fmt.Errorf("USER_SERVICE_URL: %w", err))
+	}
+	positive("USER_SERVICE_TIMEOUT", c.UserService.Timeout)
+	positive("USER_SERVICE_BREAKER_OPEN_TIMEOUT", c.UserService.BreakerOpenTimeout)
+	if c.UserService.BreakerFailures < 1 {
+		errs = append(errs, fmt.Errorf("USER_SERVICE_BREAKER_FAILURES must be a positive integer"))
+	}
+
+	if c.Pagination.DefaultLimit < 1 {
+		errs = append(errs, fmt.Errorf("PAGINATION_DEFAULT_LIMIT must be a positive integer"))
+	}
+	if c.Pagination.MaxLimit < c.Pagination.DefaultLimit {
+		errs = append(errs, fmt.Errorf("PAGINATION_MAX_LIMIT must not be less than PAGINATION_DEFAULT_LIMIT"))
+	}
+
+	if err := c.APIKeys.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Endpoint разбирает URL user-service на схему, host:port и базовый путь
+// для HTTP-транспорта клиента
+func (u *UserService) Endpoint() (scheme, host, basePath string, err error) {
+	raw := u.URL
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", "", "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", "", "", fmt.Errorf("scheme must be http or https, got %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return "", "", "", errors.New("host must not be empty")
+	}
+	return parsed.Scheme, parsed.Host, strings.TrimSuffix(parsed.Path, "/"), nil
+}
+
+// DialAddress - host:port user-service для проверки доступности,
+// без порта берётся порт по умолчанию для схемы
+func (u *UserService) DialAddress() string {
+	scheme, host, _, err := u.Endpoint()
+	if err != nil {
+		return u.URL
+	}
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	if scheme == "https" {
+		return net.JoinHostPort(host, "443")
+	}
+	return net.JoinHostPort(host, "80")
+}
+
+// intEnv возвращает fallback, если переменная не задана.
+// Некорректное значение превращается в -1 и отлавливается в Validate
+func intEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// durationEnv работает как intEnv: некорректное значение даёт -1
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return -1
+	}
+	return d
+}
+
+// listEnv разбирает значение через запятую, пустые элементы выкидывает
+func listEnv(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BikeSpecVersion - версия формата спецификации, меняется при несовместимых правках
+const BikeSpecVersion = 1
+
+// MaxSpecComponents - сколько компонентов можно импортировать одной спецификацией
+const MaxSpecComponents = 100
+
+var ErrInvalidBikeSpec = errors.New("invalid bike spec")
+
+// BikeSpec - сборка байка для обмена: без ID, владельца, пробега и истории
+// установки, только то, что нужно, чтобы повторить сборку на другом аккаунте
+type BikeSpec struct {
+	Version    int             `json:"version" example:"1"`
+	BikeName   string          `json:"bike_name,omitempty" validate:"max=100" example:"Gravel commuter"`
+	Type       BikeType        `json:"type" validate:"required" example:"road"`
+	Model      string          `json:"model" validate:"required,max=100" example:"Canyon Grail"`
+	Year       *int            `json:"year,omitempty" validate:"omitempty,bike_year" example:"2022"`
+	Components []ComponentSpec `json:"components" validate:"dive"`
+}
+
+type ComponentSpec struct {
+	Name       ComponentName `json:"name" validate:"required,oneof=handlebars frame wheels" example:"wheels"`
+	Brand      string        `json:"brand,omitempty" validate:"max=100" example:"DT Swiss"`
+	Model      string        `json:"model,omitempty" validate:"max=100" example:"G 1800"`
+	Position   Position      `json:"position,omitempty" validate:"omitempty,oneof=front rear left right none" example:"front"`
+	MaxMileage int           `json:"max_mileage,omitempty" validate:"omitempty,min=1,max=1000000" example:"15000"`
+	MaxAgeDays *int          `json:"max_age_days,omitempty" validate:"omitempty,min=1,max=36500" example:"1825"`
+}
+
+// NewBikeSpec собирает спецификацию из байка с загруженными компонентами
+func NewBikeSpec(b *Bike) *BikeSpec {
+	spec := &BikeSpec{
+		Version:    BikeSpecVersion,
+		BikeName:   b.BikeName,
+		Type:       b.Type,
+		Model:      b.Model,
+		Year:       b.Year,
+		Components: make([]ComponentSpec, 0, len(b.Components)),
+	}
+	for _, c := range b.Components {
+		spec.Components = append(spec.Components, ComponentSpec{
+			Name:       c.Name,
+			Brand:      c.Brand,
+			Model:      c.Model,
+			Position:   c.Position,
+			MaxMileage: c.MaxMileage,
+			MaxAgeDays: c.MaxAgeDays,
+		})
+	}
+	return spec
+}
+
+// Validate - правила, которые не выразить тегами: версия, пороги и
+// одна позиция на парный компонент
+func (s *BikeSpec) Validate() error {
+	if s.Version != BikeSpecVersion {
+		return fmt.Errorf("unsupported spec version %d, expected %d", s.Version, BikeSpecVersion)
+	}
+	if err := s.Type.Validate(); err != nil {
+		return err
+	}
+	if len(s.Components) > MaxSpecComponents {
+		return fmt.Errorf("at most %d components allowed, got %d", MaxSpecComponents, len(s.Components))
+	}
+
+	type slot struct {
+		name     ComponentName
+		position Position
+	}
+	taken := make(map[slot]bool)
+	for i, c := range s.Components {
+		if c.MaxMileage == 0 && c.MaxAgeDays == nil {
+			return fmt.Errorf("components[%d]: %w", i, ErrNoReplacementThreshold)
+		}
+		if !PairedComponents[c.Name] || c.Position == "" || c.Position == PositionNone {
+			continue
+		}
+		key := slot{c.Name, c.Position}
+		if taken[key] {
+			return fmt.Errorf("components[%d]: %w", i, ErrDuplicatePosition)
+		}
+		taken[key] = true
+	}
+	return nil
+}
+
+// Build создаёт из спецификации новый байк для userID. Компоненты считаются
+// установленными сейчас на нулевом пробеге
+func (s *BikeSpec) Build(userID uuid.UUID, now time.Time) *Bike {
+	bike := &Bike{
+		UserID:     userID,
+		BikeID:     uuid.New(),
+		BikeName:   s.BikeName,
+		Type:       s.Type,
+		Model:      s.Model,
+		Year:       s.Year,
+		Components: make([]*Component, 0, len(s.Components)),
+	}
+	for _, c := range s.Components {
+		component := &Component{
+			ID:          uuid.New(),
+			BikeID:      bike.BikeID,
+			Name:        c.Name,
+			Brand:       c.Brand,
+			Model:       c.Model,
+			Position:    c.Position,
+			MaxMileage:  c.MaxMileage,
+			MaxAgeDays:  c.MaxAgeDays,
+			InstalledAt: now,
+		}
+		component.NormalizePosition()
+		bike.Components = append(bike.Components, component)
+	}
+	return bike
+}
@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,12 +17,57 @@ type Bike struct {
 	Type       BikeType     `json:"type"`
 	Model      string       `json:"model"`
 	Components []*Component `json:"components,omitempty"`
-	Year       int          `json:"year"`
+	Year       *int         `json:"year" validate:"omitempty,bike_year"` // nil - год неизвестен
 	Mileage    int          `json:"mileage"`
 	CreatedAt  time.Time    `json:"created_at"`
 	UpdatedAt  time.Time    `json:"updated_at"`
+	ArchivedAt *time.Time   `json:"archived_at,omitempty"` // nil - байк в строю
+	DeletedAt  *time.Time   `json:"deleted_at,omitempty"`  // не nil - удалён, но его можно восстановить
 }
 
+// ErrBikeNotFound - байка нет, в том числе если его удалили между
+// проверкой и записью компонента
+var ErrBikeNotFound = errors.New("bike not found")
+
+// ErrBikeNotDeleted - восстанавливать нечего, байк не удалён
+var ErrBikeNotDeleted = errors.New("bike is not deleted")
+
+// MinBikeYear - раньше велосипеды в учёт не попадают
+const MinBikeYear = 1900
+
+// MaxBikeYear - модели следующего года продаются уже в текущем
+func MaxBikeYear(now time.Time) int {
+	return now.Year() + 1
+}
+
+// ErrInvalidBikeYear - год вне MinBikeYear..MaxBikeYear
+var ErrInvalidBikeYear = errors.New("invalid bike year")
+
+// NewBikeYearError называет допустимый диапазон, верхняя граница зависит от now
+func NewBikeYearError(now time.Time) error {
+	return fmt.Errorf("%w: year must be between %d and %d", ErrInvalidBikeYear, MinBikeYear, MaxBikeYear(now))
+}
+
+// ErrSameOwner - байк передают тому, кто им уже владеет
+var ErrSameOwner = errors.New("bike already belongs to this user")
+
+// ErrOwnerChanged - пока шла передача, владельца байка успели сменить:
+// разрешение проверялось для прежнего владельца
+var ErrOwnerChanged = errors.New("bike owner was changed concurrently")
+
+// BikeTransfer - данные события bike.transferred
+type BikeTransfer struct {
+	PreviousUserID uuid.UUID `json:"previous_user_id"`
+	Bike           *Bike     `json:"bike"`
+}
+
+// ErrMileageDecrease - новый пробег меньше сохранённого, одометр назад не крутится
+var ErrMileageDecrease = errors.New("mileage cannot decrease")
+
+// ErrMileageConflict - пробег успели увеличить параллельным запросом
+// уже после проверки, запись не прошла условие mileage <= новому
+var ErrMileageConflict = errors.New("mileage was changed concurrently")
+
 type BikeType string
 
 const (
@@ -26,3 +75,160 @@ const (
 	MTB  BikeType = "mtb"
 	Road BikeType = "road"
 )
+
+// BikeTypes - все допустимые типы байков
+var BikeTypes = []BikeType{BMX, MTB, Road}
+
+// ErrInvalidBikeType - тип байка не из BikeTypes
+var ErrInvalidBikeType = errors.New("invalid bike type")
+
+func (t BikeType) IsValid() bool {
+	return slices.Contains(BikeTypes, t)
+}
+
+// Validate возвращает ErrInvalidBikeType со списком допустимых значений
+func (t BikeType) Validate() error {
+	if !t.IsValid() {
+		return fmt.Errorf("%w %q, expected one of %v", ErrInvalidBikeType, t, BikeTypes)
+	}
+	return nil
+}
+
+// BikeFilter - необязательные условия для списков байков
+type BikeFilter struct {
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// false - только байки в строю, true - только архивные
+	Archived bool
+	// вместе с удалёнными, только для админов
+	IncludeDeleted bool
+	// Sort учитывается только в списке байков пользователя, пустой - created_at_desc
+	Sort BikeSort
+	Page Page
+}
+
+// BikeSort - порядок списка байков: поле и направление через подчёркивание
+type BikeSort string
+
+const (
+	BikeSortCreatedAtDesc BikeSort = "created_at_desc"
+	BikeSortCreatedAtAsc  BikeSort = "created_at_asc"
+	BikeSortMileageDesc   BikeSort = "mileage_desc"
+	BikeSortMileageAsc    BikeSort = "mileage_asc"
+	BikeSortNameAsc       BikeSort = "name_asc"
+	BikeSortNameDesc      BikeSort = "name_desc"
+	BikeSortYearDesc      BikeSort = "year_desc"
+	BikeSortYearAsc       BikeSort = "year_asc"
+)
+
+// BikeSorts - все допустимые значения sort в порядке для документации и ошибок
+var BikeSorts = []BikeSort{
+	BikeSortCreatedAtDesc, BikeSortCreatedAtAsc,
+	BikeSortMileageDesc, BikeSortMileageAsc,
+	BikeSortNameAsc, BikeSortNameDesc,
+	BikeSortYearDesc, BikeSortYearAsc,
+}
+
+func (f BikeFilter) Validate() error {
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore) {
+		return errors.New("created_after must not be later than created_before")
+	}
+	if f.Sort != "" && !slices.Contains(BikeSorts, f.Sort) {
+		return fmt.Errorf("unknown sort %q, expected one of %v", f.Sort, BikeSorts)
+	}
+	return nil
+}
+
+// BikeListFilter - условия админского списка всех байков. Пустые поля не фильтруют
+type BikeListFilter struct {
+	Type   BikeType
+	UserID uuid.UUID
+	// подстрока модели без учёта регистра
+	Model         string
+	MinMileage    *int
+	MaxMileage    *int
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// вместе с удалёнными
+	IncludeDeleted bool
+	Sort           BikeSort
+	Page           Page
+}
+
+func (f BikeListFilter) Validate() error {
+	if f.Type != "" {
+		if err := f.Type.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.MinMileage != nil && *f.MinMileage < 0 || f.MaxMileage != nil && *f.MaxMileage < 0 {
+		return errors.New("mileage bounds must not be negative")
+	}
+	if f.MinMileage != nil && f.MaxMileage != nil && *f.MinMileage > *f.MaxMileage {
+		return errors.New("min_mileage must not be greater than max_mileage")
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore) {
+		return errors.New("created_after must not be later than created_before")
+	}
+	if f.Sort != "" && !slices.Contains(BikeSorts, f.Sort) {
+		return fmt.Errorf("unknown sort %q, expected one of %v", f.Sort, BikeSorts)
+	}
+	return nil
+}
+
+// BikeSummary - сводка по байкам одного пользователя
+type BikeSummary struct {
+	Total        int              `json:"total"`
+	ByType       map[BikeType]int `json:"by_type"`
+	TotalMileage int              `json:"total_mileage"`
+}
+
+func SummarizeBikes(bikes []*Bike) BikeSummary {
+	summary := BikeSummary{ByType: map[BikeType]int{}}
+	for _, b := range bikes {
+		summary.Total++
+		summary.ByType[b.Type]++
+		summary.TotalMileage += b.Mileage
+	}
+	return summary
+}
+
+// BikeBulkUpdate - одна правка сразу для многих байков.
+// Отбор либо по списку ID, либо по текущему типу
+type BikeBulkUpdate struct {
+	IDs         []uuid.UUID
+	CurrentType BikeType
+	Field       BikeField
+	Value       string
+}
+
+// BikeField - поля, которые разрешено менять массово
+type BikeField string
+
+const (
+	BikeFieldType  BikeField = "type"
+	BikeFieldModel BikeField = "model"
+)
+
+func (u BikeBulkUpdate) Validate() error {
+	if (len(u.IDs) == 0) == (u.CurrentType == "") {
+		return errors.New("exactly one of ids or current type must be set")
+	}
+	if u.Field != BikeFieldType && u.Field != BikeFieldModel {
+		return fmt.Errorf("field %q cannot be bulk updated", u.Field)
+	}
+	if strings.TrimSpace(u.Value) == "" {
+		return errors.New("value must not be blank")
+	}
+	// неверный тип в таблицу не попадает по CHECK, так что отбор по нему
+	// - опечатка, которая тихо не нашла бы ни одного байка
+	if u.CurrentType != "" {
+		if err := u.CurrentType.Validate(); err != nil {
+			return err
+		}
+	}
+	if u.Field == BikeFieldType {
+		return BikeType(u.Value).Validate()
+	}
+	return nil
+}
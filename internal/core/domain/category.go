@@ -0,0 +1,101 @@
+package domain
+
+import "time"
+
+// ComponentCategory - узел байка, к которому относится компонент
+type ComponentCategory string
+
+const (
+	Drivetrain ComponentCategory = "drivetrain"
+	WheelSet   ComponentCategory = "wheels"
+	Cockpit    ComponentCategory = "cockpit"
+	Brakes     ComponentCategory = "brakes"
+	FrameSet   ComponentCategory = "frame"
+	Other      ComponentCategory = "other"
+)
+
+// CategoryOrder - порядок категорий в сгруппированных ответах
+var CategoryOrder = []ComponentCategory{Drivetrain, WheelSet, Cockpit, Brakes, FrameSet, Other}
+
+// ComponentCategories - к какой категории относится каждое известное название.
+// Всё, чего здесь нет, попадает в other
+var ComponentCategories = map[ComponentName]ComponentCategory{
+	Handlebars: Cockpit,
+	Wheels:     WheelSet,
+	Frame:      FrameSet,
+}
+
+func (n ComponentName) Category() ComponentCategory {
+	if category, ok := ComponentCategories[n]; ok {
+		return category
+	}
+	return Other
+}
+
+// ComponentGroup - компоненты одной категории со сводкой по износу.
+// Износ - худший из порогов (пробег или возраст), 1 и больше значит пора менять
+type ComponentGroup struct {
+	Category   ComponentCategory `json:"category"`
+	Status     WearStatus        `json:"status"`
+	Count      int               `json:"count"`
+	Warning    int               `json:"warning"`
+	Overdue    int               `json:"overdue"`
+	MaxWear    float64           `json:"max_wear"`
+	AvgWear    float64           `json:"avg_wear"`
+	Components []ComponentWear   `json:"components"`
+}
+
+// ComponentWear - компонент с износом и сработавшими порогами
+type ComponentWear struct {
+	*Component
+	Wear     float64              `json:"wear"`
+	Status   WearStatus           `json:"wear_status"`
+	Triggers []ReplacementTrigger `json:"triggers"`
+}
+
+// GroupByCategory раскладывает компоненты байка по категориям в порядке CategoryOrder,
+// пустые категории не попадают в результат. Status группы - по самому изношенному компоненту
+func (b *Bike) GroupByCategory(warnPercent int) []ComponentGroup {
+	now := time.Now()
+	byCategory := make(map[ComponentCategory]*ComponentGroup)
+	for _, c := range b.Components {
+		category := c.Name.Category()
+		group, ok := byCategory[category]
+		if !ok {
+			group = &ComponentGroup{Category: category}
+			byCategory[category] = group
+		}
+
+		wear := c.Wear(b.Mileage, now)
+		status := WearStatusOf(wear, warnPercent)
+		group.Components = append(group.Components, ComponentWear{
+			Component: c,
+			Wear:      wear,
+			Status:    status,
+			Triggers:  c.Triggers(b.Mileage, now),
+		})
+		group.Count++
+		group.AvgWear += wear
+		if wear > group.MaxWear {
+			group.MaxWear = wear
+		}
+		switch status {
+		case WearOverdue:
+			group.Overdue++
+		case WearWarning:
+			group.Warning++
+		}
+	}
+
+	groups := make([]ComponentGroup, 0, len(byCategory))
+	for _, category := range CategoryOrder {
+		group, ok := byCategory[category]
+		if !ok {
+			continue
+		}
+		group.AvgWear /= float64(group.Count)
+		group.Status = WearStatusOf(group.MaxWear, warnPercent)
+		groups = append(groups, *group)
+	}
+	return groups
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestComponentNameCategory(t *testing.T) {
+	tests := []struct {
+		name ComponentName
+		want ComponentCategory
+	}{
+		{name: Handlebars, want: Cockpit},
+		{name: Wheels, want: WheelSet},
+		{name: Frame, want: FrameSet},
+		{name: "saddle", want: Other},
+		{name: "", want: Other},
+	}
+	for _, tt := range tests {
+		if got := tt.name.Category(); got != tt.want {
+			t.Errorf("%q.Category() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	for _, name := range ComponentNames {
+		if name.Category() == Other {
+			t.Errorf("known component %q has no category", name)
+		}
+	}
+}
+
+func TestGroupByCategory(t *testing.T) {
+	installed := time.Now().Add(-time.Hour)
+	component := func(name ComponentName, installedMileage int) *Component {
+		return &Component{ID: uuid.New(), Name: name, InstalledAt: installed, InstalledMileage: installedMileage, MaxMileage: 1000}
+	}
+	// пробег байка 1000: износ = (1000 - installed) / 1000
+	bike := &Bike{Mileage: 1000, Components: []*Component{
+		component("saddle", 900),     // other, 10%
+		component(Wheels, 0),         // wheels, 100%
+		component(Handlebars, 500),   // cockpit, 50%
+		component(Wheels, 150),       // wheels, 85%
+		component("chainring", 1000), // other, 0%
+	}}
+
+	groups := bike.GroupByCategory(80)
+
+	want := []struct {
+		category ComponentCategory
+		status   WearStatus
+		count    int
+		warning  int
+		overdue  int
+		maxWear  float64
+		avgWear  float64
+	}{
+		{category: WheelSet, status: WearOverdue, count: 2, warning: 1, overdue: 1, maxWear: 1, avgWear: 0.925},
+		{category: Cockpit, status: WearOK, count: 1, maxWear: 0.5, avgWear: 0.5},
+		{category: Other, status: WearOK, count: 2, maxWear: 0.1, avgWear: 0.05},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(groups), len(want), groups)
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Category != w.category || g.Status != w.status || g.Count != w.count || g.Warning != w.warning || g.Overdue != w.overdue {
+			t.Errorf("group %d = %s/%s count %d warning %d overdue %d, want %+v", i, g.Category, g.Status, g.Count, g.Warning, g.Overdue, w)
+		}
+		if math.Abs(g.MaxWear-w.maxWear) > 1e-9 || math.Abs(g.AvgWear-w.avgWear) > 1e-9 {
+			t.Errorf("group %s wear max %v avg %v, want %v %v", g.Category, g.MaxWear, g.AvgWear, w.maxWear, w.avgWear)
+		}
+		if len(g.Components) != g.Count {
+			t.Errorf("group %s has %d components, count %d", g.Category, len(g.Components), g.Count)
+		}
+	}
+}
+
+func TestGroupByCategoryEmpty(t *testing.T) {
+	if groups := (&Bike{}).GroupByCategory(80); len(groups) != 0 {
+		t.Errorf("groups = %+v, want none", groups)
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// ComponentDefault - пороги замены, которые подставляются при создании
+// компонента, если клиент не передал ни одного своего
+type ComponentDefault struct {
+	Name       ComponentName `json:"name" validate:"required,oneof=handlebars frame wheels"`
+	MaxMileage int           `json:"max_mileage,omitempty" validate:"omitempty,min=1,max=1000000"`
+	MaxAgeDays *int          `json:"max_age_days,omitempty" validate:"omitempty,min=1,max=36500"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+func (d *ComponentDefault) Validate() error {
+	if d.MaxMileage == 0 && d.MaxAgeDays == nil {
+		return ErrNoReplacementThreshold
+	}
+	return nil
+}
+
+// ApplyDefault подставляет пороги по умолчанию, только если у компонента нет ни одного
+func (c *Component) ApplyDefault(d *ComponentDefault) {
+	if d == nil || c.MaxMileage != 0 || c.MaxAgeDays != nil {
+		return
+	}
+	c.MaxMileage = d.MaxMileage
+	if d.MaxAgeDays != nil {
+		days := *d.MaxAgeDays
+		c.MaxAgeDays = &days
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrComponentReplaced = errors.New("component is already replaced")
+
+// ComponentReplacement - новая деталь вместо изношенной. Название и позиция
+// берутся у старой, пороги тоже, если не переданы
+type ComponentReplacement struct {
+	Brand      string
+	Model      string
+	MaxMileage int
+	MaxAgeDays *int
+}
+
+// ComponentReplacementRecord - что попадает в историю (событие в outbox)
+type ComponentReplacementRecord struct {
+	Replaced  *Component `json:"replaced"`
+	Installed *Component `json:"installed"`
+}
+
+// NewComponent - компонент, который ставится вместо old на текущем пробеге байка
+func (r ComponentReplacement) NewComponent(old *Component, bikeMileage int, now time.Time) *Component {
+	c := &Component{
+		ID:               uuid.New(),
+		BikeID:           old.BikeID,
+		Name:             old.Name,
+		Brand:            r.Brand,
+		Model:            r.Model,
+		InstalledAt:      now,
+		InstalledMileage: bikeMileage,
+		MaxMileage:       old.MaxMileage,
+		MaxAgeDays:       old.MaxAgeDays,
+		Position:         old.Position,
+	}
+	if r.MaxMileage > 0 || r.MaxAgeDays != nil {
+		c.MaxMileage = r.MaxMileage
+		c.MaxAgeDays = r.MaxAgeDays
+	}
+	return c
+}
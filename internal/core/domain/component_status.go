@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ComponentStatusItem - компонент с износом в процентах для экрана обслуживания
+type ComponentStatusItem struct {
+	ComponentWear
+	WearPercent float64 `json:"wear_percent"`
+}
+
+type ComponentStatusCounts struct {
+	OK      int `json:"ok"`
+	DueSoon int `json:"due_soon"`
+	Overdue int `json:"overdue"`
+}
+
+// ComponentStatusBuckets - компоненты байка, разложенные по полосам износа
+// (см. WearStatusOf): due_soon - это warning. Внутри полосы - от самого изношенного
+type ComponentStatusBuckets struct {
+	OK      []ComponentStatusItem `json:"ok"`
+	DueSoon []ComponentStatusItem `json:"due_soon"`
+	Overdue []ComponentStatusItem `json:"overdue"`
+	Counts  ComponentStatusCounts `json:"counts"`
+}
+
+// BucketByStatus раскладывает компоненты байка по полосам износа при пороге warnPercent
+func (b *Bike) BucketByStatus(warnPercent int, now time.Time) ComponentStatusBuckets {
+	buckets := ComponentStatusBuckets{
+		OK:      []ComponentStatusItem{},
+		DueSoon: []ComponentStatusItem{},
+		Overdue: []ComponentStatusItem{},
+	}
+	for _, c := range b.Components {
+		wear := c.Wear(b.Mileage, now)
+		item := ComponentStatusItem{
+			ComponentWear: ComponentWear{
+				Component: c,
+				Wear:      wear,
+				Status:    WearStatusOf(wear, warnPercent),
+				Triggers:  c.Triggers(b.Mileage, now),
+			},
+			WearPercent: math.Round(wear*1000) / 10,
+		}
+		switch item.Status {
+		case WearOverdue:
+			buckets.Overdue = append(buckets.Overdue, item)
+		case WearWarning:
+			buckets.DueSoon = append(buckets.DueSoon, item)
+		default:
+			buckets.OK = append(buckets.OK, item)
+		}
+	}
+
+	for _, bucket := range [][]ComponentStatusItem{buckets.OK, buckets.DueSoon, buckets.Overdue} {
+		sort.SliceStable(bucket, func(i, j int) bool {
+			return bucket[i].Wear > bucket[j].Wear
+		})
+	}
+	buckets.Counts = ComponentStatusCounts{
+		OK:      len(buckets.OK),
+		DueSoon: len(buckets.DueSoon),
+		Overdue: len(buckets.Overdue),
+	}
+	return buckets
+}
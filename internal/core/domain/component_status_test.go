@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBucketByStatus(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	days := func(n int) *int { return &n }
+
+	// пробег байка 5000, порог по пробегу 1000 км: износ = (5000 - installed) / 1000
+	tests := []struct {
+		name             string
+		installedMileage int
+		maxAgeDays       *int
+		installedAt      time.Time
+		wantBucket       string
+		wantPercent      float64
+	}{
+		{name: "чуть ниже порога предупреждения", installedMileage: 4201, installedAt: now, wantBucket: "ok", wantPercent: 79.9},
+		{name: "ровно порог предупреждения", installedMileage: 4200, installedAt: now, wantBucket: "due_soon", wantPercent: 80},
+		{name: "чуть ниже замены", installedMileage: 4001, installedAt: now, wantBucket: "due_soon", wantPercent: 99.9},
+		{name: "ровно замена", installedMileage: 4000, installedAt: now, wantBucket: "overdue", wantPercent: 100},
+		{name: "просрочен по пробегу", installedMileage: 3500, installedAt: now, wantBucket: "overdue", wantPercent: 150},
+		{name: "просрочен по возрасту", installedMileage: 5000, maxAgeDays: days(30), installedAt: now.AddDate(0, 0, -30), wantBucket: "overdue", wantPercent: 100},
+		{name: "возраст у порога предупреждения", installedMileage: 5000, maxAgeDays: days(10), installedAt: now.AddDate(0, 0, -8), wantBucket: "due_soon", wantPercent: 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bike := &Bike{Mileage: 5000, Components: []*Component{{
+				ID:               uuid.New(),
+				InstalledMileage: tt.installedMileage,
+				MaxMileage:       1000,
+				MaxAgeDays:       tt.maxAgeDays,
+				InstalledAt:      tt.installedAt,
+			}}}
+
+			buckets := bike.BucketByStatus(80, now)
+			got := map[string][]ComponentStatusItem{"ok": buckets.OK, "due_soon": buckets.DueSoon, "overdue": buckets.Overdue}
+			for bucket, items := range got {
+				if want := bucket == tt.wantBucket; (len(items) == 1) != want {
+					t.Errorf("%s has %d items, want component there: %t", bucket, len(items), want)
+				}
+			}
+			item := got[tt.wantBucket]
+			if len(item) == 1 && item[0].WearPercent != tt.wantPercent {
+				t.Errorf("wear_percent = %v, want %v", item[0].WearPercent, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestBucketByStatusOrderAndCounts(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	component := func(installed int) *Component {
+		return &Component{ID: uuid.New(), InstalledMileage: installed, MaxMileage: 1000, InstalledAt: now}
+	}
+	// износ 10%, 50%, 85%, 95%, 120%
+	c10, c50, c85, c95, c120 := component(4900), component(4500), component(4150), component(4050), component(3800)
+	bike := &Bike{Mileage: 5000, Components: []*Component{c50, c85, c120, c10, c95}}
+
+	buckets := bike.BucketByStatus(80, now)
+	ids := func(items []ComponentStatusItem) []uuid.UUID {
+		var ids []uuid.UUID
+		for _, item := range items {
+			ids = append(ids, item.Component.ID)
+		}
+		return ids
+	}
+	// внутри полосы - от самого изношенного
+	if got, want := ids(buckets.OK), []uuid.UUID{c50.ID, c10.ID}; !slices.Equal(got, want) {
+		t.Errorf("ok = %v, want %v", got, want)
+	}
+	if got, want := ids(buckets.DueSoon), []uuid.UUID{c95.ID, c85.ID}; !slices.Equal(got, want) {
+		t.Errorf("due_soon = %v, want %v", got, want)
+	}
+	if got, want := ids(buckets.Overdue), []uuid.UUID{c120.ID}; !slices.Equal(got, want) {
+		t.Errorf("overdue = %v, want %v", got, want)
+	}
+	if want := (ComponentStatusCounts{OK: 2, DueSoon: 2, Overdue: 1}); buckets.Counts != want {
+		t.Errorf("counts = %+v, want %+v", buckets.Counts, want)
+	}
+
+	// у байка без компонентов полосы пустые, а не nil: в JSON это [], а не null
+	empty := (&Bike{Mileage: 5000}).BucketByStatus(80, now)
+	if empty.OK == nil || empty.DueSoon == nil || empty.Overdue == nil {
+		t.Errorf("empty buckets = %+v, want non-nil slices", empty)
+	}
+}
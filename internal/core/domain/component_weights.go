@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultComponentWeight - вес типа, для которого админ ничего не задавал
+	DefaultComponentWeight = 1.0
+	MaxComponentWeight     = 10.0
+)
+
+// ComponentWeight - насколько износ компонента этого типа важен для срочности
+// обслуживания байка: износ рамы срочнее, чем такой же износ руля
+type ComponentWeight struct {
+	Name      ComponentName `json:"name" validate:"required,oneof=handlebars frame wheels"`
+	Weight    float64       `json:"weight" validate:"gt=0,lte=10"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,11 +14,15 @@ type Component struct {
 	ID               uuid.UUID     `json:"id"`
 	BikeID           uuid.UUID     `json:"bike_id" validate:"required"`
 	Name             ComponentName `json:"name" validate:"required"`
+	DisplayName      string        `json:"display_name,omitempty"` // только в ответах API, по Accept-Language
 	Brand            string        `json:"brand,omitempty" validate:"max=100"`
 	Model            string        `json:"model,omitempty" validate:"max=100"`
 	InstalledAt      time.Time     `json:"installed_at" validate:"required"`
 	InstalledMileage int           `json:"installed_mileage" validate:"min=0"`
-	MaxMileage       int           `json:"max_mileage" validate:"required,min=1,max=1000000"`
+	MaxMileage       int           `json:"max_mileage" validate:"omitempty,min=1,max=1000000"` // 0 - без порога по пробегу
+	MaxAgeDays       *int          `json:"max_age_days,omitempty" validate:"omitempty,min=1,max=36500"`
+	Position         Position      `json:"position" validate:"omitempty,oneof=front rear left right none"`
+	ReplacedAt       *time.Time    `json:"replaced_at,omitempty"` // заменённый компонент остаётся только в истории
 	CreatedAt        time.Time     `json:"created_at"`
 	UpdatedAt        time.Time     `json:"updated_at"`
 }
@@ -28,10 +35,154 @@ const (
 	Wheels     ComponentName = "wheels"
 )
 
+// ComponentNames - все известные названия в порядке показа в справочниках
+var ComponentNames = []ComponentName{Handlebars, Frame, Wheels}
+
+// Position - где стоит компонент, чтобы различать парные детали
+type Position string
+
+const (
+	PositionFront Position = "front"
+	PositionRear  Position = "rear"
+	PositionLeft  Position = "left"
+	PositionRight Position = "right"
+	PositionNone  Position = "none"
+)
+
+// PairedComponents - типы, которые ставятся парами: на байке может быть
+// по одному такому компоненту на позицию. Список совпадает с
+// uq_components_paired_position в миграциях
+var PairedComponents = map[ComponentName]bool{
+	Wheels: true,
+}
+
+var ErrDuplicatePosition = errors.New("bike already has this component in this position")
+
+// NormalizePosition - без позиции значит none
+func (c *Component) NormalizePosition() {
+	if c.Position == "" {
+		c.Position = PositionNone
+	}
+}
+
+// ReplacementTrigger - какой из порогов сработал
+type ReplacementTrigger string
+
+const (
+	TriggerMileage ReplacementTrigger = "mileage"
+	TriggerAge     ReplacementTrigger = "age"
+)
+
+var ErrNoReplacementThreshold = errors.New("either max_mileage or max_age_days must be set")
+
+// ValidateThresholds проверяет, что у компонента есть хотя бы один порог замены
+func (c *Component) ValidateThresholds() error {
+	if c.MaxMileage == 0 && c.MaxAgeDays == nil {
+		return ErrNoReplacementThreshold
+	}
+	return nil
+}
+
 func (c *Component) CurrentMileage(bikeMileage int) int {
 	return bikeMileage - c.InstalledMileage
 }
 
+// AgeDays - сколько дней компонент стоит на байке
+func (c *Component) AgeDays(now time.Time) float64 {
+	return now.Sub(c.InstalledAt).Hours() / 24
+}
+
+// Wear - износ по худшему из порогов: пробег / max_mileage или возраст / max_age_days
+func (c *Component) Wear(bikeMileage int, now time.Time) float64 {
+	var wear float64
+	if c.MaxMileage > 0 {
+		wear = float64(c.CurrentMileage(bikeMileage)) / float64(c.MaxMileage)
+	}
+	if c.MaxAgeDays != nil {
+		wear = max(wear, c.AgeDays(now)/float64(*c.MaxAgeDays))
+	}
+	return wear
+}
+
+// PercentWorn - износ в процентах с одним знаком после запятой
+func (c *Component) PercentWorn(bikeMileage int, now time.Time) float64 {
+	return math.Round(c.Wear(bikeMileage, now)*1000) / 10
+}
+
+// WearStatus - полоса износа компонента при пороге warnPercent (см. WearStatusOf)
+func (c *Component) WearStatus(bikeMileage int, warnPercent int, now time.Time) WearStatus {
+	return WearStatusOf(c.Wear(bikeMileage, now), warnPercent)
+}
+
+// Triggers - какие пороги уже превышены
+func (c *Component) Triggers(bikeMileage int, now time.Time) []ReplacementTrigger {
+	triggers := []ReplacementTrigger{}
+	if c.MaxMileage > 0 && c.CurrentMileage(bikeMileage) >= c.MaxMileage {
+		triggers = append(triggers, TriggerMileage)
+	}
+	if c.MaxAgeDays != nil && c.AgeDays(now) >= float64(*c.MaxAgeDays) {
+		triggers = append(triggers, TriggerAge)
+	}
+	return triggers
+}
+
 func (c *Component) NeedsReplacement(bikeMileage int) bool {
-	return c.CurrentMileage(bikeMileage) >= c.MaxMileage
+	return len(c.Triggers(bikeMileage, time.Now())) > 0
+}
+
+// ComponentSort - поле, по которому упорядочен список компонентов.
+// Пустое значение - installed_at
+type ComponentSort string
+
+const (
+	ComponentSortInstalledAt ComponentSort = "installed_at"
+	ComponentSortWear        ComponentSort = "wear"
+	ComponentSortName        ComponentSort = "name"
+	ComponentSortMaxMileage  ComponentSort = "max_mileage"
+)
+
+// componentSortOrders - допустимые поля и направление по умолчанию для каждого:
+// свежие и самые изношенные сверху, названия и пороги по возрастанию
+var componentSortOrders = map[ComponentSort]SortOrder{
+	ComponentSortInstalledAt: SortDesc,
+	ComponentSortWear:        SortDesc,
+	ComponentSortName:        SortAsc,
+	ComponentSortMaxMileage:  SortAsc,
+}
+
+// ComponentFilter - необязательные условия для выборки компонентов байка
+type ComponentFilter struct {
+	Name            ComponentName // пустое - компоненты с любым названием
+	IncludeReplaced bool          // вместе с заменёнными, для истории обслуживания
+	InstalledAfter  *time.Time
+	InstalledBefore *time.Time
+	Sort            ComponentSort
+	Order           SortOrder
+	Page            Page
+}
+
+func (f ComponentFilter) Validate() error {
+	if f.InstalledAfter != nil && f.InstalledBefore != nil && f.InstalledAfter.After(*f.InstalledBefore) {
+		return errors.New("installed_after must not be later than installed_before")
+	}
+	if _, ok := componentSortOrders[f.Sort]; f.Sort != "" && !ok {
+		return fmt.Errorf("sort must be one of installed_at, wear, name, max_mileage, got %q", f.Sort)
+	}
+	if f.Order != "" && f.Order != SortAsc && f.Order != SortDesc {
+		return fmt.Errorf("order must be asc or desc, got %q", f.Order)
+	}
+	return nil
+}
+
+// Ordering - поле и направление с учётом значений по умолчанию
+func (f ComponentFilter) Ordering() (ComponentSort, SortOrder) {
+	sort := f.Sort
+	if sort == "" {
+		sort = ComponentSortInstalledAt
+	}
+	order := f.Order
+	if order == "" {
+		order = componentSortOrders[sort]
+	}
+	return sort, order
 }
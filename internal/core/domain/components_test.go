@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestComponentTriggers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name        string
+		maxMileage  int
+		maxAgeDays  *int
+		installedAt time.Time
+		bikeMileage int
+		want        []ReplacementTrigger
+	}{
+		{name: "оба порога не достигнуты", maxMileage: 1000, maxAgeDays: days(365), installedAt: now.AddDate(0, 0, -10), bikeMileage: 999, want: []ReplacementTrigger{}},
+		{name: "ровно max_mileage", maxMileage: 1000, installedAt: now, bikeMileage: 1000, want: []ReplacementTrigger{TriggerMileage}},
+		{name: "только пробег", maxMileage: 1000, maxAgeDays: days(365), installedAt: now.AddDate(0, 0, -10), bikeMileage: 1500, want: []ReplacementTrigger{TriggerMileage}},
+		{name: "только возраст", maxMileage: 1000, maxAgeDays: days(30), installedAt: now.AddDate(0, 0, -30), bikeMileage: 10, want: []ReplacementTrigger{TriggerAge}},
+		{name: "возраст на час меньше порога", maxAgeDays: days(30), installedAt: now.AddDate(0, 0, -30).Add(time.Hour), want: []ReplacementTrigger{}},
+		{name: "оба порога", maxMileage: 1000, maxAgeDays: days(30), installedAt: now.AddDate(0, 0, -60), bikeMileage: 2000, want: []ReplacementTrigger{TriggerMileage, TriggerAge}},
+		{name: "без порога по пробегу пробег не учитывается", maxAgeDays: days(365), installedAt: now, bikeMileage: 1_000_000, want: []ReplacementTrigger{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Component{MaxMileage: tt.maxMileage, MaxAgeDays: tt.maxAgeDays, InstalledAt: tt.installedAt}
+			if got := c.Triggers(tt.bikeMileage, now); !slices.Equal(got, tt.want) {
+				t.Errorf("Triggers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentNeedsReplacement(t *testing.T) {
+	days := 30
+
+	tests := []struct {
+		name        string
+		component   Component
+		bikeMileage int
+		want        bool
+	}{
+		{name: "новый компонент", component: Component{MaxMileage: 1000, MaxAgeDays: &days, InstalledAt: time.Now()}, bikeMileage: 10, want: false},
+		{name: "изношен по пробегу", component: Component{MaxMileage: 1000, InstalledMileage: 100, InstalledAt: time.Now()}, bikeMileage: 1100, want: true},
+		{name: "устарел по времени", component: Component{MaxAgeDays: &days, InstalledAt: time.Now().AddDate(0, 0, -31)}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.component.NeedsReplacement(tt.bikeMileage); got != tt.want {
+				t.Errorf("NeedsReplacement = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentValidateThresholds(t *testing.T) {
+	days := 30
+
+	tests := []struct {
+		name      string
+		component Component
+		wantErr   error
+	}{
+		{name: "только пробег", component: Component{MaxMileage: 1000}},
+		{name: "только возраст", component: Component{MaxAgeDays: &days}},
+		{name: "оба порога", component: Component{MaxMileage: 1000, MaxAgeDays: &days}},
+		{name: "без порогов", component: Component{}, wantErr: ErrNoReplacementThreshold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.component.ValidateThresholds(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateThresholds = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
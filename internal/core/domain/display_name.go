@@ -0,0 +1,37 @@
+package domain
+
+// DefaultLanguage - язык отображаемых названий, если клиент не попросил другой
+const DefaultLanguage = "en"
+
+// componentDisplayNames - человекочитаемые названия компонентов по языкам.
+// В базе и в name остаётся значение enum
+var componentDisplayNames = map[string]map[ComponentName]string{
+	"en": {
+		Handlebars: "Handlebars",
+		Frame:      "Frame",
+		Wheels:     "Wheels",
+	},
+	"ru": {
+		Handlebars: "Руль",
+		Frame:      "Рама",
+		Wheels:     "Колёса",
+	},
+}
+
+// IsSupportedLanguage - есть ли перевод названий на язык lang (базовый тег, например "ru")
+func IsSupportedLanguage(lang string) bool {
+	_, ok := componentDisplayNames[lang]
+	return ok
+}
+
+// DisplayName - название для показа на языке lang. Без перевода берётся
+// английское, для неизвестного компонента - само значение
+func (n ComponentName) DisplayName(lang string) string {
+	if name, ok := componentDisplayNames[lang][n]; ok {
+		return name
+	}
+	if name, ok := componentDisplayNames[DefaultLanguage][n]; ok {
+		return name
+	}
+	return string(n)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestComponentNameDisplayName(t *testing.T) {
+	tests := []struct {
+		name      string
+		component ComponentName
+		lang      string
+		want      string
+	}{
+		{name: "английский", component: Handlebars, lang: "en", want: "Handlebars"},
+		{name: "русский", component: Wheels, lang: "ru", want: "Колёса"},
+		{name: "язык без перевода", component: Frame, lang: "de", want: "Frame"},
+		{name: "неизвестный компонент", component: ComponentName("saddle"), lang: "ru", want: "saddle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.component.DisplayName(tt.lang); got != tt.want {
+				t.Errorf("DisplayName(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+// у каждого компонента есть перевод на все поддерживаемые языки
+func TestComponentDisplayNamesComplete(t *testing.T) {
+	for lang, names := range componentDisplayNames {
+		for _, name := range ComponentNames {
+			if names[name] == "" {
+				t.Errorf("%s has no %q display name", name, lang)
+			}
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package domain
+
+import "errors"
+
+// ErrRequestCanceled - клиент ушёл раньше, чем закончился запрос к хранилищу.
+// Это не отказ БД: такие ошибки не нужно логировать как сбой и отвечать 500
+var ErrRequestCanceled = errors.New("request canceled")
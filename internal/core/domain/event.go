@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	BikeCreated      EventType = "bike.created"
+	BikeUpdated      EventType = "bike.updated"
+	BikeDeleted      EventType = "bike.deleted"
+	BikeArchived     EventType = "bike.archived"
+	BikeUnarchived   EventType = "bike.unarchived"
+	BikeRestored     EventType = "bike.restored"
+	BikeTransferred  EventType = "bike.transferred" // в данных прежний владелец
+	ComponentCreated EventType = "component.created"
+	ComponentUpdated EventType = "component.updated"
+	ComponentDeleted EventType = "component.deleted"
+	// ComponentLifeExtended - max_mileage подняли после осмотра, в данных причина
+	ComponentLifeExtended EventType = "component.life_extended"
+	// ComponentReplaced - изношенный компонент снят, вместо него поставлен новый
+	ComponentReplaced EventType = "component.replaced"
+)
+
+var EventTypes = []EventType{
+	BikeCreated,
+	BikeUpdated,
+	BikeDeleted,
+	BikeArchived,
+	BikeUnarchived,
+	BikeRestored,
+	BikeTransferred,
+	ComponentCreated,
+	ComponentUpdated,
+	ComponentDeleted,
+	ComponentLifeExtended,
+	ComponentReplaced,
+}
+
+func (t EventType) IsValid() bool {
+	for _, et := range EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Event - доменное событие об изменении байка или компонента.
+// UserID может быть пустым, тогда владелец определяется по BikeID
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       EventType   `json:"type"`
+	UserID     uuid.UUID   `json:"user_id"`
+	BikeID     uuid.UUID   `json:"bike_id"`
+	Data       interface{} `json:"data,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+func NewEvent(eventType EventType, userID, bikeID uuid.UUID, data interface{}) *Event {
+	return &Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		UserID:     userID,
+		BikeID:     bikeID,
+		Data:       data,
+		OccurredAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// maxForecastDays - дальше прогноз не считаем: сто лет и так означает "никогда"
+const maxForecastDays = 36500
+
+// ComponentForecast - когда компонент придётся менять при заданном
+// среднем дневном пробеге
+type ComponentForecast struct {
+	Component *Component `json:"component"`
+	// сколько км осталось до max_mileage, 0 - без порога по пробегу или уже превышен
+	RemainingKm int                `json:"remaining_km"`
+	DaysLeft    int                `json:"days_left"`
+	ReplaceBy   time.Time          `json:"replace_by"`
+	Trigger     ReplacementTrigger `json:"trigger"`
+	Status      WearStatus         `json:"status"`
+}
+
+// ForecastReplacements считает дату замены каждого компонента с порогом:
+// (max_mileage - текущий пробег) / dailyKm дней от now, либо дата по
+// max_age_days, если она раньше. Просроченные получают now. Сортировка - от ближайшей
+func ForecastReplacements(b *Bike, dailyKm float64, warnPercent int, now time.Time) []ComponentForecast {
+	forecasts := make([]ComponentForecast, 0, len(b.Components))
+	for _, c := range b.Components {
+		if c.MaxMileage == 0 && c.MaxAgeDays == nil {
+			continue
+		}
+
+		f := ComponentForecast{Component: c}
+		var replaceBy time.Time
+		if c.MaxMileage > 0 {
+			f.RemainingKm = max(c.MaxMileage-c.CurrentMileage(b.Mileage), 0)
+			days := min(float64(f.RemainingKm)/dailyKm, maxForecastDays)
+			replaceBy = now.Add(time.Duration(days * 24 * float64(time.Hour)))
+			f.Trigger = TriggerMileage
+		}
+		if c.MaxAgeDays != nil {
+			byAge := c.InstalledAt.AddDate(0, 0, *c.MaxAgeDays)
+			if replaceBy.IsZero() || byAge.Before(replaceBy) {
+				replaceBy = byAge
+				f.Trigger = TriggerAge
+			}
+		}
+		if replaceBy.Before(now) {
+			replaceBy = now
+		}
+
+		f.ReplaceBy = replaceBy
+		f.DaysLeft = int(math.Ceil(replaceBy.Sub(now).Hours() / 24))
+		f.Status = WearStatusOf(c.Wear(b.Mileage, now), warnPercent)
+		forecasts = append(forecasts, f)
+	}
+
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		return forecasts[i].ReplaceBy.Before(forecasts[j].ReplaceBy)
+	})
+	return forecasts
+}
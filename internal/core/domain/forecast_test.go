@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestForecastReplacements(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		// ridden - пробег компонента с установки, installedDaysAgo - его возраст
+		ridden           int
+		installedDaysAgo int
+		maxMileage       int
+		maxAgeDays       *int
+		dailyKm          float64
+		wantRemaining    int
+		wantDays         int
+		wantTrigger      ReplacementTrigger
+	}{
+		{name: "ровно по дням", ridden: 1000, maxMileage: 5000, dailyKm: 100, wantRemaining: 4000, wantDays: 40, wantTrigger: TriggerMileage},
+		{name: "дробные дни округляются вверх", ridden: 1000, maxMileage: 5000, dailyKm: 30, wantRemaining: 4000, wantDays: 134, wantTrigger: TriggerMileage},
+		{name: "дробный пробег в день", ridden: 0, maxMileage: 100, dailyKm: 2.5, wantRemaining: 100, wantDays: 40, wantTrigger: TriggerMileage},
+		{name: "порог уже превышен", ridden: 6000, maxMileage: 5000, dailyKm: 10, wantRemaining: 0, wantDays: 0, wantTrigger: TriggerMileage},
+		{name: "возраст наступит раньше", ridden: 0, installedDaysAgo: 20, maxMileage: 5000, maxAgeDays: days(30), dailyKm: 10, wantRemaining: 5000, wantDays: 10, wantTrigger: TriggerAge},
+		{name: "пробег наступит раньше возраста", ridden: 0, installedDaysAgo: 20, maxMileage: 100, maxAgeDays: days(30), dailyKm: 10, wantRemaining: 100, wantDays: 10, wantTrigger: TriggerMileage},
+		{name: "только возраст", installedDaysAgo: 5, maxAgeDays: days(365), dailyKm: 10, wantDays: 360, wantTrigger: TriggerAge},
+		{name: "просрочен по возрасту", installedDaysAgo: 400, maxAgeDays: days(365), dailyKm: 10, wantDays: 0, wantTrigger: TriggerAge},
+		{name: "прогноз ограничен сотней лет", maxMileage: 1_000_000, dailyKm: 0.001, wantRemaining: 1_000_000, wantDays: maxForecastDays, wantTrigger: TriggerMileage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bike := &Bike{Mileage: 10000, Components: []*Component{{
+				Name:             Frame,
+				InstalledMileage: 10000 - tt.ridden,
+				InstalledAt:      now.AddDate(0, 0, -tt.installedDaysAgo),
+				MaxMileage:       tt.maxMileage,
+				MaxAgeDays:       tt.maxAgeDays,
+			}}}
+
+			forecasts := ForecastReplacements(bike, tt.dailyKm, 80, now)
+			if len(forecasts) != 1 {
+				t.Fatalf("got %d forecasts, want 1", len(forecasts))
+			}
+			f := forecasts[0]
+			if f.RemainingKm != tt.wantRemaining || f.DaysLeft != tt.wantDays || f.Trigger != tt.wantTrigger {
+				t.Errorf("remaining, days, trigger = %d, %d, %s, want %d, %d, %s", f.RemainingKm, f.DaysLeft, f.Trigger, tt.wantRemaining, tt.wantDays, tt.wantTrigger)
+			}
+			if f.ReplaceBy.Before(now) {
+				t.Errorf("replace_by %s is before now", f.ReplaceBy)
+			}
+		})
+	}
+}
+
+func TestForecastReplacementsOrder(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	bike := &Bike{Mileage: 1000, Components: []*Component{
+		{Name: Frame, MaxMileage: 9000, InstalledAt: now},
+		// без порогов прогнозировать нечего
+		{Name: Handlebars, InstalledAt: now},
+		{Name: Wheels, Position: PositionFront, MaxMileage: 2000, InstalledAt: now},
+		{Name: Wheels, Position: PositionRear, MaxMileage: 500, InstalledAt: now},
+	}}
+
+	forecasts := ForecastReplacements(bike, 10, 80, now)
+	var got []int
+	for _, f := range forecasts {
+		got = append(got, f.DaysLeft)
+	}
+	if want := []int{0, 100, 800}; !slices.Equal(got, want) {
+		t.Errorf("days left = %v, want %v", got, want)
+	}
+}
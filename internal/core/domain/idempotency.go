@@ -0,0 +1,21 @@
+package domain
+
+import "github.com/google/uuid"
+
+// IdempotencyScope - к какому виду ресурсов относится ключ
+type IdempotencyScope string
+
+const (
+	IdempotencyScopeBike      IdempotencyScope = "bike"
+	IdempotencyScopeComponent IdempotencyScope = "component"
+	IdempotencyScopeBikeBatch IdempotencyScope = "bike_batch"
+)
+
+// IdempotencyKey - заголовок Idempotency-Key запроса на создание.
+// Пустой Key - запрос без ключа. RequestHash - хеш тела, по нему повтор
+// отличается от другого запроса с тем же ключом
+type IdempotencyKey struct {
+	UserID      uuid.UUID
+	Key         string
+	RequestHash string
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidLifeExtension = errors.New("invalid life extension")
+
+// LifeExtension - продление ресурса компонента после осмотра:
+// либо прибавка к max_mileage, либо новое значение целиком
+type LifeExtension struct {
+	Delta      int
+	MaxMileage int
+	Reason     string
+}
+
+// LifeExtensionRecord - что попадает в историю (событие в outbox)
+type LifeExtensionRecord struct {
+	Component          *Component `json:"component"`
+	PreviousMaxMileage int        `json:"previous_max_mileage"`
+	Reason             string     `json:"reason"`
+}
+
+// NewMaxMileage считает новый порог и проверяет, что это действительно продление:
+// больше прежнего порога и больше того, что компонент уже проехал
+func (e LifeExtension) NewMaxMileage(c *Component, bikeMileage int) (int, error) {
+	if (e.Delta > 0) == (e.MaxMileage > 0) {
+		return 0, fmt.Errorf("%w: exactly one of delta or max_mileage must be positive", ErrInvalidLifeExtension)
+	}
+	if c.MaxMileage == 0 {
+		return 0, fmt.Errorf("%w: component has no mileage threshold", ErrInvalidLifeExtension)
+	}
+
+	newMax := e.MaxMileage
+	if e.Delta > 0 {
+		newMax = c.MaxMileage + e.Delta
+	}
+	if newMax <= c.MaxMileage {
+		return 0, fmt.Errorf("%w: max_mileage must be greater than current %d", ErrInvalidLifeExtension, c.MaxMileage)
+	}
+	if current := c.CurrentMileage(bikeMileage); newMax <= current {
+		return 0, fmt.Errorf("%w: max_mileage must be greater than mileage already ridden %d", ErrInvalidLifeExtension, current)
+	}
+	return newMax, nil
+}
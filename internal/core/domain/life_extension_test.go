@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLifeExtensionNewMaxMileage(t *testing.T) {
+	tests := []struct {
+		name       string
+		ext        LifeExtension
+		maxMileage int
+		// ridden - сколько компонент уже проехал
+		ridden  int
+		want    int
+		wantErr bool
+	}{
+		{name: "прибавка", ext: LifeExtension{Delta: 2000}, maxMileage: 5000, ridden: 4800, want: 7000},
+		{name: "новый порог", ext: LifeExtension{MaxMileage: 6000}, maxMileage: 5000, ridden: 4800, want: 6000},
+		{name: "порог ниже текущего", ext: LifeExtension{MaxMileage: 4000}, maxMileage: 5000, ridden: 100, wantErr: true},
+		{name: "порог равен текущему", ext: LifeExtension{MaxMileage: 5000}, maxMileage: 5000, ridden: 100, wantErr: true},
+		{name: "порог не выше пройденного", ext: LifeExtension{MaxMileage: 6000}, maxMileage: 5000, ridden: 6000, wantErr: true},
+		{name: "прибавка не покрывает пройденное", ext: LifeExtension{Delta: 500}, maxMileage: 5000, ridden: 7000, wantErr: true},
+		{name: "и прибавка, и порог", ext: LifeExtension{Delta: 500, MaxMileage: 8000}, maxMileage: 5000, wantErr: true},
+		{name: "ни прибавки, ни порога", ext: LifeExtension{}, maxMileage: 5000, wantErr: true},
+		{name: "компонент без порога по пробегу", ext: LifeExtension{Delta: 500}, maxMileage: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Component{MaxMileage: tt.maxMileage, InstalledMileage: 1000}
+			got, err := tt.ext.NewMaxMileage(c, 1000+tt.ridden)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidLifeExtension) {
+					t.Errorf("err = %v, want ErrInvalidLifeExtension", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NewMaxMileage = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
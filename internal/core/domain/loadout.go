@@ -0,0 +1,51 @@
+package domain
+
+// Стандартный набор компонентов, который должен быть у полностью
+// заполненного байка каждого типа
+var StandardLoadouts = map[BikeType][]ComponentName{
+	BMX:  {Frame, Handlebars, Wheels},
+	MTB:  {Frame, Handlebars, Wheels},
+	Road: {Frame, Handlebars, Wheels},
+}
+
+type BikeCompleteness struct {
+	BikeID   string          `json:"bike_id"`
+	Type     BikeType        `json:"type"`
+	Expected []ComponentName `json:"expected"`
+	Present  []ComponentName `json:"present"`
+	Missing  []ComponentName `json:"missing"`
+	FillRate float64         `json:"fill_rate"`
+}
+
+// Completeness сравнивает компоненты байка со стандартным набором его типа
+func (b *Bike) Completeness() *BikeCompleteness {
+	expected := StandardLoadouts[b.Type]
+
+	installed := make(map[ComponentName]bool, len(b.Components))
+	for _, c := range b.Components {
+		installed[c.Name] = true
+	}
+
+	result := &BikeCompleteness{
+		BikeID:   b.BikeID.String(),
+		Type:     b.Type,
+		Expected: expected,
+		Present:  []ComponentName{},
+		Missing:  []ComponentName{},
+	}
+	for _, name := range expected {
+		if installed[name] {
+			result.Present = append(result.Present, name)
+		} else {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+
+	if len(expected) > 0 {
+		result.FillRate = float64(len(result.Present)) / float64(len(expected))
+	} else {
+		result.FillRate = 1
+	}
+
+	return result
+}
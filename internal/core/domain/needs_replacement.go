@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ReplacementItem - компонент, который пора менять, с пробегом и остатком до порога
+type ReplacementItem struct {
+	*Component
+	CurrentMileage int `json:"current_mileage"`
+	// сколько км осталось до max_mileage, 0 - порог превышен или его нет
+	RemainingKm int                  `json:"remaining_km"`
+	PercentWorn float64              `json:"percent_worn"`
+	Triggers    []ReplacementTrigger `json:"triggers"`
+}
+
+// NeedingReplacement - компоненты байка, у которых превышен хотя бы один порог
+// (см. NeedsReplacement), от самого изношенного
+func (b *Bike) NeedingReplacement(now time.Time) []ReplacementItem {
+	items := []ReplacementItem{}
+	for _, c := range b.Components {
+		if !c.NeedsReplacement(b.Mileage) {
+			continue
+		}
+		current := c.CurrentMileage(b.Mileage)
+		item := ReplacementItem{
+			Component:      c,
+			CurrentMileage: current,
+			PercentWorn:    c.PercentWorn(b.Mileage, now),
+			Triggers:       c.Triggers(b.Mileage, now),
+		}
+		if c.MaxMileage > 0 {
+			item.RemainingKm = max(c.MaxMileage-current, 0)
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].PercentWorn > items[j].PercentWorn
+	})
+	return items
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxOrphanRepair - сколько компонентов можно починить одним запросом
+const MaxOrphanRepair = 1000
+
+// OrphanAction - что сделать с компонентами, у которых пропал байк
+type OrphanAction string
+
+const (
+	OrphanReassign OrphanAction = "reassign"
+	OrphanDelete   OrphanAction = "delete"
+)
+
+// OrphanRepair - починка компонентов-сирот по списку ID.
+// BikeID нужен только для reassign
+type OrphanRepair struct {
+	Action       OrphanAction
+	ComponentIDs []uuid.UUID
+	BikeID       uuid.UUID
+}
+
+func (r OrphanRepair) Validate() error {
+	if len(r.ComponentIDs) == 0 {
+		return errors.New("component ids must not be empty")
+	}
+	if len(r.ComponentIDs) > MaxOrphanRepair {
+		return fmt.Errorf("at most %d component ids allowed, got %d", MaxOrphanRepair, len(r.ComponentIDs))
+	}
+	switch r.Action {
+	case OrphanReassign:
+		if r.BikeID == uuid.Nil {
+			return errors.New("bike id is required to reassign components")
+		}
+	case OrphanDelete:
+		if r.BikeID != uuid.Nil {
+			return errors.New("bike id must not be set to delete components")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	return nil
+}
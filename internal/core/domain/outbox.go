@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage - событие, записанное в той же транзакции, что и изменение данных,
+// и ожидающее отправки релеем
+type OutboxMessage struct {
+	ID        uuid.UUID
+	EventType EventType
+	Payload   []byte
+	// BikeID - ключ порядка: события одного байка публикуются по очереди
+	BikeID    uuid.UUID
+	Seq       int64
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+// Page - окно выборки для списочных запросов. Limit = 0 означает без ограничения
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// SortOrder - направление сортировки, пустое - направление по умолчанию для поля
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// RequestIDHeader - заголовок, в котором приходит и уходит ID запроса
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID кладёт ID запроса в контекст, по нему логи и исходящие
+// вызовы одного запроса связываются между собой
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext - ID запроса или пустая строка вне запроса
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
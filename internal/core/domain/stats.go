@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// FleetStats - сводка по всему парку для админского дашборда.
+// Новые метрики добавляются новыми полями, существующие не меняются
+type FleetStats struct {
+	UsersWithBikes    int                   `json:"users_with_bikes"`
+	TotalBikes        int                   `json:"total_bikes"`
+	BikesByType       map[BikeType]int      `json:"bikes_by_type"`
+	AvgBikesPerUser   float64               `json:"avg_bikes_per_user"`
+	TotalComponents   int                   `json:"total_components"`
+	ComponentsByName  map[ComponentName]int `json:"components_by_name"`
+	OverdueComponents int                   `json:"overdue_components"`
+	WarningComponents int                   `json:"warning_components"`
+	WarnThreshold     int                   `json:"warn_threshold_percent"`
+	GeneratedAt       time.Time             `json:"generated_at"`
+}
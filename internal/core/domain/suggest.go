@@ -0,0 +1,19 @@
+package domain
+
+import "github.com/google/uuid"
+
+// SuggestField - поле компонента, по которому подсказываем значения
+type SuggestField string
+
+const (
+	SuggestBrand SuggestField = "brand"
+	SuggestModel SuggestField = "model"
+)
+
+// SuggestQuery - запрос подсказок. UserID = uuid.Nil значит искать по всем данным
+type SuggestQuery struct {
+	Field  SuggestField
+	Prefix string
+	UserID uuid.UUID
+	Limit  int
+}
@@ -9,10 +9,30 @@ type UserRole string
 const (
 	Admin   UserRole = "admin"
 	AppUser UserRole = "appuser"
+	// ServiceAccount - внутренний сервис по API-ключу, пользователя за ним нет
+	ServiceAccount UserRole = "service"
 )
 
 type TokenPayload struct {
-	ID     uuid.UUID
-	UserID uuid.UUID
-	Role   UserRole
+	ID uuid.UUID
+	// TokenID - jti токена (или id, если jti нет), по нему токен отзывают
+	TokenID string
+	UserID  uuid.UUID
+	Role    UserRole
+	// Service - имя внутреннего сервиса при входе по API-ключу, у пользователей пусто
+	Service string
+}
+
+// IsService - запрос от сервиса по API-ключу, а не от пользователя
+func (p *TokenPayload) IsService() bool {
+	return p.Service != ""
+}
+
+// Actor - кто выполнил действие, для audit-логов: id пользователя или
+// "service:<имя>" для API-ключа, у которого UserID пустой
+func (p *TokenPayload) Actor() string {
+	if p.IsService() {
+		return "service:" + p.Service
+	}
+	return p.UserID.String()
 }
@@ -0,0 +1,21 @@
+package domain
+
+import "github.com/google/uuid"
+
+// BikeUrgency - байк с оценкой срочности обслуживания.
+// Urgency = max(wear_i * weight_i) по компонентам байка, где wear_i - износ
+// по худшему из порогов (как в Component.Wear), weight_i - вес типа из
+// ComponentWeight (по умолчанию 1). По Urgency байки сортируются, а самый
+// срочный компонент попадает в WorstComponent.
+// MaxWear - просто наибольший износ без весов: по нему считаются Overdue и Status,
+// 1 и больше значит, что компонент пора менять. У байка без компонентов оба 0
+type BikeUrgency struct {
+	Bike
+	Urgency           float64        `json:"urgency"`
+	MaxWear           float64        `json:"max_wear"`
+	Overdue           bool           `json:"overdue"`
+	Status            WearStatus     `json:"status"`
+	WorstComponentID  *uuid.UUID     `json:"worst_component_id,omitempty"`
+	WorstComponent    *ComponentName `json:"worst_component,omitempty"`
+	ComponentsOverdue int            `json:"components_overdue"`
+}
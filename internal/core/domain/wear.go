@@ -0,0 +1,27 @@
+package domain
+
+// WearStatus - полоса износа компонента
+type WearStatus string
+
+const (
+	WearOK      WearStatus = "ok"
+	WearWarning WearStatus = "warning"
+	WearOverdue WearStatus = "overdue"
+)
+
+// DefaultWarnThresholdPercent - с какого процента износа компонент считается "скоро менять".
+// Переопределяется WEAR_WARN_THRESHOLD_PERCENT, в запросе - warn_threshold_percent
+const DefaultWarnThresholdPercent = 80
+
+// WearStatusOf переводит износ (пробег с установки / max_mileage) в полосу:
+// от 100% overdue, от warnPercent warning, ниже ok
+func WearStatusOf(wear float64, warnPercent int) WearStatus {
+	switch {
+	case wear >= 1:
+		return WearOverdue
+	case wear*100 >= float64(warnPercent):
+		return WearWarning
+	default:
+		return WearOK
+	}
+}
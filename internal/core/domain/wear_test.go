@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestWearStatusOf(t *testing.T) {
+	tests := []struct {
+		wear        float64
+		warnPercent int
+		want        WearStatus
+	}{
+		{wear: 0, warnPercent: 80, want: WearOK},
+		{wear: 0.799, warnPercent: 80, want: WearOK},
+		{wear: 0.8, warnPercent: 80, want: WearWarning},
+		{wear: 0.999, warnPercent: 80, want: WearWarning},
+		{wear: 1, warnPercent: 80, want: WearOverdue},
+		{wear: 2.5, warnPercent: 80, want: WearOverdue},
+		// крайние пороги
+		{wear: 0.009, warnPercent: 1, want: WearOK},
+		{wear: 0.01, warnPercent: 1, want: WearWarning},
+		{wear: 0.999, warnPercent: 100, want: WearOK},
+		{wear: 1, warnPercent: 100, want: WearOverdue},
+	}
+	for _, tt := range tests {
+		if got := WearStatusOf(tt.wear, tt.warnPercent); got != tt.want {
+			t.Errorf("WearStatusOf(%v, %d) = %q, want %q", tt.wear, tt.warnPercent, got, tt.want)
+		}
+	}
+}
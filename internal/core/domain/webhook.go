@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Webhook struct {
+	ID         uuid.UUID   `json:"id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	URL        string      `json:"url" validate:"required,url,max=2048"`
+	EventTypes []EventType `json:"event_types" validate:"required,min=1"`
+	Secret     string      `json:"-" validate:"required,min=16,max=255"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (w *Webhook) Subscribed(eventType EventType) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeadLetter - событие, которое не удалось доставить после всех попыток
+type WebhookDeadLetter struct {
+	ID        uuid.UUID `json:"id"`
+	WebhookID uuid.UUID `json:"webhook_id"`
+	EventID   uuid.UUID `json:"event_id"`
+	EventType EventType `json:"event_type"`
+	Payload   []byte    `json:"payload"`
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery - событие в очереди доставки одного вебхука. У каждой
+// доставки свои попытки и время следующей, так что недоступный подписчик
+// задерживает только свои события
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	EventID       uuid.UUID
+	EventType     EventType
+	Payload       []byte
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
@@ -5,3 +5,7 @@ import "github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 type TokenService interface {
 	VerifyToken(token string) (*domain.TokenPayload, error)
 }
+
+type APIKeyVerifier interface {
+	VerifyAPIKey(key string) (*domain.TokenPayload, error)
+}
@@ -10,14 +10,28 @@ import (
 type BikeRepository interface {
 	CreateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error)
 	GetBikeByID(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error)
-	GetBikesByUserID(ctx context.Context, user_id uuid.UUID) ([]*domain.Bike, error)
+	// GetBikeByIDForWrite читает с primary, для проверок перед записью
+	GetBikeByIDForWrite(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error)
+	GetBikeByIDWithDeleted(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error)
+	GetBikesByUserID(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.Bike, error)
+	CountBikesByUserID(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) (int, error)
+	ListBikes(ctx context.Context, filter domain.BikeListFilter) ([]*domain.Bike, error)
+	CountBikes(ctx context.Context, filter domain.BikeListFilter) (int, error)
 	UpdateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error)
 	DeleteBike(ctx context.Context, bike_id uuid.UUID) error
+	RestoreBike(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error)
+	TransferBike(ctx context.Context, bike_id uuid.UUID, from_user_id uuid.UUID, to_user_id uuid.UUID) (*domain.Bike, error)
+	SetBikeArchived(ctx context.Context, bike_id uuid.UUID, archived bool) (*domain.Bike, error)
+	UpdateMileage(ctx context.Context, bike_id uuid.UUID, mileage int) (*domain.Bike, error)
+	GetBikesByUrgency(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.BikeUrgency, error)
+	GetBikesWithoutComponents(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.Bike, error)
+	BulkUpdateBikes(ctx context.Context, update domain.BikeBulkUpdate) ([]*domain.Bike, error)
+	GetBikesByIDs(ctx context.Context, bike_ids []uuid.UUID) ([]*domain.Bike, error)
 }
 type BikeService interface {
-	CreateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error)
+	CreateBike(ctx context.Context, bike *domain.Bike, key domain.IdempotencyKey) (*domain.Bike, bool, error)
 	GetBikeByID(ctx context.Context, bike_id string) (*domain.Bike, error)
-	GetBikesByUserID(ctx context.Context, user_id string) ([]*domain.Bike, error)
+	GetBikesByUserID(ctx context.Context, user_id string, filter domain.BikeFilter) ([]*domain.Bike, error)
 	UpdateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error)
 	DeleteBike(ctx context.Context, bike_id string) error
 }
@@ -1,9 +1,16 @@
 package ports
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss - ключа нет. Любая другая ошибка Get - сбой самого кеша
+var ErrCacheMiss = errors.New("cache miss")
 
 type CachePort interface {
 	Get(key string) ([]byte, error)
 	Set(key string, value []byte, ttl time.Duration) error
 	Delete(key string) error
+	DeletePattern(pattern string) error
 }
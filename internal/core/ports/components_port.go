@@ -10,7 +10,18 @@ import (
 type ComponentRepository interface {
 	CreateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error)
 	GetComponentByID(ctx context.Context, componentID uuid.UUID) (*domain.Component, error)
-	GetComponentsByBikeID(ctx context.Context, bikeID uuid.UUID) ([]*domain.Component, error)
+	// GetComponentByIDForWrite читает с primary, для правок компонента
+	GetComponentByIDForWrite(ctx context.Context, componentID uuid.UUID) (*domain.Component, error)
+	GetComponentsByBikeID(ctx context.Context, bikeID uuid.UUID, filter domain.ComponentFilter) ([]*domain.Component, error)
 	UpdateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error)
+	MarkComponentReplaced(ctx context.Context, componentID uuid.UUID) (*domain.Component, error)
 	DeleteComponent(ctx context.Context, componentID uuid.UUID) error
+	SuggestValues(ctx context.Context, query domain.SuggestQuery) ([]string, error)
+	ListComponentDefaults(ctx context.Context) ([]*domain.ComponentDefault, error)
+	UpsertComponentDefault(ctx context.Context, d *domain.ComponentDefault) (*domain.ComponentDefault, error)
+	ListComponentWeights(ctx context.Context) ([]*domain.ComponentWeight, error)
+	UpsertComponentWeight(ctx context.Context, w *domain.ComponentWeight) (*domain.ComponentWeight, error)
+	ListOrphanedComponents(ctx context.Context, page domain.Page) ([]*domain.Component, error)
+	ReassignOrphanedComponents(ctx context.Context, ids []uuid.UUID, bikeID uuid.UUID) ([]uuid.UUID, error)
+	DeleteOrphanedComponents(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
 }
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.Event) error
+}
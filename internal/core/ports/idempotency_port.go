@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type IdempotencyRepository interface {
+	// ClaimKey занимает ключ за resourceIDs, ключ старше ttl занимается заново.
+	// false - ключ уже занят. Вызывается внутри транзакции создания ресурсов
+	ClaimKey(ctx context.Context, scope domain.IdempotencyScope, key domain.IdempotencyKey, resourceIDs []uuid.UUID, ttl time.Duration) (bool, error)
+	GetKey(ctx context.Context, scope domain.IdempotencyScope, userID uuid.UUID, key string) (requestHash string, resourceIDs []uuid.UUID, err error)
+	// PurgeExpiredKeys удаляет ключи старше ttl и возвращает, сколько удалено
+	PurgeExpiredKeys(ctx context.Context, ttl time.Duration) (int64, error)
+}
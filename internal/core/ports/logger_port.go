@@ -8,6 +8,8 @@ type LoggerPort interface {
 	Error(msg string, fields map[string]interface{})
 	Debug(msg string, fields map[string]interface{})
 	Warn(msg string, fields map[string]interface{})
+	// WithContext - логгер, добавляющий request_id из ctx к каждой записи
+	WithContext(ctx context.Context) LoggerPort
 
 	// Methods for gRPC
 	InfoGRPC(ctx context.Context, msg string, fields any)
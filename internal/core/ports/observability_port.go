@@ -10,4 +10,15 @@ type MetricsPort interface {
 	IncrementCounter(name string, labels map[string]string)
 	RecordDuration(name string, duration time.Duration, labels map[string]string)
 	RecordMetrics(c *gin.Context, start time.Time)
+	IncInFlight(group string)
+	DecInFlight(group string)
+	// обогащение байка профилем из user-service
+	IncUserEnrichmentSuccess(source string)
+	IncUserEnrichmentFailure(reason string)
+	// состояние circuit breaker: 0 - closed, 1 - half-open, 2 - open
+	SetCircuitBreakerState(name string, state int)
+	// попадание или промах кеша байков, operation - какое чтение
+	RecordCacheResult(operation string, hit bool)
+	// доставка доменного события во внешний приёмник (kafka)
+	RecordEventPublish(sink string, ok bool)
 }
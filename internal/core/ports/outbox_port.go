@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithoutTransaction - тот же контекст, но репозитории с ним идут мимо
+	// открытой транзакции, прямо в пул
+	WithoutTransaction(ctx context.Context) context.Context
+}
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, event *domain.Event) error
+	// ClaimPending берёт сообщения на lease и должен вызываться внутри
+	// WithinTransaction. Публиковать их нужно уже после commit
+	ClaimPending(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*domain.OutboxMessage, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+	Release(ctx context.Context, ids []uuid.UUID) error
+}
@@ -0,0 +1,475 @@
+package portstest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// AddBike кладёт байк как есть, без событий и проверок, для подготовки теста
+func (s *Store) AddBike(bike *domain.Bike) *domain.Bike {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bike.BikeID == uuid.Nil {
+		bike.BikeID = uuid.New()
+	}
+	if bike.CreatedAt.IsZero() {
+		bike.CreatedAt = s.now()
+	}
+	if bike.UpdatedAt.IsZero() {
+		bike.UpdatedAt = bike.CreatedAt
+	}
+	s.bikes[bike.BikeID] = cloneBike(bike)
+	return bike
+}
+
+// Bike - сохранённый байк, в том числе удалённый
+func (s *Store) Bike(id uuid.UUID) (*domain.Bike, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[id]
+	return cloneBike(bike), ok
+}
+
+func (s *Store) CreateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error) {
+	if err := s.fail("CreateBike"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.bikes[bike.BikeID]; ok {
+		return nil, errors.New("duplicate bike_id")
+	}
+	bike.CreatedAt = s.now()
+	bike.UpdatedAt = bike.CreatedAt
+	bike.ArchivedAt = nil
+	s.bikes[bike.BikeID] = cloneBike(bike)
+	return bike, nil
+}
+
+func (s *Store) GetBikeByID(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	return s.getBike(ctx, "GetBikeByID", bike_id, false)
+}
+
+func (s *Store) GetBikeByIDForWrite(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	return s.getBike(ctx, "GetBikeByIDForWrite", bike_id, false)
+}
+
+func (s *Store) GetBikeByIDWithDeleted(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	return s.getBike(ctx, "GetBikeByIDWithDeleted", bike_id, true)
+}
+
+func (s *Store) getBike(ctx context.Context, method string, bike_id uuid.UUID, withDeleted bool) (*domain.Bike, error) {
+	if err := s.fail(method); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bike_id]
+	if !ok || bike.DeletedAt != nil && !withDeleted {
+		return nil, domain.ErrBikeNotFound
+	}
+	return cloneBike(bike), nil
+}
+
+func (s *Store) GetBikesByUserID(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.Bike, error) {
+	if err := s.fail("GetBikesByUserID"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bikes := s.filterBikes(func(b *domain.Bike) bool {
+		return b.UserID == user_id && matchBikeFilter(b, filter)
+	})
+	sortBikes(bikes, filter.Sort)
+	return page(bikes, filter.Page), nil
+}
+
+func (s *Store) CountBikesByUserID(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) (int, error) {
+	if err := s.fail("CountBikesByUserID"); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.filterBikes(func(b *domain.Bike) bool {
+		return b.UserID == user_id && matchBikeFilter(b, filter)
+	})), nil
+}
+
+func (s *Store) ListBikes(ctx context.Context, filter domain.BikeListFilter) ([]*domain.Bike, error) {
+	if err := s.fail("ListBikes"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bikes := s.filterBikes(func(b *domain.Bike) bool { return matchBikeListFilter(b, filter) })
+	sortBikes(bikes, filter.Sort)
+	return page(bikes, filter.Page), nil
+}
+
+func (s *Store) CountBikes(ctx context.Context, filter domain.BikeListFilter) (int, error) {
+	if err := s.fail("CountBikes"); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.filterBikes(func(b *domain.Bike) bool { return matchBikeListFilter(b, filter) })), nil
+}
+
+// UpdateBike повторяет postgres: пустые поля не меняются, пробег только растёт
+func (s *Store) UpdateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error) {
+	if err := s.fail("UpdateBike"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.bikes[bike.BikeID]
+	if !ok || stored.DeletedAt != nil {
+		return nil, domain.ErrBikeNotFound
+	}
+	if bike.Mileage != 0 && stored.Mileage > bike.Mileage {
+		return nil, domain.ErrMileageConflict
+	}
+	if bike.BikeName != "" {
+		stored.BikeName = bike.BikeName
+	}
+	if bike.Type != "" {
+		stored.Type = bike.Type
+	}
+	if bike.Model != "" {
+		stored.Model = bike.Model
+	}
+	if bike.Year != nil && *bike.Year != 0 {
+		year := *bike.Year
+		stored.Year = &year
+	}
+	if bike.Mileage != 0 {
+		stored.Mileage = bike.Mileage
+	}
+	stored.UpdatedAt = s.now()
+	updated := cloneBike(stored)
+	updated.DeletedAt = nil
+	return updated, nil
+}
+
+func (s *Store) DeleteBike(ctx context.Context, bike_id uuid.UUID) error {
+	if err := s.fail("DeleteBike"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bike_id]
+	if !ok || bike.DeletedAt != nil {
+		return domain.ErrBikeNotFound
+	}
+	now := s.now()
+	bike.DeletedAt = &now
+	bike.UpdatedAt = now
+	return nil
+}
+
+func (s *Store) RestoreBike(ctx context.Context, bike_id uuid.UUID) (*domain.Bike, error) {
+	if err := s.fail("RestoreBike"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bike_id]
+	if !ok || bike.DeletedAt == nil {
+		return nil, domain.ErrBikeNotDeleted
+	}
+	bike.DeletedAt = nil
+	bike.UpdatedAt = s.now()
+	return cloneBike(bike), nil
+}
+
+func (s *Store) TransferBike(ctx context.Context, bike_id uuid.UUID, from_user_id uuid.UUID, to_user_id uuid.UUID) (*domain.Bike, error) {
+	if err := s.fail("TransferBike"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bike_id]
+	if !ok || bike.DeletedAt != nil {
+		return nil, domain.ErrBikeNotFound
+	}
+	if bike.UserID != from_user_id {
+		return nil, domain.ErrOwnerChanged
+	}
+	bike.UserID = to_user_id
+	bike.UpdatedAt = s.now()
+	return cloneBike(bike), nil
+}
+
+func (s *Store) SetBikeArchived(ctx context.Context, bike_id uuid.UUID, archived bool) (*domain.Bike, error) {
+	if err := s.fail("SetBikeArchived"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bike_id]
+	if !ok || bike.DeletedAt != nil {
+		return nil, domain.ErrBikeNotFound
+	}
+	now := s.now()
+	switch {
+	case !archived:
+		bike.ArchivedAt = nil
+	case bike.ArchivedAt == nil:
+		bike.ArchivedAt = &now
+	}
+	bike.UpdatedAt = now
+	return cloneBike(bike), nil
+}
+
+func (s *Store) UpdateMileage(ctx context.Context, bike_id uuid.UUID, mileage int) (*domain.Bike, error) {
+	if err := s.fail("UpdateMileage"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bike_id]
+	if !ok || bike.DeletedAt != nil || bike.Mileage > mileage {
+		return nil, domain.ErrMileageConflict
+	}
+	bike.Mileage = mileage
+	bike.UpdatedAt = s.now()
+	return cloneBike(bike), nil
+}
+
+// GetBikesByUrgency считает то же, что запрос в postgres, через domain.Component.Wear
+func (s *Store) GetBikesByUrgency(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.BikeUrgency, error) {
+	if err := s.fail("GetBikesByUrgency"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var result []*domain.BikeUrgency
+	for _, bike := range s.filterBikes(func(b *domain.Bike) bool {
+		return b.UserID == user_id && matchBikeFilter(b, filter)
+	}) {
+		urgency := &domain.BikeUrgency{Bike: *bike}
+		var worst *domain.Component
+		var worstWear float64
+		for _, c := range s.activeComponents(bike.BikeID) {
+			wear := c.Wear(bike.Mileage, now)
+			weight := domain.DefaultComponentWeight
+			if w, ok := s.weights[c.Name]; ok {
+				weight = w.Weight
+			}
+			score := wear * weight
+			if worst == nil || score > urgency.Urgency || score == urgency.Urgency && wear > worstWear {
+				worst, worstWear, urgency.Urgency = c, wear, score
+			}
+			urgency.MaxWear = max(urgency.MaxWear, wear)
+			if wear >= 1 {
+				urgency.ComponentsOverdue++
+			}
+		}
+		if worst != nil {
+			id, name := worst.ID, worst.Name
+			urgency.WorstComponentID = &id
+			urgency.WorstComponent = &name
+		}
+		urgency.Overdue = urgency.MaxWear >= 1
+		result = append(result, urgency)
+	}
+	slices.SortStableFunc(result, func(a, b *domain.BikeUrgency) int {
+		if c := cmp.Compare(b.Urgency, a.Urgency); c != 0 {
+			return c
+		}
+		return strings.Compare(a.BikeID.String(), b.BikeID.String())
+	})
+	return page(result, filter.Page), nil
+}
+
+func (s *Store) GetBikesWithoutComponents(ctx context.Context, user_id uuid.UUID, filter domain.BikeFilter) ([]*domain.Bike, error) {
+	if err := s.fail("GetBikesWithoutComponents"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bikes := s.filterBikes(func(b *domain.Bike) bool {
+		return (user_id == uuid.Nil || b.UserID == user_id) && matchBikeFilter(b, filter) &&
+			len(s.activeComponents(b.BikeID)) == 0
+	})
+	sortBikes(bikes, domain.BikeSortCreatedAtDesc)
+	return page(bikes, filter.Page), nil
+}
+
+func (s *Store) BulkUpdateBikes(ctx context.Context, update domain.BikeBulkUpdate) ([]*domain.Bike, error) {
+	if err := s.fail("BulkUpdateBikes"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var updated []*domain.Bike
+	for _, bike := range s.bikes {
+		if bike.DeletedAt != nil {
+			continue
+		}
+		if len(update.IDs) > 0 && !slices.Contains(update.IDs, bike.BikeID) ||
+			len(update.IDs) == 0 && bike.Type != update.CurrentType {
+			continue
+		}
+		switch update.Field {
+		case domain.BikeFieldType:
+			bike.Type = domain.BikeType(update.Value)
+		case domain.BikeFieldModel:
+			bike.Model = update.Value
+		default:
+			return nil, errors.New("field cannot be bulk updated")
+		}
+		bike.UpdatedAt = s.now()
+		updated = append(updated, cloneBike(bike))
+	}
+	return updated, nil
+}
+
+func (s *Store) GetBikesByIDs(ctx context.Context, bike_ids []uuid.UUID) ([]*domain.Bike, error) {
+	if err := s.fail("GetBikesByIDs"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filterBikes(func(b *domain.Bike) bool {
+		return b.DeletedAt == nil && slices.Contains(bike_ids, b.BikeID)
+	}), nil
+}
+
+// filterBikes - копии подходящих байков в порядке bike_id
+func (s *Store) filterBikes(match func(*domain.Bike) bool) []*domain.Bike {
+	var bikes []*domain.Bike
+	for _, b := range s.bikes {
+		if match(b) {
+			bikes = append(bikes, cloneBike(b))
+		}
+	}
+	slices.SortFunc(bikes, func(a, b *domain.Bike) int {
+		return strings.Compare(a.BikeID.String(), b.BikeID.String())
+	})
+	return bikes
+}
+
+func matchBikeFilter(b *domain.Bike, f domain.BikeFilter) bool {
+	if b.DeletedAt != nil && !f.IncludeDeleted {
+		return false
+	}
+	if (b.ArchivedAt != nil) != f.Archived {
+		return false
+	}
+	if f.CreatedAfter != nil && b.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && b.CreatedAt.After(*f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func matchBikeListFilter(b *domain.Bike, f domain.BikeListFilter) bool {
+	switch {
+	case b.DeletedAt != nil && !f.IncludeDeleted:
+		return false
+	case f.Type != "" && b.Type != f.Type:
+		return false
+	case f.UserID != uuid.Nil && b.UserID != f.UserID:
+		return false
+	case f.Model != "" && !strings.Contains(strings.ToLower(b.Model), strings.ToLower(f.Model)):
+		return false
+	case f.MinMileage != nil && b.Mileage < *f.MinMileage:
+		return false
+	case f.MaxMileage != nil && b.Mileage > *f.MaxMileage:
+		return false
+	case f.CreatedAfter != nil && b.CreatedAt.Before(*f.CreatedAfter):
+		return false
+	case f.CreatedBefore != nil && b.CreatedAt.After(*f.CreatedBefore):
+		return false
+	}
+	return true
+}
+
+// sortBikes - ORDER BY из postgres, bike_id как тай-брейкер уже задан filterBikes
+func sortBikes(bikes []*domain.Bike, sort domain.BikeSort) {
+	year := func(b *domain.Bike) int {
+		if b.Year == nil {
+			return 0
+		}
+		return *b.Year
+	}
+	// год без значения - в конце при любом направлении
+	nullsLast := func(a, b *domain.Bike, desc bool) int {
+		switch {
+		case a.Year == nil && b.Year == nil:
+			return 0
+		case a.Year == nil:
+			return 1
+		case b.Year == nil:
+			return -1
+		case desc:
+			return cmp.Compare(year(b), year(a))
+		}
+		return cmp.Compare(year(a), year(b))
+	}
+	slices.SortStableFunc(bikes, func(a, b *domain.Bike) int {
+		switch sort {
+		case domain.BikeSortCreatedAtAsc:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case domain.BikeSortMileageDesc:
+			return cmp.Compare(b.Mileage, a.Mileage)
+		case domain.BikeSortMileageAsc:
+			return cmp.Compare(a.Mileage, b.Mileage)
+		case domain.BikeSortNameAsc:
+			return strings.Compare(a.BikeName, b.BikeName)
+		case domain.BikeSortNameDesc:
+			return strings.Compare(b.BikeName, a.BikeName)
+		case domain.BikeSortYearDesc:
+			return nullsLast(a, b, true)
+		case domain.BikeSortYearAsc:
+			return nullsLast(a, b, false)
+		}
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+}
+
+// page - LIMIT/OFFSET, нулевой Limit - без ограничения
+func page[T any](items []T, p domain.Page) []T {
+	if p.Limit <= 0 {
+		return items
+	}
+	if p.Offset >= len(items) {
+		return nil
+	}
+	return items[p.Offset:min(p.Offset+p.Limit, len(items))]
+}
+
+func cloneBike(b *domain.Bike) *domain.Bike {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	c.Components = nil
+	if b.Year != nil {
+		year := *b.Year
+		c.Year = &year
+	}
+	if b.ArchivedAt != nil {
+		at := *b.ArchivedAt
+		c.ArchivedAt = &at
+	}
+	if b.DeletedAt != nil {
+		at := *b.DeletedAt
+		c.DeletedAt = &at
+	}
+	return &c
+}
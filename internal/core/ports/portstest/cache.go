@@ -0,0 +1,98 @@
+package portstest
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+// Cache - кеш в памяти без срока жизни. Err, если задан, возвращают все методы
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	ttls    map[string]time.Duration
+	Err     error
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (c *Cache) Get(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	value, ok := c.entries[key]
+	if !ok {
+		return nil, ports.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	c.entries[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *Cache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	delete(c.entries, key)
+	return nil
+}
+
+// DeletePattern понимает glob-шаблоны, как SCAN MATCH в redis
+func (c *Cache) DeletePattern(pattern string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	for key := range c.entries {
+		if ok, _ := path.Match(pattern, key); ok {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+// Has - есть ли ключ в кеше
+func (c *Cache) Has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.entries[key]
+	return ok
+}
+
+// TTL - срок, с которым ключ записали последний раз
+func (c *Cache) TTL(key string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttls[key]
+}
+
+// Keys - все ключи в кеше
+func (c *Cache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+var _ ports.CachePort = (*Cache)(nil)
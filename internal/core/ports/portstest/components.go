@@ -0,0 +1,447 @@
+package portstest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// errComponentNotFound - тот же текст, что отдаёт postgres
+var errComponentNotFound = errors.New("component not found")
+
+// AddComponent кладёт компонент как есть, без проверки байка: так готовят
+// и компоненты-сироты
+func (s *Store) AddComponent(component *domain.Component) *domain.Component {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if component.ID == uuid.Nil {
+		component.ID = uuid.New()
+	}
+	component.NormalizePosition()
+	if component.CreatedAt.IsZero() {
+		component.CreatedAt = s.now()
+	}
+	if component.UpdatedAt.IsZero() {
+		component.UpdatedAt = component.CreatedAt
+	}
+	s.components[component.ID] = cloneComponent(component)
+	return component
+}
+
+// Component - сохранённый компонент, в том числе заменённый
+func (s *Store) Component(id uuid.UUID) (*domain.Component, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	component, ok := s.components[id]
+	return cloneComponent(component), ok
+}
+
+// Components - все компоненты байка, включая заменённые, по created_at
+func (s *Store) Components(bikeID uuid.UUID) []*domain.Component {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var components []*domain.Component
+	for _, c := range s.components {
+		if c.BikeID == bikeID {
+			components = append(components, cloneComponent(c))
+		}
+	}
+	slices.SortFunc(components, func(a, b *domain.Component) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return components
+}
+
+func (s *Store) CreateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error) {
+	if err := s.fail("CreateComponent"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.liveBike(component.BikeID) {
+		return nil, domain.ErrBikeNotFound
+	}
+	if err := component.ValidateThresholds(); err != nil {
+		return nil, err
+	}
+	if s.positionTaken(component) {
+		return nil, domain.ErrDuplicatePosition
+	}
+	if component.ID == uuid.Nil {
+		component.ID = uuid.New()
+	}
+	component.CreatedAt = s.now()
+	component.UpdatedAt = component.CreatedAt
+	s.components[component.ID] = cloneComponent(component)
+	return component, nil
+}
+
+func (s *Store) GetComponentByID(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	return s.getComponent("GetComponentByID", componentID)
+}
+
+func (s *Store) GetComponentByIDForWrite(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	return s.getComponent("GetComponentByIDForWrite", componentID)
+}
+
+func (s *Store) getComponent(method string, componentID uuid.UUID) (*domain.Component, error) {
+	if err := s.fail(method); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	component, ok := s.components[componentID]
+	if !ok {
+		return nil, errComponentNotFound
+	}
+	return cloneComponent(component), nil
+}
+
+// GetComponentsByBikeID поддерживает те же фильтры и сортировки, что postgres
+func (s *Store) GetComponentsByBikeID(ctx context.Context, bikeID uuid.UUID, filter domain.ComponentFilter) ([]*domain.Component, error) {
+	if err := s.fail("GetComponentsByBikeID"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bike, ok := s.bikes[bikeID]
+	if !ok {
+		return nil, nil
+	}
+	var components []*domain.Component
+	for _, c := range s.components {
+		switch {
+		case c.BikeID != bikeID:
+		case c.ReplacedAt != nil && !filter.IncludeReplaced:
+		case filter.Name != "" && c.Name != filter.Name:
+		case filter.InstalledAfter != nil && c.InstalledAt.Before(*filter.InstalledAfter):
+		case filter.InstalledBefore != nil && c.InstalledAt.After(*filter.InstalledBefore):
+		default:
+			components = append(components, cloneComponent(c))
+		}
+	}
+
+	sort, order := filter.Ordering()
+	now := s.now()
+	slices.SortStableFunc(components, func(a, b *domain.Component) int {
+		if a.ID == b.ID {
+			return 0
+		}
+		var c int
+		switch sort {
+		case domain.ComponentSortWear:
+			c = cmp.Compare(a.Wear(bike.Mileage, now), b.Wear(bike.Mileage, now))
+		case domain.ComponentSortName:
+			c = strings.Compare(string(a.Name), string(b.Name))
+		case domain.ComponentSortMaxMileage:
+			// без порога по пробегу - всегда в конце
+			switch {
+			case a.MaxMileage == 0 && b.MaxMileage == 0:
+			case a.MaxMileage == 0:
+				return 1
+			case b.MaxMileage == 0:
+				return -1
+			default:
+				c = cmp.Compare(a.MaxMileage, b.MaxMileage)
+			}
+		default:
+			c = a.InstalledAt.Compare(b.InstalledAt)
+		}
+		if order == domain.SortDesc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return page(components, filter.Page), nil
+}
+
+func (s *Store) UpdateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error) {
+	if err := s.fail("UpdateComponent"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.components[component.ID]
+	if !ok {
+		return nil, errComponentNotFound
+	}
+	if stored.ReplacedAt != nil {
+		return nil, domain.ErrComponentReplaced
+	}
+	if err := component.ValidateThresholds(); err != nil {
+		return nil, err
+	}
+	updated := cloneComponent(component)
+	updated.BikeID = stored.BikeID
+	updated.ReplacedAt = nil
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = s.now()
+	if s.positionTaken(updated) {
+		return nil, domain.ErrDuplicatePosition
+	}
+	s.components[component.ID] = updated
+	return cloneComponent(updated), nil
+}
+
+func (s *Store) MarkComponentReplaced(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	if err := s.fail("MarkComponentReplaced"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	component, ok := s.components[componentID]
+	if !ok || component.ReplacedAt != nil {
+		return nil, domain.ErrComponentReplaced
+	}
+	now := s.now()
+	component.ReplacedAt = &now
+	component.UpdatedAt = now
+	return cloneComponent(component), nil
+}
+
+func (s *Store) DeleteComponent(ctx context.Context, componentID uuid.UUID) error {
+	if err := s.fail("DeleteComponent"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.components[componentID]; !ok {
+		return errComponentNotFound
+	}
+	delete(s.components, componentID)
+	return nil
+}
+
+// SuggestValues - различающиеся без учёта регистра значения по префиксу
+func (s *Store) SuggestValues(ctx context.Context, q domain.SuggestQuery) ([]string, error) {
+	if err := s.fail("SuggestValues"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]string)
+	for _, c := range s.components {
+		if q.UserID != uuid.Nil {
+			bike, ok := s.bikes[c.BikeID]
+			if !ok || bike.UserID != q.UserID {
+				continue
+			}
+		}
+		value := c.Brand
+		if q.Field == domain.SuggestModel {
+			value = c.Model
+		}
+		lower := strings.ToLower(value)
+		if value == "" || !strings.HasPrefix(lower, strings.ToLower(q.Prefix)) {
+			continue
+		}
+		if prev, ok := seen[lower]; !ok || value < prev {
+			seen[lower] = value
+		}
+	}
+	keys := slices.Sorted(func(yield func(string) bool) {
+		for k := range seen {
+			if !yield(k) {
+				return
+			}
+		}
+	})
+	values := []string{}
+	for _, k := range keys {
+		if q.Limit > 0 && len(values) == q.Limit {
+			break
+		}
+		values = append(values, seen[k])
+	}
+	return values, nil
+}
+
+func (s *Store) ListComponentDefaults(ctx context.Context) ([]*domain.ComponentDefault, error) {
+	if err := s.fail("ListComponentDefaults"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var defaults []*domain.ComponentDefault
+	for _, d := range s.defaults {
+		copied := *d
+		defaults = append(defaults, &copied)
+	}
+	slices.SortFunc(defaults, func(a, b *domain.ComponentDefault) int {
+		return strings.Compare(string(a.Name), string(b.Name))
+	})
+	return defaults, nil
+}
+
+func (s *Store) UpsertComponentDefault(ctx context.Context, d *domain.ComponentDefault) (*domain.ComponentDefault, error) {
+	if err := s.fail("UpsertComponentDefault"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.UpdatedAt = s.now()
+	copied := *d
+	s.defaults[d.Name] = &copied
+	return d, nil
+}
+
+func (s *Store) ListComponentWeights(ctx context.Context) ([]*domain.ComponentWeight, error) {
+	if err := s.fail("ListComponentWeights"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var weights []*domain.ComponentWeight
+	for _, w := range s.weights {
+		copied := *w
+		weights = append(weights, &copied)
+	}
+	slices.SortFunc(weights, func(a, b *domain.ComponentWeight) int {
+		return strings.Compare(string(a.Name), string(b.Name))
+	})
+	return weights, nil
+}
+
+func (s *Store) UpsertComponentWeight(ctx context.Context, w *domain.ComponentWeight) (*domain.ComponentWeight, error) {
+	if err := s.fail("UpsertComponentWeight"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.UpdatedAt = s.now()
+	copied := *w
+	s.weights[w.Name] = &copied
+	return w, nil
+}
+
+func (s *Store) ListOrphanedComponents(ctx context.Context, p domain.Page) ([]*domain.Component, error) {
+	if err := s.fail("ListOrphanedComponents"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orphans []*domain.Component
+	for _, c := range s.components {
+		if !s.liveBike(c.BikeID) {
+			orphans = append(orphans, cloneComponent(c))
+		}
+	}
+	slices.SortFunc(orphans, func(a, b *domain.Component) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return page(orphans, p), nil
+}
+
+func (s *Store) ReassignOrphanedComponents(ctx context.Context, ids []uuid.UUID, bikeID uuid.UUID) ([]uuid.UUID, error) {
+	if err := s.fail("ReassignOrphanedComponents"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.bikes[bikeID]; !ok {
+		return nil, domain.ErrBikeNotFound
+	}
+	var moved []uuid.UUID
+	for _, id := range ids {
+		c, ok := s.components[id]
+		if !ok {
+			continue
+		}
+		if s.liveBike(c.BikeID) {
+			continue
+		}
+		candidate := cloneComponent(c)
+		candidate.BikeID = bikeID
+		if candidate.ReplacedAt == nil && s.positionTaken(candidate) {
+			return nil, domain.ErrDuplicatePosition
+		}
+		c.BikeID = bikeID
+		c.UpdatedAt = s.now()
+		moved = append(moved, id)
+	}
+	return moved, nil
+}
+
+func (s *Store) DeleteOrphanedComponents(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if err := s.fail("DeleteOrphanedComponents"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted []uuid.UUID
+	for _, id := range ids {
+		c, ok := s.components[id]
+		if !ok {
+			continue
+		}
+		if s.liveBike(c.BikeID) {
+			continue
+		}
+		delete(s.components, id)
+		deleted = append(deleted, id)
+	}
+	return deleted, nil
+}
+
+// liveBike - байк есть и не удалён мягко. Компоненты остальных - сироты
+func (s *Store) liveBike(bikeID uuid.UUID) bool {
+	bike, ok := s.bikes[bikeID]
+	return ok && bike.DeletedAt == nil
+}
+
+// activeComponents - незаменённые компоненты байка
+func (s *Store) activeComponents(bikeID uuid.UUID) []*domain.Component {
+	var components []*domain.Component
+	for _, c := range s.components {
+		if c.BikeID == bikeID && c.ReplacedAt == nil {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// positionTaken повторяет uq_components_paired_position: парная деталь
+// одна на позицию среди незаменённых
+func (s *Store) positionTaken(component *domain.Component) bool {
+	if !domain.PairedComponents[component.Name] {
+		return false
+	}
+	position := component.Position
+	if position == "" {
+		position = domain.PositionNone
+	}
+	for _, c := range s.activeComponents(component.BikeID) {
+		if c.ID != component.ID && c.Name == component.Name && c.Position == position {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneComponent(c *domain.Component) *domain.Component {
+	if c == nil {
+		return nil
+	}
+	copied := *c
+	if c.MaxAgeDays != nil {
+		days := *c.MaxAgeDays
+		copied.MaxAgeDays = &days
+	}
+	if c.ReplacedAt != nil {
+		at := *c.ReplacedAt
+		copied.ReplacedAt = &at
+	}
+	return &copied
+}
@@ -0,0 +1,79 @@
+package portstest
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type idempotencyKeyID struct {
+	userID uuid.UUID
+	scope  domain.IdempotencyScope
+	key    string
+}
+
+type idempotencyKey struct {
+	requestHash string
+	resourceIDs []uuid.UUID
+	createdAt   time.Time
+}
+
+// ClaimKey занимает ключ или перезаписывает протухший, как в postgres
+func (s *Store) ClaimKey(ctx context.Context, scope domain.IdempotencyScope, key domain.IdempotencyKey, resourceIDs []uuid.UUID, ttl time.Duration) (bool, error) {
+	if err := s.fail("ClaimKey"); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := idempotencyKeyID{userID: key.UserID, scope: scope, key: key.Key}
+	now := s.now()
+	if stored, ok := s.idempotency[id]; ok && !stored.createdAt.Before(now.Add(-ttl)) {
+		return false, nil
+	}
+	s.idempotency[id] = idempotencyKey{requestHash: key.RequestHash, resourceIDs: slices.Clone(resourceIDs), createdAt: now}
+	return true, nil
+}
+
+func (s *Store) GetKey(ctx context.Context, scope domain.IdempotencyScope, userID uuid.UUID, key string) (string, []uuid.UUID, error) {
+	if err := s.fail("GetKey"); err != nil {
+		return "", nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.idempotency[idempotencyKeyID{userID: userID, scope: scope, key: key}]
+	if !ok {
+		return "", nil, sql.ErrNoRows
+	}
+	return stored.requestHash, slices.Clone(stored.resourceIDs), nil
+}
+
+// PurgeExpiredKeys удаляет ключи старше ttl, как в postgres
+func (s *Store) PurgeExpiredKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	if err := s.fail("PurgeExpiredKeys"); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := s.now().Add(-ttl)
+	var purged int64
+	for id, stored := range s.idempotency {
+		if stored.createdAt.Before(cutoff) {
+			delete(s.idempotency, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// HasIdempotencyKey - есть ли ключ, протухший или нет
+func (s *Store) HasIdempotencyKey(scope domain.IdempotencyScope, userID uuid.UUID, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.idempotency[idempotencyKeyID{userID: userID, scope: scope, key: key}]
+	return ok
+}
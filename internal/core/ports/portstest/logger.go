@@ -0,0 +1,59 @@
+// Package portstest - заглушки портов для тестов сервисов и хендлеров
+package portstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+// LogEntry - одна запись Logger
+type LogEntry struct {
+	Level   string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Logger запоминает записи, чтобы тест мог проверить аудит и предупреждения
+type Logger struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func (l *Logger) log(level, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, LogEntry{Level: level, Message: msg, Fields: fields})
+}
+
+// Entries - копия записей на текущий момент
+func (l *Logger) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LogEntry(nil), l.entries...)
+}
+
+// Find - первая запись с таким сообщением
+func (l *Logger) Find(msg string) (LogEntry, bool) {
+	for _, entry := range l.Entries() {
+		if entry.Message == msg {
+			return entry, true
+		}
+	}
+	return LogEntry{}, false
+}
+
+func (l *Logger) Info(msg string, fields map[string]interface{})  { l.log("info", msg, fields) }
+func (l *Logger) Error(msg string, fields map[string]interface{}) { l.log("error", msg, fields) }
+func (l *Logger) Debug(msg string, fields map[string]interface{}) { l.log("debug", msg, fields) }
+func (l *Logger) Warn(msg string, fields map[string]interface{})  { l.log("warn", msg, fields) }
+
+func (l *Logger) WithContext(context.Context) ports.LoggerPort { return l }
+
+func (l *Logger) InfoGRPC(context.Context, string, any)  {}
+func (l *Logger) ErrorGRPC(context.Context, string, any) {}
+func (l *Logger) DebugGRPC(context.Context, string, any) {}
+func (l *Logger) WarnGRPC(context.Context, string, any)  {}
+
+var _ ports.LoggerPort = (*Logger)(nil)
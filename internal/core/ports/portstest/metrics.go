@@ -0,0 +1,105 @@
+package portstest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics считает то, что тесты проверяют: запросы в обработке, состояния
+// breaker'ов, обогащение из user-service и публикации событий. Остальное игнорирует
+type Metrics struct {
+	mu                 sync.Mutex
+	inFlight           map[string]int
+	breakerStates      map[string]int
+	enrichmentFailures map[string]int
+	publishes          map[string]int
+}
+
+// InFlight - сколько запросов группы group сейчас в обработке
+func (m *Metrics) InFlight(group string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight[group]
+}
+
+// BreakerState - последнее состояние breaker'а name
+func (m *Metrics) BreakerState(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.breakerStates[name]
+}
+
+// EnrichmentFailures - сколько раз обогащение не удалось по reason
+func (m *Metrics) EnrichmentFailures(reason string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enrichmentFailures[reason]
+}
+
+// Publishes - сколько публикаций в sink завершились с результатом ok
+func (m *Metrics) Publishes(sink string, ok bool) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.publishes[publishKey(sink, ok)]
+}
+
+func publishKey(sink string, ok bool) string {
+	if ok {
+		return sink + ":ok"
+	}
+	return sink + ":failed"
+}
+
+func (m *Metrics) IncrementCounter(string, map[string]string)              {}
+func (m *Metrics) RecordDuration(string, time.Duration, map[string]string) {}
+func (m *Metrics) RecordMetrics(*gin.Context, time.Time)                   {}
+func (m *Metrics) IncUserEnrichmentSuccess(string)                         {}
+func (m *Metrics) RecordCacheResult(string, bool)                          {}
+
+func (m *Metrics) IncInFlight(group string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight == nil {
+		m.inFlight = make(map[string]int)
+	}
+	m.inFlight[group]++
+}
+
+func (m *Metrics) DecInFlight(group string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[group]--
+}
+
+func (m *Metrics) IncUserEnrichmentFailure(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.enrichmentFailures == nil {
+		m.enrichmentFailures = make(map[string]int)
+	}
+	m.enrichmentFailures[reason]++
+}
+
+func (m *Metrics) SetCircuitBreakerState(name string, state int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.breakerStates == nil {
+		m.breakerStates = make(map[string]int)
+	}
+	m.breakerStates[name] = state
+}
+
+func (m *Metrics) RecordEventPublish(sink string, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.publishes == nil {
+		m.publishes = make(map[string]int)
+	}
+	m.publishes[publishKey(sink, ok)]++
+}
+
+var _ ports.MetricsPort = (*Metrics)(nil)
@@ -0,0 +1,165 @@
+package portstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type outboxRow struct {
+	domain.OutboxMessage
+	event        *domain.Event
+	claimedUntil time.Time
+}
+
+func (s *Store) Enqueue(ctx context.Context, event *domain.Event) error {
+	if err := s.fail("Enqueue"); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	s.outbox = append(s.outbox, &outboxRow{
+		OutboxMessage: domain.OutboxMessage{
+			ID:        event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+			BikeID:    event.BikeID,
+			Seq:       s.seq,
+			CreatedAt: s.now(),
+		},
+		event: event,
+	})
+	return nil
+}
+
+// Events - события в порядке записи в outbox
+func (s *Store) Events() []*domain.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]*domain.Event, len(s.outbox))
+	for i, row := range s.outbox {
+		events[i] = row.event
+	}
+	return events
+}
+
+// EventTypes - типы событий в порядке записи
+func (s *Store) EventTypes() []domain.EventType {
+	var types []domain.EventType
+	for _, event := range s.Events() {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// OutboxMessage - строка outbox по id события
+func (s *Store) OutboxMessage(id uuid.UUID) (domain.OutboxMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range s.outbox {
+		if row.ID == id {
+			return row.OutboxMessage, true
+		}
+	}
+	return domain.OutboxMessage{}, false
+}
+
+// ClaimPending повторяет postgres: события байка, у которого уже есть
+// взятое неотправленное сообщение, не выдаются. События без байка, как
+// NULL bike_id в postgres, друг друга не блокируют
+func (s *Store) ClaimPending(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	if err := s.fail("ClaimPending"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	pending := func(row *outboxRow) bool {
+		return row.SentAt == nil && row.Attempts < maxAttempts
+	}
+	busy := make(map[uuid.UUID]bool)
+	for _, row := range s.outbox {
+		if pending(row) && !row.claimedUntil.Before(now) && row.BikeID != uuid.Nil {
+			busy[row.BikeID] = true
+		}
+	}
+
+	var claimed []*domain.OutboxMessage
+	for _, row := range s.outbox {
+		if len(claimed) == limit {
+			break
+		}
+		if !pending(row) || !row.claimedUntil.Before(now) || busy[row.BikeID] {
+			continue
+		}
+		row.claimedUntil = now.Add(lease)
+		msg := row.OutboxMessage
+		claimed = append(claimed, &msg)
+	}
+	slices.SortFunc(claimed, func(a, b *domain.OutboxMessage) int {
+		return int(a.Seq - b.Seq)
+	})
+	return claimed, nil
+}
+
+func (s *Store) MarkSent(ctx context.Context, id uuid.UUID) error {
+	if err := s.fail("MarkSent"); err != nil {
+		return err
+	}
+	return s.updateOutbox(id, func(row *outboxRow) {
+		now := s.now()
+		row.SentAt = &now
+		row.Attempts++
+		row.claimedUntil = time.Time{}
+	})
+}
+
+func (s *Store) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	if err := s.fail("MarkFailed"); err != nil {
+		return err
+	}
+	return s.updateOutbox(id, func(row *outboxRow) {
+		row.Attempts++
+		row.LastError = reason
+		row.claimedUntil = time.Time{}
+	})
+}
+
+func (s *Store) Release(ctx context.Context, ids []uuid.UUID) error {
+	if err := s.fail("Release"); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.updateOutbox(id, func(row *outboxRow) {
+			if row.SentAt == nil {
+				row.claimedUntil = time.Time{}
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) updateOutbox(id uuid.UUID, update func(row *outboxRow)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range s.outbox {
+		if row.ID == id {
+			update(row)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package portstest
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+// Store - репозитории в памяти, общие для байков, компонентов, ключей
+// идемпотентности, outbox и вебхуков, чтобы компоненты видели байки, а
+// WithinTransaction откатывал всё сразу. Транзакции не изолированы друг от
+// друга: параллельный тест увидит незакоммиченные записи
+type Store struct {
+	mu sync.Mutex
+	state
+
+	// Errors - ошибка, которую вернёт метод с таким именем вместо работы
+	Errors map[string]error
+	// Now - текущее время для created_at/updated_at, nil - time.Now
+	Now func() time.Time
+}
+
+type state struct {
+	bikes       map[uuid.UUID]*domain.Bike
+	components  map[uuid.UUID]*domain.Component
+	defaults    map[domain.ComponentName]*domain.ComponentDefault
+	weights     map[domain.ComponentName]*domain.ComponentWeight
+	idempotency map[idempotencyKeyID]idempotencyKey
+	outbox      []*outboxRow
+	webhooks    map[uuid.UUID]*domain.Webhook
+	deadLetters []*domain.WebhookDeadLetter
+	deliveries  []*deliveryRow
+	seq         int64
+}
+
+func NewStore() *Store {
+	return &Store{state: state{
+		bikes:       make(map[uuid.UUID]*domain.Bike),
+		components:  make(map[uuid.UUID]*domain.Component),
+		defaults:    make(map[domain.ComponentName]*domain.ComponentDefault),
+		weights:     make(map[domain.ComponentName]*domain.ComponentWeight),
+		idempotency: make(map[idempotencyKeyID]idempotencyKey),
+		webhooks:    make(map[uuid.UUID]*domain.Webhook),
+	}}
+}
+
+type txKey struct{}
+
+// WithinTransaction откатывает все изменения Store, если fn вернула ошибку.
+// Вложенный вызов, как и в postgres, переиспользует внешнюю транзакцию
+func (s *Store) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := s.fail("WithinTransaction"); err != nil {
+		return err
+	}
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	snapshot := s.state.clone()
+	s.mu.Unlock()
+
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		s.mu.Lock()
+		s.state = snapshot
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *Store) WithoutTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txKey{}, nil)
+}
+
+// InTransaction - открыта ли в ctx транзакция Store
+func (s *Store) InTransaction(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+func (st state) clone() state {
+	c := state{
+		bikes:       make(map[uuid.UUID]*domain.Bike, len(st.bikes)),
+		components:  make(map[uuid.UUID]*domain.Component, len(st.components)),
+		defaults:    maps.Clone(st.defaults),
+		weights:     maps.Clone(st.weights),
+		idempotency: maps.Clone(st.idempotency),
+		webhooks:    maps.Clone(st.webhooks),
+		deadLetters: append([]*domain.WebhookDeadLetter(nil), st.deadLetters...),
+		seq:         st.seq,
+	}
+	for id, b := range st.bikes {
+		c.bikes[id] = cloneBike(b)
+	}
+	for id, comp := range st.components {
+		c.components[id] = cloneComponent(comp)
+	}
+	for _, row := range st.outbox {
+		copied := *row
+		c.outbox = append(c.outbox, &copied)
+	}
+	for _, row := range st.deliveries {
+		copied := *row
+		c.deliveries = append(c.deliveries, &copied)
+	}
+	return c
+}
+
+// fail - ошибка из Errors для метода name
+func (s *Store) fail(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Errors[name]
+}
+
+// SetError подменяет результат метода name, nil снимает подмену
+func (s *Store) SetError(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Errors == nil {
+		s.Errors = make(map[string]error)
+	}
+	s.Errors[name] = err
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+var (
+	_ ports.Transactor            = (*Store)(nil)
+	_ ports.BikeRepository        = (*Store)(nil)
+	_ ports.ComponentRepository   = (*Store)(nil)
+	_ ports.IdempotencyRepository = (*Store)(nil)
+	_ ports.OutboxRepository      = (*Store)(nil)
+	_ ports.WebhookRepository     = (*Store)(nil)
+)
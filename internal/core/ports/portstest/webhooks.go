@@ -0,0 +1,217 @@
+package portstest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+var errWebhookNotFound = errors.New("webhook not found")
+
+func (s *Store) CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error) {
+	if err := s.fail("CreateWebhook"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	webhook.CreatedAt = s.now()
+	copied := *webhook
+	s.webhooks[webhook.ID] = &copied
+	return webhook, nil
+}
+
+func (s *Store) GetWebhookByID(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error) {
+	if err := s.fail("GetWebhookByID"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	webhook, ok := s.webhooks[webhookID]
+	if !ok {
+		return nil, errWebhookNotFound
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+func (s *Store) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Webhook, error) {
+	if err := s.fail("GetWebhooksByUserID"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var webhooks []*domain.Webhook
+	for _, w := range s.webhooks {
+		if w.UserID == userID {
+			copied := *w
+			webhooks = append(webhooks, &copied)
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b *domain.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return webhooks, nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+	if err := s.fail("DeleteWebhook"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return errWebhookNotFound
+	}
+	delete(s.webhooks, webhookID)
+	return nil
+}
+
+func (s *Store) CreateDeadLetter(ctx context.Context, deadLetter *domain.WebhookDeadLetter) error {
+	if err := s.fail("CreateDeadLetter"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if deadLetter.ID == uuid.Nil {
+		deadLetter.ID = uuid.New()
+	}
+	deadLetter.CreatedAt = s.now()
+	copied := *deadLetter
+	s.deadLetters = append(s.deadLetters, &copied)
+	return nil
+}
+
+// DeadLetters - сохранённые недоставленные события
+func (s *Store) DeadLetters() []*domain.WebhookDeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.deadLetters)
+}
+
+type deliveryRow struct {
+	domain.WebhookDelivery
+	claimedUntil time.Time
+	completedAt  time.Time
+}
+
+func (s *Store) EnqueueDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	if err := s.fail("EnqueueDeliveries"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range deliveries {
+		if slices.ContainsFunc(s.deliveries, func(row *deliveryRow) bool {
+			return row.WebhookID == d.WebhookID && row.EventID == d.EventID
+		}) {
+			continue
+		}
+		row := &deliveryRow{WebhookDelivery: *d}
+		row.CreatedAt = s.now()
+		row.NextAttemptAt = row.CreatedAt
+		s.deliveries = append(s.deliveries, row)
+	}
+	return nil
+}
+
+func (s *Store) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	if err := s.fail("ClaimDueDeliveries"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	busy := make(map[uuid.UUID]bool)
+	for _, row := range s.deliveries {
+		if row.completedAt.IsZero() && row.claimedUntil.After(now) {
+			busy[row.WebhookID] = true
+		}
+	}
+	var claimed []*domain.WebhookDelivery
+	for _, row := range s.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if !row.completedAt.IsZero() || busy[row.WebhookID] || row.NextAttemptAt.After(now) {
+			continue
+		}
+		busy[row.WebhookID] = true
+		row.claimedUntil = now.Add(lease)
+		copied := row.WebhookDelivery
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (s *Store) CompleteDelivery(ctx context.Context, id uuid.UUID) error {
+	if err := s.fail("CompleteDelivery"); err != nil {
+		return err
+	}
+	return s.updateDelivery(id, func(row *deliveryRow) {
+		row.completedAt = s.now()
+		row.claimedUntil = time.Time{}
+	})
+}
+
+func (s *Store) RetryDelivery(ctx context.Context, id uuid.UUID, delay time.Duration, reason string) error {
+	if err := s.fail("RetryDelivery"); err != nil {
+		return err
+	}
+	return s.updateDelivery(id, func(row *deliveryRow) {
+		row.Attempts++
+		row.LastError = reason
+		row.NextAttemptAt = s.now().Add(delay)
+		row.claimedUntil = time.Time{}
+	})
+}
+
+func (s *Store) PurgeCompletedDeliveries(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if err := s.fail("PurgeCompletedDeliveries"); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := s.now().Add(-olderThan)
+	before := len(s.deliveries)
+	s.deliveries = slices.DeleteFunc(s.deliveries, func(row *deliveryRow) bool {
+		return !row.completedAt.IsZero() && row.completedAt.Before(cutoff)
+	})
+	return int64(before - len(s.deliveries)), nil
+}
+
+func (s *Store) updateDelivery(id uuid.UUID, update func(row *deliveryRow)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range s.deliveries {
+		if row.ID == id {
+			update(row)
+			return nil
+		}
+	}
+	return nil
+}
+
+// PendingDeliveries - доставки, ещё не доставленные и не ушедшие в dead letter
+func (s *Store) PendingDeliveries() []domain.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []domain.WebhookDelivery
+	for _, row := range s.deliveries {
+		if row.completedAt.IsZero() {
+			pending = append(pending, row.WebhookDelivery)
+		}
+	}
+	return pending
+}
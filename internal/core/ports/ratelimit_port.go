@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitQuota - состояние корзины сразу после решения по запросу
+type RateLimitQuota struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// через сколько корзина снова будет полной и через сколько появится следующий токен
+	Reset time.Duration
+	Retry time.Duration
+}
+
+type RateLimiter interface {
+	// Allow списывает токен из корзины key, если он есть
+	Allow(ctx context.Context, key string) (RateLimitQuota, error)
+}
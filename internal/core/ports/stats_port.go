@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+)
+
+type StatsRepository interface {
+	GetFleetStats(ctx context.Context, warnPercent int) (*domain.FleetStats, error)
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error)
+	GetWebhookByID(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error)
+	GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error
+	CreateDeadLetter(ctx context.Context, deadLetter *domain.WebhookDeadLetter) error
+
+	// EnqueueDeliveries ставит события в очереди вебхуков. Повтор того же
+	// события для того же вебхука ничего не добавляет
+	EnqueueDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error
+	// ClaimDueDeliveries берёт на lease не больше одной доставки на вебхук и
+	// должен вызываться внутри WithinTransaction. Отправлять - после commit
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	// CompleteDelivery убирает доставку из очереди: доставлена или ушла в dead letter
+	CompleteDelivery(ctx context.Context, id uuid.UUID) error
+	// RetryDelivery засчитывает неудачную попытку и откладывает следующую на delay
+	RetryDelivery(ctx context.Context, id uuid.UUID, delay time.Duration, reason string) error
+	// PurgeCompletedDeliveries удаляет завершённые доставки старше olderThan
+	PurgeCompletedDeliveries(ctx context.Context, olderThan time.Duration) (int64, error)
+}
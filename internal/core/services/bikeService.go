@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,14 +14,45 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
+var ErrInvalidBulkUpdate = errors.New("invalid bulk update")
+
+var (
+	ErrInvalidBikeBatch     = errors.New("invalid bike batch")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+)
+
+// errCreateReplay откатывает транзакцию создания, если ключ идемпотентности уже занят
+var errCreateReplay = errors.New("create replay")
+
+// idempotencyKeyTTL - сколько помним ключ создания, одиночного или батча:
+// клиенты повторяют запрос минуты, а не дни
+const idempotencyKeyTTL = 24 * time.Hour
+
+// userBikesCacheTTL короткий: списки читаются с реплики, и при её отставании
+// в кеш после сброса может попасть список без только что записанного байка
+const userBikesCacheTTL = 30 * time.Second
+
+// BikeCreateResult - итог создания одного байка из батча
+type BikeCreateResult struct {
+	Bike *domain.Bike
+	Err  error
+}
+
 type BikeService struct {
 	bikeRepo      ports.BikeRepository
 	componentRepo ports.ComponentRepository
 	logger        ports.LoggerPort
 	validate      *validator.Validate
 	cache         ports.CachePort
+	tx            ports.Transactor
+	outbox        ports.OutboxRepository
+	idempotency   ports.IdempotencyRepository
+	metrics       ports.MetricsPort
+	bikeLoads     singleflight.Group
 }
 
 func NewBikeService(
@@ -27,6 +61,10 @@ func NewBikeService(
 	logger ports.LoggerPort,
 	validate *validator.Validate,
 	cache ports.CachePort,
+	tx ports.Transactor,
+	outbox ports.OutboxRepository,
+	idempotency ports.IdempotencyRepository,
+	metrics ports.MetricsPort,
 ) *BikeService {
 	return &BikeService{
 		bikeRepo:      bikeRepo,
@@ -34,107 +72,568 @@ func NewBikeService(
 		logger:        logger,
 		validate:      validate,
 		cache:         cache,
+		tx:            tx,
+		outbox:        outbox,
+		idempotency:   idempotency,
+		metrics:       metrics,
 	}
 }
 
-func (s *BikeService) CreateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error) {
+// CreateBike создаёт байк. С непустым key.Key повтор того же запроса вернёт
+// уже созданный байк с replayed=true, тот же ключ с другим телом - ErrIdempotencyKeyReused
+func (s *BikeService) CreateBike(ctx context.Context, bike *domain.Bike, key domain.IdempotencyKey) (*domain.Bike, bool, error) {
 	if err := s.validate.Struct(bike); err != nil {
-		s.logger.Error("Bike validation failed", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Bike validation failed", map[string]interface{}{
 			"error": err.Error(),
 		})
-		return nil, fmt.Errorf("validation error: %w", err)
+		return nil, false, validationError(err)
+	}
+	if err := bike.Type.Validate(); err != nil {
+		return nil, false, fmt.Errorf("validation error: %w", err)
 	}
 
 	if bike.BikeID == uuid.Nil {
 		bike.BikeID = uuid.New()
 	}
 
-	createdBike, err := s.bikeRepo.CreateBike(ctx, bike)
+	var createdBike *domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if key.Key != "" {
+			claimed, err := s.idempotency.ClaimKey(ctx, domain.IdempotencyScopeBike, key, []uuid.UUID{bike.BikeID}, idempotencyKeyTTL)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				return errCreateReplay
+			}
+		}
+		var err error
+		createdBike, err = s.bikeRepo.CreateBike(ctx, bike)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeCreated, createdBike.UserID, createdBike.BikeID, createdBike))
+	})
+	if errors.Is(err, errCreateReplay) {
+		return s.replayBikeCreate(ctx, key)
+	}
 	if err != nil {
-		s.logger.Error("Failed to create bike", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to create bike", map[string]interface{}{
 			"error":   err.Error(),
 			"user_id": bike.UserID,
 		})
-		return nil, err
+		return nil, false, err
 	}
+	s.invalidateUserBikes(createdBike.UserID)
 
-	s.logger.Info("Bike created successfully", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Bike created successfully", map[string]interface{}{
 		"bike_id": createdBike.BikeID,
 		"user_id": createdBike.UserID,
 	})
 
-	return createdBike, nil
+	return createdBike, false, nil
+}
+
+// replayBikeCreate отдаёт байк, созданный запросом с этим ключом.
+// Если байк с тех пор удалили - ErrBikeNotFound
+func (s *BikeService) replayBikeCreate(ctx context.Context, key domain.IdempotencyKey) (*domain.Bike, bool, error) {
+	storedHash, ids, err := s.idempotency.GetKey(ctx, domain.IdempotencyScopeBike, key.UserID, key.Key)
+	if err != nil {
+		return nil, false, err
+	}
+	if storedHash != key.RequestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+
+	// с primary: реплика может ещё не видеть байк, созданный секунду назад
+	bikes, err := s.bikeRepo.GetBikesByIDs(ctx, ids)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(bikes) == 0 {
+		return nil, false, domain.ErrBikeNotFound
+	}
+
+	s.logger.WithContext(ctx).Info("Bike create replayed", map[string]interface{}{
+		"bike_id": bikes[0].BikeID,
+		"user_id": key.UserID,
+	})
+
+	return bikes[0], true, nil
+}
+
+// CreateBikes создаёт батч байков одной транзакцией: либо все, либо ни одного.
+// С непустым key повтор того же батча в течение idempotencyKeyTTL вернёт уже
+// созданные байки с replayed=true, а тот же key с другим содержимым - ErrIdempotencyKeyReused
+func (s *BikeService) CreateBikes(ctx context.Context, userID uuid.UUID, key string, bikes []*domain.Bike) ([]BikeCreateResult, bool, error) {
+	results := make([]BikeCreateResult, len(bikes))
+
+	invalid := false
+	for i, bike := range bikes {
+		if err := s.validate.Struct(bike); err != nil {
+			results[i].Err = validationError(err)
+			invalid = true
+			continue
+		}
+		if err := bike.Type.Validate(); err != nil {
+			results[i].Err = fmt.Errorf("validation error: %w", err)
+			invalid = true
+		}
+	}
+	if invalid {
+		return results, false, ErrInvalidBikeBatch
+	}
+
+	// хеш считаем до выдачи ID, чтобы повтор того же тела дал тот же хеш
+	body, err := json.Marshal(bikes)
+	if err != nil {
+		return nil, false, err
+	}
+	sum := sha256.Sum256(body)
+	batchKey := domain.IdempotencyKey{UserID: userID, Key: key, RequestHash: hex.EncodeToString(sum[:])}
+
+	ids := make([]uuid.UUID, len(bikes))
+	for i, bike := range bikes {
+		if bike.BikeID == uuid.Nil {
+			bike.BikeID = uuid.New()
+		}
+		ids[i] = bike.BikeID
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if key != "" {
+			claimed, err := s.idempotency.ClaimKey(ctx, domain.IdempotencyScopeBikeBatch, batchKey, ids, idempotencyKeyTTL)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				return errCreateReplay
+			}
+		}
+		for i, bike := range bikes {
+			created, err := s.bikeRepo.CreateBike(ctx, bike)
+			if err != nil {
+				results[i].Err = err
+				return err
+			}
+			if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeCreated, created.UserID, created.BikeID, created)); err != nil {
+				return err
+			}
+			results[i].Bike = created
+		}
+		return nil
+	})
+	if errors.Is(err, errCreateReplay) {
+		return s.replayBikeBatch(ctx, batchKey)
+	}
+	if err != nil {
+		for i := range results {
+			results[i].Bike = nil
+		}
+		s.logger.WithContext(ctx).Error("Failed to create bike batch", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+			"count":   len(bikes),
+		})
+		return results, false, err
+	}
+	s.invalidateUserBikes(userID)
+
+	s.logger.WithContext(ctx).Info("Bike batch created", map[string]interface{}{
+		"user_id": userID,
+		"count":   len(bikes),
+	})
+
+	return results, false, nil
+}
+
+// replayBikeBatch отдаёт байки, созданные батчем с этим ключом. Байк, удалённый
+// после создания, приходит с ErrBikeNotFound на своём месте
+func (s *BikeService) replayBikeBatch(ctx context.Context, key domain.IdempotencyKey) ([]BikeCreateResult, bool, error) {
+	storedHash, ids, err := s.idempotency.GetKey(ctx, domain.IdempotencyScopeBikeBatch, key.UserID, key.Key)
+	if err != nil {
+		return nil, false, err
+	}
+	if storedHash != key.RequestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+
+	bikes, err := s.bikeRepo.GetBikesByIDs(ctx, ids)
+	if err != nil {
+		return nil, false, err
+	}
+	byID := make(map[uuid.UUID]*domain.Bike, len(bikes))
+	for _, bike := range bikes {
+		byID[bike.BikeID] = bike
+	}
+
+	results := make([]BikeCreateResult, len(ids))
+	for i, id := range ids {
+		if bike, ok := byID[id]; ok {
+			results[i].Bike = bike
+		} else {
+			results[i].Err = domain.ErrBikeNotFound
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Bike batch replayed", map[string]interface{}{
+		"user_id": key.UserID,
+		"count":   len(ids),
+	})
+
+	return results, true, nil
+}
+
+// ImportBikeSpec повторяет сборку из спецификации на аккаунте userID:
+// байк и все компоненты создаются одной транзакцией
+func (s *BikeService) ImportBikeSpec(ctx context.Context, userID uuid.UUID, spec *domain.BikeSpec) (*domain.Bike, error) {
+	if err := s.validate.Struct(spec); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBikeSpec, validationError(err))
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBikeSpec, err)
+	}
+
+	bike := spec.Build(userID, time.Now())
+	components := bike.Components
+
+	var created *domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.bikeRepo.CreateBike(ctx, bike)
+		if err != nil {
+			return err
+		}
+		if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeCreated, created.UserID, created.BikeID, created)); err != nil {
+			return err
+		}
+
+		created.Components = make([]*domain.Component, 0, len(components))
+		for _, component := range components {
+			createdComponent, err := s.componentRepo.CreateComponent(ctx, component)
+			if err != nil {
+				return err
+			}
+			if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentCreated, created.UserID, created.BikeID, createdComponent)); err != nil {
+				return err
+			}
+			created.Components = append(created.Components, createdComponent)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to import bike spec", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+	s.invalidateUserBikes(created.UserID)
+
+	s.logger.WithContext(ctx).Info("Bike spec imported", map[string]interface{}{
+		"bike_id":    created.BikeID,
+		"user_id":    created.UserID,
+		"components": len(created.Components),
+	})
+
+	return created, nil
 }
 
 func (s *BikeService) GetBikeByID(ctx context.Context, bikeID string) (*domain.Bike, error) {
 	bikeUUID, err := uuid.Parse(bikeID)
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
 			"bike_id": bikeID,
 			"error":   err.Error(),
 		})
 		return nil, fmt.Errorf("invalid bike ID: %w", err)
 	}
 
-	cacheKey := fmt.Sprintf("bike:%s", bikeID)
+	cacheKey := bikeCacheKey(bikeID)
 	cachedData, err := s.cache.Get(cacheKey)
 	if err == nil {
 		var cachedBike domain.Bike
 		if err := json.Unmarshal(cachedData, &cachedBike); err == nil {
-			s.logger.Info("Bike found in cache", map[string]interface{}{
+			s.logger.WithContext(ctx).Info("Bike found in cache", map[string]interface{}{
 				"bike_id": bikeID,
 			})
+			s.metrics.RecordCacheResult("get_bike", true)
 			return &cachedBike, nil
 		}
 	}
+	s.metrics.RecordCacheResult("get_bike", false)
 
-	bike, err := s.bikeRepo.GetBikeByID(ctx, bikeUUID)
+	// одновременные промахи по одному байку делят один запрос в БД. Контекст
+	// без отмены, чтобы ушедший первым клиент не уронил чтение остальным, и
+	// без транзакции: чужая транзакция может откатиться или закрыться раньше,
+	// а её незакоммиченные данные не должны уйти другим читателям и в кеш
+	v, err, _ := s.bikeLoads.Do(bikeUUID.String(), func() (interface{}, error) {
+		bike, err := s.bikeRepo.GetBikeByID(s.tx.WithoutTransaction(context.WithoutCancel(ctx)), bikeUUID)
+		if err != nil {
+			return nil, err
+		}
+		s.cacheBike(cacheKey, bike)
+		return bike, nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to get bike", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
 		return nil, err
 	}
 
+	// каждому вызывающему своя копия, общий результат не трогаем
+	return copyBike(v.(*domain.Bike)), nil
+}
+
+// copyBike - глубокая копия: указатели у копии свои
+func copyBike(b *domain.Bike) *domain.Bike {
+	c := *b
+	if b.Year != nil {
+		year := *b.Year
+		c.Year = &year
+	}
+	if b.ArchivedAt != nil {
+		at := *b.ArchivedAt
+		c.ArchivedAt = &at
+	}
+	if b.DeletedAt != nil {
+		at := *b.DeletedAt
+		c.DeletedAt = &at
+	}
+	if b.Components != nil {
+		c.Components = make([]*domain.Component, len(b.Components))
+		for i, component := range b.Components {
+			copied := *component
+			if component.MaxAgeDays != nil {
+				days := *component.MaxAgeDays
+				copied.MaxAgeDays = &days
+			}
+			if component.ReplacedAt != nil {
+				at := *component.ReplacedAt
+				copied.ReplacedAt = &at
+			}
+			c.Components[i] = &copied
+		}
+	}
+	return &c
+}
+
+func (s *BikeService) cacheBike(cacheKey string, bike *domain.Bike) {
 	bikeData, err := json.Marshal(bike)
 	if err != nil {
 		s.logger.Warn("Failed to marshal bike for cache", map[string]interface{}{
 			"error":   err.Error(),
-			"bike_id": bikeID,
+			"bike_id": bike.BikeID,
 		})
-	} else {
-		if err := s.cache.Set(cacheKey, bikeData, 15*time.Minute); err != nil {
-			s.logger.Warn("Failed to cache bike", map[string]interface{}{
-				"error":   err.Error(),
-				"bike_id": bikeID,
-			})
+		return
+	}
+	if err := s.cache.Set(cacheKey, bikeData, 15*time.Minute); err != nil {
+		s.logger.Warn("Failed to cache bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bike.BikeID,
+		})
+	}
+}
+
+func (s *BikeService) GetBikesByUserID(ctx context.Context, userID string, filter domain.BikeFilter) ([]*domain.Bike, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	cacheKey := userBikesCacheKey(userUUID, filter)
+	if cachedData, err := s.cache.Get(cacheKey); err == nil {
+		var cachedBikes []*domain.Bike
+		if err := json.Unmarshal(cachedData, &cachedBikes); err == nil {
+			s.metrics.RecordCacheResult("get_user_bikes", true)
+			return cachedBikes, nil
 		}
 	}
+	s.metrics.RecordCacheResult("get_user_bikes", false)
 
-	return bike, nil
+	bikes, err := s.bikeRepo.GetBikesByUserID(ctx, userUUID, filter)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get bikes", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+	s.cacheJSON(cacheKey, bikes)
+
+	s.logger.WithContext(ctx).Info("Retrieved bikes for user", map[string]interface{}{
+		"user_id":     userID,
+		"bikes_count": len(bikes),
+	})
+
+	return bikes, nil
+}
+
+// CountBikesByUserID - сколько байков пользователя под фильтром, без учёта страницы
+func (s *BikeService) CountBikesByUserID(ctx context.Context, userID uuid.UUID, filter domain.BikeFilter) (int, error) {
+	cacheKey := userBikesCountCacheKey(userID, filter)
+	if cachedData, err := s.cache.Get(cacheKey); err == nil {
+		var total int
+		if err := json.Unmarshal(cachedData, &total); err == nil {
+			s.metrics.RecordCacheResult("count_user_bikes", true)
+			return total, nil
+		}
+	}
+	s.metrics.RecordCacheResult("count_user_bikes", false)
+
+	total, err := s.bikeRepo.CountBikesByUserID(ctx, userID, filter)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to count bikes", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return 0, err
+	}
+	s.cacheJSON(cacheKey, total)
+	return total, nil
+}
+
+// ListBikes - байки всех пользователей под админским фильтром и сколько их
+// всего без учёта страницы. Мимо кеша: список нужен только поддержке
+func (s *BikeService) ListBikes(ctx context.Context, filter domain.BikeListFilter) ([]*domain.Bike, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("validation error: %w", err)
+	}
+
+	var (
+		bikes []*domain.Bike
+		total int
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		bikes, err = s.bikeRepo.ListBikes(gctx, filter)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		total, err = s.bikeRepo.CountBikes(gctx, filter)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list bikes", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, 0, err
+	}
+
+	return bikes, total, nil
+}
+
+// cacheJSON кладёт список или счётчик байков пользователя на userBikesCacheTTL.
+// Ошибка кеша не мешает ответу
+func (s *BikeService) cacheJSON(cacheKey string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		s.logger.Warn("Failed to marshal value for cache", map[string]interface{}{
+			"error": err.Error(),
+			"key":   cacheKey,
+		})
+		return
+	}
+	if err := s.cache.Set(cacheKey, data, userBikesCacheTTL); err != nil {
+		s.logger.Warn("Failed to cache value", map[string]interface{}{
+			"error": err.Error(),
+			"key":   cacheKey,
+		})
+	}
+}
+
+// invalidateUserBikes сбрасывает закешированные списки и счётчики байков
+// владельца. Вызывается сразу после успешной записи в БД, до всего остального,
+// чтобы сбой на следующих шагах не оставил старый список
+func (s *BikeService) invalidateUserBikes(userID uuid.UUID) {
+	if err := s.cache.DeletePattern(userBikesCachePattern(userID)); err != nil {
+		s.logger.Warn("Failed to invalidate user bikes cache", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+	}
 }
 
-func (s *BikeService) GetBikesByUserID(ctx context.Context, userID string) ([]*domain.Bike, error) {
+// GetUserBikeSummary считает сводку по байкам пользователя для /me, архивные не входят
+func (s *BikeService) GetUserBikeSummary(ctx context.Context, userID string) (domain.BikeSummary, error) {
+	bikes, err := s.GetBikesByUserID(ctx, userID, domain.BikeFilter{})
+	if err != nil {
+		return domain.BikeSummary{}, err
+	}
+	return domain.SummarizeBikes(bikes), nil
+}
+
+func (s *BikeService) GetBikesByUrgency(ctx context.Context, userID string, filter domain.BikeFilter) ([]*domain.BikeUrgency, error) {
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
 			"user_id": userID,
 			"error":   err.Error(),
 		})
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	bikes, err := s.bikeRepo.GetBikesByUrgency(ctx, userUUID, filter)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get bikes by urgency", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Retrieved bikes by urgency", map[string]interface{}{
+		"user_id":     userID,
+		"bikes_count": len(bikes),
+	})
+
+	return bikes, nil
+}
 
-	bikes, err := s.bikeRepo.GetBikesByUserID(ctx, userUUID)
+// GetBikesWithoutComponents - байки без единого компонента, скорее всего
+// ещё не до конца заполненные. Пустой userID - по всем пользователям
+func (s *BikeService) GetBikesWithoutComponents(ctx context.Context, userID string, filter domain.BikeFilter) ([]*domain.Bike, error) {
+	userUUID := uuid.Nil
+	if userID != "" {
+		var err error
+		userUUID, err = uuid.Parse(userID)
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	bikes, err := s.bikeRepo.GetBikesWithoutComponents(ctx, userUUID, filter)
 	if err != nil {
-		s.logger.Error("Failed to get bikes", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to get bikes without components", map[string]interface{}{
 			"error":   err.Error(),
 			"user_id": userID,
 		})
 		return nil, err
 	}
 
-	s.logger.Info("Retrieved bikes for user", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Retrieved bikes without components", map[string]interface{}{
 		"user_id":     userID,
 		"bikes_count": len(bikes),
 	})
@@ -144,101 +643,480 @@ func (s *BikeService) GetBikesByUserID(ctx context.Context, userID string) ([]*d
 
 func (s *BikeService) UpdateBike(ctx context.Context, bike *domain.Bike) (*domain.Bike, error) {
 	if err := s.validate.Struct(bike); err != nil {
-		s.logger.Error("Bike validation failed", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Bike validation failed", map[string]interface{}{
 			"error": err.Error(),
 		})
-		return nil, fmt.Errorf("validation error: %w", err)
+		return nil, validationError(err)
+	}
+	// пустой тип - поле не меняется
+	if bike.Type != "" {
+		if err := bike.Type.Validate(); err != nil {
+			return nil, fmt.Errorf("validation error: %w", err)
+		}
+	}
+	// 0 - пробег не меняется, иначе те же правила, что и в UpdateMileage
+	if bike.Mileage < 0 {
+		return nil, fmt.Errorf("%w: mileage must not be negative", domain.ErrMileageDecrease)
+	}
+	if bike.Mileage > 0 {
+		current, err := s.bikeRepo.GetBikeByIDForWrite(ctx, bike.BikeID)
+		if err != nil {
+			return nil, err
+		}
+		if bike.Mileage < current.Mileage {
+			return nil, fmt.Errorf("%w: stored %d, got %d", domain.ErrMileageDecrease, current.Mileage, bike.Mileage)
+		}
 	}
 
-	updatedBike, err := s.bikeRepo.UpdateBike(ctx, bike)
+	var updatedBike *domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updatedBike, err = s.bikeRepo.UpdateBike(ctx, bike)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeUpdated, updatedBike.UserID, updatedBike.BikeID, updatedBike))
+	})
 	if err != nil {
-		s.logger.Error("Failed to update bike", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to update bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bike.BikeID,
 		})
 		return nil, err
 	}
 
-	cacheKey := fmt.Sprintf("bike:%s", bike.BikeID.String())
-	if err := s.cache.Delete(cacheKey); err != nil {
-		s.logger.Warn("Failed to invalidate bike cache", map[string]interface{}{
+	if err := deleteBikeCache(s.cache, bike.BikeID); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bike.BikeID.String(),
 		})
 	}
+	s.invalidateUserBikes(updatedBike.UserID)
 
-	s.logger.Info("Bike updated successfully", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Bike updated successfully", map[string]interface{}{
 		"bike_id": bike.BikeID,
 	})
 
 	return updatedBike, nil
 }
 
+// SetBikeArchived убирает байк в архив (продан, украден) или возвращает обратно.
+// История и компоненты остаются, меняется только видимость в списках
+func (s *BikeService) SetBikeArchived(ctx context.Context, bikeID uuid.UUID, archived bool) (*domain.Bike, error) {
+	eventType := domain.BikeUnarchived
+	if archived {
+		eventType = domain.BikeArchived
+	}
+
+	var bike *domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		bike, err = s.bikeRepo.SetBikeArchived(ctx, bikeID, archived)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(eventType, bike.UserID, bike.BikeID, bike))
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to change bike archive state", map[string]interface{}{
+			"error":    err.Error(),
+			"bike_id":  bikeID,
+			"archived": archived,
+		})
+		return nil, err
+	}
+
+	if err := deleteBikeCache(s.cache, bikeID); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID.String(),
+		})
+	}
+	s.invalidateUserBikes(bike.UserID)
+
+	s.logger.WithContext(ctx).Info("Bike archive state changed", map[string]interface{}{
+		"bike_id":  bikeID,
+		"archived": archived,
+	})
+
+	return bike, nil
+}
+
+// UpdateMileage обновляет пробег байка и не даёт ему уменьшиться: от пробега
+// считается износ компонентов. Меньше сохранённого - ErrMileageDecrease,
+// обогнал параллельный запрос - ErrMileageConflict
+func (s *BikeService) UpdateMileage(ctx context.Context, bikeID string, mileage int) (*domain.Bike, error) {
+	id, err := uuid.Parse(bikeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bike ID: %w", err)
+	}
+	if mileage < 0 {
+		return nil, fmt.Errorf("%w: mileage must not be negative", domain.ErrMileageDecrease)
+	}
+
+	current, err := s.bikeRepo.GetBikeByIDForWrite(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if mileage < current.Mileage {
+		return nil, fmt.Errorf("%w: stored %d, got %d", domain.ErrMileageDecrease, current.Mileage, mileage)
+	}
+
+	var bike *domain.Bike
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		bike, err = s.bikeRepo.UpdateMileage(ctx, id, mileage)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeUpdated, bike.UserID, bike.BikeID, bike))
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to update bike mileage", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+			"mileage": mileage,
+		})
+		return nil, err
+	}
+
+	if err := deleteBikeCache(s.cache, id); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+	}
+	s.invalidateUserBikes(bike.UserID)
+
+	s.logger.WithContext(ctx).Info("Bike mileage updated", map[string]interface{}{
+		"bike_id":     bikeID,
+		"old_mileage": current.Mileage,
+		"new_mileage": bike.Mileage,
+	})
+
+	return bike, nil
+}
+
+// BulkUpdateBikes применяет массовую правку одной транзакцией вместе с событиями
+// и сбрасывает кеш каждого изменённого байка и пространства их владельцев
+func (s *BikeService) BulkUpdateBikes(ctx context.Context, update domain.BikeBulkUpdate) ([]*domain.Bike, error) {
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBulkUpdate, err)
+	}
+
+	var bikes []*domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		bikes, err = s.bikeRepo.BulkUpdateBikes(ctx, update)
+		if err != nil {
+			return err
+		}
+		for _, bike := range bikes {
+			if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeUpdated, bike.UserID, bike.BikeID, bike)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to bulk update bikes", map[string]interface{}{
+			"error": err.Error(),
+			"field": update.Field,
+		})
+		return nil, err
+	}
+
+	owners := make(map[uuid.UUID]bool)
+	for _, bike := range bikes {
+		if err := deleteBikeCache(s.cache, bike.BikeID); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
+				"error":   err.Error(),
+				"bike_id": bike.BikeID,
+			})
+		}
+		owners[bike.UserID] = true
+	}
+	for userID := range owners {
+		s.invalidateUserCache(ctx, userID)
+	}
+
+	s.logger.WithContext(ctx).Info("Bikes bulk updated", map[string]interface{}{
+		"field":    update.Field,
+		"affected": len(bikes),
+	})
+
+	return bikes, nil
+}
+
 func (s *BikeService) DeleteBike(ctx context.Context, bikeID string) error {
 	bikeUUID, err := uuid.Parse(bikeID)
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
 			"bike_id": bikeID,
 			"error":   err.Error(),
 		})
 		return fmt.Errorf("invalid bike ID: %w", err)
 	}
 
-	err = s.bikeRepo.DeleteBike(ctx, bikeUUID)
+	// удаление мягкое: байк получает deleted_at и пропадает из чтений,
+	// компоненты и их история остаются до восстановления
+	var bike *domain.Bike
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// владелец нужен для события об удалении
+		var err error
+		bike, err = s.bikeRepo.GetBikeByID(ctx, bikeUUID)
+		if err != nil {
+			return err
+		}
+		if err := s.bikeRepo.DeleteBike(ctx, bikeUUID); err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeDeleted, bike.UserID, bike.BikeID, nil))
+	})
 	if err != nil {
-		s.logger.Error("Failed to delete bike", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to delete bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
 		return err
 	}
 
-	cacheKey := fmt.Sprintf("bike:%s", bikeID)
-	if err := s.cache.Delete(cacheKey); err != nil {
-		s.logger.Warn("Failed to invalidate bike cache", map[string]interface{}{
+	s.invalidateDeleted(ctx, bike)
+
+	s.logger.WithContext(ctx).Info("Bike deleted successfully", map[string]interface{}{
+		"bike_id": bikeID,
+	})
+
+	return nil
+}
+
+// RestoreBike возвращает удалённый байк вместе с его компонентами
+func (s *BikeService) RestoreBike(ctx context.Context, bikeID uuid.UUID) (*domain.Bike, error) {
+	var bike *domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		bike, err = s.bikeRepo.RestoreBike(ctx, bikeID)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeRestored, bike.UserID, bike.BikeID, bike))
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to restore bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
+		return nil, err
 	}
 
-	s.logger.Info("Bike deleted successfully", map[string]interface{}{
+	s.invalidateDeleted(ctx, bike)
+
+	s.logger.WithContext(ctx).Info("Bike restored", map[string]interface{}{
 		"bike_id": bikeID,
 	})
 
-	return nil
+	return bike, nil
 }
 
-func (s *BikeService) GetBikeWithComponents(ctx context.Context, bikeID string) (*domain.Bike, error) {
-	bikeUUID, err := uuid.Parse(bikeID)
+// TransferOwnership передаёт байк от ownerID к newUserID одной транзакцией
+// вместе с событием bike.transferred. ownerID - владелец, для которого
+// проверялся доступ: если байк успели передать кому-то ещё, вернётся
+// ErrOwnerChanged. Компоненты висят на bike_id и переезжают с байком
+func (s *BikeService) TransferOwnership(ctx context.Context, bikeID uuid.UUID, ownerID uuid.UUID, newUserID uuid.UUID) (*domain.Bike, error) {
+	if ownerID == newUserID {
+		return nil, domain.ErrSameOwner
+	}
+
+	var bike *domain.Bike
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// владелец сверяется в самом UPDATE, отдельное чтение его не защитит
+		var err error
+		bike, err = s.bikeRepo.TransferBike(ctx, bikeID, ownerID, newUserID)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.BikeTransferred, bike.UserID, bike.BikeID, domain.BikeTransfer{
+			PreviousUserID: ownerID,
+			Bike:           bike,
+		}))
+	})
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
-			"bike_id": bikeID,
+		s.logger.WithContext(ctx).Error("Failed to transfer bike", map[string]interface{}{
+			"error":       err.Error(),
+			"bike_id":     bikeID,
+			"new_user_id": newUserID,
+		})
+		if errors.Is(err, domain.ErrOwnerChanged) {
+			// доступ проверяли по устаревшей копии, повтор должен прочитать свежую
+			if err := deleteBikeCache(s.cache, bikeID); err != nil {
+				s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
+					"error":   err.Error(),
+					"bike_id": bikeID,
+				})
+			}
+		}
+		return nil, err
+	}
+
+	// байк пропадает из списков прежнего владельца и появляется у нового,
+	// сводки и подсказки по компонентам тоже меняются у обоих
+	if err := deleteBikeCache(s.cache, bike.BikeID); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
 			"error":   err.Error(),
+			"bike_id": bike.BikeID,
 		})
+	}
+	for _, userID := range []uuid.UUID{ownerID, newUserID} {
+		s.invalidateUserCache(ctx, userID)
+	}
+
+	s.logger.WithContext(ctx).Info("Bike transferred", map[string]interface{}{
+		"bike_id":          bikeID,
+		"previous_user_id": ownerID,
+		"new_user_id":      newUserID,
+	})
+
+	return bike, nil
+}
+
+// GetBikeByIDForWrite - байк с primary, мимо кеша и реплики: по нему
+// проверяют владельца и пробег перед записью байка и его компонентов
+func (s *BikeService) GetBikeByIDForWrite(ctx context.Context, bikeID string) (*domain.Bike, error) {
+	bikeUUID, err := uuid.Parse(bikeID)
+	if err != nil {
 		return nil, fmt.Errorf("invalid bike ID: %w", err)
 	}
+	bike, err := s.bikeRepo.GetBikeByIDForWrite(ctx, bikeUUID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get bike", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		return nil, err
+	}
+	return bike, nil
+}
 
-	bike, err := s.bikeRepo.GetBikeByID(ctx, bikeUUID)
+// GetBikeByIDWithDeleted - байк по ID, в том числе удалённый. Мимо кеша:
+// нужен только админам и для восстановления
+func (s *BikeService) GetBikeByIDWithDeleted(ctx context.Context, bikeID string) (*domain.Bike, error) {
+	bikeUUID, err := uuid.Parse(bikeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bike ID: %w", err)
+	}
+	bike, err := s.bikeRepo.GetBikeByIDWithDeleted(ctx, bikeUUID)
 	if err != nil {
-		s.logger.Error("Failed to get bike", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
 		return nil, err
 	}
+	return bike, nil
+}
 
-	components, err := s.componentRepo.GetComponentsByBikeID(ctx, bikeUUID)
+// invalidateUserCache сбрасывает всё пространство кеша пользователя: списки,
+// счётчики, подсказки и профиль
+func (s *BikeService) invalidateUserCache(ctx context.Context, userID uuid.UUID) {
+	if err := s.cache.DeletePattern(userCachePattern(userID)); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate user cache namespace", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+	}
+}
+
+// invalidateDeleted сбрасывает кеш байка и всё пространство владельца:
+// списки, счётчики и подсказки по брендам и моделям его компонентов
+func (s *BikeService) invalidateDeleted(ctx context.Context, bike *domain.Bike) {
+	if err := deleteBikeCache(s.cache, bike.BikeID); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate bike cache", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bike.BikeID,
+		})
+	}
+	s.invalidateUserCache(ctx, bike.UserID)
+}
+
+func (s *BikeService) GetBikeWithComponents(ctx context.Context, bikeID string, filter domain.ComponentFilter) (*domain.Bike, error) {
+	bikeUUID, err := uuid.Parse(bikeID)
 	if err != nil {
-		s.logger.Warn("Failed to get components", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
+			"bike_id": bikeID,
+			"error":   err.Error(),
+		})
+		return nil, fmt.Errorf("invalid bike ID: %w", err)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	// полная версия без фильтров - самый частый запрос, её и кешируем
+	cacheable := filter == domain.ComponentFilter{}
+	cacheKey := bikeFullCacheKey(bikeID)
+	if cacheable {
+		if cachedData, err := s.cache.Get(cacheKey); err == nil {
+			var cachedBike domain.Bike
+			if err := json.Unmarshal(cachedData, &cachedBike); err == nil {
+				s.logger.WithContext(ctx).Info("Bike with components found in cache", map[string]interface{}{
+					"bike_id": bikeID,
+				})
+				s.metrics.RecordCacheResult("get_bike_with_components", true)
+				return &cachedBike, nil
+			}
+		}
+		s.metrics.RecordCacheResult("get_bike_with_components", false)
+	}
+
+	// байк и компоненты независимы, читаем параллельно
+	var (
+		bike          *domain.Bike
+		components    []*domain.Component
+		componentsErr error
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		bike, err = s.bikeRepo.GetBikeByID(gctx, bikeUUID)
+		return err
+	})
+	g.Go(func() error {
+		// без компонентов байк всё равно отдаём, поэтому ошибку в группу не возвращаем
+		components, componentsErr = s.componentRepo.GetComponentsByBikeID(gctx, bikeUUID, filter)
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get bike", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
+		return nil, err
+	}
+
+	if componentsErr != nil {
+		s.logger.WithContext(ctx).Warn("Failed to get components", map[string]interface{}{
+			"error":   componentsErr.Error(),
+			"bike_id": bikeID,
+		})
 		components = []*domain.Component{}
 	}
 
 	bike.Components = components
 
-	s.logger.Info("Retrieved bike with components", map[string]interface{}{
+	// неполный ответ не кешируем
+	if cacheable && componentsErr == nil {
+		if bikeData, err := json.Marshal(bike); err == nil {
+			if err := s.cache.Set(cacheKey, bikeData, 15*time.Minute); err != nil {
+				s.logger.WithContext(ctx).Warn("Failed to cache bike with components", map[string]interface{}{
+					"error":   err.Error(),
+					"bike_id": bikeID,
+				})
+			}
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Retrieved bike with components", map[string]interface{}{
 		"bike_id":          bikeID,
 		"components_count": len(components),
 	})
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/google/uuid"
+)
+
+// blockingBikeRepo считает чтения байка и держит их до release
+type blockingBikeRepo struct {
+	ports.BikeRepository
+	fetches atomic.Int32
+	release chan struct{}
+}
+
+func (r *blockingBikeRepo) GetBikeByID(ctx context.Context, bikeID uuid.UUID) (*domain.Bike, error) {
+	r.fetches.Add(1)
+	<-r.release
+	return r.BikeRepository.GetBikeByID(ctx, bikeID)
+}
+
+// countingCache считает промахи, чтобы тест знал, что все читатели дошли до БД
+type countingCache struct {
+	ports.CachePort
+	misses atomic.Int32
+}
+
+func (c *countingCache) Get(key string) ([]byte, error) {
+	value, err := c.CachePort.Get(key)
+	if err != nil {
+		c.misses.Add(1)
+	}
+	return value, err
+}
+
+func TestGetBikeByIDCoalescesConcurrentMisses(t *testing.T) {
+	const readers = 20
+	env := newTestEnv(t)
+	archivedAt := time.Now().Add(-time.Hour).UTC()
+	bikes := []*domain.Bike{
+		env.store.AddBike(&domain.Bike{UserID: uuid.New(), BikeName: "Trail", Type: domain.MTB, Year: ptr(2020), Mileage: 100, ArchivedAt: ptr(archivedAt)}),
+		env.addBike(uuid.New(), 200),
+	}
+	repo := &blockingBikeRepo{BikeRepository: env.store, release: make(chan struct{})}
+	cache := &countingCache{CachePort: env.cache}
+	service := NewBikeService(repo, env.store, env.logger, nil, cache, env.store, env.store, env.store, env.metrics)
+
+	results := make([]*domain.Bike, readers)
+	var wg sync.WaitGroup
+	for i := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bike, err := service.GetBikeByID(context.Background(), bikes[i%2].BikeID.String())
+			if err != nil {
+				t.Errorf("GetBikeByID: %v", err)
+			}
+			results[i] = bike
+		}()
+	}
+
+	deadline := time.After(time.Second)
+	for cache.misses.Load() < readers {
+		select {
+		case <-deadline:
+			t.Fatalf("only %d of %d readers missed the cache", cache.misses.Load(), readers)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	// промахнувшиеся читатели успевают встать в очередь за первым запросом
+	time.Sleep(20 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+
+	// по одному чтению на каждый из двух байков
+	if got := repo.fetches.Load(); got != 2 {
+		t.Errorf("repository fetches = %d, want 2", got)
+	}
+	for i, bike := range results {
+		if bike == nil || bike.BikeID != bikes[i%2].BikeID {
+			t.Fatalf("reader %d got %+v, want bike %s", i, bike, bikes[i%2].BikeID)
+		}
+	}
+	// у каждого своя копия: правка одного ответа не видна остальным
+	results[0].Mileage = -1
+	*results[0].Year = 1999
+	*results[0].ArchivedAt = time.Time{}
+	if results[2].Mileage != bikes[0].Mileage || *results[2].Year != 2020 || !results[2].ArchivedAt.Equal(archivedAt) {
+		t.Errorf("shared result was mutated: mileage = %d, year = %d, archived_at = %v", results[2].Mileage, *results[2].Year, *results[2].ArchivedAt)
+	}
+
+	// следующий запрос - уже из кеша
+	if _, err := service.GetBikeByID(context.Background(), bikes[0].BikeID.String()); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.fetches.Load(); got != 2 {
+		t.Errorf("repository fetches after cache fill = %d, want 2", got)
+	}
+}
+
+// txBikeRepo запоминает, пришло ли чтение байка внутри транзакции
+type txBikeRepo struct {
+	*portstest.Store
+	inTx atomic.Bool
+}
+
+func (r *txBikeRepo) GetBikeByID(ctx context.Context, bikeID uuid.UUID) (*domain.Bike, error) {
+	r.inTx.Store(r.InTransaction(ctx))
+	return r.Store.GetBikeByID(ctx, bikeID)
+}
+
+// общая загрузка идёт мимо транзакции вызывающего: её результат достаётся
+// другим читателям и кешу, а транзакция может откатиться
+func TestGetBikeByIDOutsideCallerTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	bike := env.addBike(uuid.New(), 100)
+	repo := &txBikeRepo{Store: env.store}
+	service := NewBikeService(repo, env.store, env.logger, nil, env.cache, env.store, env.store, env.store, env.metrics)
+
+	err := env.store.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		if !env.store.InTransaction(ctx) {
+			t.Fatal("transaction is not in the context")
+		}
+		_, err := service.GetBikeByID(ctx, bike.BikeID.String())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.inTx.Load() {
+		t.Error("shared load ran inside the caller's transaction")
+	}
+}
+
+// missingReplica - реплика, до которой ещё не доехал только что созданный байк
+type missingReplica struct {
+	ports.BikeRepository
+}
+
+func (missingReplica) GetBikeByID(context.Context, uuid.UUID) (*domain.Bike, error) {
+	return nil, domain.ErrBikeNotFound
+}
+
+// проверка монотонности пробега читает байк с primary: отставшая реплика не
+// должна ни терять свежий байк, ни превращать ErrMileageDecrease в конфликт
+func TestMileageChecksReadPrimary(t *testing.T) {
+	ctx := context.Background()
+	updates := []struct {
+		name   string
+		update func(s *BikeService, bike *domain.Bike, mileage int) error
+	}{
+		{name: "UpdateMileage", update: func(s *BikeService, bike *domain.Bike, mileage int) error {
+			_, err := s.UpdateMileage(ctx, bike.BikeID.String(), mileage)
+			return err
+		}},
+		{name: "UpdateBike", update: func(s *BikeService, bike *domain.Bike, mileage int) error {
+			_, err := s.UpdateBike(ctx, &domain.Bike{BikeID: bike.BikeID, Mileage: mileage})
+			return err
+		}},
+	}
+	for _, u := range updates {
+		t.Run(u.name+"/свежий байк", func(t *testing.T) {
+			env := newTestEnv(t)
+			bike := env.addBike(uuid.New(), 1000)
+			service := NewBikeService(missingReplica{BikeRepository: env.store}, env.store, env.logger, env.validate, env.cache, env.store, env.store, env.store, env.metrics)
+			if err := u.update(service, bike, 1500); err != nil {
+				t.Fatalf("err = %v, want update", err)
+			}
+		})
+		t.Run(u.name+"/уменьшение", func(t *testing.T) {
+			env := newTestEnv(t)
+			bike := env.addBike(uuid.New(), 1000)
+			stale := *bike
+			stale.Mileage = 100
+			service := NewBikeService(laggingReplica{BikeRepository: env.store, bike: &stale}, env.store, env.logger, env.validate, env.cache, env.store, env.store, env.store, env.metrics)
+			if err := u.update(service, bike, 500); !errors.Is(err, domain.ErrMileageDecrease) {
+				t.Fatalf("err = %v, want ErrMileageDecrease", err)
+			}
+		})
+	}
+}
+
+func TestBikeTypeValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		bikeType domain.BikeType
+		wantErr  bool
+	}{
+		{name: "bmx", bikeType: domain.BMX},
+		{name: "mtb", bikeType: domain.MTB},
+		{name: "road", bikeType: domain.Road},
+		{name: "пример из старой документации", bikeType: "mountain", wantErr: true},
+		{name: "регистр важен", bikeType: "MTB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			check := func(err error) {
+				t.Helper()
+				if errors.Is(err, domain.ErrInvalidBikeType) != tt.wantErr {
+					t.Fatalf("err = %v, want ErrInvalidBikeType: %t", err, tt.wantErr)
+				}
+				// в ошибке перечислены допустимые значения
+				if tt.wantErr && !strings.Contains(err.Error(), "[bmx mtb road]") {
+					t.Errorf("err = %q, want permitted values listed", err)
+				}
+			}
+
+			t.Run("create", func(t *testing.T) {
+				env := newTestEnv(t)
+				owner := uuid.New()
+				_, _, err := env.bikes.CreateBike(ctx, &domain.Bike{UserID: owner, Type: tt.bikeType, Model: "Trek", Mileage: 10}, domain.IdempotencyKey{})
+				check(err)
+				bikes, _ := env.store.GetBikesByUserID(ctx, owner, domain.BikeFilter{})
+				if stored := len(bikes) == 1; stored == tt.wantErr {
+					t.Errorf("bike stored = %t", stored)
+				}
+			})
+
+			t.Run("update", func(t *testing.T) {
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 100)
+				_, err := env.bikes.UpdateBike(ctx, &domain.Bike{BikeID: bike.BikeID, Type: tt.bikeType})
+				check(err)
+				want := tt.bikeType
+				if tt.wantErr {
+					want = bike.Type
+				}
+				if stored, _ := env.store.Bike(bike.BikeID); stored.Type != want {
+					t.Errorf("stored type = %q, want %q", stored.Type, want)
+				}
+			})
+		})
+	}
+}
+
+func TestBikeYearBounds(t *testing.T) {
+	maxYear := domain.MaxBikeYear(time.Now())
+	wantMsg := fmt.Sprintf("year must be between %d and %d", domain.MinBikeYear, maxYear)
+
+	tests := []struct {
+		name    string
+		year    *int
+		wantErr bool
+	}{
+		{name: "год неизвестен", year: nil},
+		{name: "нижняя граница", year: ptr(domain.MinBikeYear)},
+		{name: "текущий год", year: ptr(time.Now().Year())},
+		// модели следующего года уже продаются
+		{name: "следующий год", year: ptr(maxYear)},
+		{name: "раньше 1900", year: ptr(domain.MinBikeYear - 1), wantErr: true},
+		{name: "через два года", year: ptr(maxYear + 1), wantErr: true},
+		{name: "нулевой год", year: ptr(0), wantErr: true},
+		{name: "3000", year: ptr(3000), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			check := func(err error) {
+				t.Helper()
+				if errors.Is(err, domain.ErrInvalidBikeYear) != tt.wantErr {
+					t.Fatalf("err = %v, want ErrInvalidBikeYear: %t", err, tt.wantErr)
+				}
+				// в ошибке назван допустимый диапазон
+				if tt.wantErr && !strings.Contains(err.Error(), wantMsg) {
+					t.Errorf("err = %q, want %q", err, wantMsg)
+				}
+			}
+
+			t.Run("create", func(t *testing.T) {
+				env := newTestEnv(t)
+				owner := uuid.New()
+				_, _, err := env.bikes.CreateBike(ctx, &domain.Bike{UserID: owner, Type: domain.MTB, Model: "Trek", Mileage: 10, Year: tt.year}, domain.IdempotencyKey{})
+				check(err)
+				bikes, _ := env.store.GetBikesByUserID(ctx, owner, domain.BikeFilter{})
+				if stored := len(bikes) == 1; stored == tt.wantErr {
+					t.Errorf("bike stored = %t", stored)
+				}
+			})
+
+			t.Run("update", func(t *testing.T) {
+				if tt.year == nil {
+					t.Skip("nil - год не меняется")
+				}
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 100)
+				_, err := env.bikes.UpdateBike(ctx, &domain.Bike{BikeID: bike.BikeID, Year: tt.year})
+				check(err)
+				stored, _ := env.store.Bike(bike.BikeID)
+				if changed := stored.Year != nil; changed == tt.wantErr {
+					t.Errorf("stored year = %v", stored.Year)
+				}
+			})
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+// Схема ключей кеша:
+//
+//	bike:<bike_id>          - байк по ID, владелец при чтении заранее неизвестен
+//	bike:<bike_id>:full     - байк вместе со всеми компонентами, для детального экрана
+//	u:<user_id>:<suffix>    - всё, что относится к конкретному пользователю
+//	stats:fleet:<warn_pct>  - админская сводка, живёт по TTL без инвалидации
+//	components:defaults     - пороги по умолчанию, сбрасывается при PUT от админа
+//	suggest:<field>:<prefix>        - глобальные подсказки для админов
+//	u:<user_id>:suggest:<field>:<prefix> - подсказки по данным пользователя
+//	u:<user_id>:profile     - профиль из user-service для /me и with-user
+//	u:<user_id>:bikes:list:<filter>  - страница списка байков пользователя
+//	u:<user_id>:bikes:count:<filter> - сколько всего байков под фильтром
+//
+// Пространство u:<user_id>:* целиком сбрасывается через invalidateUserCache,
+// например при передаче байка другому владельцу
+const componentDefaultsCacheKey = "components:defaults"
+
+func fleetStatsCacheKey(warnPercent int) string {
+	return fmt.Sprintf("stats:fleet:%d", warnPercent)
+}
+
+func bikeCacheKey(bikeID string) string {
+	return fmt.Sprintf("bike:%s", bikeID)
+}
+
+func bikeFullCacheKey(bikeID string) string {
+	return fmt.Sprintf("bike:%s:full", bikeID)
+}
+
+// deleteBikeCache сбрасывает оба ключа байка: любое изменение байка или его
+// компонентов делает устаревшей и полную версию
+func deleteBikeCache(cache ports.CachePort, bikeID uuid.UUID) error {
+	return errors.Join(
+		cache.Delete(bikeCacheKey(bikeID.String())),
+		cache.Delete(bikeFullCacheKey(bikeID.String())),
+	)
+}
+
+// UserProfileCacheKey экспортирован: профиль кеширует HTTP-слой, где живёт клиент user-service
+func UserProfileCacheKey(userID uuid.UUID) string {
+	return fmt.Sprintf("u:%s:profile", userID)
+}
+
+func userCachePattern(userID uuid.UUID) string {
+	return fmt.Sprintf("u:%s:*", userID)
+}
+
+// bikeFilterKey - часть ключа, однозначно задающая фильтр списка байков
+func bikeFilterKey(f domain.BikeFilter) string {
+	timeKey := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	return fmt.Sprintf("%t:%t:%s:%d:%d:%s:%s", f.Archived, f.IncludeDeleted, f.Sort, f.Page.Limit, f.Page.Offset, timeKey(f.CreatedAfter), timeKey(f.CreatedBefore))
+}
+
+func userBikesCacheKey(userID uuid.UUID, f domain.BikeFilter) string {
+	return fmt.Sprintf("u:%s:bikes:list:%s", userID, bikeFilterKey(f))
+}
+
+// в счётчике страница не участвует
+func userBikesCountCacheKey(userID uuid.UUID, f domain.BikeFilter) string {
+	f.Sort, f.Page = "", domain.Page{}
+	return fmt.Sprintf("u:%s:bikes:count:%s", userID, bikeFilterKey(f))
+}
+
+func userBikesCachePattern(userID uuid.UUID) string {
+	return fmt.Sprintf("u:%s:bikes:*", userID)
+}
+
+func suggestCacheKey(q domain.SuggestQuery) string {
+	key := fmt.Sprintf("suggest:%s:%s", q.Field, strings.ToLower(q.Prefix))
+	if q.UserID != uuid.Nil {
+		key = fmt.Sprintf("u:%s:%s", q.UserID, key)
+	}
+	return key
+}
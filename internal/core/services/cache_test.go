@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// после передачи байка у обоих владельцев не должно остаться кешированных
+// списков, а у посторонних пользователей кеш не трогается
+func TestTransferOwnershipInvalidatesUserCaches(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner, newOwner, other := uuid.New(), uuid.New(), uuid.New()
+	bike := env.addBike(owner, 100)
+	env.addBike(newOwner, 100)
+	env.addBike(other, 100)
+
+	for _, userID := range []uuid.UUID{owner, newOwner, other} {
+		if _, err := env.bikes.GetBikesByUserID(ctx, userID.String(), domain.BikeFilter{}); err != nil {
+			t.Fatalf("GetBikesByUserID: %v", err)
+		}
+	}
+	if _, err := env.bikes.GetBikeByID(ctx, bike.BikeID.String()); err != nil {
+		t.Fatalf("GetBikeByID: %v", err)
+	}
+	if err := env.cache.Set(UserProfileCacheKey(owner), []byte(`{}`), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.bikes.TransferOwnership(ctx, bike.BikeID, owner, newOwner); err != nil {
+		t.Fatalf("TransferOwnership: %v", err)
+	}
+
+	for _, key := range env.cache.Keys() {
+		if strings.HasPrefix(key, "u:"+owner.String()+":") || strings.HasPrefix(key, "u:"+newOwner.String()+":") || key == bikeCacheKey(bike.BikeID.String()) {
+			t.Errorf("key %s survived the transfer", key)
+		}
+	}
+	if !env.cache.Has(userBikesCacheKey(other, domain.BikeFilter{})) {
+		t.Errorf("unrelated user's cache was invalidated")
+	}
+
+	// новый владелец сразу видит байк в своём списке
+	bikes, err := env.bikes.GetBikesByUserID(ctx, newOwner.String(), domain.BikeFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bikes) != 2 {
+		t.Errorf("new owner has %d bikes, want 2", len(bikes))
+	}
+}
+
+// кеш байка сбрасывается после записи компонента, даже если запись вернула
+// ошибку: commit мог пройти, а ошибку дал обрыв соединения
+func TestComponentWriteInvalidatesBikeCache(t *testing.T) {
+	errConnReset := errors.New("connection reset")
+
+	tests := []struct {
+		name   string
+		method string
+		write  func(env *testEnv, bike *domain.Bike, stored *domain.Component) error
+	}{
+		{name: "создание", method: "CreateComponent", write: func(env *testEnv, bike *domain.Bike, _ *domain.Component) error {
+			_, _, err := env.components.CreateComponent(context.Background(), newComponent(bike, bike.CreatedAt, 0), domain.IdempotencyKey{})
+			return err
+		}},
+		{name: "обновление", method: "UpdateComponent", write: func(env *testEnv, _ *domain.Bike, stored *domain.Component) error {
+			update := *stored
+			update.Brand = "SRAM"
+			_, err := env.components.UpdateComponent(context.Background(), &update)
+			return err
+		}},
+		{name: "удаление", method: "DeleteComponent", write: func(env *testEnv, _ *domain.Bike, stored *domain.Component) error {
+			return env.components.DeleteComponent(context.Background(), stored.ID.String())
+		}},
+	}
+	for _, tt := range tests {
+		for _, writeErr := range []error{nil, errConnReset} {
+			t.Run(fmt.Sprintf("%s, ошибка записи: %v", tt.name, writeErr), func(t *testing.T) {
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 100)
+				stored := env.store.AddComponent(newComponent(bike, bike.CreatedAt, 0))
+				keys := []string{bikeCacheKey(bike.BikeID.String()), bikeFullCacheKey(bike.BikeID.String())}
+				for _, key := range keys {
+					if err := env.cache.Set(key, []byte(`{}`), 0); err != nil {
+						t.Fatal(err)
+					}
+				}
+				env.store.SetError(tt.method, writeErr)
+
+				if err := tt.write(env, bike, stored); !errors.Is(err, writeErr) {
+					t.Fatalf("err = %v, want %v", err, writeErr)
+				}
+				for _, key := range keys {
+					if env.cache.Has(key) {
+						t.Errorf("%s survived the write", key)
+					}
+				}
+			})
+		}
+	}
+}
+
+// недоступный кеш не должен ронять запись: устаревший байк доживёт до TTL
+func TestComponentWriteWithCacheDown(t *testing.T) {
+	env := newTestEnv(t)
+	bike := env.addBike(uuid.New(), 100)
+	stored := env.store.AddComponent(newComponent(bike, bike.CreatedAt, 0))
+	env.cache.Err = errors.New("redis: connection refused")
+
+	update := *stored
+	update.Brand = "SRAM"
+	if _, err := env.components.UpdateComponent(context.Background(), &update); err != nil {
+		t.Fatalf("UpdateComponent: %v", err)
+	}
+	if got, _ := env.store.Component(stored.ID); got.Brand != "SRAM" {
+		t.Errorf("brand = %q, want SRAM", got.Brand)
+	}
+	if _, ok := env.logger.Find("Failed to invalidate bike cache"); !ok {
+		t.Error("cache invalidation failure was not logged")
+	}
+}
+
+func TestGetBikeWithComponentsFullCache(t *testing.T) {
+	errDB := errors.New("db is down")
+
+	tests := []struct {
+		name   string
+		filter domain.ComponentFilter
+		// componentsErr - сбой чтения компонентов при первом запросе
+		componentsErr error
+		wantCached    bool
+	}{
+		{name: "без фильтров кешируется", wantCached: true},
+		{name: "с фильтром не кешируется", filter: domain.ComponentFilter{Name: domain.Frame}},
+		{name: "неполный ответ не кешируется", componentsErr: errDB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			ctx := context.Background()
+			bike := env.addBike(uuid.New(), 100)
+			env.store.AddComponent(newComponent(bike, bike.CreatedAt, 0))
+			fullKey := bikeFullCacheKey(bike.BikeID.String())
+
+			env.store.SetError("GetComponentsByBikeID", tt.componentsErr)
+			if _, err := env.bikes.GetBikeWithComponents(ctx, bike.BikeID.String(), tt.filter); err != nil {
+				t.Fatalf("GetBikeWithComponents: %v", err)
+			}
+			env.store.SetError("GetComponentsByBikeID", nil)
+			if env.cache.Has(fullKey) != tt.wantCached {
+				t.Fatalf("full key cached = %t, want %t", env.cache.Has(fullKey), tt.wantCached)
+			}
+			if !tt.wantCached {
+				return
+			}
+
+			// попадание в кеш не ходит в репозиторий
+			env.store.SetError("GetBikeByID", errDB)
+			env.store.SetError("GetComponentsByBikeID", errDB)
+			got, err := env.bikes.GetBikeWithComponents(ctx, bike.BikeID.String(), tt.filter)
+			if err != nil {
+				t.Fatalf("cached GetBikeWithComponents: %v", err)
+			}
+			if got.BikeID != bike.BikeID || len(got.Components) != 1 {
+				t.Errorf("cached bike = %s with %d components, want %s with 1", got.BikeID, len(got.Components), bike.BikeID)
+			}
+		})
+	}
+}
+
+// создание, обновление и удаление компонента сбрасывают полную версию байка
+func TestGetBikeWithComponentsAfterComponentWrite(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	bike := env.addBike(uuid.New(), 100)
+	components := func() []*domain.Component {
+		t.Helper()
+		got, err := env.bikes.GetBikeWithComponents(ctx, bike.BikeID.String(), domain.ComponentFilter{})
+		if err != nil {
+			t.Fatalf("GetBikeWithComponents: %v", err)
+		}
+		return got.Components
+	}
+
+	if got := components(); len(got) != 0 {
+		t.Fatalf("got %d components, want 0", len(got))
+	}
+
+	created, _, err := env.components.CreateComponent(ctx, newComponent(bike, bike.CreatedAt, 0), domain.IdempotencyKey{})
+	if err != nil {
+		t.Fatalf("CreateComponent: %v", err)
+	}
+	if got := components(); len(got) != 1 {
+		t.Fatalf("after create got %d components, want 1", len(got))
+	}
+
+	update := *created
+	update.Brand = "SRAM"
+	if _, err := env.components.UpdateComponent(ctx, &update); err != nil {
+		t.Fatalf("UpdateComponent: %v", err)
+	}
+	if got := components(); got[0].Brand != "SRAM" {
+		t.Fatalf("after update brand = %q, want SRAM", got[0].Brand)
+	}
+
+	if err := env.components.DeleteComponent(ctx, created.ID.String()); err != nil {
+		t.Fatalf("DeleteComponent: %v", err)
+	}
+	if got := components(); len(got) != 0 {
+		t.Errorf("after delete got %d components, want 0", len(got))
+	}
+}
@@ -2,7 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
 	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
@@ -11,69 +16,199 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrBatchRejected       = errors.New("batch rejected")
+	ErrInstalledInFuture   = errors.New("installed_at is in the future")
+	ErrInstalledBeforeBike = errors.New("installed_at is before the bike was created")
+	// иначе текущий пробег компонента уходит в минус
+	ErrInstalledMileageAboveBike = errors.New("installed_mileage exceeds bike mileage")
+	// скорее всего перепутаны даты; ошибка только в строгом режиме
+	ErrInstalledBeforeModelYear = errors.New("installed_at is before the bike model year")
+	ErrInvalidComponentImport   = errors.New("invalid component import")
+	ErrInvalidOrphanRepair      = errors.New("invalid orphan repair")
+	ErrInvalidComponentBatch    = errors.New("invalid component batch")
+)
+
+// ComponentItemError - элемент батча, который не прошёл проверку
+type ComponentItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ComponentItemError) Error() string {
+	return fmt.Sprintf("components[%d]: %v", e.Index, e.Err)
+}
+
+func (e *ComponentItemError) Unwrap() error {
+	return e.Err
+}
+
+// ComponentCreateResult - итог создания одного компонента из импорта
+type ComponentCreateResult struct {
+	Component *domain.Component
+	Err       error
+}
+
+const (
+	// допуск на расхождение часов клиента и сервера
+	installedAtClockSkew = 5 * time.Minute
+	// модели следующего года продаются с середины текущего, поэтому год
+	// установки может быть на один меньше модельного
+	modelYearLead = 1
+
+	SuggestMinPrefix = 2
+	SuggestMaxLimit  = 20
+	suggestTTL       = time.Minute
+)
+
+var ErrInvalidSuggestQuery = errors.New("invalid suggest query")
+
+var ErrInvalidComponentDefault = errors.New("invalid component default")
+
+var ErrInvalidComponentWeight = errors.New("invalid component weight")
+
+// пороги по умолчанию меняются руками и редко
+const componentDefaultsTTL = time.Hour
+
+// ComponentUpdateResult - итог обновления одного компонента в батче.
+// Пустой Component без Err значит, что элемент откатился вместе с батчем
+type ComponentUpdateResult struct {
+	Component *domain.Component
+	Err       error
+}
+
 type ComponentService struct {
 	componentRepo ports.ComponentRepository
+	bikeRepo      ports.BikeRepository
 	logger        ports.LoggerPort
 	validate      *validator.Validate
 	cache         ports.CachePort
+	tx            ports.Transactor
+	outbox        ports.OutboxRepository
+	idempotency   ports.IdempotencyRepository
+	// strictYear - отклонять установку раньше модельного года, а не только логировать
+	strictYear bool
 }
 
 func NewComponentService(
 	componentRepo ports.ComponentRepository,
+	bikeRepo ports.BikeRepository,
 	logger ports.LoggerPort,
 	validate *validator.Validate,
 	cache ports.CachePort,
+	tx ports.Transactor,
+	outbox ports.OutboxRepository,
+	idempotency ports.IdempotencyRepository,
+	strictYear bool,
 ) *ComponentService {
 	return &ComponentService{
 		componentRepo: componentRepo,
+		bikeRepo:      bikeRepo,
 		logger:        logger,
 		validate:      validate,
 		cache:         cache,
+		tx:            tx,
+		outbox:        outbox,
+		idempotency:   idempotency,
+		strictYear:    strictYear,
 	}
 }
 
-func (s *ComponentService) CreateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error) {
+// CreateComponent создаёт компонент. С непустым key.Key повтор того же запроса
+// вернёт уже созданный компонент с replayed=true (см. BikeService.CreateBike)
+func (s *ComponentService) CreateComponent(ctx context.Context, component *domain.Component, key domain.IdempotencyKey) (*domain.Component, bool, error) {
+	component.NormalizePosition()
+	if component.MaxMileage == 0 && component.MaxAgeDays == nil {
+		component.ApplyDefault(s.componentDefault(ctx, component.Name))
+	}
 	if err := s.validate.Struct(component); err != nil {
-		s.logger.Error("Component validation failed", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Component validation failed", map[string]interface{}{
 			"error": err.Error(),
 		})
-		return nil, fmt.Errorf("validation error: %w", err)
+		return nil, false, fmt.Errorf("validation error: %w", err)
+	}
+	if err := component.ValidateThresholds(); err != nil {
+		return nil, false, fmt.Errorf("validation error: %w", err)
+	}
+	bike, err := s.checkInstallation(ctx, component)
+	if err != nil {
+		return nil, false, err
 	}
 
 	if component.ID == uuid.Nil {
 		component.ID = uuid.New()
 	}
 
-	createdComponent, err := s.componentRepo.CreateComponent(ctx, component)
+	s.invalidateBike(component.BikeID)
+	var createdComponent *domain.Component
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if key.Key != "" {
+			claimed, err := s.idempotency.ClaimKey(ctx, domain.IdempotencyScopeComponent, key, []uuid.UUID{component.ID}, idempotencyKeyTTL)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				return errCreateReplay
+			}
+		}
+		var err error
+		createdComponent, err = s.componentRepo.CreateComponent(ctx, component)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentCreated, bike.UserID, createdComponent.BikeID, createdComponent))
+	})
+	s.invalidateBike(component.BikeID)
+	if errors.Is(err, errCreateReplay) {
+		return s.replayComponentCreate(ctx, key)
+	}
 	if err != nil {
-		s.logger.Error("Failed to create component", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to create component", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": component.BikeID,
 		})
-		return nil, err
+		return nil, false, err
 	}
 
-	cacheKey := fmt.Sprintf("bike:%s", component.BikeID.String())
-	if err := s.cache.Delete(cacheKey); err != nil {
-		s.logger.Warn("Failed to invalidate bike cache", map[string]interface{}{
-			"error":   err.Error(),
-			"bike_id": component.BikeID.String(),
-		})
-	}
-
-	s.logger.Info("Component created successfully", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Component created successfully", map[string]interface{}{
 		"component_id": createdComponent.ID,
 		"bike_id":      createdComponent.BikeID,
 		"name":         createdComponent.Name,
 	})
 
-	return createdComponent, nil
+	return createdComponent, false, nil
+}
+
+// replayComponentCreate отдаёт компонент, созданный запросом с этим ключом
+func (s *ComponentService) replayComponentCreate(ctx context.Context, key domain.IdempotencyKey) (*domain.Component, bool, error) {
+	storedHash, ids, err := s.idempotency.GetKey(ctx, domain.IdempotencyScopeComponent, key.UserID, key.Key)
+	if err != nil {
+		return nil, false, err
+	}
+	if storedHash != key.RequestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	// одиночное создание занимает ключ ровно за один компонент
+	componentID := ids[0]
+
+	// с primary: реплика может ещё не видеть компонент, созданный секунду назад
+	component, err := s.componentRepo.GetComponentByIDForWrite(ctx, componentID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.logger.WithContext(ctx).Info("Component create replayed", map[string]interface{}{
+		"component_id": componentID,
+		"user_id":      key.UserID,
+	})
+
+	return component, true, nil
 }
 
 func (s *ComponentService) GetComponentByID(ctx context.Context, componentID string) (*domain.Component, error) {
 	componentUUID, err := uuid.Parse(componentID)
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
 			"component_id": componentID,
 			"error":        err.Error(),
 		})
@@ -82,14 +217,14 @@ func (s *ComponentService) GetComponentByID(ctx context.Context, componentID str
 
 	component, err := s.componentRepo.GetComponentByID(ctx, componentUUID)
 	if err != nil {
-		s.logger.Error("Failed to get component", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to get component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
 		return nil, err
 	}
 
-	s.logger.Info("Retrieved component", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Retrieved component", map[string]interface{}{
 		"component_id": componentID,
 		"bike_id":      component.BikeID,
 	})
@@ -97,26 +232,30 @@ func (s *ComponentService) GetComponentByID(ctx context.Context, componentID str
 	return component, nil
 }
 
-func (s *ComponentService) GetComponentsByBikeID(ctx context.Context, bikeID string) ([]*domain.Component, error) {
+func (s *ComponentService) GetComponentsByBikeID(ctx context.Context, bikeID string, filter domain.ComponentFilter) ([]*domain.Component, error) {
 	bikeUUID, err := uuid.Parse(bikeID)
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
 			"bike_id": bikeID,
 			"error":   err.Error(),
 		})
 		return nil, fmt.Errorf("invalid bike ID: %w", err)
 	}
 
-	components, err := s.componentRepo.GetComponentsByBikeID(ctx, bikeUUID)
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	components, err := s.componentRepo.GetComponentsByBikeID(ctx, bikeUUID, filter)
 	if err != nil {
-		s.logger.Error("Failed to get components", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to get components", map[string]interface{}{
 			"error":   err.Error(),
 			"bike_id": bikeID,
 		})
 		return nil, err
 	}
 
-	s.logger.Info("Retrieved components for bike", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Retrieved components for bike", map[string]interface{}{
 		"bike_id":          bikeID,
 		"components_count": len(components),
 	})
@@ -125,76 +264,761 @@ func (s *ComponentService) GetComponentsByBikeID(ctx context.Context, bikeID str
 }
 
 func (s *ComponentService) UpdateComponent(ctx context.Context, component *domain.Component) (*domain.Component, error) {
+	component.NormalizePosition()
 	if err := s.validate.Struct(component); err != nil {
-		s.logger.Error("Component validation failed", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Component validation failed", map[string]interface{}{
 			"error": err.Error(),
 		})
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
+	if err := component.ValidateThresholds(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	bike, err := s.checkInstallation(ctx, component)
+	if err != nil {
+		return nil, err
+	}
 
-	updatedComponent, err := s.componentRepo.UpdateComponent(ctx, component)
+	s.invalidateBike(component.BikeID)
+	var updatedComponent *domain.Component
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updatedComponent, err = s.componentRepo.UpdateComponent(ctx, component)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentUpdated, bike.UserID, updatedComponent.BikeID, updatedComponent))
+	})
+	s.invalidateBike(component.BikeID)
 	if err != nil {
-		s.logger.Error("Failed to update component", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to update component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": component.ID,
 		})
 		return nil, err
 	}
 
-	cacheKey := fmt.Sprintf("bike:%s", component.BikeID.String())
-	if err := s.cache.Delete(cacheKey); err != nil {
-		s.logger.Warn("Failed to invalidate bike cache", map[string]interface{}{
-			"error":   err.Error(),
-			"bike_id": component.BikeID.String(),
-		})
-	}
-
-	s.logger.Info("Component updated successfully", map[string]interface{}{
+	s.logger.WithContext(ctx).Info("Component updated successfully", map[string]interface{}{
 		"component_id": component.ID,
 	})
 
 	return updatedComponent, nil
 }
 
+// GetComponentByIDForWrite - компонент с primary, мимо реплики: его правят
+// и пишут обратно целиком
+func (s *ComponentService) GetComponentByIDForWrite(ctx context.Context, componentID string) (*domain.Component, error) {
+	componentUUID, err := uuid.Parse(componentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid component ID: %w", err)
+	}
+	component, err := s.componentRepo.GetComponentByIDForWrite(ctx, componentUUID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		return nil, err
+	}
+	return component, nil
+}
+
 func (s *ComponentService) DeleteComponent(ctx context.Context, componentID string) error {
 	componentUUID, err := uuid.Parse(componentID)
 	if err != nil {
-		s.logger.Error("Invalid UUID format", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
 			"component_id": componentID,
 			"error":        err.Error(),
 		})
 		return fmt.Errorf("invalid component ID: %w", err)
 	}
 
-	component, err := s.componentRepo.GetComponentByID(ctx, componentUUID)
+	component, err := s.componentRepo.GetComponentByIDForWrite(ctx, componentUUID)
 	if err != nil {
-		s.logger.Error("Failed to get component", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to get component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
 		return err
 	}
 
-	err = s.componentRepo.DeleteComponent(ctx, componentUUID)
+	ownerID := s.bikeOwner(ctx, component.BikeID)
+	s.invalidateBike(component.BikeID)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.componentRepo.DeleteComponent(ctx, componentUUID); err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentDeleted, ownerID, component.BikeID, component))
+	})
+	s.invalidateBike(component.BikeID)
 	if err != nil {
-		s.logger.Error("Failed to delete component", map[string]interface{}{
+		s.logger.WithContext(ctx).Error("Failed to delete component", map[string]interface{}{
 			"error":        err.Error(),
 			"component_id": componentID,
 		})
 		return err
 	}
 
-	cacheKey := fmt.Sprintf("bike:%s", component.BikeID.String())
-	if err := s.cache.Delete(cacheKey); err != nil {
+	s.logger.WithContext(ctx).Info("Component deleted successfully", map[string]interface{}{
+		"component_id": componentID,
+	})
+
+	return nil
+}
+
+// UpdateComponents обновляет несколько компонентов. При atomic все изменения
+// идут одной транзакцией и первая же ошибка откатывает весь батч, иначе
+// каждый компонент пишется отдельно. Кеш каждого затронутого байка сбрасывается
+// один раз до записи и один раз после
+func (s *ComponentService) UpdateComponents(ctx context.Context, components []*domain.Component, atomic bool) ([]ComponentUpdateResult, error) {
+	results := make([]ComponentUpdateResult, len(components))
+	// владельцы байков для событий, checkInstallation их всё равно загружает
+	owners := make([]uuid.UUID, len(components))
+
+	invalid := false
+	for i, component := range components {
+		component.NormalizePosition()
+		if err := s.validate.Struct(component); err != nil {
+			results[i].Err = fmt.Errorf("validation error: %w", err)
+			invalid = true
+			continue
+		}
+		if err := component.ValidateThresholds(); err != nil {
+			results[i].Err = fmt.Errorf("validation error: %w", err)
+			invalid = true
+			continue
+		}
+		bike, err := s.checkInstallation(ctx, component)
+		if err != nil {
+			results[i].Err = err
+			invalid = true
+			continue
+		}
+		owners[i] = bike.UserID
+	}
+	if atomic && invalid {
+		return results, ErrBatchRejected
+	}
+
+	bikeIDs := make(map[uuid.UUID]bool)
+	for _, component := range components {
+		bikeIDs[component.BikeID] = true
+	}
+	invalidateAll := func() {
+		for bikeID := range bikeIDs {
+			s.invalidateBike(bikeID)
+		}
+	}
+	invalidateAll()
+	// сбрасываем и после, даже если батч откатился - см. invalidateBike
+	defer invalidateAll()
+
+	update := func(ctx context.Context, component *domain.Component, ownerID uuid.UUID) (*domain.Component, error) {
+		updated, err := s.componentRepo.UpdateComponent(ctx, component)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentUpdated, ownerID, updated.BikeID, updated)); err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+
+	if atomic {
+		err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			for i, component := range components {
+				updated, err := update(ctx, component, owners[i])
+				if err != nil {
+					results[i].Err = err
+					return err
+				}
+				results[i].Component = updated
+			}
+			return nil
+		})
+		if err != nil {
+			for i := range results {
+				results[i].Component = nil
+			}
+			s.logger.WithContext(ctx).Error("Component batch rolled back", map[string]interface{}{
+				"error": err.Error(),
+				"count": len(components),
+			})
+			return results, fmt.Errorf("%w: %v", ErrBatchRejected, err)
+		}
+	} else {
+		for i, component := range components {
+			if results[i].Err != nil {
+				continue
+			}
+			err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+				updated, err := update(ctx, component, owners[i])
+				results[i].Component = updated
+				return err
+			})
+			if err != nil {
+				results[i].Err = err
+			}
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Components batch updated", map[string]interface{}{
+		"count":  len(components),
+		"atomic": atomic,
+	})
+
+	return results, nil
+}
+
+// ExtendComponentLife поднимает max_mileage после осмотра и пишет в историю
+// прежний порог и причину
+func (s *ComponentService) ExtendComponentLife(ctx context.Context, componentID uuid.UUID, ext domain.LifeExtension) (*domain.Component, error) {
+	component, err := s.componentRepo.GetComponentByIDForWrite(ctx, componentID)
+	if err != nil {
+		return nil, err
+	}
+	if component.ReplacedAt != nil {
+		return nil, domain.ErrComponentReplaced
+	}
+	bike, err := s.bikeRepo.GetBikeByIDForWrite(ctx, component.BikeID)
+	if err != nil {
+		return nil, err
+	}
+
+	newMax, err := ext.NewMaxMileage(component, bike.Mileage)
+	if err != nil {
+		return nil, err
+	}
+	previous := component.MaxMileage
+	component.MaxMileage = newMax
+
+	s.invalidateBike(component.BikeID)
+	var updated *domain.Component
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = s.componentRepo.UpdateComponent(ctx, component)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentLifeExtended, bike.UserID, bike.BikeID, domain.LifeExtensionRecord{
+			Component:          updated,
+			PreviousMaxMileage: previous,
+			Reason:             ext.Reason,
+		}))
+	})
+	s.invalidateBike(component.BikeID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to extend component life", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Component life extended", map[string]interface{}{
+		"component_id":         componentID,
+		"previous_max_mileage": previous,
+		"max_mileage":          newMax,
+	})
+
+	return updated, nil
+}
+
+// ReplaceComponent снимает изношенный компонент и ставит на его место новый
+// на текущем пробеге байка. Старый остаётся в базе с replaced_at для истории
+func (s *ComponentService) ReplaceComponent(ctx context.Context, componentID uuid.UUID, r domain.ComponentReplacement) (*domain.Component, error) {
+	old, err := s.componentRepo.GetComponentByIDForWrite(ctx, componentID)
+	if err != nil {
+		return nil, err
+	}
+	if old.ReplacedAt != nil {
+		return nil, domain.ErrComponentReplaced
+	}
+	bike, err := s.bikeRepo.GetBikeByIDForWrite(ctx, old.BikeID)
+	if err != nil {
+		return nil, err
+	}
+
+	component := r.NewComponent(old, bike.Mileage, time.Now())
+	if err := s.prepareComponent(ctx, bike, component); err != nil {
+		return nil, err
+	}
+
+	s.invalidateBike(bike.BikeID)
+	var installed *domain.Component
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// сначала снимаем старый, иначе новый упрётся в ограничение на позицию
+		replaced, err := s.componentRepo.MarkComponentReplaced(ctx, componentID)
+		if err != nil {
+			return err
+		}
+		installed, err = s.componentRepo.CreateComponent(ctx, component)
+		if err != nil {
+			return err
+		}
+		return s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentReplaced, bike.UserID, bike.BikeID, domain.ComponentReplacementRecord{
+			Replaced:  replaced,
+			Installed: installed,
+		}))
+	})
+	s.invalidateBike(bike.BikeID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to replace component", map[string]interface{}{
+			"error":        err.Error(),
+			"component_id": componentID,
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Component replaced", map[string]interface{}{
+		"component_id":     componentID,
+		"new_component_id": installed.ID,
+		"bike_id":          bike.BikeID,
+		"bike_mileage":     bike.Mileage,
+	})
+
+	return installed, nil
+}
+
+// ImportComponents добавляет к одному байку сразу несколько компонентов одной
+// транзакцией: либо все, либо ни одного. Ошибки проверки возвращаются по
+// каждому элементу вместе с ErrInvalidComponentImport
+func (s *ComponentService) ImportComponents(ctx context.Context, bikeID uuid.UUID, components []*domain.Component) ([]ComponentCreateResult, error) {
+	bike, err := s.bikeRepo.GetBikeByIDForWrite(ctx, bikeID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ComponentCreateResult, len(components))
+	invalid := false
+	for i, component := range components {
+		component.BikeID = bikeID
+		if err := s.prepareComponent(ctx, bike, component); err != nil {
+			results[i].Err = err
+			invalid = true
+		}
+	}
+	if invalid {
+		return results, ErrInvalidComponentImport
+	}
+
+	s.invalidateBike(bikeID)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, component := range components {
+			created, err := s.componentRepo.CreateComponent(ctx, component)
+			if err != nil {
+				results[i].Err = err
+				return err
+			}
+			if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentCreated, bike.UserID, bikeID, created)); err != nil {
+				return err
+			}
+			results[i].Component = created
+		}
+		return nil
+	})
+	s.invalidateBike(bikeID)
+	if err != nil {
+		for i := range results {
+			results[i].Component = nil
+		}
+		s.logger.WithContext(ctx).Error("Failed to import components", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+			"count":   len(components),
+		})
+		return results, err
+	}
+
+	s.logger.WithContext(ctx).Info("Components imported", map[string]interface{}{
+		"bike_id": bikeID,
+		"count":   len(components),
+	})
+
+	return results, nil
+}
+
+// CreateComponents создаёт компоненты одного байка одной транзакцией: либо
+// все, либо ни одного. Ошибка проверки элемента возвращается как
+// *ComponentItemError с его индексом, до записи в базу
+func (s *ComponentService) CreateComponents(ctx context.Context, components []*domain.Component) ([]*domain.Component, error) {
+	if len(components) == 0 {
+		return nil, fmt.Errorf("%w: no components", ErrInvalidComponentBatch)
+	}
+	bikeID := components[0].BikeID
+	for i, component := range components {
+		if component.BikeID != bikeID {
+			return nil, &ComponentItemError{Index: i, Err: fmt.Errorf("%w: bike_id differs from the first component", ErrInvalidComponentBatch)}
+		}
+	}
+
+	bike, err := s.bikeRepo.GetBikeByIDForWrite(ctx, bikeID)
+	if err != nil {
+		return nil, err
+	}
+	for i, component := range components {
+		if err := s.prepareComponent(ctx, bike, component); err != nil {
+			return nil, &ComponentItemError{Index: i, Err: err}
+		}
+	}
+
+	created := make([]*domain.Component, 0, len(components))
+	s.invalidateBike(bikeID)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, component := range components {
+			c, err := s.componentRepo.CreateComponent(ctx, component)
+			if err != nil {
+				return err
+			}
+			if err := s.outbox.Enqueue(ctx, domain.NewEvent(domain.ComponentCreated, bike.UserID, bikeID, c)); err != nil {
+				return err
+			}
+			created = append(created, c)
+		}
+		return nil
+	})
+	s.invalidateBike(bikeID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to create components batch", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+			"count":   len(components),
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Components batch created", map[string]interface{}{
+		"bike_id": bikeID,
+		"count":   len(created),
+	})
+
+	return created, nil
+}
+
+// prepareComponent подставляет значения по умолчанию и проверяет компонент
+// перед созданием на уже загруженном байке
+func (s *ComponentService) prepareComponent(ctx context.Context, bike *domain.Bike, component *domain.Component) error {
+	component.NormalizePosition()
+	if component.MaxMileage == 0 && component.MaxAgeDays == nil {
+		component.ApplyDefault(s.componentDefault(ctx, component.Name))
+	}
+	err := s.validate.Struct(component)
+	if err == nil {
+		err = component.ValidateThresholds()
+	}
+	if err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+	if err := s.checkInstallationOn(bike, component); err != nil {
+		return err
+	}
+	if component.ID == uuid.Nil {
+		component.ID = uuid.New()
+	}
+	return nil
+}
+
+// ListOrphanedComponents - компоненты, чей bike_id не ведёт ни на один живой байк
+func (s *ComponentService) ListOrphanedComponents(ctx context.Context, page domain.Page) ([]*domain.Component, error) {
+	components, err := s.componentRepo.ListOrphanedComponents(ctx, page)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list orphaned components", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+	return components, nil
+}
+
+// RepairOrphanedComponents переносит компоненты-сироты на живой байк
+// или удаляет их. ID, которые уже не сироты, пропускаются, возвращаются только
+// затронутые. Проверки установки не применяются: это ремонт данных, а не
+// установка. Событий нет: у сирот не осталось владельца, которому их доставить
+func (s *ComponentService) RepairOrphanedComponents(ctx context.Context, repair domain.OrphanRepair) ([]uuid.UUID, error) {
+	if err := repair.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOrphanRepair, err)
+	}
+
+	var (
+		affected []uuid.UUID
+		err      error
+	)
+	if repair.Action == domain.OrphanReassign {
+		if _, err := s.bikeRepo.GetBikeByIDForWrite(ctx, repair.BikeID); err != nil {
+			return nil, err
+		}
+		s.invalidateBike(repair.BikeID)
+		affected, err = s.componentRepo.ReassignOrphanedComponents(ctx, repair.ComponentIDs, repair.BikeID)
+		s.invalidateBike(repair.BikeID)
+	} else {
+		affected, err = s.componentRepo.DeleteOrphanedComponents(ctx, repair.ComponentIDs)
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to repair orphaned components", map[string]interface{}{
+			"error":  err.Error(),
+			"action": repair.Action,
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Orphaned components repaired", map[string]interface{}{
+		"action":    repair.Action,
+		"bike_id":   repair.BikeID,
+		"requested": len(repair.ComponentIDs),
+		"affected":  len(affected),
+	})
+
+	return affected, nil
+}
+
+// invalidateBike сбрасывает кеш байка. Вызывается дважды вокруг каждой записи:
+// до транзакции, чтобы падение процесса между commit и сбросом не оставило
+// в кеше старые данные, и после - безусловно, даже если запись вернула ошибку,
+// потому что commit мог пройти, а ошибку дал обрыв соединения. Второй сброс
+// убирает то, что конкурентное чтение успело положить во время записи.
+// Если Redis недоступен в оба момента, устаревший байк проживёт не дольше TTL кеша
+func (s *ComponentService) invalidateBike(bikeID uuid.UUID) {
+	if err := deleteBikeCache(s.cache, bikeID); err != nil {
 		s.logger.Warn("Failed to invalidate bike cache", map[string]interface{}{
 			"error":   err.Error(),
-			"bike_id": component.BikeID.String(),
+			"bike_id": bikeID.String(),
 		})
 	}
+}
 
-	s.logger.Info("Component deleted successfully", map[string]interface{}{
-		"component_id": componentID,
+// checkInstallation не даёт поставить компонент в будущем, раньше, чем появился байк,
+// или на пробеге больше, чем у байка сейчас. Одинаково для создания и обновления.
+// Байк читается с primary: с отставшей реплики пробег может оказаться старым.
+// Загруженный байк возвращается: его владелец нужен для события
+func (s *ComponentService) checkInstallation(ctx context.Context, component *domain.Component) (*domain.Bike, error) {
+	bike, err := s.bikeRepo.GetBikeByIDForWrite(ctx, component.BikeID)
+	if err != nil {
+		return nil, err
+	}
+	return bike, s.checkInstallationOn(bike, component)
+}
+
+// bikeOwner - владелец байка для поля user_id события. У компонента, чей байк
+// уже удалён, владельца нет: событие уйдёт с uuid.Nil
+func (s *ComponentService) bikeOwner(ctx context.Context, bikeID uuid.UUID) uuid.UUID {
+	bike, err := s.bikeRepo.GetBikeByID(ctx, bikeID)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to resolve bike owner for event", map[string]interface{}{
+			"error":   err.Error(),
+			"bike_id": bikeID,
+		})
+		return uuid.Nil
+	}
+	return bike.UserID
+}
+
+// checkInstallationOn - те же проверки, когда байк уже загружен
+func (s *ComponentService) checkInstallationOn(bike *domain.Bike, component *domain.Component) error {
+	if component.InstalledAt.After(time.Now().Add(installedAtClockSkew)) {
+		return ErrInstalledInFuture
+	}
+	if component.InstalledAt.Before(bike.CreatedAt) {
+		return fmt.Errorf("%w (%s)", ErrInstalledBeforeBike, bike.CreatedAt.Format(time.RFC3339))
+	}
+	if component.InstalledMileage > bike.Mileage {
+		return fmt.Errorf("%w (%d > %d)", ErrInstalledMileageAboveBike, component.InstalledMileage, bike.Mileage)
+	}
+	if bike.Year != nil && component.InstalledAt.Year() < *bike.Year-modelYearLead {
+		if s.strictYear {
+			return fmt.Errorf("%w (%d < %d)", ErrInstalledBeforeModelYear, component.InstalledAt.Year(), *bike.Year)
+		}
+		s.logger.Warn("Component installed before bike model year", map[string]interface{}{
+			"bike_id":      bike.BikeID,
+			"bike_year":    *bike.Year,
+			"installed_at": component.InstalledAt,
+		})
+	}
+	return nil
+}
+
+// SuggestValues подсказывает бренды или модели по префиксу. Популярные префиксы
+// ненадолго кешируются, новые значения появятся в подсказках в пределах suggestTTL
+func (s *ComponentService) SuggestValues(ctx context.Context, q domain.SuggestQuery) ([]string, error) {
+	q.Prefix = strings.TrimSpace(q.Prefix)
+	if utf8.RuneCountInString(q.Prefix) < SuggestMinPrefix {
+		return nil, fmt.Errorf("%w: q must be at least %d characters", ErrInvalidSuggestQuery, SuggestMinPrefix)
+	}
+	if q.Limit < 1 || q.Limit > SuggestMaxLimit {
+		q.Limit = SuggestMaxLimit
+	}
+
+	cacheKey := suggestCacheKey(q)
+	if cachedData, err := s.cache.Get(cacheKey); err == nil {
+		var cached []string
+		if err := json.Unmarshal(cachedData, &cached); err == nil {
+			if len(cached) > q.Limit {
+				cached = cached[:q.Limit]
+			}
+			return cached, nil
+		}
+	}
+
+	// в кеш кладём полный набор, чтобы один ключ обслуживал любой limit
+	full := q
+	full.Limit = SuggestMaxLimit
+	values, err := s.componentRepo.SuggestValues(ctx, full)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to suggest values", map[string]interface{}{
+			"error": err.Error(),
+			"field": q.Field,
+		})
+		return nil, err
+	}
+
+	if data, err := json.Marshal(values); err == nil {
+		if err := s.cache.Set(cacheKey, data, suggestTTL); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to cache suggestions", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if len(values) > q.Limit {
+		values = values[:q.Limit]
+	}
+	return values, nil
+}
+
+// GetComponentDefaults возвращает пороги замены по умолчанию для всех типов
+func (s *ComponentService) GetComponentDefaults(ctx context.Context) ([]*domain.ComponentDefault, error) {
+	if cached, err := s.cache.Get(componentDefaultsCacheKey); err == nil {
+		var defaults []*domain.ComponentDefault
+		if err := json.Unmarshal(cached, &defaults); err == nil {
+			return defaults, nil
+		}
+	}
+
+	defaults, err := s.componentRepo.ListComponentDefaults(ctx)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get component defaults", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	if data, err := json.Marshal(defaults); err == nil {
+		if err := s.cache.Set(componentDefaultsCacheKey, data, componentDefaultsTTL); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to cache component defaults", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return defaults, nil
+}
+
+// SetComponentDefaults обновляет переданные типы одной транзакцией, остальные не трогает
+func (s *ComponentService) SetComponentDefaults(ctx context.Context, defaults []*domain.ComponentDefault) ([]*domain.ComponentDefault, error) {
+	for _, d := range defaults {
+		if err := s.validate.Struct(d); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidComponentDefault, err)
+		}
+		if err := d.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidComponentDefault, d.Name, err)
+		}
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, d := range defaults {
+			if _, err := s.componentRepo.UpsertComponentDefault(ctx, d); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to update component defaults", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	if err := s.cache.Delete(componentDefaultsCacheKey); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate component defaults cache", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
 
+	s.logger.WithContext(ctx).Info("Component defaults updated", map[string]interface{}{
+		"count": len(defaults),
+	})
+
+	return defaults, nil
+}
+
+// GetComponentWeights возвращает веса всех типов в срочности обслуживания.
+// Типы без строки в базе добавляются с весом по умолчанию
+func (s *ComponentService) GetComponentWeights(ctx context.Context) ([]*domain.ComponentWeight, error) {
+	stored, err := s.componentRepo.ListComponentWeights(ctx)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get component weights", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	byName := make(map[domain.ComponentName]*domain.ComponentWeight, len(stored))
+	for _, w := range stored {
+		byName[w.Name] = w
+	}
+	weights := make([]*domain.ComponentWeight, 0, len(domain.ComponentNames))
+	for _, name := range domain.ComponentNames {
+		w, ok := byName[name]
+		if !ok {
+			w = &domain.ComponentWeight{Name: name, Weight: domain.DefaultComponentWeight}
+		}
+		weights = append(weights, w)
+	}
+	return weights, nil
+}
+
+// SetComponentWeights обновляет веса переданных типов одной транзакцией.
+// Срочность читает веса прямо из базы, так что кеш сбрасывать не нужно
+func (s *ComponentService) SetComponentWeights(ctx context.Context, weights []*domain.ComponentWeight) ([]*domain.ComponentWeight, error) {
+	for _, w := range weights {
+		if err := s.validate.Struct(w); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidComponentWeight, err)
+		}
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, w := range weights {
+			if _, err := s.componentRepo.UpsertComponentWeight(ctx, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to update component weights", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Component weights updated", map[string]interface{}{
+		"count": len(weights),
+	})
+
+	return weights, nil
+}
+
+// componentDefault - пороги для одного типа. Без них создание просто упадёт
+// на проверке порогов, поэтому ошибку чтения только логируем
+func (s *ComponentService) componentDefault(ctx context.Context, name domain.ComponentName) *domain.ComponentDefault {
+	defaults, err := s.GetComponentDefaults(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, d := range defaults {
+		if d.Name == name {
+			return d
+		}
+	}
 	return nil
 }
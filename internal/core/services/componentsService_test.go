@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// newComponent - компонент байка с порогом 5000 км
+func newComponent(bike *domain.Bike, installedAt time.Time, installedMileage int) *domain.Component {
+	return &domain.Component{
+		BikeID:           bike.BikeID,
+		Name:             domain.Handlebars,
+		InstalledAt:      installedAt,
+		InstalledMileage: installedMileage,
+		MaxMileage:       5000,
+	}
+}
+
+func TestComponentInstalledAt(t *testing.T) {
+	tests := []struct {
+		name string
+		// offset - installed_at относительно создания байка, сутки назад
+		offset  time.Duration
+		wantErr error
+	}{
+		{name: "после создания байка", offset: time.Hour},
+		{name: "в момент создания байка", offset: 0},
+		{name: "до создания байка", offset: -time.Hour, wantErr: ErrInstalledBeforeBike},
+		{name: "в будущем", offset: 48 * time.Hour, wantErr: ErrInstalledInFuture},
+		// часы клиента могут немного спешить
+		{name: "в пределах расхождения часов", offset: 24*time.Hour + time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("create", func(t *testing.T) {
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 100)
+				_, _, err := env.components.CreateComponent(ctx, newComponent(bike, bike.CreatedAt.Add(tt.offset), 0), domain.IdempotencyKey{})
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if created := len(env.store.Components(bike.BikeID)) == 1; created != (tt.wantErr == nil) {
+					t.Errorf("component created = %t", created)
+				}
+			})
+
+			t.Run("update", func(t *testing.T) {
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 100)
+				stored := env.store.AddComponent(newComponent(bike, bike.CreatedAt, 0))
+
+				update := *stored
+				update.InstalledAt = bike.CreatedAt.Add(tt.offset)
+				_, err := env.components.UpdateComponent(ctx, &update)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				want := update.InstalledAt
+				if tt.wantErr != nil {
+					want = stored.InstalledAt
+				}
+				if got, _ := env.store.Component(stored.ID); !got.InstalledAt.Equal(want) {
+					t.Errorf("installed_at = %s, want %s", got.InstalledAt, want)
+				}
+			})
+		})
+	}
+}
+
+// установка раньше модельного года байка - по умолчанию только предупреждение
+func TestComponentInstalledBeforeModelYear(t *testing.T) {
+	installedAt := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// bikeYear - модельный год, 0 - неизвестен
+		bikeYear int
+		strict   bool
+		wantErr  error
+		wantWarn bool
+	}{
+		{name: "год байка неизвестен", strict: true},
+		{name: "тот же год", bikeYear: 2018, strict: true},
+		// модель следующего года продаётся заранее
+		{name: "на год раньше модели", bikeYear: 2019, strict: true},
+		{name: "переставленные даты", bikeYear: 2020, wantWarn: true},
+		{name: "переставленные даты в строгом режиме", bikeYear: 2020, strict: true, wantErr: ErrInstalledBeforeModelYear},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			validate := validator.New()
+			if err := RegisterValidators(validate); err != nil {
+				t.Fatal(err)
+			}
+			env.components = NewComponentService(env.store, env.store, env.logger, validate, env.cache, env.store, env.store, env.store, tt.strict)
+			var year *int
+			if tt.bikeYear != 0 {
+				year = &tt.bikeYear
+			}
+			bike := env.store.AddBike(&domain.Bike{
+				UserID:    uuid.New(),
+				BikeName:  "Trail",
+				Type:      domain.MTB,
+				Year:      year,
+				Mileage:   100,
+				CreatedAt: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+			})
+
+			_, _, err := env.components.CreateComponent(context.Background(), newComponent(bike, installedAt, 0), domain.IdempotencyKey{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if created := len(env.store.Components(bike.BikeID)) == 1; created != (tt.wantErr == nil) {
+				t.Errorf("component created = %t", created)
+			}
+			if _, warned := env.logger.Find("Component installed before bike model year"); warned != tt.wantWarn {
+				t.Errorf("warning logged = %t, want %t", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestComponentInstalledMileage(t *testing.T) {
+	tests := []struct {
+		name             string
+		installedMileage int
+		wantErr          error
+	}{
+		{name: "ноль", installedMileage: 0},
+		{name: "ниже пробега байка", installedMileage: 999},
+		{name: "равен пробегу байка", installedMileage: 1000},
+		{name: "выше пробега байка", installedMileage: 1001, wantErr: ErrInstalledMileageAboveBike},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("create", func(t *testing.T) {
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 1000)
+				_, _, err := env.components.CreateComponent(ctx, newComponent(bike, bike.CreatedAt, tt.installedMileage), domain.IdempotencyKey{})
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			})
+
+			t.Run("update", func(t *testing.T) {
+				env := newTestEnv(t)
+				bike := env.addBike(uuid.New(), 1000)
+				stored := env.store.AddComponent(newComponent(bike, bike.CreatedAt, 500))
+
+				update := *stored
+				update.InstalledMileage = tt.installedMileage
+				_, err := env.components.UpdateComponent(ctx, &update)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				want := tt.installedMileage
+				if tt.wantErr != nil {
+					want = stored.InstalledMileage
+				}
+				if got, _ := env.store.Component(stored.ID); got.InstalledMileage != want {
+					t.Errorf("installed_mileage = %d, want %d", got.InstalledMileage, want)
+				}
+			})
+		})
+	}
+}
+
+// staleBikes отдаёт байк таким, каким он был до удаления, даже с primary:
+// так выглядит удаление, случившееся между проверкой байка и вставкой компонента
+type staleBikes struct {
+	ports.BikeRepository
+	bike *domain.Bike
+}
+
+func (r staleBikes) GetBikeByIDForWrite(ctx context.Context, bikeID uuid.UUID) (*domain.Bike, error) {
+	if bikeID != r.bike.BikeID {
+		return r.BikeRepository.GetBikeByIDForWrite(ctx, bikeID)
+	}
+	copied := *r.bike
+	return &copied, nil
+}
+
+// проверка байка в сервисе устарела - вставку всё равно отклоняет репозиторий
+func TestCreateComponentBikeDeletedAfterCheck(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		create func(s *ComponentService, bike *domain.Bike, old *domain.Component) error
+	}{
+		{name: "CreateComponent", create: func(s *ComponentService, bike *domain.Bike, _ *domain.Component) error {
+			_, _, err := s.CreateComponent(ctx, newComponent(bike, bike.CreatedAt, 0), domain.IdempotencyKey{})
+			return err
+		}},
+		{name: "CreateComponents", create: func(s *ComponentService, bike *domain.Bike, _ *domain.Component) error {
+			_, err := s.CreateComponents(ctx, []*domain.Component{newComponent(bike, bike.CreatedAt, 0)})
+			return err
+		}},
+		{name: "ImportComponents", create: func(s *ComponentService, bike *domain.Bike, _ *domain.Component) error {
+			_, err := s.ImportComponents(ctx, bike.BikeID, []*domain.Component{newComponent(bike, bike.CreatedAt, 0)})
+			return err
+		}},
+		{name: "ReplaceComponent", create: func(s *ComponentService, _ *domain.Bike, old *domain.Component) error {
+			_, err := s.ReplaceComponent(ctx, old.ID, domain.ComponentReplacement{Brand: "Renthal"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			bike := env.addBike(uuid.New(), 100)
+			old := env.store.AddComponent(&domain.Component{
+				BikeID:      bike.BikeID,
+				Name:        domain.Frame,
+				InstalledAt: bike.CreatedAt,
+				MaxMileage:  5000,
+			})
+			if err := env.store.DeleteBike(ctx, bike.BikeID); err != nil {
+				t.Fatalf("DeleteBike: %v", err)
+			}
+			components := NewComponentService(env.store, staleBikes{BikeRepository: env.store, bike: bike}, env.logger, validator.New(), env.cache, env.store, env.store, env.store, false)
+
+			if err := tt.create(components, bike, old); !errors.Is(err, domain.ErrBikeNotFound) {
+				t.Fatalf("err = %v, want ErrBikeNotFound", err)
+			}
+			stored := env.store.Components(bike.BikeID)
+			if len(stored) != 1 || stored[0].ReplacedAt != nil {
+				t.Errorf("components after rejected create = %+v, want only the untouched original", stored)
+			}
+		})
+	}
+}
+
+// laggingReplica - реплика, отставшая от primary: обычное чтение отдаёт
+// байк таким, каким он был раньше
+type laggingReplica struct {
+	ports.BikeRepository
+	bike *domain.Bike
+}
+
+func (r laggingReplica) GetBikeByID(ctx context.Context, bikeID uuid.UUID) (*domain.Bike, error) {
+	if bikeID != r.bike.BikeID {
+		return r.BikeRepository.GetBikeByID(ctx, bikeID)
+	}
+	copied := *r.bike
+	return &copied, nil
+}
+
+// проверки перед записью компонентов читают байк с primary: отставшая
+// реплика не должна ни отклонять верный пробег, ни пускать на удалённый байк
+func TestComponentChecksReadPrimary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("пробег", func(t *testing.T) {
+		env := newTestEnv(t)
+		bike := env.addBike(uuid.New(), 1000)
+		stale := *bike
+		stale.Mileage = 100
+		components := NewComponentService(env.store, laggingReplica{BikeRepository: env.store, bike: &stale}, env.logger, validator.New(), env.cache, env.store, env.store, env.store, false)
+
+		created, _, err := components.CreateComponent(ctx, newComponent(bike, bike.CreatedAt, 800), domain.IdempotencyKey{})
+		if err != nil {
+			t.Fatalf("CreateComponent: %v", err)
+		}
+		created.InstalledMileage = 900
+		if _, err := components.UpdateComponent(ctx, created); err != nil {
+			t.Fatalf("UpdateComponent: %v", err)
+		}
+	})
+
+	t.Run("цель переноса сирот", func(t *testing.T) {
+		env := newTestEnv(t)
+		bike := env.addBike(uuid.New(), 1000)
+		orphan := env.store.AddComponent(&domain.Component{BikeID: uuid.New(), Name: domain.Frame, InstalledAt: bike.CreatedAt, MaxMileage: 5000})
+		if err := env.store.DeleteBike(ctx, bike.BikeID); err != nil {
+			t.Fatalf("DeleteBike: %v", err)
+		}
+		components := NewComponentService(env.store, laggingReplica{BikeRepository: env.store, bike: bike}, env.logger, validator.New(), env.cache, env.store, env.store, env.store, false)
+
+		repair := domain.OrphanRepair{Action: domain.OrphanReassign, ComponentIDs: []uuid.UUID{orphan.ID}, BikeID: bike.BikeID}
+		if _, err := components.RepairOrphanedComponents(ctx, repair); !errors.Is(err, domain.ErrBikeNotFound) {
+			t.Fatalf("err = %v, want ErrBikeNotFound", err)
+		}
+		if stored, _ := env.store.Component(orphan.ID); stored.BikeID == bike.BikeID {
+			t.Error("orphan was moved onto a deleted bike")
+		}
+	})
+}
+
+// laggingComponents - реплика, отставшая от primary: обычное чтение отдаёт
+// компонент таким, каким он был до последней правки
+type laggingComponents struct {
+	ports.ComponentRepository
+	component *domain.Component
+}
+
+func (r laggingComponents) GetComponentByID(ctx context.Context, componentID uuid.UUID) (*domain.Component, error) {
+	if componentID != r.component.ID {
+		return r.ComponentRepository.GetComponentByID(ctx, componentID)
+	}
+	copied := *r.component
+	return &copied, nil
+}
+
+// правка компонента читает его с primary и пишет обратно целиком: с
+// отставшей реплики вторая правка молча откатила бы первую
+func TestComponentEditsReadPrimary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("правка после правки", func(t *testing.T) {
+		env := newTestEnv(t)
+		bike := env.addBike(uuid.New(), 1000)
+		stale := env.store.AddComponent(newComponent(bike, bike.CreatedAt, 0))
+		components := NewComponentService(laggingComponents{ComponentRepository: env.store, component: stale}, env.store, env.logger, validator.New(), env.cache, env.store, env.store, env.store, false)
+
+		edit := func(apply func(c *domain.Component)) {
+			t.Helper()
+			component, err := components.GetComponentByIDForWrite(ctx, stale.ID.String())
+			if err != nil {
+				t.Fatalf("GetComponentByIDForWrite: %v", err)
+			}
+			apply(component)
+			if _, err := components.UpdateComponent(ctx, component); err != nil {
+				t.Fatalf("UpdateComponent: %v", err)
+			}
+		}
+		edit(func(c *domain.Component) { c.Brand = "SRAM" })
+		edit(func(c *domain.Component) { c.Model = "GX" })
+
+		if got, _ := env.store.Component(stale.ID); got.Brand != "SRAM" || got.Model != "GX" {
+			t.Errorf("brand, model = %q, %q, want both edits kept", got.Brand, got.Model)
+		}
+	})
+
+	t.Run("продление ресурса", func(t *testing.T) {
+		env := newTestEnv(t)
+		bike := env.addBike(uuid.New(), 1000)
+		stale := env.store.AddComponent(newComponent(bike, bike.CreatedAt, 0))
+		components := NewComponentService(laggingComponents{ComponentRepository: env.store, component: stale}, env.store, env.logger, validator.New(), env.cache, env.store, env.store, env.store, false)
+
+		for range 2 {
+			if _, err := components.ExtendComponentLife(ctx, stale.ID, domain.LifeExtension{Delta: 1000, Reason: "осмотр"}); err != nil {
+				t.Fatalf("ExtendComponentLife: %v", err)
+			}
+		}
+		if got, _ := env.store.Component(stale.ID); got.MaxMileage != stale.MaxMileage+2000 {
+			t.Errorf("max_mileage = %d, want %d", got.MaxMileage, stale.MaxMileage+2000)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// ChangedFields сравнивает JSON-представления до и после обновления и возвращает
+// только поля, которые поменялись. Поле, пропавшее из-за omitempty, приходит как nil.
+// id и updated_at добавляются всегда, чтобы клиенту было к чему привязать изменения
+func ChangedFields(before, after interface{}) (map[string]interface{}, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]interface{})
+	for key, value := range afterFields {
+		if !reflect.DeepEqual(beforeFields[key], value) {
+			changed[key] = value
+		}
+	}
+	for key := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			changed[key] = nil
+		}
+	}
+	for _, key := range []string{"id", "updated_at"} {
+		if value, ok := afterFields[key]; ok {
+			changed[key] = value
+		}
+	}
+	return changed, nil
+}
+
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
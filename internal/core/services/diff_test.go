@@ -0,0 +1,41 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedFields(t *testing.T) {
+	type item struct {
+		ID        string `json:"id"`
+		Brand     string `json:"brand,omitempty"`
+		Mileage   int    `json:"mileage"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	before := item{ID: "c1", Brand: "Shimano", Mileage: 100, UpdatedAt: "t1"}
+
+	tests := []struct {
+		name  string
+		after item
+		want  map[string]interface{}
+	}{
+		{name: "ничего не поменялось", after: before, want: map[string]interface{}{"id": "c1", "updated_at": "t1"}},
+		{name: "одно поле", after: item{ID: "c1", Brand: "SRAM", Mileage: 100, UpdatedAt: "t2"},
+			want: map[string]interface{}{"id": "c1", "brand": "SRAM", "updated_at": "t2"}},
+		{name: "число", after: item{ID: "c1", Brand: "Shimano", Mileage: 250, UpdatedAt: "t2"},
+			want: map[string]interface{}{"id": "c1", "mileage": float64(250), "updated_at": "t2"}},
+		{name: "очищенное omitempty-поле", after: item{ID: "c1", Mileage: 100, UpdatedAt: "t2"},
+			want: map[string]interface{}{"id": "c1", "brand": nil, "updated_at": "t2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChangedFields(before, tt.after)
+			if err != nil {
+				t.Fatalf("ChangedFields: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedFields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+// NopPublisher молча принимает события, когда внешний брокер не настроен
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(context.Context, *domain.Event) error {
+	return nil
+}
+
+// FanoutPublisher отдаёт событие всем приёмникам по очереди и останавливается
+// на первой ошибке, тогда релей повторит событие целиком. Kafka ставится
+// раньше вебхуков: её сбой частый и дешёвый для повтора. Вебхуки событие
+// только ставят в очередь, и повтор её не задваивает, но доставка - как
+// минимум один раз: подписчик получит событие повторно, если ответ на
+// удачную попытку не успели записать
+type FanoutPublisher struct {
+	publishers []ports.EventPublisher
+}
+
+func NewFanoutPublisher(publishers ...ports.EventPublisher) *FanoutPublisher {
+	return &FanoutPublisher{publishers: publishers}
+}
+
+func (f *FanoutPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	for _, publisher := range f.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
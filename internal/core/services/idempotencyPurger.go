@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+// idempotencyPurgeInterval - как часто чистим протухшие ключи. Протухший
+// ключ и так перезаписывается, очистка только не даёт таблице расти
+const idempotencyPurgeInterval = time.Hour
+
+// IdempotencyPurger периодически удаляет ключи идемпотентности старше
+// idempotencyKeyTTL
+type IdempotencyPurger struct {
+	idempotency ports.IdempotencyRepository
+	logger      ports.LoggerPort
+}
+
+func NewIdempotencyPurger(idempotency ports.IdempotencyRepository, logger ports.LoggerPort) *IdempotencyPurger {
+	return &IdempotencyPurger{
+		idempotency: idempotency,
+		logger:      logger,
+	}
+}
+
+// Run работает до отмены ctx
+func (p *IdempotencyPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+func (p *IdempotencyPurger) purge(ctx context.Context) {
+	purged, err := p.idempotency.PurgeExpiredKeys(ctx, idempotencyKeyTTL)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("Failed to purge idempotency keys", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	if purged > 0 {
+		p.logger.Info("Purged expired idempotency keys", map[string]interface{}{
+			"count": purged,
+		})
+	}
+}
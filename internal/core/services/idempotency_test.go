@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// батч байков идёт через общие ключи идемпотентности: повтор в пределах TTL
+// отдаёт тот же батч, после TTL ключ перезанимается и батч создаётся заново
+func TestCreateBikesKeyExpires(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	env.store.Now = func() time.Time { return now }
+	userID := uuid.New()
+
+	create := func() ([]uuid.UUID, bool) {
+		t.Helper()
+		bikes := []*domain.Bike{
+			{UserID: userID, BikeName: "Trail", Type: domain.MTB, Model: "Trek"},
+			{UserID: userID, BikeName: "Road", Type: domain.Road, Model: "Giant"},
+		}
+		results, replayed, err := env.bikes.CreateBikes(context.Background(), userID, "sync-1", bikes)
+		if err != nil {
+			t.Fatalf("CreateBikes: %v", err)
+		}
+		var ids []uuid.UUID
+		for _, r := range results {
+			ids = append(ids, r.Bike.BikeID)
+		}
+		return ids, replayed
+	}
+
+	first, replayed := create()
+	if replayed {
+		t.Fatal("first batch was replayed")
+	}
+	if !env.store.HasIdempotencyKey(domain.IdempotencyScopeBikeBatch, userID, "sync-1") {
+		t.Fatal("batch key is not stored with the idempotency keys")
+	}
+
+	now = now.Add(idempotencyKeyTTL - time.Minute)
+	again, replayed := create()
+	if !replayed || !slices.Equal(again, first) {
+		t.Errorf("within ttl: replayed = %t, bikes = %v, want %v", replayed, again, first)
+	}
+
+	now = now.Add(2 * time.Minute)
+	fresh, replayed := create()
+	if replayed || slices.Equal(fresh, first) {
+		t.Errorf("after ttl: replayed = %t, bikes = %v, want new batch", replayed, fresh)
+	}
+}
+
+func TestIdempotencyPurgerRemovesExpiredKeys(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	env.store.Now = func() time.Time { return now }
+	userID := uuid.New()
+
+	claim := func(key string) {
+		t.Helper()
+		k := domain.IdempotencyKey{UserID: userID, Key: key, RequestHash: "hash"}
+		if _, err := env.store.ClaimKey(context.Background(), domain.IdempotencyScopeBike, k, []uuid.UUID{uuid.New()}, idempotencyKeyTTL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claim("old")
+	now = now.Add(idempotencyKeyTTL)
+	claim("fresh")
+	now = now.Add(time.Minute)
+
+	NewIdempotencyPurger(env.store, env.logger).purge(context.Background())
+
+	if env.store.HasIdempotencyKey(domain.IdempotencyScopeBike, userID, "old") {
+		t.Error("expired key was not purged")
+	}
+	if !env.store.HasIdempotencyKey(domain.IdempotencyScopeBike, userID, "fresh") {
+		t.Error("live key was purged")
+	}
+}
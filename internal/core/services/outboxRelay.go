@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+const (
+	outboxBatchSize    = 100
+	outboxPollInterval = 2 * time.Second
+	// после стольких неудач сообщение остаётся в таблице для ручного разбора
+	outboxMaxAttempts = 20
+	// outboxClaimLease - сколько взятые сообщения принадлежат одному релею.
+	// Не успел за это время - сообщения заберёт следующий проход
+	outboxClaimLease = 2 * time.Minute
+)
+
+// OutboxRelay периодически забирает неотправленные события из outbox
+// и публикует их. Неудачные попытки остаются в очереди до следующего прохода,
+// так что доставка как минимум один раз
+type OutboxRelay struct {
+	tx        ports.Transactor
+	outbox    ports.OutboxRepository
+	publisher ports.EventPublisher
+	logger    ports.LoggerPort
+}
+
+func NewOutboxRelay(
+	tx ports.Transactor,
+	outbox ports.OutboxRepository,
+	publisher ports.EventPublisher,
+	logger ports.LoggerPort,
+) *OutboxRelay {
+	return &OutboxRelay{
+		tx:        tx,
+		outbox:    outbox,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Run работает до отмены ctx
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.relayBatch(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("Outbox relay failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+}
+
+// relayBatch берёт пачку сообщений короткой транзакцией и публикует их уже
+// после commit. Если событие байка не ушло, более поздние события того же
+// байка в этом проходе не отправляются: потребители с ключом по bike_id
+// не должны увидеть их раньше. Они возвращаются в очередь и уйдут после
+// успешного повтора
+func (r *OutboxRelay) relayBatch(ctx context.Context) error {
+	var messages []*domain.OutboxMessage
+	err := r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		messages, err = r.outbox.ClaimPending(ctx, outboxBatchSize, outboxMaxAttempts, outboxClaimLease)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// после lease сообщения может взять другой релей, публиковать их дальше нельзя
+	publishCtx, cancel := context.WithTimeout(ctx, outboxClaimLease)
+	defer cancel()
+
+	// итог публикации записываем и при остановке релея, иначе уже доставленное
+	// событие ушло бы повторно, а недоставленное ждало бы конца lease
+	store := context.WithoutCancel(ctx)
+
+	// события без байка друг от друга не зависят, поэтому в failedBikes
+	// попадают только настоящие bike_id
+	failedBikes := make(map[uuid.UUID]bool)
+	var held []uuid.UUID
+	// abort возвращает в очередь всё, до чего проход не дошёл, чтобы оно
+	// не ждало конца lease. Сообщение, на котором упала запись итога,
+	// остаётся взятым и по-прежнему держит более поздние события своего байка
+	abort := func(i int, err error) error {
+		for _, msg := range messages[i+1:] {
+			held = append(held, msg.ID)
+		}
+		if len(held) > 0 {
+			if releaseErr := r.outbox.Release(store, held); releaseErr != nil {
+				r.logger.Warn("Failed to release outbox messages", map[string]interface{}{
+					"error": releaseErr.Error(),
+					"count": len(held),
+				})
+			}
+		}
+		return err
+	}
+
+	for i, msg := range messages {
+		if failedBikes[msg.BikeID] || publishCtx.Err() != nil {
+			held = append(held, msg.ID)
+			continue
+		}
+
+		if err := r.publish(publishCtx, msg); err != nil {
+			if msg.BikeID != uuid.Nil {
+				failedBikes[msg.BikeID] = true
+			}
+			// прерванная остановкой публикация - не неудачная попытка
+			if publishCtx.Err() != nil {
+				held = append(held, msg.ID)
+				continue
+			}
+			if err := r.outbox.MarkFailed(store, msg.ID, err.Error()); err != nil {
+				return abort(i, err)
+			}
+			continue
+		}
+
+		if err := r.outbox.MarkSent(store, msg.ID); err != nil {
+			return abort(i, err)
+		}
+	}
+
+	if len(held) > 0 {
+		return r.outbox.Release(store, held)
+	}
+	return nil
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, msg *domain.OutboxMessage) error {
+	var event domain.Event
+	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+		r.logger.Error("Failed to decode outbox message", map[string]interface{}{
+			"error":     err.Error(),
+			"outbox_id": msg.ID,
+		})
+		return err
+	}
+
+	if err := r.publisher.Publish(ctx, &event); err != nil {
+		r.logger.Warn("Failed to publish outbox event", map[string]interface{}{
+			"error":      err.Error(),
+			"outbox_id":  msg.ID,
+			"event_type": msg.EventType,
+			"bike_id":    msg.BikeID,
+			"attempts":   msg.Attempts + 1,
+		})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// failingPublisher не доставляет события из fail, остальные запоминает
+type failingPublisher struct {
+	fail      map[uuid.UUID]bool
+	published []uuid.UUID
+}
+
+func (p *failingPublisher) Publish(_ context.Context, event *domain.Event) error {
+	if p.fail[event.ID] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func enqueueEvents(t *testing.T, env *testEnv, bikeIDs ...uuid.UUID) []*domain.Event {
+	t.Helper()
+	var events []*domain.Event
+	for _, bikeID := range bikeIDs {
+		event := domain.NewEvent(domain.BikeUpdated, uuid.New(), bikeID, nil)
+		if err := env.store.Enqueue(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// неудача события без байка не задерживает другие события без байка,
+// а событие того же байка после неудачного ждёт повтора
+func TestRelayBatchHoldsOnlyFailedBike(t *testing.T) {
+	env := newTestEnv(t)
+	bikeID := uuid.New()
+	events := enqueueEvents(t, env, uuid.Nil, uuid.Nil, bikeID, bikeID)
+	publisher := &failingPublisher{fail: map[uuid.UUID]bool{events[0].ID: true, events[2].ID: true}}
+
+	relay := NewOutboxRelay(env.store, env.store, publisher, env.logger)
+	if err := relay.relayBatch(context.Background()); err != nil {
+		t.Fatalf("relayBatch: %v", err)
+	}
+
+	if want := []uuid.UUID{events[1].ID}; !slices.Equal(publisher.published, want) {
+		t.Errorf("published = %v, want %v", publisher.published, want)
+	}
+	if msg, _ := env.store.OutboxMessage(events[3].ID); msg.Attempts != 0 {
+		t.Errorf("held event attempts = %d, want 0", msg.Attempts)
+	}
+}
+
+// если итог публикации не записался, необработанный хвост пачки сразу
+// возвращается в очередь, а не ждёт конца lease
+func TestRelayBatchReleasesRestOnMarkError(t *testing.T) {
+	env := newTestEnv(t)
+	events := enqueueEvents(t, env, uuid.New(), uuid.New(), uuid.New())
+	errStore := errors.New("connection reset")
+	env.store.SetError("MarkSent", errStore)
+
+	relay := NewOutboxRelay(env.store, env.store, &failingPublisher{}, env.logger)
+	if err := relay.relayBatch(context.Background()); !errors.Is(err, errStore) {
+		t.Fatalf("relayBatch = %v, want %v", err, errStore)
+	}
+
+	claimed, err := env.store.ClaimPending(context.Background(), outboxBatchSize, outboxMaxAttempts, outboxClaimLease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []uuid.UUID
+	for _, msg := range claimed {
+		ids = append(ids, msg.ID)
+	}
+	if want := []uuid.UUID{events[1].ID, events[2].ID}; !slices.Equal(ids, want) {
+		t.Errorf("claimable after failed MarkSent = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports/portstest"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// testEnv - сервисы поверх одного portstest.Store, как в проде поверх одной БД
+type testEnv struct {
+	store      *portstest.Store
+	cache      *portstest.Cache
+	logger     *portstest.Logger
+	metrics    *portstest.Metrics
+	validate   *validator.Validate
+	bikes      *BikeService
+	components *ComponentService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	validate := validator.New()
+	if err := RegisterValidators(validate); err != nil {
+		t.Fatalf("RegisterValidators: %v", err)
+	}
+	env := &testEnv{
+		store:    portstest.NewStore(),
+		cache:    portstest.NewCache(),
+		logger:   &portstest.Logger{},
+		metrics:  &portstest.Metrics{},
+		validate: validate,
+	}
+	env.bikes = NewBikeService(env.store, env.store, env.logger, validate, env.cache, env.store, env.store, env.store, env.metrics)
+	env.components = NewComponentService(env.store, env.store, env.logger, validate, env.cache, env.store, env.store, env.store, false)
+	return env
+}
+
+// addBike - байк пользователя с пробегом mileage, созданный сутки назад
+func (env *testEnv) addBike(userID uuid.UUID, mileage int) *domain.Bike {
+	return env.store.AddBike(&domain.Bike{
+		UserID:    userID,
+		BikeName:  "Trail",
+		Type:      domain.MTB,
+		Model:     "Stumpjumper",
+		Mileage:   mileage,
+		CreatedAt: time.Now().Add(-24 * time.Hour),
+	})
+}
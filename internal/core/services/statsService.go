@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+)
+
+// сводка немного отстаёт от базы, зато дашборд не гоняет агрегаты на каждый запрос
+const fleetStatsTTL = time.Minute
+
+type StatsService struct {
+	statsRepo ports.StatsRepository
+	logger    ports.LoggerPort
+	cache     ports.CachePort
+}
+
+func NewStatsService(statsRepo ports.StatsRepository, logger ports.LoggerPort, cache ports.CachePort) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+		logger:    logger,
+		cache:     cache,
+	}
+}
+
+func (s *StatsService) GetFleetStats(ctx context.Context, warnPercent int) (*domain.FleetStats, error) {
+	cacheKey := fleetStatsCacheKey(warnPercent)
+	if cachedData, err := s.cache.Get(cacheKey); err == nil {
+		var cached domain.FleetStats
+		if err := json.Unmarshal(cachedData, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	stats, err := s.statsRepo.GetFleetStats(ctx, warnPercent)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get fleet stats", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	if data, err := json.Marshal(stats); err == nil {
+		if err := s.cache.Set(cacheKey, data, fleetStatsTTL); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to cache fleet stats", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return stats, nil
+}
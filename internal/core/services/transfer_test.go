@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestTransferOwnership(t *testing.T) {
+	owner, other, newOwner := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		from      uuid.UUID
+		to        uuid.UUID
+		wantErr   error
+		wantOwner uuid.UUID
+	}{
+		{name: "передача новому владельцу", from: owner, to: newOwner, wantOwner: newOwner},
+		{name: "тот же владелец", from: owner, to: owner, wantErr: domain.ErrSameOwner, wantOwner: owner},
+		// доступ проверяли по устаревшей копии: UPDATE не должен пройти
+		{name: "владелец уже сменился", from: other, to: newOwner, wantErr: domain.ErrOwnerChanged, wantOwner: owner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			bike := env.addBike(owner, 100)
+
+			_, err := env.bikes.TransferOwnership(context.Background(), bike.BikeID, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			stored, _ := env.store.Bike(bike.BikeID)
+			if stored.UserID != tt.wantOwner {
+				t.Errorf("owner = %s, want %s", stored.UserID, tt.wantOwner)
+			}
+			wantEvents := 0
+			if tt.wantErr == nil {
+				wantEvents = 1
+			}
+			if events := env.store.EventTypes(); len(events) != wantEvents {
+				t.Errorf("events = %v, want %d", events, wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidators добавляет доменные правила в общий валидатор.
+// Вызывается один раз при сборке приложения, до создания сервисов
+func RegisterValidators(v *validator.Validate) error {
+	return v.RegisterValidation("bike_year", validBikeYear)
+}
+
+// validBikeYear - год от domain.MinBikeYear до следующего за текущим
+func validBikeYear(fl validator.FieldLevel) bool {
+	year := int(fl.Field().Int())
+	return year >= domain.MinBikeYear && year <= domain.MaxBikeYear(time.Now())
+}
+
+// validationError заменяет ошибку bike_year на domain.ErrInvalidBikeYear
+// с допустимым диапазоном, остальные ошибки оборачивает как раньше
+func validationError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, fe := range validationErrs {
+			if fe.Tag() == "bike_year" {
+				return domain.NewBikeYearError(time.Now())
+			}
+		}
+	}
+	return fmt.Errorf("validation error: %w", err)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/ports"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+type WebhookService struct {
+	webhookRepo  ports.WebhookRepository
+	logger       ports.LoggerPort
+	validate     *validator.Validate
+	requireHTTPS bool
+}
+
+func NewWebhookService(
+	webhookRepo ports.WebhookRepository,
+	logger ports.LoggerPort,
+	validate *validator.Validate,
+	requireHTTPS bool,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		logger:       logger,
+		validate:     validate,
+		requireHTTPS: requireHTTPS,
+	}
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error) {
+	if err := s.validate.Struct(webhook); err != nil {
+		s.logger.WithContext(ctx).Error("Webhook validation failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	parsedURL, err := url.Parse(webhook.URL)
+	if err != nil || parsedURL.Host == "" {
+		return nil, fmt.Errorf("%w: url is not valid", ErrInvalidWebhook)
+	}
+	if parsedURL.Scheme != "https" && (s.requireHTTPS || parsedURL.Scheme != "http") {
+		return nil, fmt.Errorf("%w: url must use https", ErrInvalidWebhook)
+	}
+	// внутренние адреса отсекает и диспетчер при каждой доставке, здесь -
+	// только то, что видно без DNS
+	if !publicWebhookHost(parsedURL.Hostname()) {
+		return nil, fmt.Errorf("%w: url host must be a public domain name", ErrInvalidWebhook)
+	}
+
+	for _, t := range webhook.EventTypes {
+		if !t.IsValid() {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, t)
+		}
+	}
+
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+
+	createdWebhook, err := s.webhookRepo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to create webhook", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": webhook.UserID,
+		})
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Webhook created successfully", map[string]interface{}{
+		"webhook_id": createdWebhook.ID,
+		"user_id":    createdWebhook.UserID,
+	})
+
+	return createdWebhook, nil
+}
+
+// publicWebhookHost - хост это доменное имя, а не IP-адрес или localhost
+func publicWebhookHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || net.ParseIP(host) != nil {
+		return false
+	}
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+func (s *WebhookService) GetWebhookByID(ctx context.Context, webhookID string) (*domain.Webhook, error) {
+	webhookUUID, err := uuid.Parse(webhookID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
+			"webhook_id": webhookID,
+			"error":      err.Error(),
+		})
+		return nil, fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	webhook, err := s.webhookRepo.GetWebhookByID(ctx, webhookUUID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get webhook", map[string]interface{}{
+			"error":      err.Error(),
+			"webhook_id": webhookID,
+		})
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Webhook, error) {
+	webhooks, err := s.webhookRepo.GetWebhooksByUserID(ctx, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to get webhooks", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	webhookUUID, err := uuid.Parse(webhookID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Invalid UUID format", map[string]interface{}{
+			"webhook_id": webhookID,
+			"error":      err.Error(),
+		})
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	if err := s.webhookRepo.DeleteWebhook(ctx, webhookUUID); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to delete webhook", map[string]interface{}{
+			"error":      err.Error(),
+			"webhook_id": webhookID,
+		})
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Webhook deleted successfully", map[string]interface{}{
+		"webhook_id": webhookID,
+	})
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sm8ta/webike_bike_microservice_nikita/internal/core/domain"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+func TestCreateWebhookHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		allowed bool
+	}{
+		{name: "доменное имя", url: "https://hooks.example.com/bikes", allowed: true},
+		{name: "IPv4", url: "https://93.184.216.34/hook"},
+		{name: "метаданные облака", url: "https://169.254.169.254/latest/meta-data"},
+		{name: "IPv6", url: "https://[::1]:8443/hook"},
+		{name: "IPv4-mapped IPv6", url: "https://[::ffff:10.0.0.1]/hook"},
+		{name: "localhost", url: "https://localhost:8080/hook"},
+		{name: "localhost с точкой и в верхнем регистре", url: "https://LOCALHOST./hook"},
+		{name: "поддомен localhost", url: "https://api.localhost/hook"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			service := NewWebhookService(env.store, env.logger, validator.New(), true)
+			webhook := &domain.Webhook{
+				UserID:     uuid.New(),
+				URL:        tt.url,
+				EventTypes: []domain.EventType{domain.BikeCreated},
+				Secret:     "webhook-secret-0123456789",
+			}
+			_, err := service.CreateWebhook(context.Background(), webhook)
+			if tt.allowed && err != nil {
+				t.Errorf("CreateWebhook(%s) = %v, want created", tt.url, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrInvalidWebhook) {
+				t.Errorf("CreateWebhook(%s) = %v, want ErrInvalidWebhook", tt.url, err)
+			}
+		})
+	}
+}